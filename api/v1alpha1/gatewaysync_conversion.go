@@ -0,0 +1,174 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/ia-eknorr/stoker-operator/api/v1beta1"
+)
+
+// conversionDataAnnotation holds the v1alpha1 values v1beta1 cannot
+// represent exactly, so converting to v1beta1 and back is lossless.
+const conversionDataAnnotation = "stoker.io/v1alpha1-conversion"
+
+// conversionData is the content of conversionDataAnnotation.
+type conversionData struct {
+	// Durations maps a field path to the v1alpha1 string and the string it
+	// converts back to from v1beta1. The original is restored only while the
+	// field still holds that converted value.
+	Durations map[string]convertedDuration `json:"durations,omitempty"`
+
+	// DefaultsPaused records spec.sync.defaults.paused=true, which v1beta1
+	// expresses as paused=true on each profile that did not override it.
+	DefaultsPaused bool `json:"defaultsPaused,omitempty"`
+	// InheritedPaused lists the profiles that inherited it.
+	InheritedPaused []string `json:"inheritedPaused,omitempty"`
+}
+
+type convertedDuration struct {
+	Original  string `json:"original"`
+	Converted string `json:"converted"`
+}
+
+// ConvertTo converts this GatewaySync to the v1beta1 hub.
+//
+// The two versions share their JSON shape apart from the fields listed in
+// the v1beta1 package doc, so everything is copied through JSON and only
+// those fields are handled here.
+func (src *GatewaySync) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.GatewaySync)
+	in := src.DeepCopy()
+	data := conversionData{}
+
+	for path, d := range durationFields(in) {
+		converted := "0s"
+		if parsed, err := time.ParseDuration(*d); err == nil {
+			converted = parsed.String()
+		}
+		if converted != *d {
+			if data.Durations == nil {
+				data.Durations = map[string]convertedDuration{}
+			}
+			data.Durations[path] = convertedDuration{Original: *d, Converted: converted}
+			*d = converted
+		}
+	}
+
+	if err := copyJSON(&in.ObjectMeta, &dst.ObjectMeta); err != nil {
+		return err
+	}
+	if err := copyJSON(&in.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := copyJSON(&in.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	if in.Spec.Sync.Defaults.Paused {
+		data.DefaultsPaused = true
+		for name, p := range dst.Spec.Sync.Profiles {
+			if p.Paused == nil {
+				paused := true
+				p.Paused = &paused
+				dst.Spec.Sync.Profiles[name] = p
+				data.InheritedPaused = append(data.InheritedPaused, name)
+			}
+		}
+	}
+
+	delete(dst.Annotations, conversionDataAnnotation)
+	if data.Durations != nil || data.DefaultsPaused {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("encoding %s: %w", conversionDataAnnotation, err)
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[conversionDataAnnotation] = string(raw)
+	}
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+	return nil
+}
+
+// ConvertFrom converts the v1beta1 hub to this version, restoring the
+// values recorded by ConvertTo.
+func (dst *GatewaySync) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.GatewaySync)
+
+	if err := copyJSON(&src.ObjectMeta, &dst.ObjectMeta); err != nil {
+		return err
+	}
+	if err := copyJSON(&src.Spec, &dst.Spec); err != nil {
+		return err
+	}
+	if err := copyJSON(&src.Status, &dst.Status); err != nil {
+		return err
+	}
+
+	raw, ok := dst.Annotations[conversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(dst.Annotations, conversionDataAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+	var data conversionData
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return fmt.Errorf("decoding %s: %w", conversionDataAnnotation, err)
+	}
+
+	fields := durationFields(dst)
+	for path, d := range data.Durations {
+		if f, ok := fields[path]; ok && *f == d.Converted {
+			*f = d.Original
+		}
+	}
+	if data.DefaultsPaused {
+		dst.Spec.Sync.Defaults.Paused = true
+		for _, name := range data.InheritedPaused {
+			if p, ok := dst.Spec.Sync.Profiles[name]; ok && p.Paused != nil && *p.Paused {
+				p.Paused = nil
+				dst.Spec.Sync.Profiles[name] = p
+			}
+		}
+	}
+	return nil
+}
+
+// durationFields returns the duration strings that are metav1.Duration in
+// v1beta1, keyed by field path. Optional fields are left out while empty,
+// since they are omitted rather than converted.
+func durationFields(gs *GatewaySync) map[string]*string {
+	fields := map[string]*string{}
+	if gs.Spec.Polling.Interval != "" {
+		fields["spec.polling.interval"] = &gs.Spec.Polling.Interval
+	}
+	if b := gs.Spec.Gateway.Backup; b != nil && b.Retention != nil && b.Retention.MaxAge != "" {
+		fields["spec.gateway.backup.retention.maxAge"] = &b.Retention.MaxAge
+	}
+	if rb := gs.Spec.Rollback; rb != nil && rb.Window != "" {
+		fields["spec.rollback.window"] = &rb.Window
+	}
+	if s := gs.Spec.Sync.Schedule; s != nil {
+		for i := range s.Windows {
+			fields[fmt.Sprintf("spec.sync.schedule.windows[%d].duration", i)] = &s.Windows[i].Duration
+		}
+	}
+	return fields
+}
+
+// copyJSON copies in to out through their JSON encoding.
+func copyJSON(in, out any) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
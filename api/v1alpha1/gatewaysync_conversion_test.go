@@ -0,0 +1,171 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/ia-eknorr/stoker-operator/api/v1beta1"
+)
+
+func testGatewaySync() *GatewaySync {
+	return &GatewaySync{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "default",
+			Annotations: map[string]string{"team": "controls"},
+		},
+		Spec: GatewaySyncSpec{
+			Git:     GitSpec{Repo: "https://example.com/repo.git", Ref: "main"},
+			Polling: PollingSpec{Interval: "1m0s"},
+			Gateway: GatewaySpec{
+				API: GatewayAPISpec{SecretName: "api-key"},
+				Backup: &GatewayBackupSpec{
+					ClaimName: "backups",
+					Retention: &BackupRetention{MaxCount: 5, MaxAge: "168h0m0s"},
+				},
+			},
+			Agent: AgentSpec{Image: AgentImageSpec{PullPolicy: "IfNotPresent"}},
+			Sync: SyncSpec{
+				Defaults: SyncDefaults{DesignerSessionPolicy: "wait"},
+				Profiles: map[string]SyncProfileSpec{
+					"default": {Mappings: []SyncMapping{{Source: "config/", Destination: "config/", Type: "dir"}}},
+				},
+				ProfileRefs: []ProfileRef{{Kind: KindClusterSyncProfile, Name: "standard-site", As: "site"}},
+				Schedule: &SyncSchedule{
+					Windows: []ScheduleWindow{{Start: "0 2 * * *", Duration: "4h0m0s"}},
+				},
+			},
+			Rollback: &RollbackSpec{Window: "10m0s"},
+		},
+		Status: GatewaySyncStatus{
+			LastSyncCommit: "abc123",
+			NextWindow:     &metav1.Time{Time: time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func roundTrip(t *testing.T, in *GatewaySync) (*v1beta1.GatewaySync, *GatewaySync) {
+	t.Helper()
+	hub := &v1beta1.GatewaySync{}
+	if err := in.DeepCopy().ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo: %v", err)
+	}
+	out := &GatewaySync{}
+	if err := out.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom: %v", err)
+	}
+	return hub, out
+}
+
+func TestConversion_RoundTrip(t *testing.T) {
+	in := testGatewaySync()
+	hub, out := roundTrip(t, in)
+
+	if !equality.Semantic.DeepEqual(in, out) {
+		t.Errorf("round trip changed the object:\n in: %+v\nout: %+v", in, out)
+	}
+	if _, ok := hub.Annotations[conversionDataAnnotation]; ok {
+		t.Errorf("canonical object should not need %s", conversionDataAnnotation)
+	}
+	if hub.Spec.Polling.Interval == nil || hub.Spec.Polling.Interval.Duration != time.Minute {
+		t.Errorf("expected polling interval 1m, got %v", hub.Spec.Polling.Interval)
+	}
+	if hub.Spec.Sync.Schedule.Windows[0].Duration.Duration != 4*time.Hour {
+		t.Errorf("expected window duration 4h, got %v", hub.Spec.Sync.Schedule.Windows[0].Duration)
+	}
+	if hub.Spec.Sync.ProfileRefs[0].Kind != v1beta1.ProfileRefClusterSyncProfile {
+		t.Errorf("expected profile ref kind ClusterSyncProfile, got %q", hub.Spec.Sync.ProfileRefs[0].Kind)
+	}
+	if hub.Spec.Sync.Profiles["default"].Mappings[0].Type != v1beta1.MappingTypeDir {
+		t.Errorf("expected mapping type dir, got %q", hub.Spec.Sync.Profiles["default"].Mappings[0].Type)
+	}
+}
+
+func TestConversion_NonCanonicalDurations(t *testing.T) {
+	in := testGatewaySync()
+	in.Spec.Polling.Interval = "5m"
+	in.Spec.Rollback.Window = "90s"
+	in.Spec.Gateway.Backup.Retention.MaxAge = "not-a-duration"
+	in.Spec.Sync.Schedule.Windows[0].Duration = "4h"
+
+	hub, out := roundTrip(t, in)
+
+	if !equality.Semantic.DeepEqual(in, out) {
+		t.Errorf("round trip changed the object:\n in: %+v\nout: %+v", in.Spec, out.Spec)
+	}
+	if hub.Spec.Polling.Interval.Duration != 5*time.Minute {
+		t.Errorf("expected polling interval 5m, got %v", hub.Spec.Polling.Interval)
+	}
+	if hub.Spec.Gateway.Backup.Retention.MaxAge.Duration != 0 {
+		t.Errorf("expected an invalid maxAge to convert to 0s, got %v", hub.Spec.Gateway.Backup.Retention.MaxAge)
+	}
+	if _, ok := hub.Annotations[conversionDataAnnotation]; !ok {
+		t.Errorf("expected %s on the hub", conversionDataAnnotation)
+	}
+}
+
+func TestConversion_ChangedDurationIsNotRestored(t *testing.T) {
+	in := testGatewaySync()
+	in.Spec.Polling.Interval = "5m"
+
+	hub := &v1beta1.GatewaySync{}
+	if err := in.ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo: %v", err)
+	}
+	hub.Spec.Polling.Interval = &metav1.Duration{Duration: 10 * time.Minute}
+	out := &GatewaySync{}
+	if err := out.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom: %v", err)
+	}
+	if out.Spec.Polling.Interval != "10m0s" {
+		t.Errorf("expected the v1beta1 edit to win, got %q", out.Spec.Polling.Interval)
+	}
+}
+
+func TestConversion_DefaultsPaused(t *testing.T) {
+	in := testGatewaySync()
+	in.Spec.Sync.Defaults.Paused = true
+	in.Spec.Sync.Profiles["canary"] = SyncProfileSpec{
+		Mappings: []SyncMapping{{Source: "canary/", Destination: "config/"}},
+		Paused:   ptr.To(false),
+	}
+
+	hub, out := roundTrip(t, in)
+
+	if p := hub.Spec.Sync.Profiles["default"].Paused; p == nil || !*p {
+		t.Errorf("expected default profile to inherit paused, got %v", p)
+	}
+	if p := hub.Spec.Sync.Profiles["canary"].Paused; p == nil || *p {
+		t.Errorf("expected canary override to be kept, got %v", p)
+	}
+	if !equality.Semantic.DeepEqual(in, out) {
+		t.Errorf("round trip changed the object:\n in: %+v\nout: %+v", in.Spec.Sync, out.Spec.Sync)
+	}
+}
+
+func TestConversion_HubRoundTrip(t *testing.T) {
+	hub := &v1beta1.GatewaySync{}
+	if err := testGatewaySync().ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo: %v", err)
+	}
+	hub.Spec.Sync.Profiles["default"] = v1beta1.SyncProfileSpec{
+		Mappings: []v1beta1.SyncMapping{{Source: "a/", Destination: "b/", Type: v1beta1.MappingTypeFile}},
+		Paused:   ptr.To(true),
+	}
+
+	spoke := &GatewaySync{}
+	if err := spoke.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom: %v", err)
+	}
+	back := &v1beta1.GatewaySync{}
+	if err := spoke.ConvertTo(back); err != nil {
+		t.Fatalf("ConvertTo: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(hub, back) {
+		t.Errorf("round trip changed the hub:\n in: %+v\nout: %+v", hub, back)
+	}
+}
@@ -3,6 +3,7 @@ package v1alpha1
 import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // ============================================================
@@ -22,6 +23,19 @@ type SecretKeyRef struct {
 	Key string `json:"key"`
 }
 
+// ConfigMapKeyRef references a key within a Kubernetes ConfigMap.
+type ConfigMapKeyRef struct {
+	// name is the name of the ConfigMap in the same namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// key is the key within the ConfigMap data.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
 // ============================================================
 // Git
 // ============================================================
@@ -35,12 +49,38 @@ type GitSpec struct {
 
 	// ref is the git reference to sync — tag, branch, or commit SHA.
 	// Typically managed by Kargo or a webhook.
+	// A ref expression tracks the highest matching tag instead:
+	// "semver:<constraint>" (e.g. "semver:~2.3", "semver:>=1.0.0 <2.0.0")
+	// or "glob:<pattern>" (e.g. "glob:release-*").
 	// +kubebuilder:validation:Required
 	Ref string `json:"ref"`
 
 	// auth configures git authentication. Exactly one method should be specified.
 	// +optional
 	Auth *GitAuthSpec `json:"auth,omitempty"`
+
+	// verification requires the synced commit or tag to be signed by a trusted key.
+	// The controller will not publish, and agents will not sync, an unverified commit.
+	// +optional
+	Verification *GitVerificationSpec `json:"verification,omitempty"`
+}
+
+// GitVerificationSpec configures commit signature verification.
+type GitVerificationSpec struct {
+	// policy selects what must be signed. SignedCommit requires the resolved
+	// commit to be signed; SignedTag requires ref to be an annotated tag whose
+	// signature is trusted.
+	// +kubebuilder:validation:Enum=SignedCommit;SignedTag
+	// +kubebuilder:default="SignedCommit"
+	// +optional
+	Policy string `json:"policy,omitempty"`
+
+	// trustedKeysSecretName is the name of a Secret whose values contain the
+	// trusted signing keys: ASCII-armored GPG public keys and/or SSH public
+	// keys (one per line, authorized_keys or allowed_signers format).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	TrustedKeysSecretName string `json:"trustedKeysSecretName"`
 }
 
 // GitAuthSpec selects one git authentication method.
@@ -134,8 +174,87 @@ type GatewaySpec struct {
 	// +optional
 	TLS *bool `json:"tls,omitempty"`
 
+	// tlsTrust configures how the agent verifies the gateway's TLS certificate
+	// when tls is enabled. When omitted, the certificate is verified against the
+	// system roots of the agent image.
+	// +optional
+	TLSTrust *GatewayTLSTrust `json:"tlsTrust,omitempty"`
+
 	// api configures the Ignition gateway API key secret.
 	API GatewayAPISpec `json:"api"`
+
+	// backup configures a gateway backup (.gwbk) captured before the agent
+	// applies changes to config paths.
+	// +optional
+	Backup *GatewayBackupSpec `json:"backup,omitempty"`
+}
+
+// GatewayBackupSpec configures pre-change gateway backups.
+type GatewayBackupSpec struct {
+	// enabled turns on pre-change backups.
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// claimName is the PersistentVolumeClaim the agent stores backups on.
+	// The claim must be mountable by every gateway pod using this GatewaySync.
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// paths are destination path prefixes (relative to the gateway data directory)
+	// that trigger a backup when a sync would add, modify, or delete files under them.
+	// +kubebuilder:default={"config"}
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+
+	// retention limits how many backups are kept per gateway.
+	// +optional
+	Retention *BackupRetention `json:"retention,omitempty"`
+}
+
+// BackupRetention prunes old backups by count and/or age.
+type BackupRetention struct {
+	// maxCount is the number of most recent backups kept per gateway.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxCount int32 `json:"maxCount,omitempty"`
+
+	// maxAge removes backups older than this duration (e.g., "720h").
+	// +kubebuilder:validation:Pattern=`^([0-9]+(\.[0-9]+)?(ns|us|ms|s|m|h))+$`
+	// +optional
+	MaxAge string `json:"maxAge,omitempty"`
+}
+
+// GatewayTLSTrust configures gateway certificate verification for the agent.
+// +kubebuilder:validation:XValidation:rule="!(has(self.insecureSkipVerify) && self.insecureSkipVerify && has(self.caBundle))",message="caBundle and insecureSkipVerify are mutually exclusive"
+type GatewayTLSTrust struct {
+	// caBundle references PEM-encoded CA certificates used to verify the gateway certificate.
+	// +optional
+	CABundle *CABundleSource `json:"caBundle,omitempty"`
+
+	// serverName is the name verified against the gateway certificate.
+	// Defaults to "localhost", the address the agent dials.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// insecureSkipVerify disables gateway certificate verification entirely.
+	// This is an explicit opt-in; the controller reports GatewayTLSVerification=False
+	// while it is set.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// CABundleSource selects the Secret or ConfigMap key holding a PEM CA bundle.
+// +kubebuilder:validation:XValidation:rule="has(self.secretRef) != has(self.configMapRef)",message="exactly one of secretRef or configMapRef must be set"
+type CABundleSource struct {
+	// secretRef points to a Secret key containing the CA bundle.
+	// +optional
+	SecretRef *SecretKeyRef `json:"secretRef,omitempty"`
+
+	// configMapRef points to a ConfigMap key containing the CA bundle.
+	// +optional
+	ConfigMapRef *ConfigMapKeyRef `json:"configMapRef,omitempty"`
 }
 
 // GatewayAPISpec references the Secret containing the Ignition API key.
@@ -164,6 +283,85 @@ type AgentSpec struct {
 	// resources configures the agent container resource requirements.
 	// +optional
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// syncHistoryLimit is the number of recent sync records each agent keeps
+	// in its status. The full history is served by the controller's
+	// GET /history endpoint; status.discoveredGateways[].recentSyncs shows the
+	// latest few.
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	SyncHistoryLimit int32 `json:"syncHistoryLimit,omitempty"`
+
+	// audit configures sinks that receive one structured JSON record per sync
+	// (commit, author, gateway, profile, changed files, scan result, duration).
+	// +optional
+	Audit *AuditSpec `json:"audit,omitempty"`
+}
+
+// AuditSpec configures the agent's audit record sinks. Any combination may
+// be enabled; a failing sink is logged and does not fail the sync.
+type AuditSpec struct {
+	// stdout writes audit records as JSON lines to the agent's stdout.
+	// +optional
+	Stdout bool `json:"stdout,omitempty"`
+
+	// file appends audit records to a size-rotated file on a PersistentVolumeClaim.
+	// +optional
+	File *AuditFileSink `json:"file,omitempty"`
+
+	// syslog sends audit records as RFC 5424 messages.
+	// +optional
+	Syslog *AuditSyslogSink `json:"syslog,omitempty"`
+
+	// http POSTs each audit record as JSON (e.g. to a SIEM HTTP collector).
+	// +optional
+	HTTP *AuditHTTPSink `json:"http,omitempty"`
+}
+
+// AuditFileSink writes audit records to {pod name}.log on a PersistentVolumeClaim.
+type AuditFileSink struct {
+	// claimName is the PersistentVolumeClaim the agent writes audit logs to.
+	// The claim must be mountable by every gateway pod using this GatewaySync.
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// maxSizeMB rotates the audit file when it would exceed this size.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxSizeMB int32 `json:"maxSizeMB,omitempty"`
+
+	// maxBackups is the number of rotated files kept per pod.
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxBackups int32 `json:"maxBackups,omitempty"`
+}
+
+// AuditSyslogSink sends audit records to a syslog receiver.
+type AuditSyslogSink struct {
+	// address is the host:port of the syslog receiver.
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+
+	// protocol is the transport. TCP uses octet-counting framing (RFC 6587).
+	// +kubebuilder:default="udp"
+	// +kubebuilder:validation:Enum=udp;tcp
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// AuditHTTPSink POSTs audit records to an HTTP endpoint.
+type AuditHTTPSink struct {
+	// url receives one POST per audit record with a JSON body.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// tokenSecretRef references a bearer token sent in the Authorization header.
+	// +optional
+	TokenSecretRef *SecretKeyRef `json:"tokenSecretRef,omitempty"`
 }
 
 // AgentImageSpec configures the agent container image.
@@ -188,6 +386,7 @@ type AgentImageSpec struct {
 // ============================================================
 
 // SyncSpec configures file sync behavior and profiles.
+// +kubebuilder:validation:XValidation:rule="(has(self.profiles) && size(self.profiles) > 0) || (has(self.profileRefs) && size(self.profileRefs) > 0)",message="at least one of profiles or profileRefs must be set"
 type SyncSpec struct {
 	// defaults provides baseline settings inherited by all profiles unless overridden.
 	// +optional
@@ -196,8 +395,102 @@ type SyncSpec struct {
 	// profiles is a named map of sync profiles. Each profile defines mappings
 	// and optional behavioral overrides. Pods select a profile via the
 	// stoker.io/profile annotation. The "default" profile is used as fallback.
-	// +kubebuilder:validation:MinProperties=1
-	Profiles map[string]SyncProfileSpec `json:"profiles"`
+	// An inline profile takes precedence over a profileRefs entry of the same name.
+	// +optional
+	Profiles map[string]SyncProfileSpec `json:"profiles,omitempty"`
+
+	// profileRefs adds profiles from the shared SyncProfile (same namespace)
+	// and ClusterSyncProfile library. Defaults apply to them as to inline
+	// profiles.
+	// +listType=map
+	// +listMapKey=name
+	// +listMapKey=kind
+	// +optional
+	ProfileRefs []ProfileRef `json:"profileRefs,omitempty"`
+
+	// schedule limits when new commits are published and applied. Outside
+	// its windows, or during a blackout, gateways keep their current commit.
+	// +optional
+	Schedule *SyncSchedule `json:"schedule,omitempty"`
+}
+
+// ProfileRef references a library profile.
+type ProfileRef struct {
+	// kind is SyncProfile (in the GatewaySync's namespace) or ClusterSyncProfile.
+	// +kubebuilder:validation:Enum=SyncProfile;ClusterSyncProfile
+	// +kubebuilder:default=SyncProfile
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// name is the name of the SyncProfile or ClusterSyncProfile.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// as is the profile name gateways select with the stoker.io/profile
+	// annotation. Defaults to name.
+	// +optional
+	As string `json:"as,omitempty"`
+}
+
+// ProfileName is the profile name gateways select for ref: as, or name when unset.
+func (ref ProfileRef) ProfileName() string {
+	if ref.As != "" {
+		return ref.As
+	}
+	return ref.Name
+}
+
+// ProfileKind is kind with the SyncProfile default applied.
+func (ref ProfileRef) ProfileKind() string {
+	if ref.Kind == "" {
+		return KindSyncProfile
+	}
+	return ref.Kind
+}
+
+// SyncSchedule defines maintenance windows and change freezes.
+type SyncSchedule struct {
+	// windows are the times new commits may be applied. When empty, any
+	// time outside a blackout is allowed.
+	// +optional
+	Windows []ScheduleWindow `json:"windows,omitempty"`
+
+	// timeZone is the IANA time zone for windows and blackout dates
+	// (e.g., "America/Chicago"). Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// blackouts are date ranges when no new commit is applied, even inside a window.
+	// +optional
+	Blackouts []ScheduleBlackout `json:"blackouts,omitempty"`
+}
+
+// ScheduleWindow is a recurring window that opens on a cron schedule.
+type ScheduleWindow struct {
+	// start is a standard 5-field cron expression for when the window opens
+	// (e.g., "0 6,14,22 * * *" for shift changes).
+	// +kubebuilder:validation:MinLength=1
+	Start string `json:"start"`
+
+	// duration is how long the window stays open (e.g., "30m").
+	// +kubebuilder:validation:MinLength=1
+	Duration string `json:"duration"`
+}
+
+// ScheduleBlackout is a change freeze between two dates or times.
+type ScheduleBlackout struct {
+	// start is the first day ("2026-12-24") or instant (RFC 3339) of the freeze.
+	// +kubebuilder:validation:MinLength=1
+	Start string `json:"start"`
+
+	// end is the last day ("2026-12-26", inclusive) or instant (RFC 3339,
+	// exclusive) of the freeze.
+	// +kubebuilder:validation:MinLength=1
+	End string `json:"end"`
+
+	// reason is shown in the Deferred condition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
 }
 
 // SyncDefaults provides baseline settings inherited by all profiles unless overridden.
@@ -332,6 +625,99 @@ type MappingPatch struct {
 	Set map[string]string `json:"set"`
 }
 
+// ============================================================
+// Rollout
+// ============================================================
+
+// RolloutSpec stages a new commit across gateways instead of publishing it
+// to all of them at once.
+type RolloutSpec struct {
+	// canary selects the gateways that receive a new commit first.
+	// +optional
+	Canary *RolloutCanary `json:"canary,omitempty"`
+
+	// waves follow the canary in order. Each wave starts only after every
+	// gateway in the previous steps reports Synced at the new commit.
+	// Gateways not covered by any wave are updated in a final step.
+	// +optional
+	Waves []RolloutWave `json:"waves,omitempty"`
+
+	// maxUnavailable limits how many gateways may be updating (not yet Synced
+	// at the new commit) at once, as a count or a percentage of all gateways.
+	// Unlimited when unset.
+	// +kubebuilder:validation:XIntOrString
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// haltOnError stops the rollout when an updated gateway reports Error.
+	// Gateways not yet updated stay on the previous commit until a new
+	// commit is resolved.
+	// +kubebuilder:default=true
+	// +optional
+	HaltOnError *bool `json:"haltOnError,omitempty"`
+}
+
+// RolloutCanary selects canary gateways by pod label or profile.
+// A gateway matching either is a canary.
+type RolloutCanary struct {
+	// selector matches gateway pod labels.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// profiles lists sync profile names whose gateways are canaries.
+	// +optional
+	Profiles []string `json:"profiles,omitempty"`
+}
+
+// RolloutWave is one step of a rollout. Exactly one of percent or selector
+// is set.
+// +kubebuilder:validation:XValidation:rule="has(self.percent) != has(self.selector)",message="exactly one of percent or selector must be set"
+type RolloutWave struct {
+	// name identifies the wave in status and events. Defaults to "wave-<n>".
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// percent is the cumulative share of all gateways updated once this
+	// wave completes, in gateway name order after the canaries.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percent *int32 `json:"percent,omitempty"`
+
+	// selector adds the gateways whose pod labels match.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// RollbackSpec pins gateways back to the last fully synced commit when too
+// many of them fail on a new commit.
+type RollbackSpec struct {
+	// errorThreshold is how many gateways, as a count or a percentage of all
+	// gateways, may report Error on a new commit before it is rolled back.
+	// The rollback triggers when the number of failing gateways exceeds it.
+	// +kubebuilder:default=0
+	// +kubebuilder:validation:XIntOrString
+	// +optional
+	ErrorThreshold *intstr.IntOrString `json:"errorThreshold,omitempty"`
+
+	// window is how long after a new commit is resolved its gateway errors
+	// count toward errorThreshold (e.g., "10m"). Later errors do not roll back.
+	// +kubebuilder:default="10m"
+	// +optional
+	Window string `json:"window,omitempty"`
+}
+
+// ApprovalSpec holds each newly resolved commit until a named approver
+// releases it with the stoker.io/approved-by and stoker.io/approved-commit
+// annotations.
+type ApprovalSpec struct {
+	// approvers restricts who may approve, by Kubernetes username. The
+	// validating webhook only admits a stoker.io/approved-by value naming the
+	// user who sets it. When empty, any user approves.
+	// +optional
+	Approvers []string `json:"approvers,omitempty"`
+}
+
 // ============================================================
 // Top-Level Spec
 // ============================================================
@@ -357,6 +743,30 @@ type GatewaySyncSpec struct {
 	// +optional
 	Agent AgentSpec `json:"agent,omitempty"`
 
+	// rollout stages new commits across gateways (canary, then waves).
+	// When unset, every gateway receives a new commit at once.
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+
+	// rollback pins gateways back to the last fully synced commit when a new
+	// commit fails on too many of them. The pin holds until acknowledged with
+	// the stoker.io/rollback-acknowledged annotation.
+	// +optional
+	Rollback *RollbackSpec `json:"rollback,omitempty"`
+
+	// approval holds new commits in status.pendingCommit until approved.
+	// Gateways keep the last approved commit meanwhile.
+	// +optional
+	Approval *ApprovalSpec `json:"approval,omitempty"`
+
+	// allowedNamespaces lists the namespaces whose gateway pods may bind to
+	// this GatewaySync from outside its namespace with the
+	// stoker.io/cr-namespace annotation. "*" allows every namespace when the
+	// operator enables it (webhook.validation.allowAllNamespaces).
+	// +listType=set
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
 	// paused halts all sync operations when set to true.
 	// +optional
 	Paused bool `json:"paused,omitempty"`
@@ -385,6 +795,11 @@ type DiscoveredGateway struct {
 	// +optional
 	Profile string `json:"profile,omitempty"`
 
+	// profileLibrary is the library profile version this gateway last synced
+	// with, as Kind/name@generation. Empty for inline profiles.
+	// +optional
+	ProfileLibrary string `json:"profileLibrary,omitempty"`
+
 	// syncStatus is the current sync state of this gateway.
 	// +kubebuilder:validation:Enum=Pending;Synced;Error;MissingSidecar
 	// +optional
@@ -394,6 +809,11 @@ type DiscoveredGateway struct {
 	// +optional
 	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
 
+	// targetCommit is the commit published to this gateway when it differs
+	// from lastSyncCommit during a staged rollout.
+	// +optional
+	TargetCommit string `json:"targetCommit,omitempty"`
+
 	// lastSyncDuration is how long the last sync took.
 	// +optional
 	LastSyncDuration string `json:"lastSyncDuration,omitempty"`
@@ -406,6 +826,15 @@ type DiscoveredGateway struct {
 	// +optional
 	SyncedRef string `json:"syncedRef,omitempty"`
 
+	// syncedCommitInfo describes the synced commit, as read from the agent's clone.
+	// +optional
+	SyncedCommitInfo *CommitInfo `json:"syncedCommitInfo,omitempty"`
+
+	// refOverride is the ref this gateway is pinned to by the
+	// stoker.io/ref-override pod annotation, instead of spec.git.ref.
+	// +optional
+	RefOverride string `json:"refOverride,omitempty"`
+
 	// agentVersion is the version of the sync agent on this gateway.
 	// +optional
 	AgentVersion string `json:"agentVersion,omitempty"`
@@ -421,6 +850,175 @@ type DiscoveredGateway struct {
 	// projectsSynced lists the Ignition project names synced to this gateway.
 	// +optional
 	ProjectsSynced []string `json:"projectsSynced,omitempty"`
+
+	// lastBackup is the file name of the most recent pre-change gateway backup.
+	// +optional
+	LastBackup string `json:"lastBackup,omitempty"`
+
+	// gatewayVersion is the Ignition version detected by the agent.
+	// +optional
+	GatewayVersion string `json:"gatewayVersion,omitempty"`
+
+	// recentSyncs is a condensed view of this gateway's latest syncs, newest first.
+	// +optional
+	RecentSyncs []SyncHistoryEntry `json:"recentSyncs,omitempty"`
+}
+
+// RolloutStatus reports the progress of a staged rollout.
+type RolloutStatus struct {
+	// commit is the commit being rolled out.
+	Commit string `json:"commit"`
+
+	// ref is the ref that resolved to commit.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// stableCommit is the previous fully rolled-out commit, which gateways
+	// keep until they are updated. Empty for the first commit.
+	// +optional
+	StableCommit string `json:"stableCommit,omitempty"`
+
+	// phase is Progressing, Halted, or Complete.
+	// +kubebuilder:validation:Enum=Progressing;Halted;Complete
+	Phase string `json:"phase"`
+
+	// step is the index of the current step: the canary (when configured),
+	// then each wave, then the final step covering all gateways.
+	// +optional
+	Step int32 `json:"step,omitempty"`
+
+	// stepName names the current step: "canary", a wave name, or "all".
+	// +optional
+	StepName string `json:"stepName,omitempty"`
+
+	// updatedGateways lists the gateways given the new commit so far.
+	// +optional
+	UpdatedGateways []string `json:"updatedGateways,omitempty"`
+
+	// message describes the current state.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// startTime is when the rollout of commit began.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+}
+
+// RollbackStatus describes an automatic rollback awaiting acknowledgement.
+type RollbackStatus struct {
+	// failedCommit is the commit that was rolled back.
+	FailedCommit string `json:"failedCommit"`
+
+	// commit is the last fully synced commit gateways were pinned back to.
+	Commit string `json:"commit"`
+
+	// failedGateways lists the gateways that reported Error on failedCommit.
+	// +optional
+	FailedGateways []string `json:"failedGateways,omitempty"`
+
+	// time is when the rollback happened.
+	Time metav1.Time `json:"time"`
+
+	// acknowledgedTime is when the rollback was acknowledged. An acknowledged
+	// rollback no longer pins gateways, and failedCommit is not rolled back
+	// again; the record is cleared once spec.git.ref resolves to a new commit.
+	// +optional
+	AcknowledgedTime *metav1.Time `json:"acknowledgedTime,omitempty"`
+}
+
+// PendingCommit is a resolved commit awaiting approval.
+type PendingCommit struct {
+	// commit is the full SHA awaiting approval.
+	Commit string `json:"commit"`
+
+	// ref is the ref that resolved to commit.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// commitInfo describes commit.
+	// +optional
+	CommitInfo *CommitInfo `json:"commitInfo,omitempty"`
+
+	// diff summarizes the changes from the published commit.
+	// +optional
+	Diff *DiffSummary `json:"diff,omitempty"`
+
+	// since is when the commit was first seen.
+	Since metav1.Time `json:"since"`
+}
+
+// DiffSummary summarizes the paths changed between two commits.
+type DiffSummary struct {
+	// added is the number of files added.
+	Added int32 `json:"added"`
+
+	// modified is the number of files modified.
+	Modified int32 `json:"modified"`
+
+	// deleted is the number of files deleted.
+	Deleted int32 `json:"deleted"`
+
+	// files lists up to 20 changed paths, each prefixed with "A ", "M ", or "D ".
+	// +optional
+	Files []string `json:"files,omitempty"`
+
+	// error is set when the diff could not be computed.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ApprovalRecord records who approved a commit.
+type ApprovalRecord struct {
+	// commit is the approved commit SHA.
+	Commit string `json:"commit"`
+
+	// approver is the stoker.io/approved-by value: the approving user's
+	// Kubernetes username.
+	Approver string `json:"approver"`
+
+	// time is when the approval was recorded.
+	Time metav1.Time `json:"time"`
+}
+
+// CommitInfo describes a git commit.
+type CommitInfo struct {
+	// author is the commit author's name.
+	// +optional
+	Author string `json:"author,omitempty"`
+
+	// committer is the committer's name. It differs from the author for
+	// commits merged or rebased through a git server UI.
+	// +optional
+	Committer string `json:"committer,omitempty"`
+
+	// subject is the first line of the commit message.
+	// +optional
+	Subject string `json:"subject,omitempty"`
+
+	// time is the commit (committer) time.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+}
+
+// SyncHistoryEntry summarizes one sync in a gateway's history.
+type SyncHistoryEntry struct {
+	// commit is the abbreviated git commit SHA synced (or attempted).
+	Commit string `json:"commit"`
+
+	// trigger is what started the sync (e.g. commit, profile-switch, resync:full).
+	// +optional
+	Trigger string `json:"trigger,omitempty"`
+
+	// result is the sync outcome (Pending, Synced, Error).
+	Result string `json:"result"`
+
+	// filesChanged is the number of files added, modified, or deleted.
+	// +optional
+	FilesChanged int32 `json:"filesChanged,omitempty"`
+
+	// time is when the sync finished.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
 }
 
 // GatewaySyncStatus defines the observed state of GatewaySync.
@@ -433,10 +1031,16 @@ type GatewaySyncStatus struct {
 	// +optional
 	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
 
-	// lastSyncRef is the git ref that was last synced.
+	// lastSyncRef is the git ref that was last synced. For a ref expression
+	// this is the chosen tag.
 	// +optional
 	LastSyncRef string `json:"lastSyncRef,omitempty"`
 
+	// refExpression is the spec.git.ref expression that selected lastSyncRef.
+	// Empty when spec.git.ref names a ref exactly.
+	// +optional
+	RefExpression string `json:"refExpression,omitempty"`
+
 	// lastSyncCommit is the git commit SHA that was last synced.
 	// +optional
 	LastSyncCommit string `json:"lastSyncCommit,omitempty"`
@@ -445,19 +1049,64 @@ type GatewaySyncStatus struct {
 	// +optional
 	LastSyncCommitShort string `json:"lastSyncCommitShort,omitempty"`
 
+	// lastSyncCommitInfo describes lastSyncCommit, fetched from the remote
+	// when the commit changes.
+	// +optional
+	LastSyncCommitInfo *CommitInfo `json:"lastSyncCommitInfo,omitempty"`
+
 	// refResolutionStatus indicates the state of git ref resolution.
 	// +kubebuilder:validation:Enum=NotResolved;Resolving;Resolved;Error
 	// +optional
 	RefResolutionStatus string `json:"refResolutionStatus,omitempty"`
 
-	// profileCount is the number of profiles defined in spec.sync.profiles.
+	// profileCount is the number of profiles published to gateways, inline
+	// and from spec.sync.profileRefs.
 	// +optional
 	ProfileCount int32 `json:"profileCount,omitempty"`
 
+	// profileLibrary lists the library profiles published from
+	// spec.sync.profileRefs and the version of each.
+	// +optional
+	ProfileLibrary []ProfileLibraryStatus `json:"profileLibrary,omitempty"`
+
 	// discoveredGateways lists all gateways discovered by the controller.
 	// +optional
 	DiscoveredGateways []DiscoveredGateway `json:"discoveredGateways,omitempty"`
 
+	// rollout reports the staged rollout of the latest commit when
+	// spec.rollout is set.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// lastFullySyncedCommit is the most recent commit every gateway following
+	// spec.git.ref reported Synced at. Automatic rollbacks pin back to it.
+	// +optional
+	LastFullySyncedCommit string `json:"lastFullySyncedCommit,omitempty"`
+
+	// rollback describes the latest automatic rollback. Gateways stay pinned
+	// back until it is acknowledged.
+	// +optional
+	Rollback *RollbackStatus `json:"rollback,omitempty"`
+
+	// pendingCommit is the resolved commit awaiting approval when
+	// spec.approval is set. lastSyncCommit stays the last approved commit.
+	// +optional
+	PendingCommit *PendingCommit `json:"pendingCommit,omitempty"`
+
+	// lastApproval records the most recent approval.
+	// +optional
+	LastApproval *ApprovalRecord `json:"lastApproval,omitempty"`
+
+	// deferredCommit is the resolved commit waiting for the next
+	// spec.sync.schedule window. lastSyncCommit stays the published commit.
+	// +optional
+	DeferredCommit string `json:"deferredCommit,omitempty"`
+
+	// nextWindow is when the next spec.sync.schedule window opens while a
+	// commit is deferred.
+	// +optional
+	NextWindow *metav1.Time `json:"nextWindow,omitempty"`
+
 	// conditions represent the current state of the GatewaySync resource.
 	// +listType=map
 	// +listMapKey=type
@@ -465,6 +1114,31 @@ type GatewaySyncStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// ProfileLibraryStatus is a library profile published to gateways.
+type ProfileLibraryStatus struct {
+	// profile is the profile name gateways select.
+	Profile string `json:"profile"`
+
+	// kind is SyncProfile or ClusterSyncProfile.
+	Kind string `json:"kind"`
+
+	// name is the name of the library object.
+	Name string `json:"name"`
+
+	// generation is the library object's metadata.generation published.
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+
+	// version identifies the published spec as Kind/name@generation; gateways
+	// report it in discoveredGateways[].profileLibrary.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// shadowed is true when an inline profile of the same name replaces it.
+	// +optional
+	Shadowed bool `json:"shadowed,omitempty"`
+}
+
 // ============================================================
 // Root Objects
 // ============================================================
@@ -478,7 +1152,10 @@ type GatewaySyncStatus struct {
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=`.status.conditions[?(@.type=="Ready")].message`
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Expression",type="string",JSONPath=`.status.refExpression`,priority=1
 // +kubebuilder:printcolumn:name="Commit",type="string",JSONPath=`.status.lastSyncCommitShort`,priority=1
+// +kubebuilder:printcolumn:name="Author",type="string",JSONPath=`.status.lastSyncCommitInfo.author`,priority=1
+// +kubebuilder:printcolumn:name="Subject",type="string",JSONPath=`.status.lastSyncCommitInfo.subject`,priority=1
 // +kubebuilder:printcolumn:name="Profiles",type="integer",JSONPath=`.status.profileCount`,priority=1
 // +kubebuilder:printcolumn:name="Last Sync",type="date",JSONPath=`.status.lastSyncTime`,priority=1
 
@@ -498,6 +1175,19 @@ type GatewaySync struct {
 	Status GatewaySyncStatus `json:"status,omitzero"`
 }
 
+// AllowsNamespace reports whether gateway pods in namespace may bind to gs.
+func (gs *GatewaySync) AllowsNamespace(namespace string) bool {
+	if namespace == gs.Namespace {
+		return true
+	}
+	for _, ns := range gs.Spec.AllowedNamespaces {
+		if ns == "*" || ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
 // +kubebuilder:object:root=true
 
 // GatewaySyncList contains a list of GatewaySync.
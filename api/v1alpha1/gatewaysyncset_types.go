@@ -0,0 +1,172 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GatewaySyncSetSpec defines the desired state of GatewaySyncSet.
+type GatewaySyncSetSpec struct {
+	// namespaceSelector selects the namespaces that receive a GatewaySync.
+	// An empty selector matches every namespace.
+	// +required
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// template is the GatewaySync created in each selected namespace.
+	// +required
+	Template GatewaySyncTemplate `json:"template"`
+
+	// parameters are vars read from each selected namespace's labels or
+	// annotations and merged into the child's spec.sync.defaults.vars.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Parameters []NamespaceParameter `json:"parameters,omitempty"`
+
+	// namespaceVars sets vars for individual namespaces, keyed by namespace name.
+	// These override parameters and the template's defaults.vars.
+	// +optional
+	NamespaceVars map[string]map[string]string `json:"namespaceVars,omitempty"`
+}
+
+// GatewaySyncTemplate is the GatewaySync stamped out by a GatewaySyncSet.
+type GatewaySyncTemplate struct {
+	// metadata sets the child's name, labels and annotations.
+	// +optional
+	Metadata GatewaySyncTemplateMeta `json:"metadata,omitzero"`
+
+	// spec is the child GatewaySync spec. Namespace parameters are merged into
+	// spec.sync.defaults.vars.
+	// +required
+	Spec GatewaySyncSpec `json:"spec"`
+}
+
+// GatewaySyncTemplateMeta is the metadata copied to each child GatewaySync.
+type GatewaySyncTemplateMeta struct {
+	// name of the child GatewaySync. Defaults to the GatewaySyncSet name.
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// labels added to the child GatewaySync.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// annotations added to the child GatewaySync.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// NamespaceParameter maps a namespace label or annotation to a template var.
+// +kubebuilder:validation:XValidation:rule="has(self.fromLabel) != has(self.fromAnnotation)",message="exactly one of fromLabel or fromAnnotation must be set"
+type NamespaceParameter struct {
+	// name of the var in spec.sync.defaults.vars.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	// +required
+	Name string `json:"name"`
+
+	// fromLabel is the namespace label key to read.
+	// +optional
+	FromLabel string `json:"fromLabel,omitempty"`
+
+	// fromAnnotation is the namespace annotation key to read.
+	// +optional
+	FromAnnotation string `json:"fromAnnotation,omitempty"`
+
+	// default is used when the namespace does not carry the key. A parameter
+	// without a default is left unset in that namespace.
+	// +optional
+	Default string `json:"default,omitempty"`
+}
+
+// GatewaySyncSetStatus defines the observed state of GatewaySyncSet.
+type GatewaySyncSetStatus struct {
+	// observedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// namespaces is the number of selected namespaces.
+	// +optional
+	Namespaces int32 `json:"namespaces,omitempty"`
+
+	// readyNamespaces is the number of children with Ready=True.
+	// +optional
+	ReadyNamespaces int32 `json:"readyNamespaces,omitempty"`
+
+	// syncedNamespaces is the number of children with AllGatewaysSynced=True.
+	// +optional
+	SyncedNamespaces int32 `json:"syncedNamespaces,omitempty"`
+
+	// children reports each child GatewaySync, ordered by namespace.
+	// +optional
+	Children []GatewaySyncSetChild `json:"children,omitempty"`
+
+	// conditions aggregate the children's Ready and AllGatewaysSynced conditions.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// GatewaySyncSetChild is the observed state of one child GatewaySync.
+type GatewaySyncSetChild struct {
+	// namespace of the child.
+	Namespace string `json:"namespace"`
+
+	// name of the child.
+	Name string `json:"name"`
+
+	// ready is the child's Ready condition status (True, False or Unknown).
+	// +optional
+	Ready metav1.ConditionStatus `json:"ready,omitempty"`
+
+	// allGatewaysSynced is the child's AllGatewaysSynced condition status.
+	// +optional
+	AllGatewaysSynced metav1.ConditionStatus `json:"allGatewaysSynced,omitempty"`
+
+	// commit is the child's last synced commit (short SHA).
+	// +optional
+	Commit string `json:"commit,omitempty"`
+
+	// message is the child's Ready message, or why the child could not be applied.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=gss
+// +kubebuilder:printcolumn:name="Namespaces",type="integer",JSONPath=`.status.namespaces`
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=`.status.readyNamespaces`
+// +kubebuilder:printcolumn:name="Synced",type="integer",JSONPath=`.status.syncedNamespaces`
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=`.status.conditions[?(@.type=="Ready")].message`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+
+// GatewaySyncSet creates a GatewaySync in every namespace matching a label
+// selector and aggregates their status into one fleet status.
+type GatewaySyncSet struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of GatewaySyncSet.
+	// +required
+	Spec GatewaySyncSetSpec `json:"spec"`
+
+	// status defines the observed state of GatewaySyncSet.
+	// +optional
+	Status GatewaySyncSetStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// GatewaySyncSetList contains a list of GatewaySyncSet.
+type GatewaySyncSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []GatewaySyncSet `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GatewaySyncSet{}, &GatewaySyncSetList{})
+}
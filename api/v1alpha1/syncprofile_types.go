@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds a ProfileRef can point to.
+const (
+	// KindSyncProfile is a SyncProfile in the referencing GatewaySync's namespace.
+	KindSyncProfile = "SyncProfile"
+	// KindClusterSyncProfile is a cluster-scoped ClusterSyncProfile.
+	KindClusterSyncProfile = "ClusterSyncProfile"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=sp
+// +kubebuilder:printcolumn:name="Generation",type="integer",JSONPath=`.metadata.generation`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+
+// SyncProfile is a sync profile shared by the GatewaySyncs in its namespace
+// through spec.sync.profileRefs.
+type SyncProfile struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec is the profile, as in GatewaySync spec.sync.profiles.
+	// +required
+	Spec SyncProfileSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// SyncProfileList contains a list of SyncProfile.
+type SyncProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []SyncProfile `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=csp
+// +kubebuilder:printcolumn:name="Generation",type="integer",JSONPath=`.metadata.generation`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+
+// ClusterSyncProfile is a sync profile shared by GatewaySyncs in every
+// namespace through spec.sync.profileRefs.
+type ClusterSyncProfile struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec is the profile, as in GatewaySync spec.sync.profiles.
+	// +required
+	Spec SyncProfileSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterSyncProfileList contains a list of ClusterSyncProfile.
+type ClusterSyncProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []ClusterSyncProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SyncProfile{}, &SyncProfileList{}, &ClusterSyncProfile{}, &ClusterSyncProfileList{})
+}
@@ -11,6 +11,7 @@ import (
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -37,6 +38,11 @@ func (in *AgentSpec) DeepCopyInto(out *AgentSpec) {
 		*out = new(v1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentSpec.
@@ -49,6 +55,274 @@ func (in *AgentSpec) DeepCopy() *AgentSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalRecord) DeepCopyInto(out *ApprovalRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalRecord.
+func (in *ApprovalRecord) DeepCopy() *ApprovalRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApprovalSpec) DeepCopyInto(out *ApprovalSpec) {
+	*out = *in
+	if in.Approvers != nil {
+		in, out := &in.Approvers, &out.Approvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApprovalSpec.
+func (in *ApprovalSpec) DeepCopy() *ApprovalSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApprovalSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditFileSink) DeepCopyInto(out *AuditFileSink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditFileSink.
+func (in *AuditFileSink) DeepCopy() *AuditFileSink {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditFileSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditHTTPSink) DeepCopyInto(out *AuditHTTPSink) {
+	*out = *in
+	if in.TokenSecretRef != nil {
+		in, out := &in.TokenSecretRef, &out.TokenSecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditHTTPSink.
+func (in *AuditHTTPSink) DeepCopy() *AuditHTTPSink {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditHTTPSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditSpec) DeepCopyInto(out *AuditSpec) {
+	*out = *in
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(AuditFileSink)
+		**out = **in
+	}
+	if in.Syslog != nil {
+		in, out := &in.Syslog, &out.Syslog
+		*out = new(AuditSyslogSink)
+		**out = **in
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(AuditHTTPSink)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditSpec.
+func (in *AuditSpec) DeepCopy() *AuditSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditSyslogSink) DeepCopyInto(out *AuditSyslogSink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditSyslogSink.
+func (in *AuditSyslogSink) DeepCopy() *AuditSyslogSink {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditSyslogSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetention) DeepCopyInto(out *BackupRetention) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetention.
+func (in *BackupRetention) DeepCopy() *BackupRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleSource) DeepCopyInto(out *CABundleSource) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(SecretKeyRef)
+		**out = **in
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(ConfigMapKeyRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleSource.
+func (in *CABundleSource) DeepCopy() *CABundleSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSyncProfile) DeepCopyInto(out *ClusterSyncProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSyncProfile.
+func (in *ClusterSyncProfile) DeepCopy() *ClusterSyncProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSyncProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterSyncProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSyncProfileList) DeepCopyInto(out *ClusterSyncProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterSyncProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSyncProfileList.
+func (in *ClusterSyncProfileList) DeepCopy() *ClusterSyncProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSyncProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterSyncProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommitInfo) DeepCopyInto(out *CommitInfo) {
+	*out = *in
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommitInfo.
+func (in *CommitInfo) DeepCopy() *CommitInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(CommitInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapKeyRef) DeepCopyInto(out *ConfigMapKeyRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapKeyRef.
+func (in *ConfigMapKeyRef) DeepCopy() *ConfigMapKeyRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapKeyRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiffSummary) DeepCopyInto(out *DiffSummary) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiffSummary.
+func (in *DiffSummary) DeepCopy() *DiffSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(DiffSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiscoveredGateway) DeepCopyInto(out *DiscoveredGateway) {
 	*out = *in
@@ -56,11 +330,23 @@ func (in *DiscoveredGateway) DeepCopyInto(out *DiscoveredGateway) {
 		in, out := &in.LastSyncTime, &out.LastSyncTime
 		*out = (*in).DeepCopy()
 	}
+	if in.SyncedCommitInfo != nil {
+		in, out := &in.SyncedCommitInfo, &out.SyncedCommitInfo
+		*out = new(CommitInfo)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ProjectsSynced != nil {
 		in, out := &in.ProjectsSynced, &out.ProjectsSynced
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.RecentSyncs != nil {
+		in, out := &in.RecentSyncs, &out.RecentSyncs
+		*out = make([]SyncHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiscoveredGateway.
@@ -88,6 +374,36 @@ func (in *GatewayAPISpec) DeepCopy() *GatewayAPISpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayBackupSpec) DeepCopyInto(out *GatewayBackupSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(BackupRetention)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayBackupSpec.
+func (in *GatewayBackupSpec) DeepCopy() *GatewayBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GatewaySpec) DeepCopyInto(out *GatewaySpec) {
 	*out = *in
@@ -96,272 +412,820 @@ func (in *GatewaySpec) DeepCopyInto(out *GatewaySpec) {
 		*out = new(bool)
 		**out = **in
 	}
-	out.API = in.API
+	if in.TLSTrust != nil {
+		in, out := &in.TLSTrust, &out.TLSTrust
+		*out = new(GatewayTLSTrust)
+		(*in).DeepCopyInto(*out)
+	}
+	out.API = in.API
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(GatewayBackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySpec.
+func (in *GatewaySpec) DeepCopy() *GatewaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySync) DeepCopyInto(out *GatewaySync) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySync.
+func (in *GatewaySync) DeepCopy() *GatewaySync {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySync)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GatewaySync) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncList) DeepCopyInto(out *GatewaySyncList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GatewaySync, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncList.
+func (in *GatewaySyncList) DeepCopy() *GatewaySyncList {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GatewaySyncList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncSet) DeepCopyInto(out *GatewaySyncSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncSet.
+func (in *GatewaySyncSet) DeepCopy() *GatewaySyncSet {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GatewaySyncSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncSetChild) DeepCopyInto(out *GatewaySyncSetChild) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncSetChild.
+func (in *GatewaySyncSetChild) DeepCopy() *GatewaySyncSetChild {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncSetChild)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncSetList) DeepCopyInto(out *GatewaySyncSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GatewaySyncSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncSetList.
+func (in *GatewaySyncSetList) DeepCopy() *GatewaySyncSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GatewaySyncSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncSetSpec) DeepCopyInto(out *GatewaySyncSetSpec) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	in.Template.DeepCopyInto(&out.Template)
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make([]NamespaceParameter, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceVars != nil {
+		in, out := &in.NamespaceVars, &out.NamespaceVars
+		*out = make(map[string]map[string]string, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(map[string]string, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncSetSpec.
+func (in *GatewaySyncSetSpec) DeepCopy() *GatewaySyncSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncSetStatus) DeepCopyInto(out *GatewaySyncSetStatus) {
+	*out = *in
+	if in.Children != nil {
+		in, out := &in.Children, &out.Children
+		*out = make([]GatewaySyncSetChild, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncSetStatus.
+func (in *GatewaySyncSetStatus) DeepCopy() *GatewaySyncSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncSpec) DeepCopyInto(out *GatewaySyncSpec) {
+	*out = *in
+	in.Git.DeepCopyInto(&out.Git)
+	in.Polling.DeepCopyInto(&out.Polling)
+	in.Gateway.DeepCopyInto(&out.Gateway)
+	in.Sync.DeepCopyInto(&out.Sync)
+	in.Agent.DeepCopyInto(&out.Agent)
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(RollbackSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Approval != nil {
+		in, out := &in.Approval, &out.Approval
+		*out = new(ApprovalSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncSpec.
+func (in *GatewaySyncSpec) DeepCopy() *GatewaySyncSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncStatus) DeepCopyInto(out *GatewaySyncStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSyncCommitInfo != nil {
+		in, out := &in.LastSyncCommitInfo, &out.LastSyncCommitInfo
+		*out = new(CommitInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProfileLibrary != nil {
+		in, out := &in.ProfileLibrary, &out.ProfileLibrary
+		*out = make([]ProfileLibraryStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.DiscoveredGateways != nil {
+		in, out := &in.DiscoveredGateways, &out.DiscoveredGateways
+		*out = make([]DiscoveredGateway, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(RollbackStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingCommit != nil {
+		in, out := &in.PendingCommit, &out.PendingCommit
+		*out = new(PendingCommit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastApproval != nil {
+		in, out := &in.LastApproval, &out.LastApproval
+		*out = new(ApprovalRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NextWindow != nil {
+		in, out := &in.NextWindow, &out.NextWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncStatus.
+func (in *GatewaySyncStatus) DeepCopy() *GatewaySyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncTemplate) DeepCopyInto(out *GatewaySyncTemplate) {
+	*out = *in
+	in.Metadata.DeepCopyInto(&out.Metadata)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncTemplate.
+func (in *GatewaySyncTemplate) DeepCopy() *GatewaySyncTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewaySyncTemplateMeta) DeepCopyInto(out *GatewaySyncTemplateMeta) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncTemplateMeta.
+func (in *GatewaySyncTemplateMeta) DeepCopy() *GatewaySyncTemplateMeta {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewaySyncTemplateMeta)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayTLSTrust) DeepCopyInto(out *GatewayTLSTrust) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(CABundleSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayTLSTrust.
+func (in *GatewayTLSTrust) DeepCopy() *GatewayTLSTrust {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayTLSTrust)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitAuthSpec) DeepCopyInto(out *GitAuthSpec) {
+	*out = *in
+	if in.SSHKey != nil {
+		in, out := &in.SSHKey, &out.SSHKey
+		*out = new(SSHKeyAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitHubApp != nil {
+		in, out := &in.GitHubApp, &out.GitHubApp
+		*out = new(GitHubAppAuth)
+		**out = **in
+	}
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(TokenAuth)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitAuthSpec.
+func (in *GitAuthSpec) DeepCopy() *GitAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitHubAppAuth) DeepCopyInto(out *GitHubAppAuth) {
+	*out = *in
+	out.PrivateKeySecretRef = in.PrivateKeySecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubAppAuth.
+func (in *GitHubAppAuth) DeepCopy() *GitHubAppAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubAppAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitSpec) DeepCopyInto(out *GitSpec) {
+	*out = *in
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(GitAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(GitVerificationSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSpec.
+func (in *GitSpec) DeepCopy() *GitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitVerificationSpec) DeepCopyInto(out *GitVerificationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitVerificationSpec.
+func (in *GitVerificationSpec) DeepCopy() *GitVerificationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitVerificationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KnownHosts) DeepCopyInto(out *KnownHosts) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnownHosts.
+func (in *KnownHosts) DeepCopy() *KnownHosts {
+	if in == nil {
+		return nil
+	}
+	out := new(KnownHosts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MappingPatch) DeepCopyInto(out *MappingPatch) {
+	*out = *in
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MappingPatch.
+func (in *MappingPatch) DeepCopy() *MappingPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(MappingPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceParameter) DeepCopyInto(out *NamespaceParameter) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceParameter.
+func (in *NamespaceParameter) DeepCopy() *NamespaceParameter {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceParameter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingCommit) DeepCopyInto(out *PendingCommit) {
+	*out = *in
+	if in.CommitInfo != nil {
+		in, out := &in.CommitInfo, &out.CommitInfo
+		*out = new(CommitInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = new(DiffSummary)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingCommit.
+func (in *PendingCommit) DeepCopy() *PendingCommit {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingCommit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PollingSpec) DeepCopyInto(out *PollingSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySpec.
-func (in *GatewaySpec) DeepCopy() *GatewaySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PollingSpec.
+func (in *PollingSpec) DeepCopy() *PollingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GatewaySpec)
+	out := new(PollingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GatewaySync) DeepCopyInto(out *GatewaySync) {
+func (in *ProfileLibraryStatus) DeepCopyInto(out *ProfileLibraryStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySync.
-func (in *GatewaySync) DeepCopy() *GatewaySync {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileLibraryStatus.
+func (in *ProfileLibraryStatus) DeepCopy() *ProfileLibraryStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GatewaySync)
+	out := new(ProfileLibraryStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GatewaySync) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GatewaySyncList) DeepCopyInto(out *GatewaySyncList) {
+func (in *ProfileRef) DeepCopyInto(out *ProfileRef) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]GatewaySync, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncList.
-func (in *GatewaySyncList) DeepCopy() *GatewaySyncList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileRef.
+func (in *ProfileRef) DeepCopy() *ProfileRef {
 	if in == nil {
 		return nil
 	}
-	out := new(GatewaySyncList)
+	out := new(ProfileRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *GatewaySyncList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GatewaySyncSpec) DeepCopyInto(out *GatewaySyncSpec) {
+func (in *RollbackSpec) DeepCopyInto(out *RollbackSpec) {
 	*out = *in
-	in.Git.DeepCopyInto(&out.Git)
-	in.Polling.DeepCopyInto(&out.Polling)
-	in.Gateway.DeepCopyInto(&out.Gateway)
-	in.Sync.DeepCopyInto(&out.Sync)
-	in.Agent.DeepCopyInto(&out.Agent)
+	if in.ErrorThreshold != nil {
+		in, out := &in.ErrorThreshold, &out.ErrorThreshold
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncSpec.
-func (in *GatewaySyncSpec) DeepCopy() *GatewaySyncSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackSpec.
+func (in *RollbackSpec) DeepCopy() *RollbackSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GatewaySyncSpec)
+	out := new(RollbackSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GatewaySyncStatus) DeepCopyInto(out *GatewaySyncStatus) {
+func (in *RollbackStatus) DeepCopyInto(out *RollbackStatus) {
 	*out = *in
-	if in.LastSyncTime != nil {
-		in, out := &in.LastSyncTime, &out.LastSyncTime
-		*out = (*in).DeepCopy()
-	}
-	if in.DiscoveredGateways != nil {
-		in, out := &in.DiscoveredGateways, &out.DiscoveredGateways
-		*out = make([]DiscoveredGateway, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.FailedGateways != nil {
+		in, out := &in.FailedGateways, &out.FailedGateways
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	in.Time.DeepCopyInto(&out.Time)
+	if in.AcknowledgedTime != nil {
+		in, out := &in.AcknowledgedTime, &out.AcknowledgedTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewaySyncStatus.
-func (in *GatewaySyncStatus) DeepCopy() *GatewaySyncStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollbackStatus.
+func (in *RollbackStatus) DeepCopy() *RollbackStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GatewaySyncStatus)
+	out := new(RollbackStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitAuthSpec) DeepCopyInto(out *GitAuthSpec) {
+func (in *RolloutCanary) DeepCopyInto(out *RolloutCanary) {
 	*out = *in
-	if in.SSHKey != nil {
-		in, out := &in.SSHKey, &out.SSHKey
-		*out = new(SSHKeyAuth)
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.GitHubApp != nil {
-		in, out := &in.GitHubApp, &out.GitHubApp
-		*out = new(GitHubAppAuth)
-		**out = **in
-	}
-	if in.Token != nil {
-		in, out := &in.Token, &out.Token
-		*out = new(TokenAuth)
-		**out = **in
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitAuthSpec.
-func (in *GitAuthSpec) DeepCopy() *GitAuthSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutCanary.
+func (in *RolloutCanary) DeepCopy() *RolloutCanary {
 	if in == nil {
 		return nil
 	}
-	out := new(GitAuthSpec)
+	out := new(RolloutCanary)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitHubAppAuth) DeepCopyInto(out *GitHubAppAuth) {
+func (in *RolloutSpec) DeepCopyInto(out *RolloutSpec) {
 	*out = *in
-	out.PrivateKeySecretRef = in.PrivateKeySecretRef
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(RolloutCanary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Waves != nil {
+		in, out := &in.Waves, &out.Waves
+		*out = make([]RolloutWave, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.HaltOnError != nil {
+		in, out := &in.HaltOnError, &out.HaltOnError
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubAppAuth.
-func (in *GitHubAppAuth) DeepCopy() *GitHubAppAuth {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutSpec.
+func (in *RolloutSpec) DeepCopy() *RolloutSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(GitHubAppAuth)
+	out := new(RolloutSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *GitSpec) DeepCopyInto(out *GitSpec) {
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
 	*out = *in
-	if in.Auth != nil {
-		in, out := &in.Auth, &out.Auth
-		*out = new(GitAuthSpec)
-		(*in).DeepCopyInto(*out)
+	if in.UpdatedGateways != nil {
+		in, out := &in.UpdatedGateways, &out.UpdatedGateways
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitSpec.
-func (in *GitSpec) DeepCopy() *GitSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(GitSpec)
+	out := new(RolloutStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KnownHosts) DeepCopyInto(out *KnownHosts) {
+func (in *RolloutWave) DeepCopyInto(out *RolloutWave) {
 	*out = *in
-	out.SecretRef = in.SecretRef
+	if in.Percent != nil {
+		in, out := &in.Percent, &out.Percent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KnownHosts.
-func (in *KnownHosts) DeepCopy() *KnownHosts {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutWave.
+func (in *RolloutWave) DeepCopy() *RolloutWave {
 	if in == nil {
 		return nil
 	}
-	out := new(KnownHosts)
+	out := new(RolloutWave)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MappingPatch) DeepCopyInto(out *MappingPatch) {
+func (in *SSHKeyAuth) DeepCopyInto(out *SSHKeyAuth) {
 	*out = *in
-	if in.Set != nil {
-		in, out := &in.Set, &out.Set
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	out.SecretRef = in.SecretRef
+	if in.KnownHosts != nil {
+		in, out := &in.KnownHosts, &out.KnownHosts
+		*out = new(KnownHosts)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MappingPatch.
-func (in *MappingPatch) DeepCopy() *MappingPatch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHKeyAuth.
+func (in *SSHKeyAuth) DeepCopy() *SSHKeyAuth {
 	if in == nil {
 		return nil
 	}
-	out := new(MappingPatch)
+	out := new(SSHKeyAuth)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PollingSpec) DeepCopyInto(out *PollingSpec) {
+func (in *ScheduleBlackout) DeepCopyInto(out *ScheduleBlackout) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PollingSpec.
-func (in *PollingSpec) DeepCopy() *PollingSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleBlackout.
+func (in *ScheduleBlackout) DeepCopy() *ScheduleBlackout {
 	if in == nil {
 		return nil
 	}
-	out := new(PollingSpec)
+	out := new(ScheduleBlackout)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SSHKeyAuth) DeepCopyInto(out *SSHKeyAuth) {
+func (in *ScheduleWindow) DeepCopyInto(out *ScheduleWindow) {
 	*out = *in
-	out.SecretRef = in.SecretRef
-	if in.KnownHosts != nil {
-		in, out := &in.KnownHosts, &out.KnownHosts
-		*out = new(KnownHosts)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHKeyAuth.
-func (in *SSHKeyAuth) DeepCopy() *SSHKeyAuth {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleWindow.
+func (in *ScheduleWindow) DeepCopy() *ScheduleWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(SSHKeyAuth)
+	out := new(ScheduleWindow)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -408,6 +1272,25 @@ func (in *SyncDefaults) DeepCopy() *SyncDefaults {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncHistoryEntry) DeepCopyInto(out *SyncHistoryEntry) {
+	*out = *in
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncHistoryEntry.
+func (in *SyncHistoryEntry) DeepCopy() *SyncHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncMapping) DeepCopyInto(out *SyncMapping) {
 	*out = *in
@@ -430,6 +1313,64 @@ func (in *SyncMapping) DeepCopy() *SyncMapping {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncProfile) DeepCopyInto(out *SyncProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncProfile.
+func (in *SyncProfile) DeepCopy() *SyncProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SyncProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncProfileList) DeepCopyInto(out *SyncProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SyncProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncProfileList.
+func (in *SyncProfileList) DeepCopy() *SyncProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SyncProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncProfileSpec) DeepCopyInto(out *SyncProfileSpec) {
 	*out = *in
@@ -479,6 +1420,31 @@ func (in *SyncProfileSpec) DeepCopy() *SyncProfileSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncSchedule) DeepCopyInto(out *SyncSchedule) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]ScheduleWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Blackouts != nil {
+		in, out := &in.Blackouts, &out.Blackouts
+		*out = make([]ScheduleBlackout, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncSchedule.
+func (in *SyncSchedule) DeepCopy() *SyncSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncSpec) DeepCopyInto(out *SyncSpec) {
 	*out = *in
@@ -490,6 +1456,16 @@ func (in *SyncSpec) DeepCopyInto(out *SyncSpec) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.ProfileRefs != nil {
+		in, out := &in.ProfileRefs, &out.ProfileRefs
+		*out = make([]ProfileRef, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(SyncSchedule)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncSpec.
@@ -0,0 +1,1210 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ============================================================
+// Utility Types
+// ============================================================
+
+// SecretKeyRef references a key within a Kubernetes Secret.
+type SecretKeyRef struct {
+	// name is the name of the Secret in the same namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// key is the key within the Secret data.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// ConfigMapKeyRef references a key within a Kubernetes ConfigMap.
+type ConfigMapKeyRef struct {
+	// name is the name of the ConfigMap in the same namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// key is the key within the ConfigMap data.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
+
+// ============================================================
+// Git
+// ============================================================
+
+// GitSpec configures the source git repository.
+type GitSpec struct {
+	// repo is the git repository URL (SSH or HTTPS).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Repo string `json:"repo"`
+
+	// ref is the git reference to sync — tag, branch, or commit SHA.
+	// Typically managed by Kargo or a webhook.
+	// A ref expression tracks the highest matching tag instead:
+	// "semver:<constraint>" (e.g. "semver:~2.3", "semver:>=1.0.0 <2.0.0")
+	// or "glob:<pattern>" (e.g. "glob:release-*").
+	// +kubebuilder:validation:Required
+	Ref string `json:"ref"`
+
+	// auth configures git authentication. Exactly one method should be specified.
+	// +optional
+	Auth *GitAuthSpec `json:"auth,omitempty"`
+
+	// verification requires the synced commit or tag to be signed by a trusted key.
+	// The controller will not publish, and agents will not sync, an unverified commit.
+	// +optional
+	Verification *GitVerificationSpec `json:"verification,omitempty"`
+}
+
+// VerificationPolicy selects what must be signed.
+// +kubebuilder:validation:Enum=SignedCommit;SignedTag
+type VerificationPolicy string
+
+const (
+	// VerificationSignedCommit requires the resolved commit to be signed.
+	VerificationSignedCommit VerificationPolicy = "SignedCommit"
+	// VerificationSignedTag requires ref to be a signed annotated tag.
+	VerificationSignedTag VerificationPolicy = "SignedTag"
+)
+
+// GitVerificationSpec configures commit signature verification.
+type GitVerificationSpec struct {
+	// policy selects what must be signed. SignedCommit requires the resolved
+	// commit to be signed; SignedTag requires ref to be an annotated tag whose
+	// signature is trusted.
+	// +kubebuilder:default="SignedCommit"
+	// +optional
+	Policy VerificationPolicy `json:"policy,omitempty"`
+
+	// trustedKeysSecretName is the name of a Secret whose values contain the
+	// trusted signing keys: ASCII-armored GPG public keys and/or SSH public
+	// keys (one per line, authorized_keys or allowed_signers format).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	TrustedKeysSecretName string `json:"trustedKeysSecretName"`
+}
+
+// GitAuthSpec selects one git authentication method.
+type GitAuthSpec struct {
+	// sshKey authenticates via SSH deploy key.
+	// +optional
+	SSHKey *SSHKeyAuth `json:"sshKey,omitempty"`
+
+	// githubApp authenticates via a GitHub App installation.
+	// Enables bi-directional PR creation.
+	// +optional
+	GitHubApp *GitHubAppAuth `json:"githubApp,omitempty"`
+
+	// token authenticates via a personal access token or service account token.
+	// +optional
+	Token *TokenAuth `json:"token,omitempty"`
+}
+
+// SSHKeyAuth references an SSH private key stored in a Secret.
+type SSHKeyAuth struct {
+	// secretRef points to the Secret containing the SSH private key.
+	SecretRef SecretKeyRef `json:"secretRef"`
+
+	// knownHosts optionally references a Secret for SSH host key verification.
+	// When omitted, host key verification is disabled (InsecureIgnoreHostKey).
+	// +optional
+	KnownHosts *KnownHosts `json:"knownHosts,omitempty"`
+}
+
+// KnownHosts references a Secret containing SSH known_hosts data.
+type KnownHosts struct {
+	// secretRef points to the Secret containing the known_hosts file.
+	SecretRef SecretKeyRef `json:"secretRef"`
+}
+
+// GitHubAppAuth authenticates using a GitHub App installation.
+type GitHubAppAuth struct {
+	// appId is the GitHub App ID.
+	AppID int64 `json:"appId"`
+
+	// installationId is the GitHub App installation ID.
+	InstallationID int64 `json:"installationId"`
+
+	// privateKeySecretRef points to the Secret containing the App's private key PEM.
+	PrivateKeySecretRef SecretKeyRef `json:"privateKeySecretRef"`
+
+	// apiBaseURL is the GitHub API base URL. Defaults to https://api.github.com.
+	// Set this for GitHub Enterprise Server (e.g. https://github.example.com/api/v3).
+	// +optional
+	APIBaseURL string `json:"apiBaseURL,omitempty"`
+}
+
+// TokenAuth references a token stored in a Secret.
+type TokenAuth struct {
+	// secretRef points to the Secret containing the git token.
+	SecretRef SecretKeyRef `json:"secretRef"`
+}
+
+// ============================================================
+// Polling
+// ============================================================
+
+// PollingSpec configures the fallback polling interval for git changes.
+type PollingSpec struct {
+	// enabled controls whether periodic polling is active.
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// interval is the polling period (e.g., "60s", "5m").
+	// +kubebuilder:default="60s"
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ============================================================
+// Gateway
+// ============================================================
+
+// GatewaySpec configures how the operator connects to Ignition gateways.
+// These settings apply to all discovered gateways; individual gateways
+// can override via pod annotations.
+type GatewaySpec struct {
+	// port is the Ignition gateway API port.
+	// +kubebuilder:default=8088
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// tls enables TLS for gateway API connections.
+	// +kubebuilder:default=false
+	// +optional
+	TLS *bool `json:"tls,omitempty"`
+
+	// tlsTrust configures how the agent verifies the gateway's TLS certificate
+	// when tls is enabled. When omitted, the certificate is verified against the
+	// system roots of the agent image.
+	// +optional
+	TLSTrust *GatewayTLSTrust `json:"tlsTrust,omitempty"`
+
+	// api configures the Ignition gateway API key secret.
+	API GatewayAPISpec `json:"api"`
+
+	// backup configures a gateway backup (.gwbk) captured before the agent
+	// applies changes to config paths.
+	// +optional
+	Backup *GatewayBackupSpec `json:"backup,omitempty"`
+}
+
+// GatewayBackupSpec configures pre-change gateway backups.
+type GatewayBackupSpec struct {
+	// enabled turns on pre-change backups.
+	// +kubebuilder:default=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// claimName is the PersistentVolumeClaim the agent stores backups on.
+	// The claim must be mountable by every gateway pod using this GatewaySync.
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// paths are destination path prefixes (relative to the gateway data directory)
+	// that trigger a backup when a sync would add, modify, or delete files under them.
+	// +kubebuilder:default={"config"}
+	// +optional
+	Paths []string `json:"paths,omitempty"`
+
+	// retention limits how many backups are kept per gateway.
+	// +optional
+	Retention *BackupRetention `json:"retention,omitempty"`
+}
+
+// BackupRetention prunes old backups by count and/or age.
+type BackupRetention struct {
+	// maxCount is the number of most recent backups kept per gateway.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxCount int32 `json:"maxCount,omitempty"`
+
+	// maxAge removes backups older than this duration (e.g., "720h").
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// GatewayTLSTrust configures gateway certificate verification for the agent.
+// +kubebuilder:validation:XValidation:rule="!(has(self.insecureSkipVerify) && self.insecureSkipVerify && has(self.caBundle))",message="caBundle and insecureSkipVerify are mutually exclusive"
+type GatewayTLSTrust struct {
+	// caBundle references PEM-encoded CA certificates used to verify the gateway certificate.
+	// +optional
+	CABundle *CABundleSource `json:"caBundle,omitempty"`
+
+	// serverName is the name verified against the gateway certificate.
+	// Defaults to "localhost", the address the agent dials.
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// insecureSkipVerify disables gateway certificate verification entirely.
+	// This is an explicit opt-in; the controller reports GatewayTLSVerification=False
+	// while it is set.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// CABundleSource selects the Secret or ConfigMap key holding a PEM CA bundle.
+// +kubebuilder:validation:XValidation:rule="has(self.secretRef) != has(self.configMapRef)",message="exactly one of secretRef or configMapRef must be set"
+type CABundleSource struct {
+	// secretRef points to a Secret key containing the CA bundle.
+	// +optional
+	SecretRef *SecretKeyRef `json:"secretRef,omitempty"`
+
+	// configMapRef points to a ConfigMap key containing the CA bundle.
+	// +optional
+	ConfigMapRef *ConfigMapKeyRef `json:"configMapRef,omitempty"`
+}
+
+// GatewayAPISpec references the Secret containing the Ignition API key.
+type GatewayAPISpec struct {
+	// secretName is the name of the Secret containing the Ignition API key.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+
+	// secretKey is the key within the Secret. Defaults to "apiKey".
+	// +kubebuilder:default="apiKey"
+	// +optional
+	SecretKey string `json:"secretKey,omitempty"`
+}
+
+// ============================================================
+// Agent
+// ============================================================
+
+// AgentSpec configures the sync agent sidecar injected by the webhook.
+type AgentSpec struct {
+	// image configures the agent container image.
+	// +optional
+	Image AgentImageSpec `json:"image,omitempty"`
+
+	// resources configures the agent container resource requirements.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// syncHistoryLimit is the number of recent sync records each agent keeps
+	// in its status. The full history is served by the controller's
+	// GET /history endpoint; status.discoveredGateways[].recentSyncs shows the
+	// latest few.
+	// +kubebuilder:default=20
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	SyncHistoryLimit int32 `json:"syncHistoryLimit,omitempty"`
+
+	// audit configures sinks that receive one structured JSON record per sync
+	// (commit, author, gateway, profile, changed files, scan result, duration).
+	// +optional
+	Audit *AuditSpec `json:"audit,omitempty"`
+}
+
+// AuditSpec configures the agent's audit record sinks. Any combination may
+// be enabled; a failing sink is logged and does not fail the sync.
+type AuditSpec struct {
+	// stdout writes audit records as JSON lines to the agent's stdout.
+	// +optional
+	Stdout bool `json:"stdout,omitempty"`
+
+	// file appends audit records to a size-rotated file on a PersistentVolumeClaim.
+	// +optional
+	File *AuditFileSink `json:"file,omitempty"`
+
+	// syslog sends audit records as RFC 5424 messages.
+	// +optional
+	Syslog *AuditSyslogSink `json:"syslog,omitempty"`
+
+	// http POSTs each audit record as JSON (e.g. to a SIEM HTTP collector).
+	// +optional
+	HTTP *AuditHTTPSink `json:"http,omitempty"`
+}
+
+// AuditFileSink writes audit records to {pod name}.log on a PersistentVolumeClaim.
+type AuditFileSink struct {
+	// claimName is the PersistentVolumeClaim the agent writes audit logs to.
+	// The claim must be mountable by every gateway pod using this GatewaySync.
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// maxSizeMB rotates the audit file when it would exceed this size.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxSizeMB int32 `json:"maxSizeMB,omitempty"`
+
+	// maxBackups is the number of rotated files kept per pod.
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxBackups int32 `json:"maxBackups,omitempty"`
+}
+
+// AuditSyslogSink sends audit records to a syslog receiver.
+type AuditSyslogSink struct {
+	// address is the host:port of the syslog receiver.
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+
+	// protocol is the transport. TCP uses octet-counting framing (RFC 6587).
+	// +kubebuilder:default="udp"
+	// +kubebuilder:validation:Enum=udp;tcp
+	// +optional
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// AuditHTTPSink POSTs audit records to an HTTP endpoint.
+type AuditHTTPSink struct {
+	// url receives one POST per audit record with a JSON body.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// tokenSecretRef references a bearer token sent in the Authorization header.
+	// +optional
+	TokenSecretRef *SecretKeyRef `json:"tokenSecretRef,omitempty"`
+}
+
+// AgentImageSpec configures the agent container image.
+// When unset, the webhook falls through to the DEFAULT_AGENT_IMAGE env var
+// (set by the Helm chart's agentImage values).
+type AgentImageSpec struct {
+	// repository is the container image repository.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// tag is the container image tag.
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// pullPolicy is the image pull policy.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+}
+
+// ============================================================
+// Sync Configuration
+// ============================================================
+
+// SyncSpec configures file sync behavior and profiles.
+// +kubebuilder:validation:XValidation:rule="(has(self.profiles) && size(self.profiles) > 0) || (has(self.profileRefs) && size(self.profileRefs) > 0)",message="at least one of profiles or profileRefs must be set"
+type SyncSpec struct {
+	// defaults provides baseline settings inherited by all profiles unless overridden.
+	// +optional
+	Defaults SyncDefaults `json:"defaults,omitempty"`
+
+	// profiles is a named map of sync profiles. Each profile defines mappings
+	// and optional behavioral overrides. Pods select a profile via the
+	// stoker.io/profile annotation. The "default" profile is used as fallback.
+	// An inline profile takes precedence over a profileRefs entry of the same name.
+	// +optional
+	Profiles map[string]SyncProfileSpec `json:"profiles,omitempty"`
+
+	// profileRefs adds profiles from the shared SyncProfile (same namespace)
+	// and ClusterSyncProfile library. Defaults apply to them as to inline
+	// profiles.
+	// +listType=map
+	// +listMapKey=name
+	// +listMapKey=kind
+	// +optional
+	ProfileRefs []ProfileRef `json:"profileRefs,omitempty"`
+
+	// schedule limits when new commits are published and applied. Outside
+	// its windows, or during a blackout, gateways keep their current commit.
+	// +optional
+	Schedule *SyncSchedule `json:"schedule,omitempty"`
+}
+
+// ProfileRefKind is the kind of library profile a ProfileRef points to.
+// +kubebuilder:validation:Enum=SyncProfile;ClusterSyncProfile
+type ProfileRefKind string
+
+const (
+	// ProfileRefSyncProfile is a SyncProfile in the GatewaySync's namespace.
+	ProfileRefSyncProfile ProfileRefKind = "SyncProfile"
+	// ProfileRefClusterSyncProfile is a cluster-scoped ClusterSyncProfile.
+	ProfileRefClusterSyncProfile ProfileRefKind = "ClusterSyncProfile"
+)
+
+// ProfileRef references a library profile.
+type ProfileRef struct {
+	// kind is SyncProfile (in the GatewaySync's namespace) or ClusterSyncProfile.
+	// +kubebuilder:default=SyncProfile
+	// +optional
+	Kind ProfileRefKind `json:"kind,omitempty"`
+
+	// name is the name of the SyncProfile or ClusterSyncProfile.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// as is the profile name gateways select with the stoker.io/profile
+	// annotation. Defaults to name.
+	// +optional
+	As string `json:"as,omitempty"`
+}
+
+// SyncSchedule defines maintenance windows and change freezes.
+type SyncSchedule struct {
+	// windows are the times new commits may be applied. When empty, any
+	// time outside a blackout is allowed.
+	// +optional
+	Windows []ScheduleWindow `json:"windows,omitempty"`
+
+	// timeZone is the IANA time zone for windows and blackout dates
+	// (e.g., "America/Chicago"). Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// blackouts are date ranges when no new commit is applied, even inside a window.
+	// +optional
+	Blackouts []ScheduleBlackout `json:"blackouts,omitempty"`
+}
+
+// ScheduleWindow is a recurring window that opens on a cron schedule.
+type ScheduleWindow struct {
+	// start is a standard 5-field cron expression for when the window opens
+	// (e.g., "0 6,14,22 * * *" for shift changes).
+	// +kubebuilder:validation:MinLength=1
+	Start string `json:"start"`
+
+	// duration is how long the window stays open (e.g., "30m").
+	Duration metav1.Duration `json:"duration"`
+}
+
+// ScheduleBlackout is a change freeze between two dates or times.
+type ScheduleBlackout struct {
+	// start is the first day ("2026-12-24") or instant (RFC 3339) of the freeze.
+	// +kubebuilder:validation:MinLength=1
+	Start string `json:"start"`
+
+	// end is the last day ("2026-12-26", inclusive) or instant (RFC 3339,
+	// exclusive) of the freeze.
+	// +kubebuilder:validation:MinLength=1
+	End string `json:"end"`
+
+	// reason is shown in the Deferred condition.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// SyncDefaults provides baseline settings inherited by all profiles unless overridden.
+type SyncDefaults struct {
+	// excludePatterns are glob patterns for files to exclude from sync.
+	// The pattern "**/.resources/**" is always enforced by the agent even if omitted.
+	// +kubebuilder:default={"**/.git/","**/.gitkeep","**/.resources/**"}
+	// +optional
+	ExcludePatterns []string `json:"excludePatterns,omitempty"`
+
+	// vars provides default template variables inherited by all profiles.
+	// Profile-level vars override these on a per-key basis; unmatched keys are inherited.
+	// +optional
+	Vars map[string]string `json:"vars,omitempty"`
+
+	// syncPeriod is the agent-side polling interval in seconds.
+	// +kubebuilder:default=30
+	// +kubebuilder:validation:Minimum=5
+	// +kubebuilder:validation:Maximum=3600
+	// +optional
+	SyncPeriod int32 `json:"syncPeriod,omitempty"`
+
+	// designerSessionPolicy controls sync behavior when Ignition Designer
+	// sessions are active. "proceed" (default) logs a warning and continues,
+	// "wait" retries until sessions close (up to 5 min), "fail" aborts the sync.
+	// +kubebuilder:default="proceed"
+	// +optional
+	DesignerSessionPolicy DesignerSessionPolicy `json:"designerSessionPolicy,omitempty"`
+
+	// dryRun causes the agent to sync to a staging directory without
+	// copying to /ignition-data/.
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// DesignerSessionPolicy controls sync behavior when Ignition Designer
+// sessions are active.
+// +kubebuilder:validation:Enum=proceed;wait;fail
+type DesignerSessionPolicy string
+
+const (
+	// DesignerSessionProceed logs a warning and continues the sync.
+	DesignerSessionProceed DesignerSessionPolicy = "proceed"
+	// DesignerSessionWait retries until sessions close (up to 5 minutes).
+	DesignerSessionWait DesignerSessionPolicy = "wait"
+	// DesignerSessionFail aborts the sync.
+	DesignerSessionFail DesignerSessionPolicy = "fail"
+)
+
+// SyncProfileSpec defines a sync profile's configuration.
+type SyncProfileSpec struct {
+	// mappings is an ordered list of source->destination file mappings.
+	// +kubebuilder:validation:MinItems=1
+	Mappings []SyncMapping `json:"mappings"`
+
+	// excludePatterns are additional glob patterns for files to exclude.
+	// Merged with defaults.excludePatterns (additive).
+	// +optional
+	ExcludePatterns []string `json:"excludePatterns,omitempty"`
+
+	// vars is a map of template variables resolved by the agent at sync time.
+	// +optional
+	Vars map[string]string `json:"vars,omitempty"`
+
+	// syncPeriod overrides defaults.syncPeriod for this profile.
+	// +kubebuilder:validation:Minimum=5
+	// +kubebuilder:validation:Maximum=3600
+	// +optional
+	SyncPeriod *int32 `json:"syncPeriod,omitempty"`
+
+	// dryRun overrides defaults.dryRun for this profile.
+	// +optional
+	DryRun *bool `json:"dryRun,omitempty"`
+
+	// designerSessionPolicy overrides defaults.designerSessionPolicy.
+	// +optional
+	DesignerSessionPolicy DesignerSessionPolicy `json:"designerSessionPolicy,omitempty"`
+
+	// paused halts sync for gateways using this profile. Use spec.paused to
+	// pause every profile.
+	// +optional
+	Paused *bool `json:"paused,omitempty"`
+}
+
+// MappingType is the kind of repository entry a mapping copies.
+// +kubebuilder:validation:Enum=dir;file
+type MappingType string
+
+const (
+	// MappingTypeDir copies a directory tree.
+	MappingTypeDir MappingType = "dir"
+	// MappingTypeFile copies a single file.
+	MappingTypeFile MappingType = "file"
+)
+
+// SyncMapping defines a single source->destination file mapping.
+type SyncMapping struct {
+	// source is the repo-relative path to copy from.
+	// Supports Go template variables: {{.GatewayName}}, {{.PodName}}, {{.CRName}},
+	// {{.Labels.key}}, {{.Vars.key}}, {{.Namespace}}, {{.Ref}}, {{.Commit}}.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Source string `json:"source"`
+
+	// destination is the gateway-relative path to copy to.
+	// Supports Go template variables: {{.GatewayName}}, {{.PodName}}, {{.CRName}},
+	// {{.Labels.key}}, {{.Vars.key}}, {{.Namespace}}, {{.Ref}}, {{.Commit}}.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Destination string `json:"destination"`
+
+	// type is optional and inferred automatically from the repository at sync time.
+	// Explicit values ("dir" or "file") are validated against the actual entry type.
+	// +optional
+	Type MappingType `json:"type,omitempty"`
+
+	// required causes the sync to fail if the source path does not exist
+	// in the repo at the resolved commit.
+	// +optional
+	Required bool `json:"required,omitempty"`
+
+	// template enables Go template rendering of file contents during staging.
+	// When true, the agent resolves {{.GatewayName}}, {{.PodName}}, {{.Vars.key}},
+	// and other TemplateContext fields inside each synced file before writing to disk.
+	// Binary files (containing null bytes) are rejected with an error.
+	// +optional
+	Template bool `json:"template,omitempty"`
+
+	// patches applies surgical JSON field updates to files within this mapping after staging.
+	// Only valid for JSON files. Each patch targets a specific file (or glob pattern) and
+	// sets one or more fields using sjson-style dot-notation paths.
+	// +optional
+	Patches []MappingPatch `json:"patches,omitempty"`
+}
+
+// MappingPatch applies sjson-style field updates to a JSON file within a mapping.
+type MappingPatch struct {
+	// file is the path to the JSON file to patch, relative to the mapping's destination.
+	// Supports glob patterns (e.g. "*.json", "connections/*.json") for directory mappings.
+	// For file mappings, file may be omitted — the mapped file itself is patched.
+	// +optional
+	File string `json:"file,omitempty"`
+
+	// set is a map of sjson dot-notation paths to template values.
+	// Nested fields use dots: "SystemName", "networkInterfaces.0.address".
+	// Values support Go template syntax: {{.GatewayName}}, {{.Vars.key}}, etc.
+	// Values are type-inferred: JSON literals (true, false, numbers) are set as their
+	// native types; everything else is set as a string.
+	// +kubebuilder:validation:MinProperties=1
+	Set map[string]string `json:"set"`
+}
+
+// ============================================================
+// Rollout
+// ============================================================
+
+// RolloutSpec stages a new commit across gateways instead of publishing it
+// to all of them at once.
+type RolloutSpec struct {
+	// canary selects the gateways that receive a new commit first.
+	// +optional
+	Canary *RolloutCanary `json:"canary,omitempty"`
+
+	// waves follow the canary in order. Each wave starts only after every
+	// gateway in the previous steps reports Synced at the new commit.
+	// Gateways not covered by any wave are updated in a final step.
+	// +optional
+	Waves []RolloutWave `json:"waves,omitempty"`
+
+	// maxUnavailable limits how many gateways may be updating (not yet Synced
+	// at the new commit) at once, as a count or a percentage of all gateways.
+	// Unlimited when unset.
+	// +kubebuilder:validation:XIntOrString
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// haltOnError stops the rollout when an updated gateway reports Error.
+	// Gateways not yet updated stay on the previous commit until a new
+	// commit is resolved.
+	// +kubebuilder:default=true
+	// +optional
+	HaltOnError *bool `json:"haltOnError,omitempty"`
+}
+
+// RolloutCanary selects canary gateways by pod label or profile.
+// A gateway matching either is a canary.
+type RolloutCanary struct {
+	// selector matches gateway pod labels.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// profiles lists sync profile names whose gateways are canaries.
+	// +optional
+	Profiles []string `json:"profiles,omitempty"`
+}
+
+// RolloutWave is one step of a rollout. Exactly one of percent or selector
+// is set.
+// +kubebuilder:validation:XValidation:rule="has(self.percent) != has(self.selector)",message="exactly one of percent or selector must be set"
+type RolloutWave struct {
+	// name identifies the wave in status and events. Defaults to "wave-<n>".
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// percent is the cumulative share of all gateways updated once this
+	// wave completes, in gateway name order after the canaries.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Percent *int32 `json:"percent,omitempty"`
+
+	// selector adds the gateways whose pod labels match.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// RollbackSpec pins gateways back to the last fully synced commit when too
+// many of them fail on a new commit.
+type RollbackSpec struct {
+	// errorThreshold is how many gateways, as a count or a percentage of all
+	// gateways, may report Error on a new commit before it is rolled back.
+	// The rollback triggers when the number of failing gateways exceeds it.
+	// +kubebuilder:default=0
+	// +kubebuilder:validation:XIntOrString
+	// +optional
+	ErrorThreshold *intstr.IntOrString `json:"errorThreshold,omitempty"`
+
+	// window is how long after a new commit is resolved its gateway errors
+	// count toward errorThreshold (e.g., "10m"). Later errors do not roll back.
+	// +kubebuilder:default="10m"
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+}
+
+// ApprovalSpec holds each newly resolved commit until a named approver
+// releases it with the stoker.io/approved-by and stoker.io/approved-commit
+// annotations.
+type ApprovalSpec struct {
+	// approvers restricts who may approve, by Kubernetes username. The
+	// validating webhook only admits a stoker.io/approved-by value naming the
+	// user who sets it. When empty, any user approves.
+	// +optional
+	Approvers []string `json:"approvers,omitempty"`
+}
+
+// ============================================================
+// Top-Level Spec
+// ============================================================
+
+// GatewaySyncSpec defines the desired state of GatewaySync.
+type GatewaySyncSpec struct {
+	// git configures the source repository.
+	// +kubebuilder:validation:Required
+	Git GitSpec `json:"git"`
+
+	// polling configures the fallback git polling interval.
+	// +optional
+	Polling PollingSpec `json:"polling,omitempty"`
+
+	// gateway configures how the operator connects to Ignition gateways.
+	Gateway GatewaySpec `json:"gateway"`
+
+	// sync configures file sync behavior and profiles.
+	// +kubebuilder:validation:Required
+	Sync SyncSpec `json:"sync"`
+
+	// agent configures the sync agent sidecar injected by the mutating webhook.
+	// +optional
+	Agent AgentSpec `json:"agent,omitempty"`
+
+	// rollout stages new commits across gateways (canary, then waves).
+	// When unset, every gateway receives a new commit at once.
+	// +optional
+	Rollout *RolloutSpec `json:"rollout,omitempty"`
+
+	// rollback pins gateways back to the last fully synced commit when a new
+	// commit fails on too many of them. The pin holds until acknowledged with
+	// the stoker.io/rollback-acknowledged annotation.
+	// +optional
+	Rollback *RollbackSpec `json:"rollback,omitempty"`
+
+	// approval holds new commits in status.pendingCommit until approved.
+	// Gateways keep the last approved commit meanwhile.
+	// +optional
+	Approval *ApprovalSpec `json:"approval,omitempty"`
+
+	// allowedNamespaces lists the namespaces whose gateway pods may bind to
+	// this GatewaySync from outside its namespace with the
+	// stoker.io/cr-namespace annotation. "*" allows every namespace when the
+	// operator enables it (webhook.validation.allowAllNamespaces).
+	// +listType=set
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// paused halts all sync operations when set to true.
+	// +optional
+	Paused bool `json:"paused,omitempty"`
+}
+
+// ============================================================
+// Status Types
+// ============================================================
+
+// DiscoveredGateway represents a single Ignition gateway discovered by the controller.
+type DiscoveredGateway struct {
+	// name is the gateway identity (from annotation or app.kubernetes.io/name label).
+	Name string `json:"name"`
+
+	// namespace is the namespace of the gateway pod.
+	Namespace string `json:"namespace"`
+
+	// podName is the name of the gateway pod.
+	PodName string `json:"podName"`
+
+	// serviceAccountName is the ServiceAccount used by the gateway pod.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// profile is the name of the sync profile used by this gateway.
+	// +optional
+	Profile string `json:"profile,omitempty"`
+
+	// profileLibrary is the library profile version this gateway last synced
+	// with, as Kind/name@generation. Empty for inline profiles.
+	// +optional
+	ProfileLibrary string `json:"profileLibrary,omitempty"`
+
+	// syncStatus is the current sync state of this gateway.
+	// +kubebuilder:validation:Enum=Pending;Synced;Error;MissingSidecar
+	// +optional
+	SyncStatus string `json:"syncStatus,omitempty"`
+
+	// lastSyncTime is when this gateway was last synced.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// targetCommit is the commit published to this gateway when it differs
+	// from lastSyncCommit during a staged rollout.
+	// +optional
+	TargetCommit string `json:"targetCommit,omitempty"`
+
+	// lastSyncDuration is how long the last sync took.
+	// +optional
+	LastSyncDuration string `json:"lastSyncDuration,omitempty"`
+
+	// syncedCommit is the git commit SHA currently synced to this gateway.
+	// +optional
+	SyncedCommit string `json:"syncedCommit,omitempty"`
+
+	// syncedRef is the git ref currently synced to this gateway.
+	// +optional
+	SyncedRef string `json:"syncedRef,omitempty"`
+
+	// syncedCommitInfo describes the synced commit, as read from the agent's clone.
+	// +optional
+	SyncedCommitInfo *CommitInfo `json:"syncedCommitInfo,omitempty"`
+
+	// refOverride is the ref this gateway is pinned to by the
+	// stoker.io/ref-override pod annotation, instead of spec.git.ref.
+	// +optional
+	RefOverride string `json:"refOverride,omitempty"`
+
+	// agentVersion is the version of the sync agent on this gateway.
+	// +optional
+	AgentVersion string `json:"agentVersion,omitempty"`
+
+	// lastScanResult summarizes the last Ignition scan API response.
+	// +optional
+	LastScanResult string `json:"lastScanResult,omitempty"`
+
+	// filesChanged is the number of files changed in the last sync.
+	// +optional
+	FilesChanged int32 `json:"filesChanged,omitempty"`
+
+	// projectsSynced lists the Ignition project names synced to this gateway.
+	// +optional
+	ProjectsSynced []string `json:"projectsSynced,omitempty"`
+
+	// lastBackup is the file name of the most recent pre-change gateway backup.
+	// +optional
+	LastBackup string `json:"lastBackup,omitempty"`
+
+	// gatewayVersion is the Ignition version detected by the agent.
+	// +optional
+	GatewayVersion string `json:"gatewayVersion,omitempty"`
+
+	// recentSyncs is a condensed view of this gateway's latest syncs, newest first.
+	// +optional
+	RecentSyncs []SyncHistoryEntry `json:"recentSyncs,omitempty"`
+}
+
+// RolloutStatus reports the progress of a staged rollout.
+type RolloutStatus struct {
+	// commit is the commit being rolled out.
+	Commit string `json:"commit"`
+
+	// ref is the ref that resolved to commit.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// stableCommit is the previous fully rolled-out commit, which gateways
+	// keep until they are updated. Empty for the first commit.
+	// +optional
+	StableCommit string `json:"stableCommit,omitempty"`
+
+	// phase is Progressing, Halted, or Complete.
+	// +kubebuilder:validation:Enum=Progressing;Halted;Complete
+	Phase string `json:"phase"`
+
+	// step is the index of the current step: the canary (when configured),
+	// then each wave, then the final step covering all gateways.
+	// +optional
+	Step int32 `json:"step,omitempty"`
+
+	// stepName names the current step: "canary", a wave name, or "all".
+	// +optional
+	StepName string `json:"stepName,omitempty"`
+
+	// updatedGateways lists the gateways given the new commit so far.
+	// +optional
+	UpdatedGateways []string `json:"updatedGateways,omitempty"`
+
+	// message describes the current state.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// startTime is when the rollout of commit began.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+}
+
+// RollbackStatus describes an automatic rollback awaiting acknowledgement.
+type RollbackStatus struct {
+	// failedCommit is the commit that was rolled back.
+	FailedCommit string `json:"failedCommit"`
+
+	// commit is the last fully synced commit gateways were pinned back to.
+	Commit string `json:"commit"`
+
+	// failedGateways lists the gateways that reported Error on failedCommit.
+	// +optional
+	FailedGateways []string `json:"failedGateways,omitempty"`
+
+	// time is when the rollback happened.
+	Time metav1.Time `json:"time"`
+
+	// acknowledgedTime is when the rollback was acknowledged. An acknowledged
+	// rollback no longer pins gateways, and failedCommit is not rolled back
+	// again; the record is cleared once spec.git.ref resolves to a new commit.
+	// +optional
+	AcknowledgedTime *metav1.Time `json:"acknowledgedTime,omitempty"`
+}
+
+// PendingCommit is a resolved commit awaiting approval.
+type PendingCommit struct {
+	// commit is the full SHA awaiting approval.
+	Commit string `json:"commit"`
+
+	// ref is the ref that resolved to commit.
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// commitInfo describes commit.
+	// +optional
+	CommitInfo *CommitInfo `json:"commitInfo,omitempty"`
+
+	// diff summarizes the changes from the published commit.
+	// +optional
+	Diff *DiffSummary `json:"diff,omitempty"`
+
+	// since is when the commit was first seen.
+	Since metav1.Time `json:"since"`
+}
+
+// DiffSummary summarizes the paths changed between two commits.
+type DiffSummary struct {
+	// added is the number of files added.
+	Added int32 `json:"added"`
+
+	// modified is the number of files modified.
+	Modified int32 `json:"modified"`
+
+	// deleted is the number of files deleted.
+	Deleted int32 `json:"deleted"`
+
+	// files lists up to 20 changed paths, each prefixed with "A ", "M ", or "D ".
+	// +optional
+	Files []string `json:"files,omitempty"`
+
+	// error is set when the diff could not be computed.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ApprovalRecord records who approved a commit.
+type ApprovalRecord struct {
+	// commit is the approved commit SHA.
+	Commit string `json:"commit"`
+
+	// approver is the stoker.io/approved-by value: the approving user's
+	// Kubernetes username.
+	Approver string `json:"approver"`
+
+	// time is when the approval was recorded.
+	Time metav1.Time `json:"time"`
+}
+
+// CommitInfo describes a git commit.
+type CommitInfo struct {
+	// author is the commit author's name.
+	// +optional
+	Author string `json:"author,omitempty"`
+
+	// committer is the committer's name. It differs from the author for
+	// commits merged or rebased through a git server UI.
+	// +optional
+	Committer string `json:"committer,omitempty"`
+
+	// subject is the first line of the commit message.
+	// +optional
+	Subject string `json:"subject,omitempty"`
+
+	// time is the commit (committer) time.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+}
+
+// SyncHistoryEntry summarizes one sync in a gateway's history.
+type SyncHistoryEntry struct {
+	// commit is the abbreviated git commit SHA synced (or attempted).
+	Commit string `json:"commit"`
+
+	// trigger is what started the sync (e.g. commit, profile-switch, resync:full).
+	// +optional
+	Trigger string `json:"trigger,omitempty"`
+
+	// result is the sync outcome (Pending, Synced, Error).
+	Result string `json:"result"`
+
+	// filesChanged is the number of files added, modified, or deleted.
+	// +optional
+	FilesChanged int32 `json:"filesChanged,omitempty"`
+
+	// time is when the sync finished.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+}
+
+// GatewaySyncStatus defines the observed state of GatewaySync.
+type GatewaySyncStatus struct {
+	// observedGeneration is the most recent generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// lastSyncTime is when the most recent sync completed.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// lastSyncRef is the git ref that was last synced. For a ref expression
+	// this is the chosen tag.
+	// +optional
+	LastSyncRef string `json:"lastSyncRef,omitempty"`
+
+	// refExpression is the spec.git.ref expression that selected lastSyncRef.
+	// Empty when spec.git.ref names a ref exactly.
+	// +optional
+	RefExpression string `json:"refExpression,omitempty"`
+
+	// lastSyncCommit is the git commit SHA that was last synced.
+	// +optional
+	LastSyncCommit string `json:"lastSyncCommit,omitempty"`
+
+	// lastSyncCommitShort is the abbreviated (7-char) git commit SHA for display.
+	// +optional
+	LastSyncCommitShort string `json:"lastSyncCommitShort,omitempty"`
+
+	// lastSyncCommitInfo describes lastSyncCommit, fetched from the remote
+	// when the commit changes.
+	// +optional
+	LastSyncCommitInfo *CommitInfo `json:"lastSyncCommitInfo,omitempty"`
+
+	// refResolutionStatus indicates the state of git ref resolution.
+	// +kubebuilder:validation:Enum=NotResolved;Resolving;Resolved;Error
+	// +optional
+	RefResolutionStatus string `json:"refResolutionStatus,omitempty"`
+
+	// profileCount is the number of profiles published to gateways, inline
+	// and from spec.sync.profileRefs.
+	// +optional
+	ProfileCount int32 `json:"profileCount,omitempty"`
+
+	// profileLibrary lists the library profiles published from
+	// spec.sync.profileRefs and the version of each.
+	// +optional
+	ProfileLibrary []ProfileLibraryStatus `json:"profileLibrary,omitempty"`
+
+	// discoveredGateways lists all gateways discovered by the controller.
+	// +optional
+	DiscoveredGateways []DiscoveredGateway `json:"discoveredGateways,omitempty"`
+
+	// rollout reports the staged rollout of the latest commit when
+	// spec.rollout is set.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// lastFullySyncedCommit is the most recent commit every gateway following
+	// spec.git.ref reported Synced at. Automatic rollbacks pin back to it.
+	// +optional
+	LastFullySyncedCommit string `json:"lastFullySyncedCommit,omitempty"`
+
+	// rollback describes the latest automatic rollback. Gateways stay pinned
+	// back until it is acknowledged.
+	// +optional
+	Rollback *RollbackStatus `json:"rollback,omitempty"`
+
+	// pendingCommit is the resolved commit awaiting approval when
+	// spec.approval is set. lastSyncCommit stays the last approved commit.
+	// +optional
+	PendingCommit *PendingCommit `json:"pendingCommit,omitempty"`
+
+	// lastApproval records the most recent approval.
+	// +optional
+	LastApproval *ApprovalRecord `json:"lastApproval,omitempty"`
+
+	// deferredCommit is the resolved commit waiting for the next
+	// spec.sync.schedule window. lastSyncCommit stays the published commit.
+	// +optional
+	DeferredCommit string `json:"deferredCommit,omitempty"`
+
+	// nextWindow is when the next spec.sync.schedule window opens while a
+	// commit is deferred.
+	// +optional
+	NextWindow *metav1.Time `json:"nextWindow,omitempty"`
+
+	// conditions represent the current state of the GatewaySync resource.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ProfileLibraryStatus is a library profile published to gateways.
+type ProfileLibraryStatus struct {
+	// profile is the profile name gateways select.
+	Profile string `json:"profile"`
+
+	// kind is SyncProfile or ClusterSyncProfile.
+	Kind string `json:"kind"`
+
+	// name is the name of the library object.
+	Name string `json:"name"`
+
+	// generation is the library object's metadata.generation published.
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+
+	// version identifies the published spec as Kind/name@generation; gateways
+	// report it in discoveredGateways[].profileLibrary.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// shadowed is true when an inline profile of the same name replaces it.
+	// +optional
+	Shadowed bool `json:"shadowed,omitempty"`
+}
+
+// ============================================================
+// Root Objects
+// ============================================================
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+// +kubebuilder:resource:shortName=gs
+// +kubebuilder:printcolumn:name="Ref",type="string",JSONPath=`.status.lastSyncRef`
+// +kubebuilder:printcolumn:name="Gateways",type="string",JSONPath=`.status.conditions[?(@.type=="AllGatewaysSynced")].message`
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=`.status.conditions[?(@.type=="Ready")].message`
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:printcolumn:name="Expression",type="string",JSONPath=`.status.refExpression`,priority=1
+// +kubebuilder:printcolumn:name="Commit",type="string",JSONPath=`.status.lastSyncCommitShort`,priority=1
+// +kubebuilder:printcolumn:name="Author",type="string",JSONPath=`.status.lastSyncCommitInfo.author`,priority=1
+// +kubebuilder:printcolumn:name="Subject",type="string",JSONPath=`.status.lastSyncCommitInfo.subject`,priority=1
+// +kubebuilder:printcolumn:name="Profiles",type="integer",JSONPath=`.status.profileCount`,priority=1
+// +kubebuilder:printcolumn:name="Last Sync",type="date",JSONPath=`.status.lastSyncTime`,priority=1
+
+// GatewaySync is the Schema for the gatewaysyncs API.
+type GatewaySync struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitzero"`
+
+	// spec defines the desired state of GatewaySync.
+	// +required
+	Spec GatewaySyncSpec `json:"spec"`
+
+	// status defines the observed state of GatewaySync.
+	// +optional
+	Status GatewaySyncStatus `json:"status,omitzero"`
+}
+
+// +kubebuilder:object:root=true
+
+// GatewaySyncList contains a list of GatewaySync.
+type GatewaySyncList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitzero"`
+	Items           []GatewaySync `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GatewaySync{}, &GatewaySyncList{})
+}
@@ -0,0 +1,48 @@
+// Package v1beta1 contains API Schema definitions for the sync v1beta1 API group.
+//
+// v1beta1 is the conversion hub: every other served version converts to and
+// from it through the /convert webhook. It differs from v1alpha1 only where
+// v1alpha1 was loosely typed:
+//
+//   - durations (polling.interval, backup retention maxAge, rollback.window,
+//     schedule window duration) are metav1.Duration instead of strings
+//   - mapping type, designerSessionPolicy, verification policy and agent image
+//     pullPolicy are typed enums
+//   - spec.sync.defaults.paused is removed; spec.paused pauses the whole
+//     GatewaySync and profiles[].paused pauses a single profile
+//
+// The CRD ships with v1beta1 unserved. Without the conversion webhook the API
+// server would convert by rewriting apiVersion alone, dropping the fields
+// above, so the controller serves v1beta1 only when it installs the webhook
+// (see webhook.CRDConversionPatcher).
+//
+// Storage version migration. v1alpha1 remains the storage version (and the
+// version the controller reads) until every cluster runs a release that
+// serves v1beta1. To move storage:
+//
+//  1. Move the +kubebuilder:storageversion marker from v1alpha1.GatewaySync
+//     to v1beta1.GatewaySync and regenerate the CRD.
+//  2. Rewrite every GatewaySync once so it is persisted as v1beta1, e.g. with
+//     kube-storage-version-migrator or `kubectl get gs -A -o json | kubectl replace -f -`.
+//  3. Remove "v1alpha1" from the CRD's status.storedVersions.
+//  4. In a later release, stop serving v1alpha1 (served: false), then delete it.
+//
+// +kubebuilder:object:generate=true
+// +groupName=stoker.io
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects.
+	GroupVersion = schema.GroupVersion{Group: "stoker.io", Version: "v1beta1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"os"
 	"os/signal"
 	"syscall"
@@ -27,7 +28,20 @@ func main() {
 	logf.SetLogger(zap.New(zap.UseDevMode(devMode)))
 	log := logf.Log.WithName("agent")
 
-	log.Info("stoker-agent starting", "devMode", devMode)
+	var configFile string
+	flag.StringVar(&configFile, "config", os.Getenv("STOKER_AGENT_CONFIG"),
+		"Path to a standalone agent config file. When set, the agent runs without Kubernetes.")
+	flag.Parse()
+
+	log.Info("stoker-agent starting", "devMode", devMode, "standalone", configFile != "")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if configFile != "" {
+		runStandalone(logf.IntoContext(ctx, log), log, configFile)
+		return
+	}
 
 	// Load configuration from environment.
 	cfg, err := agent.LoadConfig()
@@ -50,10 +64,11 @@ func main() {
 	}
 
 	// Create and run the agent.
-	a := agent.New(cfg, k8sClient, recorder)
-
-	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer cancel()
+	a, err := agent.New(cfg, k8sClient, recorder)
+	if err != nil {
+		log.Error(err, "failed to create agent")
+		os.Exit(1)
+	}
 
 	if err := a.Run(logf.IntoContext(ctx, log)); err != nil {
 		log.Error(err, "agent exited with error")
@@ -63,7 +78,32 @@ func main() {
 	log.Info("agent shutdown complete")
 }
 
-func buildK8sClient() (client.Client, error) {
+// runStandalone runs the agent from a config file, polling git directly and
+// publishing status locally instead of through ConfigMaps.
+func runStandalone(ctx context.Context, log logr.Logger, configFile string) {
+	sc, err := agent.LoadStandaloneConfig(configFile)
+	if err != nil {
+		log.Error(err, "failed to load standalone config")
+		os.Exit(1)
+	}
+
+	a, err := agent.NewStandalone(sc)
+	if err != nil {
+		log.Error(err, "failed to create agent")
+		os.Exit(1)
+	}
+
+	if err := a.Run(ctx); err != nil {
+		log.Error(err, "agent exited with error")
+		os.Exit(1)
+	}
+
+	log.Info("agent shutdown complete")
+}
+
+// buildK8sClient returns a client that can also watch, which the agent uses to
+// follow the metadata ConfigMap without polling.
+func buildK8sClient() (client.WithWatch, error) {
 	scheme := runtime.NewScheme()
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
@@ -72,7 +112,7 @@ func buildK8sClient() (client.Client, error) {
 		return nil, err
 	}
 
-	return client.New(config, client.Options{Scheme: scheme})
+	return client.NewWithWatch(config, client.Options{Scheme: scheme})
 }
 
 // buildEventRecorder creates a K8s event recorder for the agent. Returns
@@ -4,12 +4,15 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"path/filepath"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -19,8 +22,10 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
 	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	stokerv1beta1 "github.com/ia-eknorr/stoker-operator/api/v1beta1"
 	"github.com/ia-eknorr/stoker-operator/internal/controller"
 	"github.com/ia-eknorr/stoker-operator/internal/git"
 	iswebhook "github.com/ia-eknorr/stoker-operator/internal/webhook"
@@ -36,6 +41,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(stokerv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(stokerv1beta1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -49,6 +55,7 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var webhookReceiverPort int
+	var conversionWebhookService string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -69,6 +76,9 @@ func main() {
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.IntVar(&webhookReceiverPort, "webhook-receiver-port", 9444,
 		"Port for the inbound webhook receiver (git push events). Set to 0 to disable.")
+	flag.StringVar(&conversionWebhookService, "conversion-webhook-service", "",
+		"namespace/name of the webhook Service. If set, the GatewaySync CRD is patched at startup to convert "+
+			"versions through it, using ca.crt from --webhook-cert-path.")
 	devMode := os.Getenv("LOG_DEV_MODE") == "true"
 	opts := zap.Options{
 		Development: devMode,
@@ -170,10 +180,25 @@ func main() {
 	}
 
 	autoBindRBAC := os.Getenv("AUTO_BIND_AGENT_RBAC") != "false"
+
+	gitClientKind := os.Getenv("GIT_CLIENT")
+	if gitClientKind == "" {
+		gitClientKind = git.ClientGoGit
+	}
+	gitOpts, err := git.LoadTransportOptions(os.Getenv("GIT_PROXY_URL"), os.Getenv("GIT_CA_FILE"))
+	if err != nil {
+		setupLog.Error(err, "unable to configure git transport")
+		os.Exit(1)
+	}
+	gitClient, err := git.NewClient(gitClientKind, gitOpts)
+	if err != nil {
+		setupLog.Error(err, "unable to create git client")
+		os.Exit(1)
+	}
 	if err := (&controller.GatewaySyncReconciler{
 		Client:            mgr.GetClient(),
 		Scheme:            mgr.GetScheme(),
-		GitClient:         &git.GoGitClient{},
+		GitClient:         gitClient,
 		AutoBindAgentRBAC: autoBindRBAC,
 		//nolint:staticcheck // TODO: migrate to events.EventRecorder
 		Recorder: mgr.GetEventRecorderFor("gatewaysync-controller"),
@@ -181,6 +206,15 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "GatewaySync")
 		os.Exit(1)
 	}
+	if err := (&controller.GatewaySyncSetReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		//nolint:staticcheck // TODO: migrate to events.EventRecorder
+		Recorder: mgr.GetEventRecorderFor("gatewaysyncset-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "GatewaySyncSet")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	// Register mutating webhook for pod injection
@@ -191,6 +225,34 @@ func main() {
 		},
 	})
 
+	// Register validating webhook for GatewaySync resources
+	mgr.GetWebhookServer().Register("/validate-stoker-io-v1alpha1-gatewaysync", &webhook.Admission{
+		Handler: &iswebhook.GatewaySyncValidator{
+			Decoder:            admission.NewDecoder(mgr.GetScheme()),
+			AllowAllNamespaces: os.Getenv("ALLOW_ALL_NAMESPACES") == "true",
+		},
+	})
+
+	// Register conversion webhook for GatewaySync versions
+	mgr.GetWebhookServer().Register(iswebhook.ConversionPath,
+		conversion.NewWebhookHandler(mgr.GetScheme(), mgr.GetConverterRegistry()))
+	if conversionWebhookService != "" {
+		ns, name, ok := strings.Cut(conversionWebhookService, "/")
+		if !ok || ns == "" || name == "" || webhookCertPath == "" {
+			setupLog.Error(nil, "--conversion-webhook-service needs namespace/name and --webhook-cert-path",
+				"conversion-webhook-service", conversionWebhookService)
+			os.Exit(1)
+		}
+		if err := mgr.Add(&iswebhook.CRDConversionPatcher{
+			Client:       mgr.GetClient(),
+			Service:      types.NamespacedName{Namespace: ns, Name: name},
+			CABundlePath: filepath.Join(webhookCertPath, "ca.crt"),
+		}); err != nil {
+			setupLog.Error(err, "unable to add CRD conversion patcher")
+			os.Exit(1)
+		}
+	}
+
 	// Register webhook receiver if port is set
 	if webhookReceiverPort > 0 {
 		//nolint:staticcheck // TODO: migrate to events.EventRecorder
@@ -26,6 +26,8 @@ const sidebars: SidebarsConfig = {
         "guides/webhook-sync",
         "guides/monitoring",
         "guides/multi-site-deployment",
+        "guides/standalone-agent",
+        "guides/audit-logging",
       ],
     },
     {
@@ -3,16 +3,14 @@ package agent
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	gogithttp "github.com/go-git/go-git/v5/plumbing/transport/http"
-	gogitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/go-logr/logr"
-	"golang.org/x/crypto/ssh"
-	"golang.org/x/crypto/ssh/knownhosts"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -22,6 +20,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/ia-eknorr/stoker-operator/internal/audit"
 	"github.com/ia-eknorr/stoker-operator/internal/git"
 	"github.com/ia-eknorr/stoker-operator/internal/ignition"
 	"github.com/ia-eknorr/stoker-operator/internal/syncengine"
@@ -42,19 +41,43 @@ var gatewaySyncGVK = schema.GroupVersionKind{
 // Agent orchestrates the sync process.
 type Agent struct {
 	Config       *Config
-	K8sClient    client.Client
+	K8sClient    client.Client // nil in standalone mode
+	Backend      Backend
 	GitClient    git.Client
+	RefResolver  git.Client // resolves stoker.io/ref-override via ls-remote
 	SyncEngine   *syncengine.Engine
-	IgnitionAPI  *ignition.Client
+	IgnitionAPI  ignition.GatewayClient
 	HealthServer *HealthServer
 	Metrics      *AgentMetrics
 	Watcher      *Watcher
 	Recorder     record.EventRecorder // may be nil
+	Audit        *audit.Logger        // nil when no audit sink is configured
 
 	crRef              *unstructured.Unstructured // cached for event target
 	lastSyncedCommit   string
-	lastSyncedProfiles string // raw profiles JSON; re-sync when CR profile changes
-	initialSyncDone    bool
+	lastSyncedRef      string
+	lastSyncedProfiles string     // raw profiles JSON; re-sync when CR profile changes
+	lastBackup         string     // file name of the most recent pre-change backup
+	lastFetch          git.Result // most recent clone/fetch, with commit details; see commitDetails
+	checkedOut         string     // commit in RepoPath, possibly fetched but not applied; see restoreCheckout
+	gatewayVersion     string     // detected Ignition version; empty until detected
+	refOverride        string     // active stoker.io/ref-override; empty when following metadata
+	profileName        string     // profile from the stoker.io/profile annotation; see refreshProfileName
+	profileLibrary     string     // library version of the last profile synced; see ResolvedProfile.Library
+	deferredCommit     string     // new commit held by spec.sync.schedule; see deferredBySchedule
+
+	// Profile transitions: the profile and destinations of the last applied
+	// plan, and the most recent switch for status reporting.
+	lastSyncedProfileName string
+	lastManagedRoots      []string
+	previousProfileName   string
+	profileChangedTime    string
+
+	// Sync history reported in status; see recordSync.
+	history         syncHistory
+	syncTrigger     string
+	syncStart       time.Time
+	initialSyncDone bool
 
 	// Exponential backoff for consecutive sync failures.
 	consecutiveErrors int
@@ -66,25 +89,62 @@ type Agent struct {
 }
 
 // New creates a new Agent with all dependencies wired.
-func New(cfg *Config, k8sClient client.Client, recorder record.EventRecorder) *Agent {
+func New(cfg *Config, k8sClient client.WithWatch, recorder record.EventRecorder) (*Agent, error) {
+	a, err := newAgent(cfg, &KubeBackend{Client: k8sClient, Config: cfg})
+	if err != nil {
+		return nil, err
+	}
+	a.K8sClient = k8sClient
+	a.Recorder = recorder
+	a.Watcher = NewWatcher(k8sClient, cfg.CRNamespace, cfg.CRName, time.Duration(cfg.SyncPeriod)*time.Second)
+	return a, nil
+}
+
+// newAgent wires the components shared by the Kubernetes and standalone modes.
+// The caller sets the watcher and any Kubernetes clients.
+func newAgent(cfg *Config, backend Backend) (*Agent, error) {
 	// Build exclude patterns.
 	excludes := []string{"**/.git/**", "**/.git", "**/.gitkeep", "**/.resources/**", "**/.resources"}
 
 	// Build Ignition API client.
-	igClient := ignition.NewClient(cfg.GatewayScheme(), cfg.GatewayHost(), cfg.APIKey())
+	tlsConfig, err := cfg.GatewayTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("building gateway TLS config: %w", err)
+	}
+	// The API generation is detected once the gateway is up (see postCommissionSync).
+	igClient := ignition.NewVersionedClient(
+		ignition.NewClient(cfg.GatewayScheme(), cfg.GatewayHost(), cfg.APIKey(), tlsConfig))
+
+	// Manual resync requests authenticate with the gateway API key.
+	healthServer := NewHealthServer(cfg.HealthAddr)
+	healthServer.EnableResync(cfg.APIKey)
+
+	auditLogger, err := audit.New(cfg.Audit, cfg.GatewayName)
+	if err != nil {
+		return nil, fmt.Errorf("configuring audit sinks: %w", err)
+	}
+
+	gitOpts, err := git.LoadTransportOptions(cfg.GitProxyURL, cfg.GitCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("configuring git transport: %w", err)
+	}
+	gitClient, err := git.NewClient(cfg.GitClient, gitOpts)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Agent{
 		Config:       cfg,
-		K8sClient:    k8sClient,
-		GitClient:    &git.NativeGitClient{},
+		Backend:      backend,
+		GitClient:    gitClient,
+		RefResolver:  gitClient,
 		SyncEngine:   &syncengine.Engine{ExcludePatterns: excludes},
 		IgnitionAPI:  igClient,
-		HealthServer: NewHealthServer(":8082"),
+		HealthServer: healthServer,
 		Metrics:      NewAgentMetrics(),
-		Watcher:      NewWatcher(k8sClient, cfg.CRNamespace, cfg.CRName, time.Duration(cfg.SyncPeriod)*time.Second),
-		Recorder:     recorder,
+		Audit:        auditLogger,
 		shutdownCh:   make(chan struct{}, 1),
-	}
+	}, nil
 }
 
 // Run starts the agent. It clones the repo, performs the initial sync, marks
@@ -93,6 +153,10 @@ func New(cfg *Config, k8sClient client.Client, recorder record.EventRecorder) *A
 func (a *Agent) Run(ctx context.Context) error {
 	log := logf.FromContext(ctx).WithName("agent")
 
+	if a.Config.GatewayTLS && a.Config.GatewayInsecure {
+		log.Info("gateway TLS certificate verification disabled by insecureSkipVerify opt-in")
+	}
+
 	log.Info("starting agent",
 		"gateway", a.Config.GatewayName,
 		"cr", a.Config.CRName,
@@ -106,19 +170,27 @@ func (a *Agent) Run(ctx context.Context) error {
 	go a.HealthServer.Start(ctx)
 
 	// Start metrics server on a dedicated port (separate from health probes).
-	metricsServer := NewMetricsServer(":8083", a.Metrics.Handler())
+	metricsServer := NewMetricsServer(a.Config.MetricsAddr, a.Metrics.Handler())
 	go metricsServer.Start(ctx)
 
-	// Read metadata ConfigMap to get git URL and commit.
-	log.Info("reading metadata ConfigMap")
+	defer func() {
+		if err := a.Audit.Close(); err != nil {
+			log.Error(err, "closing audit sinks")
+		}
+	}()
+
+	// Read sync metadata (ConfigMap, or ls-remote in standalone mode) to get git URL and commit.
+	log.Info("reading sync metadata")
 	meta, err := a.waitForMetadata(ctx)
 	if err != nil {
 		return fmt.Errorf("waiting for metadata: %w", err)
 	}
 
 	log.Info("metadata loaded", "gitURL", meta.GitURL, "commit", meta.Commit, "ref", meta.Ref)
+	a.restoreHistory(ctx)
 
 	// Apply profile-level syncPeriod if present (overrides env var default).
+	a.refreshProfileName(ctx)
 	a.applySyncPeriodFromMeta(meta, log)
 
 	// Cache GatewaySync CR reference for event emission.
@@ -133,10 +205,13 @@ func (a *Agent) Run(ctx context.Context) error {
 		return fmt.Errorf("gitURL not found in metadata ConfigMap")
 	}
 
-	// Initial clone.
-	log.Info("cloning repository", "url", gitURL, "ref", meta.Ref)
+	// Initial clone. A stoker.io/ref-override annotation pins this pod to its own
+	// ref; a staged rollout may publish this gateway its own target.
+	ref := a.targetRef(ctx, meta)
+	sparse := a.sparsePaths(ctx, meta, meta.Commit, ref)
+	log.Info("cloning repository", "url", gitURL, "ref", ref, "sparsePaths", len(sparse))
 	cloneStart := time.Now()
-	result, err := a.GitClient.CloneOrFetch(ctx, gitURL, meta.Ref, a.Config.RepoPath, auth)
+	result, err := a.GitClient.CloneOrFetch(ctx, gitURL, ref, a.Config.RepoPath, sparse, auth)
 	a.Metrics.GitFetchDuration.WithLabelValues("clone").Observe(time.Since(cloneStart).Seconds())
 	if err != nil {
 		a.Metrics.GitFetchTotal.WithLabelValues("clone", "error").Inc()
@@ -144,12 +219,21 @@ func (a *Agent) Run(ctx context.Context) error {
 		return fmt.Errorf("initial clone: %w", err)
 	}
 	a.Metrics.GitFetchTotal.WithLabelValues("clone", "success").Inc()
+	a.checkedOut = result.Commit
+	if signer, err := a.verifyCheckout(result); err != nil {
+		a.event(corev1.EventTypeWarning, conditions.ReasonSignatureVerificationFailed, "Refusing to sync %s: %v", result.Ref, err)
+		return fmt.Errorf("verifying clone: %w", err)
+	} else if signer != "" {
+		log.Info("commit signature verified", "commit", result.Commit, "signer", signer)
+	}
+	a.lastFetch = result
 	log.Info("clone complete", "commit", result.Commit)
 
 	// Initial sync (blocking). Files land on disk before startup probe passes,
 	// so the gateway container won't start until config is ready.
 	log.Info("performing initial sync")
-	syncErr := a.syncOnce(ctx, result.Commit, result.Ref, true, meta.Profiles)
+	a.beginSync(stokertypes.SyncTriggerInitial)
+	syncErr := a.syncOnce(ctx, result.Commit, result.Ref, syncInitial, meta.Profiles)
 	if syncErr != nil {
 		log.Error(syncErr, "initial sync had errors (continuing)")
 	}
@@ -197,6 +281,9 @@ func (a *Agent) Run(ctx context.Context) error {
 		case <-a.Watcher.Events():
 			log.V(1).Info("sync triggered")
 			a.handleSyncTrigger(ctx, gitURL, auth)
+
+		case mode := <-a.HealthServer.ResyncRequests():
+			a.handleResync(ctx, mode, gitURL, auth)
 		}
 	}
 }
@@ -227,8 +314,10 @@ func (a *Agent) postCommissionSync(ctx context.Context, commit, ref string) {
 		}
 
 		a.Metrics.GatewayStartupDuration.Observe(time.Since(startupStart).Seconds())
+		a.detectGatewayVersion(ctx)
 		log.Info("gateway responsive, running post-commission re-sync")
-		if err := a.syncOnce(ctx, commit, ref, false, a.lastSyncedProfiles); err != nil {
+		a.beginSync(stokertypes.SyncTriggerPostCommission)
+		if err := a.syncOnce(ctx, commit, ref, syncNormal, a.lastSyncedProfiles); err != nil {
 			log.Error(err, "post-commission sync failed")
 		} else {
 			log.Info("post-commission sync complete")
@@ -237,27 +326,52 @@ func (a *Agent) postCommissionSync(ctx context.Context, commit, ref string) {
 	}
 }
 
-// waitForMetadata polls for the metadata ConfigMap until it's available.
+// gatewayVersionDetector is implemented by gateway clients that select their
+// API implementation from the running gateway's version.
+type gatewayVersionDetector interface {
+	Detect(ctx context.Context) (ignition.GatewayVersion, error)
+}
+
+// detectGatewayVersion identifies the Ignition version so the client can fall
+// back to the reduced 8.1 API. Detection failures keep the 8.3 defaults.
+func (a *Agent) detectGatewayVersion(ctx context.Context) {
+	log := logf.FromContext(ctx).WithName("gateway-version")
+
+	detector, ok := a.IgnitionAPI.(gatewayVersionDetector)
+	if !ok {
+		return
+	}
+	version, err := detector.Detect(ctx)
+	if err != nil {
+		log.Info("could not detect gateway version, assuming 8.3 API", "error", err)
+		return
+	}
+
+	a.gatewayVersion = version.String()
+	a.Metrics.GatewayInfo.WithLabelValues(a.gatewayVersion).Set(1)
+
+	caps := a.IgnitionAPI.Capabilities()
+	log.Info("detected gateway version",
+		"version", a.gatewayVersion,
+		"scanAPI", caps.ScanAPI,
+		"designerSessions", caps.DesignerSessions,
+		"backup", caps.Backup,
+	)
+}
+
+// waitForMetadata polls the backend until sync metadata with a commit is available.
 func (a *Agent) waitForMetadata(ctx context.Context) (*Metadata, error) {
 	log := logf.FromContext(ctx)
 
 	for {
-		meta, err := ReadMetadataConfigMap(ctx, a.K8sClient, a.Config.CRNamespace, a.Config.CRName)
+		a.invalidateMetadata()
+		meta, err := a.Backend.ReadMetadata(ctx)
 		if err == nil && meta.Commit != "" {
 			return meta, nil
 		}
 
 		if err != nil {
-			if isForbidden(err) {
-				log.Error(err, "RBAC permission denied — agent cannot read metadata ConfigMap",
-					"namespace", a.Config.CRNamespace,
-					"configmap", MetadataConfigMapName(a.Config.CRName),
-					"hint", fmt.Sprintf("ensure agent RBAC is configured: kubectl create rolebinding stoker-agent -n %s --clusterrole=stoker-agent --serviceaccount=%s:<service-account>",
-						a.Config.CRNamespace, a.Config.CRNamespace),
-				)
-			} else {
-				log.V(1).Info("metadata not available yet, retrying", "error", err)
-			}
+			log.V(1).Info("metadata not available yet, retrying", "error", err)
 		}
 
 		select {
@@ -279,11 +393,13 @@ func (a *Agent) handleSyncTrigger(ctx context.Context, gitURL string, auth trans
 		return
 	}
 
-	meta, err := ReadMetadataConfigMap(ctx, a.K8sClient, a.Config.CRNamespace, a.Config.CRName)
+	a.invalidateMetadata()
+	meta, err := a.Backend.ReadMetadata(ctx)
 	if err != nil {
-		log.Error(err, "failed to read metadata ConfigMap")
+		log.Error(err, "failed to read sync metadata")
 		return
 	}
+	a.beginSync(stokertypes.SyncTriggerCommit)
 
 	if meta.Paused == "true" {
 		log.V(1).Info("CR is paused, skipping sync")
@@ -299,35 +415,57 @@ func (a *Agent) handleSyncTrigger(ctx context.Context, gitURL string, auth trans
 		}
 	}
 
-	// Check if commit or profiles changed.
-	if meta.Commit == a.lastSyncedCommit && meta.Profiles == a.lastSyncedProfiles {
-		log.V(1).Info("commit and profiles unchanged, skipping sync", "commit", meta.Commit)
+	// Resolve the target commit (metadata commit, rollout target, or the pod's ref override).
+	commit, ref, err := a.resolveTarget(ctx, meta, gitURL, auth)
+	if err != nil {
+		a.consecutiveErrors++
+		delay := min(30*time.Second<<(a.consecutiveErrors-1), 5*time.Minute)
+		a.backoffUntil = time.Now().Add(delay)
+		log.Error(err, "target resolution failed, backing off", "consecutiveErrors", a.consecutiveErrors, "retryIn", delay)
+		a.reportError(ctx, a.lastSyncedCommit, ref, err.Error())
+		return
+	}
+
+	// Check if commit, profiles, or the selected profile changed.
+	profileName := a.refreshProfileName(ctx)
+	if commit == a.lastSyncedCommit && meta.Profiles == a.lastSyncedProfiles && profileName == a.lastSyncedProfileName {
+		log.V(1).Info("commit and profiles unchanged, skipping sync", "commit", commit)
 		a.Metrics.SyncSkippedTotal.WithLabelValues("commit_unchanged").Inc()
+		if ref != a.lastSyncedRef {
+			a.updateSyncedRef(ctx, ref)
+		}
 		return
 	}
 
-	if meta.Commit != a.lastSyncedCommit {
-		log.Info("new commit detected", "old", a.lastSyncedCommit, "new", meta.Commit, "ref", meta.Ref)
-	} else {
-		log.Info("profiles changed, re-syncing", "commit", meta.Commit)
+	// A new commit waits for the next maintenance window. The initial sync
+	// and profile changes are never deferred.
+	if commit != a.lastSyncedCommit && a.lastSyncedCommit != "" && a.deferredBySchedule(ctx, meta, commit, time.Now()) {
+		a.Metrics.SyncSkippedTotal.WithLabelValues("outside_window").Inc()
+		return
+	}
+
+	switch {
+	case commit != a.lastSyncedCommit:
+		log.Info("new commit detected", "old", a.lastSyncedCommit, "new", commit, "ref", ref)
+	case profileName != a.lastSyncedProfileName:
+		a.syncTrigger = stokertypes.SyncTriggerProfileSwitch
+		log.Info("profile switch requested, re-planning", "from", a.lastSyncedProfileName, "to", profileName, "commit", commit)
+	default:
+		a.syncTrigger = stokertypes.SyncTriggerProfileUpdate
+		log.Info("profiles changed, re-syncing", "commit", commit)
 	}
 
 	// Mark sync in progress for graceful shutdown tracking.
-	a.syncInProgress.Store(true)
-	defer func() {
-		a.syncInProgress.Store(false)
-		select {
-		case a.shutdownCh <- struct{}{}:
-		default:
-		}
-	}()
+	done := a.trackSync()
+	defer done()
 
 	// Use a context that survives SIGTERM so in-flight syncs can complete.
 	syncCtx := context.WithoutCancel(ctx)
 
-	// Fetch and checkout new commit.
+	// Fetch and checkout new commit, limited to the paths the profile maps.
+	sparse := a.sparsePaths(ctx, meta, commit, ref)
 	fetchStart := time.Now()
-	result, err := a.GitClient.CloneOrFetch(syncCtx, gitURL, meta.Ref, a.Config.RepoPath, auth)
+	result, err := a.GitClient.CloneOrFetch(syncCtx, gitURL, ref, a.Config.RepoPath, sparse, auth)
 	a.Metrics.GitFetchDuration.WithLabelValues("fetch").Observe(time.Since(fetchStart).Seconds())
 	if err != nil {
 		a.Metrics.GitFetchTotal.WithLabelValues("fetch", "error").Inc()
@@ -335,15 +473,33 @@ func (a *Agent) handleSyncTrigger(ctx context.Context, gitURL string, auth trans
 		delay := min(30*time.Second<<(a.consecutiveErrors-1), 5*time.Minute)
 		a.backoffUntil = time.Now().Add(delay)
 		log.Error(err, "git fetch failed, backing off", "consecutiveErrors", a.consecutiveErrors, "retryIn", delay)
-		a.reportError(ctx, meta.Commit, meta.Ref, fmt.Sprintf("git fetch: %v", err))
+		a.reportError(ctx, commit, ref, fmt.Sprintf("git fetch: %v", err))
 		return
 	}
 	a.Metrics.GitFetchTotal.WithLabelValues("fetch", "success").Inc()
+	a.checkedOut = result.Commit
+	if signer, err := a.verifyCheckout(result); err != nil {
+		a.consecutiveErrors++
+		delay := min(30*time.Second<<(a.consecutiveErrors-1), 5*time.Minute)
+		a.backoffUntil = time.Now().Add(delay)
+		log.Error(err, "signature verification failed, backing off", "commit", result.Commit, "retryIn", delay)
+		a.event(corev1.EventTypeWarning, conditions.ReasonSignatureVerificationFailed, "Refusing to sync %s: %v", result.Ref, err)
+		a.reportError(ctx, result.Commit, result.Ref, fmt.Sprintf("signature verification: %v", err))
+		// The unverified tree is already in RepoPath; put the synced commit
+		// back so no later sync or resync copies from it.
+		if err := a.restoreCheckout(syncCtx, meta, gitURL, auth); err != nil {
+			log.Error(err, "failed to restore the synced commit after verification failure")
+		}
+		return
+	} else if signer != "" {
+		log.V(1).Info("commit signature verified", "commit", result.Commit, "signer", signer)
+	}
+	a.lastFetch = result
 
 	log.V(1).Info("git updated", "commit", result.Commit)
 
 	// Pre-sync designer session check via resolved profile from metadata.
-	profile, profileName, err := a.lookupProfile(meta)
+	profile, _, err := a.lookupProfile(meta)
 	if err != nil {
 		log.Error(err, "failed to look up profile for designer check")
 		a.Metrics.SyncSkippedTotal.WithLabelValues("profile_error").Inc()
@@ -354,20 +510,11 @@ func (a *Agent) handleSyncTrigger(ctx context.Context, gitURL string, auth trans
 	// Update watcher period if profile specifies a different syncPeriod.
 	a.applySyncPeriod(profile, log)
 
-	if !profile.Paused && !profile.DryRun {
-		if blocked := a.checkDesignerSessions(ctx, profile.DesignerSessionPolicy, result.Commit, result.Ref); blocked {
-			a.Metrics.DesignerBlocked.Set(1)
-			a.Metrics.SyncSkippedTotal.WithLabelValues("designer_blocked").Inc()
-			a.event(corev1.EventTypeWarning, conditions.ReasonSyncSkipped,
-				"Sync skipped: designer sessions blocked sync (policy=%s)", profile.DesignerSessionPolicy)
-			return
-		}
-		a.Metrics.DesignerBlocked.Set(0)
+	if a.designerBlocksSync(ctx, profile, result.Commit, result.Ref) {
+		return
 	}
 
-	_ = profileName // used in syncWithProfile via metadata re-read
-
-	if syncErr := a.syncOnce(syncCtx, result.Commit, result.Ref, false, meta.Profiles); syncErr != nil {
+	if syncErr := a.syncOnce(syncCtx, result.Commit, result.Ref, syncNormal, meta.Profiles); syncErr != nil {
 		a.consecutiveErrors++
 		delay := min(30*time.Second<<(a.consecutiveErrors-1), 5*time.Minute)
 		a.backoffUntil = time.Now().Add(delay)
@@ -378,6 +525,27 @@ func (a *Agent) handleSyncTrigger(ctx context.Context, gitURL string, auth trans
 	}
 }
 
+// invalidateMetadata starts a new sync trigger for backends that cache
+// remotely resolved metadata.
+func (a *Agent) invalidateMetadata() {
+	if c, ok := a.Backend.(metadataCache); ok {
+		c.InvalidateMetadata()
+	}
+}
+
+// trackSync marks a sync as in progress for graceful shutdown. The returned
+// func clears the flag and wakes a pending shutdown.
+func (a *Agent) trackSync() func() {
+	a.syncInProgress.Store(true)
+	return func() {
+		a.syncInProgress.Store(false)
+		select {
+		case a.shutdownCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
 // applySyncPeriodFromMeta looks up the resolved profile and applies its
 // syncPeriod to the watcher if set.
 func (a *Agent) applySyncPeriodFromMeta(meta *Metadata, log logr.Logger) {
@@ -406,11 +574,7 @@ func (a *Agent) lookupProfile(meta *Metadata) (*stokertypes.ResolvedProfile, str
 		return nil, "", fmt.Errorf("parsing profiles: %w", err)
 	}
 
-	profileName := a.Config.ProfileName
-	if profileName == "" {
-		profileName = "default"
-	}
-
+	profileName := a.activeProfileName()
 	profile, ok := profiles[profileName]
 	if !ok {
 		return nil, profileName, fmt.Errorf("profile %q not found in metadata ConfigMap", profileName)
@@ -419,6 +583,24 @@ func (a *Agent) lookupProfile(meta *Metadata) (*stokertypes.ResolvedProfile, str
 	return profile, profileName, nil
 }
 
+// designerBlocksSync applies profile's designer session policy before files
+// change on a running gateway. It returns true, after recording the skip, when
+// the sync must not proceed. Paused and dry-run profiles never change files.
+func (a *Agent) designerBlocksSync(ctx context.Context, profile *stokertypes.ResolvedProfile, commit, ref string) bool {
+	if profile.Paused || profile.DryRun {
+		return false
+	}
+	if a.checkDesignerSessions(ctx, profile.DesignerSessionPolicy, commit, ref) {
+		a.Metrics.DesignerBlocked.Set(1)
+		a.Metrics.SyncSkippedTotal.WithLabelValues("designer_blocked").Inc()
+		a.event(corev1.EventTypeWarning, conditions.ReasonSyncSkipped,
+			"Sync skipped: designer sessions blocked sync (policy=%s)", profile.DesignerSessionPolicy)
+		return true
+	}
+	a.Metrics.DesignerBlocked.Set(0)
+	return false
+}
+
 // checkDesignerSessions enforces the designer session policy before sync.
 // Returns true if the sync should be skipped (blocked or failed).
 func (a *Agent) checkDesignerSessions(ctx context.Context, policy, commit, ref string) bool {
@@ -428,6 +610,13 @@ func (a *Agent) checkDesignerSessions(ctx context.Context, policy, commit, ref s
 		policy = "proceed"
 	}
 
+	if !a.IgnitionAPI.Capabilities().DesignerSessions {
+		if policy != "proceed" {
+			log.Info("gateway cannot list designer sessions, policy not enforced", "policy", policy, "gatewayVersion", a.gatewayVersion)
+		}
+		return false
+	}
+
 	sessions, err := a.IgnitionAPI.GetDesignerSessions(ctx)
 	if err != nil {
 		log.Info("failed to query designer sessions (continuing sync)", "error", err)
@@ -501,11 +690,26 @@ func (a *Agent) setDesignerBlocked(ctx context.Context, blocked bool) {
 		AgentVersion:            agentVersion,
 		LastSyncTime:            time.Now().UTC().Format(time.RFC3339),
 		DesignerSessionsBlocked: blocked,
+		History:                 a.history.list(),
 	}
 	if a.lastSyncedCommit != "" {
 		status.SyncedCommit = a.lastSyncedCommit
 	}
-	_ = WriteStatusConfigMap(ctx, a.K8sClient, a.Config.CRNamespace, a.Config.CRName, a.Config.GatewayName, status)
+	_ = a.Backend.WriteStatus(ctx, status)
+}
+
+// updateSyncedRef reports a new ref, e.g. a new tag, for the commit already
+// synced without syncing again.
+func (a *Agent) updateSyncedRef(ctx context.Context, ref string) {
+	a.lastSyncedRef = ref
+	status, err := a.Backend.ReadStatus(ctx)
+	if err != nil || status == nil || status.SyncedCommit != a.lastSyncedCommit {
+		return
+	}
+	status.SyncedRef = ref
+	if err := a.Backend.WriteStatus(ctx, status); err != nil {
+		logf.FromContext(ctx).Error(err, "failed to report synced ref", "ref", ref)
+	}
 }
 
 // formatDesignerSessions builds a human-readable summary of active sessions.
@@ -517,12 +721,36 @@ func formatDesignerSessions(sessions []ignition.DesignerSession) string {
 	return strings.Join(parts, ", ")
 }
 
+// syncMode selects what syncOnce does around copying files.
+type syncMode int
+
+const (
+	// syncNormal takes a pre-change backup, copies files, and triggers a scan.
+	// Status is Synced only if the scan succeeds.
+	syncNormal syncMode = iota
+	// syncInitial copies files before the gateway starts: no backup and no
+	// scan. Status is Pending until the gateway picks the files up.
+	syncInitial
+	// syncStageOnly copies files on a running gateway without a scan, for a
+	// stage resync. Status is Synced once the files are in place.
+	syncStageOnly
+)
+
 // syncOnce performs a single sync cycle: copy files, trigger scan, report status.
-func (a *Agent) syncOnce(ctx context.Context, commit, ref string, isInitial bool, profiles string) error {
+func (a *Agent) syncOnce(ctx context.Context, commit, ref string, mode syncMode, profiles string) error {
 	log := logf.FromContext(ctx).WithName("sync")
+	isInitial := mode == syncInitial
+
+	// Pre-change backups need a running gateway, so the initial sync (which
+	// runs before the gateway starts) never takes one.
+	var backupName string
+	var beforeApply func(*syncengine.DryRunDiff) error
+	if !isInitial {
+		beforeApply = a.backupBeforeApply(ctx, commit, &backupName)
+	}
 
 	syncStart := time.Now()
-	syncResult, profileName, isDryRun, err := a.syncWithProfile(ctx)
+	syncResult, profileName, isDryRun, err := a.syncWithProfile(ctx, commit, ref, beforeApply)
 	a.Metrics.SyncDuration.WithLabelValues(profileName).Observe(time.Since(syncStart).Seconds())
 
 	if err != nil {
@@ -530,9 +758,21 @@ func (a *Agent) syncOnce(ctx context.Context, commit, ref string, isInitial bool
 		a.Metrics.LastSyncSuccess.Set(0)
 		a.reportError(ctx, commit, ref, fmt.Sprintf("sync engine: %v", err))
 		a.event(corev1.EventTypeWarning, conditions.ReasonSyncFailed, "Sync failed: %v", err)
+		a.auditSync(ctx, &audit.Record{
+			Commit:  commit,
+			Ref:     ref,
+			Profile: profileName,
+			Result:  stokertypes.SyncStatusError,
+			DryRun:  isDryRun,
+			Error:   err.Error(),
+		}, syncResult)
 		return fmt.Errorf("sync engine: %w", err)
 	}
 
+	if backupName != "" {
+		a.lastBackup = backupName
+	}
+
 	filesChanged := int32(syncResult.FilesAdded + syncResult.FilesModified + syncResult.FilesDeleted)
 	a.Metrics.FilesChanged.WithLabelValues(profileName).Set(float64(filesChanged))
 	a.Metrics.FilesAdded.WithLabelValues(profileName).Set(float64(syncResult.FilesAdded))
@@ -556,8 +796,11 @@ func (a *Agent) syncOnce(ctx context.Context, commit, ref string, isInitial bool
 	// During shutdown, skip scan and status write — file sync (critical) is done.
 	if a.HealthServer.IsShuttingDown() {
 		log.Info("shutdown in progress, skipping scan and status write")
+		a.recordProfileSwitch(ctx, profileName, syncResult.FilesDeleted)
 		a.lastSyncedCommit = commit
+		a.lastSyncedRef = ref
 		a.lastSyncedProfiles = profiles
+		a.lastSyncedProfileName = profileName
 		return nil
 	}
 
@@ -566,13 +809,18 @@ func (a *Agent) syncOnce(ctx context.Context, commit, ref string, isInitial bool
 	var scanResultStr string
 	if isDryRun {
 		log.Info("dry-run mode, skipping scan API")
+	} else if mode == syncStageOnly {
+		log.V(1).Info("stage-only sync, skipping scan API")
 	} else if !isInitial {
 		log.V(1).Info("triggering Ignition scan API")
 		scanStart := time.Now()
 		scanResult := a.IgnitionAPI.TriggerScan()
 		a.Metrics.ScanDuration.Observe(time.Since(scanStart).Seconds())
 		scanResultStr = scanResult.String()
-		if scanResult.Error != "" {
+		if scanResult.Skipped {
+			a.Metrics.ScanTotal.WithLabelValues("skipped").Inc()
+			log.V(1).Info("gateway has no scan API, skipping scan", "gatewayVersion", a.gatewayVersion)
+		} else if scanResult.Error != "" {
 			a.Metrics.ScanTotal.WithLabelValues("error").Inc()
 			log.Info("scan API failed (non-fatal)", "error", scanResult.Error)
 		} else {
@@ -589,11 +837,11 @@ func (a *Agent) syncOnce(ctx context.Context, commit, ref string, isInitial bool
 
 	// Determine status: only "Synced" if scan succeeded (both 200).
 	// Initial sync reports "Pending" since gateway isn't running yet to validate.
-	// Dry-run is always "Synced" on success — staging files IS the success state.
+	// Dry-run and stage-only syncs are "Synced" on success — staging files IS the success state.
 	syncStatus := stokertypes.SyncStatusSynced
 	var errorMsg string
-	if isDryRun {
-		// dry-run: no scan needed, staging files successfully is the success state
+	if isDryRun || mode == syncStageOnly {
+		// no scan needed, staging files successfully is the success state
 	} else if isInitial {
 		syncStatus = stokertypes.SyncStatusPending
 	} else if scanResultStr == "" || strings.Contains(scanResultStr, "error") {
@@ -604,20 +852,30 @@ func (a *Agent) syncOnce(ctx context.Context, commit, ref string, isInitial bool
 		}
 	}
 
+	a.recordProfileSwitch(ctx, profileName, syncResult.FilesDeleted)
+
+	commitInfo := a.commitDetails(ctx, commit)
+
 	// Report status to ConfigMap.
 	status := &stokertypes.GatewayStatus{
-		SyncStatus:       syncStatus,
-		SyncedCommit:     commit,
-		SyncedRef:        ref,
-		LastSyncTime:     time.Now().UTC().Format(time.RFC3339),
-		LastSyncDuration: syncResult.Duration.Round(time.Millisecond).String(),
-		AgentVersion:     agentVersion,
-		LastScanResult:   scanResultStr,
-		FilesChanged:     filesChanged,
-		ProjectsSynced:   syncResult.ProjectsSynced,
-		ErrorMessage:     errorMsg,
-		ProfileName:      profileName,
-		DryRun:           isDryRun,
+		SyncStatus:          syncStatus,
+		SyncedCommit:        commit,
+		SyncedRef:           ref,
+		SyncedCommitInfo:    commitInfo.Status(),
+		LastSyncTime:        time.Now().UTC().Format(time.RFC3339),
+		LastSyncDuration:    syncResult.Duration.Round(time.Millisecond).String(),
+		AgentVersion:        agentVersion,
+		LastScanResult:      scanResultStr,
+		FilesChanged:        filesChanged,
+		ProjectsSynced:      syncResult.ProjectsSynced,
+		LastBackup:          a.lastBackup,
+		GatewayVersion:      a.gatewayVersion,
+		ErrorMessage:        errorMsg,
+		ProfileName:         profileName,
+		ProfileLibrary:      a.profileLibrary,
+		PreviousProfileName: a.previousProfileName,
+		ProfileChangedTime:  a.profileChangedTime,
+		DryRun:              isDryRun,
 	}
 
 	if isDryRun && syncResult.DryRunDiff != nil {
@@ -626,7 +884,23 @@ func (a *Agent) syncOnce(ctx context.Context, commit, ref string, isInitial bool
 		status.DryRunDiffDeleted = int32(len(syncResult.DryRunDiff.Deleted))
 	}
 
-	if err := WriteStatusConfigMap(ctx, a.K8sClient, a.Config.CRNamespace, a.Config.CRName, a.Config.GatewayName, status); err != nil {
+	a.recordSync(status, stokertypes.SyncRecord{
+		FilesAdded:    int32(syncResult.FilesAdded),
+		FilesModified: int32(syncResult.FilesModified),
+		FilesDeleted:  int32(syncResult.FilesDeleted),
+	})
+
+	a.auditSync(ctx, &audit.Record{
+		Commit:     commit,
+		Ref:        ref,
+		Profile:    profileName,
+		Result:     syncStatus,
+		DryRun:     isDryRun,
+		ScanResult: scanResultStr,
+		Error:      errorMsg,
+	}, syncResult)
+
+	if err := a.Backend.WriteStatus(ctx, status); err != nil {
 		log.Error(err, "failed to write status ConfigMap")
 	} else {
 		log.V(1).Info("status written to ConfigMap", "gateway", a.Config.GatewayName, "status", syncStatus)
@@ -637,20 +911,23 @@ func (a *Agent) syncOnce(ctx context.Context, commit, ref string, isInitial bool
 	a.Metrics.LastSyncSuccess.Set(1)
 
 	a.event(corev1.EventTypeNormal, conditions.ReasonSyncCompleted,
-		"Sync completed on %s: commit %s, %d file(s) changed", a.Config.GatewayName, commit[:min(12, len(commit))], filesChanged)
+		"Sync completed on %s: commit %s, %d file(s) changed", a.Config.GatewayName, git.DescribeCommit(commit, commitInfo), filesChanged)
 
 	a.lastSyncedCommit = commit
+	a.lastSyncedRef = ref
 	a.lastSyncedProfiles = profiles
+	a.lastSyncedProfileName = profileName
 	return nil
 }
 
 // syncWithProfile looks up the resolved profile from the metadata ConfigMap,
-// builds a plan, and executes it.
-func (a *Agent) syncWithProfile(ctx context.Context) (*syncengine.SyncResult, string, bool, error) {
+// builds a plan for commit/ref, and executes it. beforeApply, if non-nil, runs
+// after staging and before the live directory is modified (ignored for dry-run profiles).
+func (a *Agent) syncWithProfile(ctx context.Context, commit, ref string, beforeApply func(*syncengine.DryRunDiff) error) (*syncengine.SyncResult, string, bool, error) {
 	log := logf.FromContext(ctx).WithName("profile-sync")
 
 	// Read metadata ConfigMap (contains profiles JSON + git info).
-	meta, err := ReadMetadataConfigMap(ctx, a.K8sClient, a.Config.CRNamespace, a.Config.CRName)
+	meta, err := a.Backend.ReadMetadata(ctx)
 	if err != nil {
 		return nil, "", false, fmt.Errorf("reading metadata: %w", err)
 	}
@@ -661,7 +938,8 @@ func (a *Agent) syncWithProfile(ctx context.Context) (*syncengine.SyncResult, st
 		return nil, "", false, err
 	}
 
-	log.V(1).Info("using profile", "name", profileName)
+	log.V(1).Info("using profile", "name", profileName, "library", profile.Library)
+	a.profileLibrary = profile.Library
 
 	// Check if profile is paused.
 	if profile.Paused {
@@ -670,17 +948,15 @@ func (a *Agent) syncWithProfile(ctx context.Context) (*syncengine.SyncResult, st
 	}
 
 	// Read pod labels for template context.
-	var pod corev1.Pod
-	if err := a.K8sClient.Get(ctx, client.ObjectKey{Name: a.Config.PodName, Namespace: a.Config.PodNamespace}, &pod); err != nil {
-		if isForbidden(err) {
-			log.Error(err, "RBAC permission denied — agent cannot read pod labels",
-				"pod", a.Config.PodName, "namespace", a.Config.PodNamespace)
-		}
-		return nil, profileName, profile.DryRun, fmt.Errorf("reading pod labels: %w", err)
+	labels, err := a.Backend.Labels(ctx)
+	if err != nil {
+		return nil, profileName, profile.DryRun, err
 	}
 
-	// Build template context.
-	tmplCtx := buildTemplateContext(a.Config, meta, profile.Vars, pod.Labels)
+	// Build template context from what is being synced, which differs from
+	// the metadata under a ref override.
+	meta.Commit, meta.Ref = commit, ref
+	tmplCtx := buildTemplateContext(a.Config, meta, profile.Vars, labels)
 
 	// Build sync plan (no crExcludes — controller already merged excludes into profile).
 	plan, err := buildSyncPlan(profile, tmplCtx, a.Config.RepoPath, a.Config.DataPath)
@@ -690,6 +966,16 @@ func (a *Agent) syncWithProfile(ctx context.Context) (*syncengine.SyncResult, st
 
 	// Add engine-level excludes to the plan.
 	plan.ExcludePatterns = append(plan.ExcludePatterns, a.SyncEngine.ExcludePatterns...)
+	plan.BeforeApply = beforeApply
+
+	// After a profile switch, remove what the previous profile synced to
+	// destinations the new profile no longer manages.
+	if a.lastSyncedProfileName != "" && profileName != a.lastSyncedProfileName {
+		plan.RetiredRoots = retiredRoots(a.lastManagedRoots, plan.Mappings)
+		if len(plan.RetiredRoots) > 0 {
+			log.Info("cleaning destinations retired by profile switch", "from", a.lastSyncedProfileName, "to", profileName, "roots", plan.RetiredRoots)
+		}
+	}
 
 	log.V(1).Info("executing sync plan",
 		"mappings", len(plan.Mappings),
@@ -702,6 +988,9 @@ func (a *Agent) syncWithProfile(ctx context.Context) (*syncengine.SyncResult, st
 	if err != nil {
 		return nil, profileName, profile.DryRun, fmt.Errorf("executing plan: %w", err)
 	}
+	if !plan.DryRun {
+		a.lastManagedRoots = managedRoots(plan.Mappings)
+	}
 
 	return result, profileName, profile.DryRun, nil
 }
@@ -710,14 +999,17 @@ func (a *Agent) syncWithProfile(ctx context.Context) (*syncengine.SyncResult, st
 func (a *Agent) reportError(ctx context.Context, commit, ref, errMsg string) {
 	a.event(corev1.EventTypeWarning, conditions.ReasonSyncFailed, "%s", errMsg)
 	status := &stokertypes.GatewayStatus{
-		SyncStatus:   stokertypes.SyncStatusError,
-		SyncedCommit: commit,
-		SyncedRef:    ref,
-		LastSyncTime: time.Now().UTC().Format(time.RFC3339),
-		AgentVersion: agentVersion,
-		ErrorMessage: errMsg,
-	}
-	_ = WriteStatusConfigMap(ctx, a.K8sClient, a.Config.CRNamespace, a.Config.CRName, a.Config.GatewayName, status)
+		SyncStatus:     stokertypes.SyncStatusError,
+		SyncedCommit:   commit,
+		SyncedRef:      ref,
+		LastSyncTime:   time.Now().UTC().Format(time.RFC3339),
+		AgentVersion:   agentVersion,
+		LastBackup:     a.lastBackup,
+		GatewayVersion: a.gatewayVersion,
+		ErrorMessage:   errMsg,
+	}
+	a.recordSync(status, stokertypes.SyncRecord{})
+	_ = a.Backend.WriteStatus(ctx, status)
 }
 
 // event emits a K8s event on the cached GatewaySync CR. No-op if recorder or
@@ -732,6 +1024,9 @@ func (a *Agent) event(eventType, reason, msgFmt string, args ...any) {
 // fetchCRRef fetches the GatewaySync CR once using unstructured client and caches
 // it as the event target. This avoids importing the CRD types package.
 func (a *Agent) fetchCRRef(ctx context.Context) {
+	if a.K8sClient == nil {
+		return
+	}
 	u := &unstructured.Unstructured{}
 	u.SetGroupVersionKind(gatewaySyncGVK)
 	key := client.ObjectKey{Namespace: a.Config.CRNamespace, Name: a.Config.CRName}
@@ -774,18 +1069,17 @@ func (a *Agent) resolveAuth(meta *Metadata) transport.AuthMethod {
 func (a *Agent) resolveFileAuth() transport.AuthMethod {
 	// SSH key takes priority.
 	if sshKey := a.Config.GitSSHKey(); len(sshKey) > 0 {
-		publicKey, err := gogitssh.NewPublicKeys("git", sshKey, "")
+		var knownHosts []byte
+		if a.Config.GitKnownHostsFile != "" {
+			knownHosts, _ = os.ReadFile(a.Config.GitKnownHostsFile)
+		}
+		auth, err := git.NewSSHAuth(sshKey, knownHosts)
+		if err != nil && len(knownHosts) > 0 {
+			// Unparseable known_hosts: fall back to accepting any host key.
+			auth, err = git.NewSSHAuth(sshKey, nil)
+		}
 		if err == nil {
-			if a.Config.GitKnownHostsFile != "" {
-				if hostKeyCallback, khErr := knownhosts.New(a.Config.GitKnownHostsFile); khErr == nil {
-					publicKey.HostKeyCallback = hostKeyCallback
-				} else {
-					publicKey.HostKeyCallback = ssh.InsecureIgnoreHostKey()
-				}
-			} else {
-				publicKey.HostKeyCallback = ssh.InsecureIgnoreHostKey()
-			}
-			return publicKey
+			return auth
 		}
 	}
 
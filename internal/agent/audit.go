@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/ia-eknorr/stoker-operator/internal/audit"
+	"github.com/ia-eknorr/stoker-operator/internal/syncengine"
+)
+
+// auditSync completes rec with the gateway identity, sync trigger, commit
+// details from the local clone, and the changed files, then writes it to the
+// configured audit sinks. For dry-run syncs the files are those that would change.
+func (a *Agent) auditSync(ctx context.Context, rec *audit.Record, result *syncengine.SyncResult) {
+	if a.Audit == nil {
+		return
+	}
+	rec.Time = time.Now().UTC()
+	rec.Gateway = a.Config.GatewayName
+	rec.Namespace = a.Config.CRNamespace
+	rec.CRName = a.Config.CRName
+	rec.Trigger = a.syncTrigger
+
+	if info := a.commitDetails(ctx, rec.Commit); !info.IsZero() {
+		rec.CommitAuthor = info.Author
+		rec.CommitEmail = info.AuthorEmail
+		rec.CommitMessage = info.Message
+		rec.CommitTime = info.Time
+	}
+
+	if result != nil {
+		rec.DurationMS = result.Duration.Milliseconds()
+		changed := result.Changed
+		if rec.DryRun {
+			changed = result.DryRunDiff
+		}
+		if changed != nil {
+			rec.Files = audit.Files{Added: changed.Added, Modified: changed.Modified, Deleted: changed.Deleted}
+		}
+	}
+
+	a.Audit.Log(ctx, rec)
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ia-eknorr/stoker-operator/internal/audit"
+	"github.com/ia-eknorr/stoker-operator/internal/syncengine"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func TestAuditSync(t *testing.T) {
+	var buf strings.Builder
+	a := &Agent{
+		Config:      &Config{GatewayName: "plant-a", CRName: "site", CRNamespace: "ignition", RepoPath: t.TempDir()},
+		Audit:       audit.NewLogger(audit.NewWriterSink(&buf)),
+		syncTrigger: stokertypes.SyncTriggerCommit,
+	}
+	result := &syncengine.SyncResult{
+		Duration: 1500 * time.Millisecond,
+		Changed: &syncengine.DryRunDiff{
+			Added:    []string{"config/resources/core/a.json"},
+			Modified: []string{"projects/scada/view.json"},
+		},
+		DryRunDiff: &syncengine.DryRunDiff{Deleted: []string{"ignored.json"}},
+	}
+
+	// The repo path is not a clone, so commit details are omitted without failing.
+	a.auditSync(context.Background(), &audit.Record{Commit: "abc123", Profile: "prod", Result: stokertypes.SyncStatusSynced}, result)
+
+	var rec audit.Record
+	if err := json.Unmarshal([]byte(buf.String()), &rec); err != nil {
+		t.Fatalf("decoding audit record %q: %v", buf.String(), err)
+	}
+	if rec.Type != audit.RecordType || rec.Gateway != "plant-a" || rec.CRName != "site" || rec.Namespace != "ignition" {
+		t.Errorf("unexpected identity: %+v", rec)
+	}
+	if rec.Trigger != stokertypes.SyncTriggerCommit || rec.DurationMS != 1500 {
+		t.Errorf("trigger/duration: got %q %d", rec.Trigger, rec.DurationMS)
+	}
+	if len(rec.Files.Added) != 1 || len(rec.Files.Modified) != 1 || len(rec.Files.Deleted) != 0 {
+		t.Errorf("live sync should report applied files, got %+v", rec.Files)
+	}
+
+	// Dry-run records report the files that would change.
+	buf.Reset()
+	a.auditSync(context.Background(), &audit.Record{Commit: "abc123", DryRun: true}, result)
+	if err := json.Unmarshal([]byte(buf.String()), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Files.Deleted) != 1 || len(rec.Files.Added) != 0 {
+		t.Errorf("dry-run should report the dry-run diff, got %+v", rec.Files)
+	}
+}
@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// Backend is where the agent learns what to sync and reports how it went.
+// The Kubernetes backend uses the controller's metadata/status ConfigMaps;
+// the standalone backend polls git directly and writes status locally.
+type Backend interface {
+	// ReadMetadata returns the current sync target (commit, ref, profiles).
+	ReadMetadata(ctx context.Context) (*Metadata, error)
+	// WriteStatus publishes this gateway's sync status.
+	WriteStatus(ctx context.Context, status *stokertypes.GatewayStatus) error
+	// ReadStatus returns the status last published for this gateway, or nil
+	// if there is none.
+	ReadStatus(ctx context.Context) (*stokertypes.GatewayStatus, error)
+	// Labels returns labels exposed to templates as {{.Labels}}.
+	Labels(ctx context.Context) (map[string]string, error)
+}
+
+// metadataCache is implemented by backends that resolve metadata remotely on
+// each read. The agent invalidates the cache once per sync trigger so the
+// reads within a sync agree on the target.
+type metadataCache interface {
+	// InvalidateMetadata makes the next ReadMetadata resolve afresh.
+	InvalidateMetadata()
+}
+
+// KubeBackend reads the metadata ConfigMap, writes the status ConfigMap, and
+// reads labels from the agent's own pod.
+type KubeBackend struct {
+	Client client.Client
+	Config *Config
+}
+
+var _ Backend = (*KubeBackend)(nil)
+
+// ReadMetadata reads the metadata ConfigMap for the agent's GatewaySync.
+func (b *KubeBackend) ReadMetadata(ctx context.Context) (*Metadata, error) {
+	meta, err := ReadMetadataConfigMap(ctx, b.Client, b.Config.CRNamespace, b.Config.CRName)
+	if err != nil && isForbidden(err) {
+		logf.FromContext(ctx).Error(err, "RBAC permission denied — agent cannot read metadata ConfigMap",
+			"namespace", b.Config.CRNamespace,
+			"configmap", stokertypes.MetadataConfigMapName(b.Config.CRName),
+			"hint", fmt.Sprintf("ensure agent RBAC is configured: kubectl create rolebinding stoker-agent -n %s --clusterrole=stoker-agent --serviceaccount=%s:<service-account>",
+				b.Config.CRNamespace, b.Config.PodNamespace),
+		)
+	}
+	return meta, err
+}
+
+// WriteStatus writes the gateway's entry in the status ConfigMap.
+func (b *KubeBackend) WriteStatus(ctx context.Context, status *stokertypes.GatewayStatus) error {
+	err := WriteStatusConfigMap(ctx, b.Client, b.Config.CRNamespace, b.Config.statusConfigMapName(), b.Config.CRName, b.Config.GatewayName, status)
+	if err != nil && isForbidden(err) {
+		logf.FromContext(ctx).Error(err, "RBAC permission denied — agent cannot write status ConfigMap",
+			"namespace", b.Config.CRNamespace,
+			"configmap", b.Config.statusConfigMapName(),
+			"hint", fmt.Sprintf("ensure agent RBAC is configured: kubectl create rolebinding stoker-agent -n %s --clusterrole=stoker-agent --serviceaccount=%s:<service-account>",
+				b.Config.CRNamespace, b.Config.PodNamespace),
+		)
+	}
+	return err
+}
+
+// ReadStatus reads the gateway's entry from its status ConfigMap.
+func (b *KubeBackend) ReadStatus(ctx context.Context) (*stokertypes.GatewayStatus, error) {
+	return ReadStatusConfigMap(ctx, b.Client, b.Config.CRNamespace, b.Config.statusConfigMapName(), b.Config.GatewayName)
+}
+
+// Labels returns the labels of the agent's pod.
+func (b *KubeBackend) Labels(ctx context.Context) (map[string]string, error) {
+	var pod corev1.Pod
+	if err := b.Client.Get(ctx, client.ObjectKey{Name: b.Config.PodName, Namespace: b.Config.PodNamespace}, &pod); err != nil {
+		if isForbidden(err) {
+			logf.FromContext(ctx).Error(err, "RBAC permission denied — agent cannot read pod labels",
+				"pod", b.Config.PodName, "namespace", b.Config.PodNamespace)
+		}
+		return nil, fmt.Errorf("reading pod labels: %w", err)
+	}
+	return pod.Labels, nil
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ia-eknorr/stoker-operator/internal/syncengine"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+)
+
+// backupTimeFormat is embedded in backup file names. It sorts lexically in
+// chronological order.
+const backupTimeFormat = "20060102T150405Z"
+
+// backupBeforeApply returns a syncengine BeforeApply hook that captures a
+// gateway backup when the pending diff touches a configured backup path.
+// The backup file name is stored in *name. Returns nil when backups are disabled.
+// Gateways without a backup API (Ignition 8.1) fail the sync, since the change
+// cannot be applied with the requested backup; disable backups to sync them.
+func (a *Agent) backupBeforeApply(ctx context.Context, commit string, name *string) func(*syncengine.DryRunDiff) error {
+	if a.Config.BackupDir == "" {
+		return nil
+	}
+	return func(diff *syncengine.DryRunDiff) error {
+		if !diffTouchesPaths(diff, a.Config.BackupPaths) {
+			return nil
+		}
+		if !a.IgnitionAPI.Capabilities().Backup {
+			a.Metrics.BackupTotal.WithLabelValues("unavailable").Inc()
+			a.event(corev1.EventTypeWarning, conditions.ReasonBackupUnavailable,
+				"Pre-change backup unavailable on %s: gateway %s has no backup API; disable backups to sync it",
+				a.Config.GatewayName, a.gatewayVersion)
+			return fmt.Errorf("pre-change backup: gateway %s has no backup API", a.gatewayVersion)
+		}
+		backup, err := a.createBackup(ctx, commit)
+		if err != nil {
+			return err
+		}
+		*name = backup
+		return nil
+	}
+}
+
+// diffTouchesPaths reports whether any added, modified, or deleted file lies
+// under one of the given destination prefixes.
+func diffTouchesPaths(diff *syncengine.DryRunDiff, prefixes []string) bool {
+	if diff == nil {
+		return false
+	}
+	for _, files := range [][]string{diff.Added, diff.Modified, diff.Deleted} {
+		for _, f := range files {
+			f = filepath.ToSlash(f)
+			for _, p := range prefixes {
+				if f == p || strings.HasPrefix(f, p+"/") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// createBackup downloads a gateway backup into the backup directory and
+// applies retention. The file is written under a temporary name and renamed
+// once complete so a partial download is never mistaken for a backup.
+func (a *Agent) createBackup(ctx context.Context, commit string) (string, error) {
+	log := logf.FromContext(ctx).WithName("backup")
+
+	name := backupFileName(a.Config.GatewayName, commit, time.Now())
+	path := filepath.Join(a.Config.BackupDir, name)
+	tmp := path + ".partial"
+
+	start := time.Now()
+	size, err := a.downloadBackup(ctx, tmp)
+	a.Metrics.BackupDuration.Observe(time.Since(start).Seconds())
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		a.Metrics.BackupTotal.WithLabelValues("error").Inc()
+		a.event(corev1.EventTypeWarning, conditions.ReasonBackupFailed,
+			"Pre-change backup failed on %s, sync aborted: %v", a.Config.GatewayName, err)
+		return "", fmt.Errorf("gateway backup: %w", err)
+	}
+
+	a.Metrics.BackupTotal.WithLabelValues("success").Inc()
+	log.Info("gateway backup created", "file", name, "bytes", size, "duration", time.Since(start).Round(time.Millisecond))
+	a.event(corev1.EventTypeNormal, conditions.ReasonBackupCreated,
+		"Pre-change backup %s created on %s", name, a.Config.GatewayName)
+
+	pruned, err := pruneBackups(a.Config.BackupDir, a.Config.GatewayName, a.Config.BackupMaxCount, a.Config.BackupMaxAge, time.Now())
+	if err != nil {
+		log.Error(err, "failed to prune old backups (non-fatal)")
+	} else if len(pruned) > 0 {
+		log.Info("pruned old backups", "files", pruned)
+	}
+
+	return name, nil
+}
+
+// downloadBackup streams a gateway backup into path.
+func (a *Agent) downloadBackup(ctx context.Context, path string) (int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("creating backup file: %w", err)
+	}
+	n, err := a.IgnitionAPI.DownloadBackup(ctx, f)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("closing backup file: %w", closeErr)
+	}
+	return n, err
+}
+
+// backupFileName returns "<gateway>-<UTC timestamp>-<short commit>.gwbk".
+func backupFileName(gateway, commit string, t time.Time) string {
+	return fmt.Sprintf("%s-%s-%s.gwbk", gateway, t.UTC().Format(backupTimeFormat), commit[:min(7, len(commit))])
+}
+
+// pruneBackups removes this gateway's backups beyond maxCount (newest kept)
+// or older than maxAge. Zero values disable the respective limit. Files
+// belonging to other gateways sharing the volume are never touched.
+func pruneBackups(dir, gateway string, maxCount int, maxAge time.Duration, now time.Time) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading backup dir: %w", err)
+	}
+
+	pattern := regexp.MustCompile(`^` + regexp.QuoteMeta(gateway) + `-(\d{8}T\d{6}Z)-[0-9a-f]*\.gwbk$`)
+
+	type backup struct {
+		name    string
+		created time.Time
+	}
+	var backups []backup
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := pattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		created, err := time.Parse(backupTimeFormat, m[1])
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{name: e.Name(), created: created})
+	}
+
+	// Newest first.
+	sort.Slice(backups, func(i, j int) bool { return backups[i].created.After(backups[j].created) })
+
+	var pruned []string
+	for i, b := range backups {
+		expired := maxAge > 0 && now.Sub(b.created) > maxAge
+		overCount := maxCount > 0 && i >= maxCount
+		if !expired && !overCount {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, b.name)); err != nil && !os.IsNotExist(err) {
+			return pruned, fmt.Errorf("removing %s: %w", b.name, err)
+		}
+		pruned = append(pruned, b.name)
+	}
+	return pruned, nil
+}
@@ -0,0 +1,215 @@
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ia-eknorr/stoker-operator/internal/ignition"
+	"github.com/ia-eknorr/stoker-operator/internal/syncengine"
+)
+
+func TestDiffTouchesPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		diff *syncengine.DryRunDiff
+		want bool
+	}{
+		{name: "nil diff", diff: nil, want: false},
+		{name: "empty diff", diff: &syncengine.DryRunDiff{}, want: false},
+		{
+			name: "project change only",
+			diff: &syncengine.DryRunDiff{Modified: []string{"projects/site/view.json"}},
+			want: false,
+		},
+		{
+			name: "config modified",
+			diff: &syncengine.DryRunDiff{Modified: []string{"config/resources/core/db.json"}},
+			want: true,
+		},
+		{
+			name: "config deleted",
+			diff: &syncengine.DryRunDiff{Deleted: []string{"config/resources/core/old.json"}},
+			want: true,
+		},
+		{
+			name: "prefix is not a path boundary",
+			diff: &syncengine.DryRunDiff{Added: []string{"configs/readme.txt"}},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffTouchesPaths(tt.diff, []string{"config"}); got != tt.want {
+				t.Errorf("diffTouchesPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupFileName(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 12, 30, 45, 0, time.UTC)
+	got := backupFileName("site-gw", "0123456789abcdef", ts)
+	want := "site-gw-20260301T123045Z-0123456.gwbk"
+	if got != want {
+		t.Errorf("backupFileName() = %q, want %q", got, want)
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	var names []string
+	for i := range 5 {
+		name := backupFileName("gw", "abc1234", now.Add(-time.Duration(i)*24*time.Hour))
+		names = append(names, name)
+		writeBackup(t, dir, name)
+	}
+	// Another gateway sharing the volume, and an in-progress download.
+	other := backupFileName("gw-2", "abc1234", now.Add(-30*24*time.Hour))
+	writeBackup(t, dir, other)
+	writeBackup(t, dir, names[0]+".partial")
+
+	pruned, err := pruneBackups(dir, "gw", 3, 0, now)
+	if err != nil {
+		t.Fatalf("pruneBackups: %v", err)
+	}
+	if len(pruned) != 2 || pruned[0] != names[3] || pruned[1] != names[4] {
+		t.Errorf("expected oldest two pruned, got %v", pruned)
+	}
+
+	pruned, err = pruneBackups(dir, "gw", 0, 36*time.Hour, now)
+	if err != nil {
+		t.Fatalf("pruneBackups: %v", err)
+	}
+	if len(pruned) != 1 || pruned[0] != names[2] {
+		t.Errorf("expected backup older than 36h pruned, got %v", pruned)
+	}
+
+	for _, keep := range []string{names[0], names[1], other, names[0] + ".partial"} {
+		if _, err := os.Stat(filepath.Join(dir, keep)); err != nil {
+			t.Errorf("expected %s to be kept: %v", keep, err)
+		}
+	}
+}
+
+func TestCreateBackup(t *testing.T) {
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data/api/v1/backup" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("gwbk-bytes"))
+	}))
+	defer gw.Close()
+
+	dir := t.TempDir()
+	a := &Agent{
+		Config:      &Config{GatewayName: "gw", BackupDir: dir, BackupPaths: []string{"config"}, BackupMaxCount: 1},
+		IgnitionAPI: ignition.NewClient("http", strings.TrimPrefix(gw.URL, "http://"), "key", nil),
+		Metrics:     NewAgentMetrics(),
+	}
+	stale := backupFileName("gw", "0000000", time.Now().Add(-time.Hour))
+	writeBackup(t, dir, stale)
+
+	var name string
+	hook := a.backupBeforeApply(context.Background(), "abcdef0123", &name)
+
+	// A diff outside config/ must not trigger a backup.
+	if err := hook(&syncengine.DryRunDiff{Added: []string{"projects/p/x.json"}}); err != nil {
+		t.Fatalf("hook: %v", err)
+	}
+	if name != "" {
+		t.Fatalf("expected no backup for project-only diff, got %q", name)
+	}
+
+	if err := hook(&syncengine.DryRunDiff{Modified: []string{"config/resources/core/x.json"}}); err != nil {
+		t.Fatalf("hook: %v", err)
+	}
+	if !strings.HasPrefix(name, "gw-") || !strings.HasSuffix(name, "-abcdef0.gwbk") {
+		t.Fatalf("unexpected backup name %q", name)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil || string(data) != "gwbk-bytes" {
+		t.Errorf("backup content: %q, err=%v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, stale)); !os.IsNotExist(err) {
+		t.Error("expected stale backup to be pruned by maxCount")
+	}
+	if v := testutil.ToFloat64(a.Metrics.BackupTotal.WithLabelValues("success")); v != 1 {
+		t.Errorf("expected backup_total{result=success}=1, got %f", v)
+	}
+}
+
+func TestCreateBackup_FailureAbortsSync(t *testing.T) {
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer gw.Close()
+
+	dir := t.TempDir()
+	a := &Agent{
+		Config:      &Config{GatewayName: "gw", BackupDir: dir, BackupPaths: []string{"config"}},
+		IgnitionAPI: ignition.NewClient("http", strings.TrimPrefix(gw.URL, "http://"), "key", nil),
+		Metrics:     NewAgentMetrics(),
+	}
+
+	var name string
+	hook := a.backupBeforeApply(context.Background(), "abcdef0123", &name)
+	if err := hook(&syncengine.DryRunDiff{Added: []string{"config/x.json"}}); err == nil {
+		t.Fatal("expected backup failure to return an error")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected no files left after failed backup, got %d", len(entries))
+	}
+	if v := testutil.ToFloat64(a.Metrics.BackupTotal.WithLabelValues("error")); v != 1 {
+		t.Errorf("expected backup_total{result=error}=1, got %f", v)
+	}
+}
+
+func TestBackupBeforeApply_FailsWithoutBackupAPI(t *testing.T) {
+	dir := t.TempDir()
+	a := &Agent{
+		Config:      &Config{GatewayName: "gw", BackupDir: dir, BackupPaths: []string{"config"}},
+		IgnitionAPI: &ignition.LegacyClient{Client: ignition.NewClient("http", "127.0.0.1:1", "key", nil)},
+		Metrics:     NewAgentMetrics(),
+	}
+
+	var name string
+	hook := a.backupBeforeApply(context.Background(), "abcdef0123", &name)
+	if err := hook(&syncengine.DryRunDiff{Modified: []string{"config/x.json"}}); err == nil {
+		t.Fatal("expected the sync to fail without a backup API")
+	}
+	if name != "" {
+		t.Errorf("expected no backup, got %q", name)
+	}
+	if v := testutil.ToFloat64(a.Metrics.BackupTotal.WithLabelValues("unavailable")); v != 1 {
+		t.Errorf("expected backup_total{result=unavailable}=1, got %f", v)
+	}
+}
+
+func TestBackupBeforeApply_DisabledWithoutDir(t *testing.T) {
+	a := &Agent{Config: &Config{}}
+	var name string
+	if hook := a.backupBeforeApply(context.Background(), "abc", &name); hook != nil {
+		t.Error("expected nil hook when BackupDir is unset")
+	}
+}
+
+func writeBackup(t *testing.T, dir, name string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
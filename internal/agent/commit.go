@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"context"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+)
+
+// commitDetails returns the author, subject, and time of commit. The details
+// from the last clone/fetch are reused when they match; otherwise they are
+// read from the local clone. Returns zero CommitInfo when unavailable.
+func (a *Agent) commitDetails(ctx context.Context, commit string) git.CommitInfo {
+	if commit == "" {
+		return git.CommitInfo{}
+	}
+	if a.lastFetch.Commit == commit && !a.lastFetch.Info.IsZero() {
+		return a.lastFetch.Info
+	}
+	info, err := git.ReadCommit(a.Config.RepoPath, commit)
+	if err != nil {
+		logf.FromContext(ctx).V(1).Info("commit details unavailable", "commit", commit, "error", err.Error())
+		return git.CommitInfo{}
+	}
+	a.lastFetch = git.Result{Commit: commit, Ref: a.lastFetch.Ref, Info: info}
+	return info
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+)
+
+func TestCommitDetails(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wt, _ := repo.Worktree()
+	if _, err := wt.Add("README"); err != nil {
+		t.Fatal(err)
+	}
+	when := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	sig := &object.Signature{Name: "Jane Doe", Email: "jane@example.com", When: when}
+	hash, err := wt.Commit("Raise tank alarm limits\n", &gogit.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := &Agent{Config: &Config{RepoPath: dir}}
+	info := a.commitDetails(context.Background(), hash.String())
+	if info.Author != "Jane Doe" || info.Subject != "Raise tank alarm limits" {
+		t.Fatalf("unexpected commit details: %+v", info)
+	}
+	status := info.Status()
+	if status == nil || status.Time != "2026-03-01T12:00:00Z" {
+		t.Errorf("unexpected status commit info: %+v", status)
+	}
+
+	// Details from the last fetch are reused without reading the clone.
+	a.lastFetch = git.Result{Commit: "abc123", Info: git.CommitInfo{Author: "CI Bot"}}
+	if got := a.commitDetails(context.Background(), "abc123"); got.Author != "CI Bot" {
+		t.Errorf("expected cached details, got %+v", got)
+	}
+	if got := a.commitDetails(context.Background(), "0000000000000000000000000000000000000000"); !got.IsZero() {
+		t.Errorf("expected empty details for unknown commit, got %+v", got)
+	}
+	if (git.CommitInfo{}).Status() != nil {
+		t.Error("expected nil status for unknown commit")
+	}
+}
@@ -1,47 +1,103 @@
 package agent
 
 import (
+	"crypto/tls"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/ia-eknorr/stoker-operator/internal/audit"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/internal/ignition"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// Default listen addresses for the agent's HTTP servers.
+const (
+	defaultHealthAddr  = ":8082"
+	defaultMetricsAddr = ":8083"
 )
 
+// defaultSyncHistoryLimit is the number of sync records kept in status.
+const defaultSyncHistoryLimit = 20
+
+// defaultBackupMaxCount is the number of backups kept per gateway when no
+// retention is configured, matching the CRD default for retention.maxCount.
+const defaultBackupMaxCount = 10
+
 // Config holds the agent runtime configuration loaded from env vars and mounted files.
 type Config struct {
-	PodName           string
-	PodNamespace      string
-	GatewayName       string
-	CRName            string
-	CRNamespace       string
-	RepoPath          string
-	DataPath          string
-	GatewayPort       string
-	GatewayTLS        bool
-	APIKeyFile        string
-	SyncPeriod        int // seconds
-	GitTokenFile      string
-	GitSSHKeyFile     string
-	GitKnownHostsFile string
-	ProfileName       string // profile name from embedded spec.sync.profiles
+	PodName            string
+	PodNamespace       string
+	GatewayName        string
+	CRName             string
+	CRNamespace        string
+	RepoPath           string
+	DataPath           string
+	GatewayPort        string
+	GatewayTLS         bool
+	GatewayCAFile      string // PEM CA bundle used to verify the gateway certificate
+	GatewayServerName  string // name verified against the gateway certificate
+	GatewayInsecure    bool   // skip gateway certificate verification (explicit opt-in)
+	APIKeyFile         string
+	SyncPeriod         int // seconds
+	GitTokenFile       string
+	GitSSHKeyFile      string
+	GitKnownHostsFile  string
+	GitClient          string // git.ClientNative (default) or git.ClientGoGit
+	GitProxyURL        string // HTTP(S) proxy for git remotes
+	GitCAFile          string // PEM CA bundle trusted for HTTPS git remotes
+	ProfileName        string // profile name from embedded spec.sync.profiles
+	PodAnnotationsFile string // Downward API file with this pod's annotations
+	HealthAddr         string // health and resync endpoints
+	MetricsAddr        string // Prometheus metrics endpoint
+	SyncHistoryLimit   int    // sync records kept in status
+
+	// Pre-change gateway backups. Disabled when BackupDir is empty.
+	BackupDir      string
+	BackupPaths    []string      // destination prefixes that trigger a backup
+	BackupMaxCount int           // backups kept per gateway; 0 = unlimited
+	BackupMaxAge   time.Duration // backups older than this are pruned; 0 = no limit
+
+	// Audit record sinks. Disabled when no sink is configured.
+	Audit audit.Config
+
+	// Commit signature verification after checkout. Disabled when VerifyPolicy is empty.
+	VerifyPolicy  string // git.VerifySignedCommit or git.VerifySignedTag
+	VerifyKeysDir string // mounted trusted keys Secret
 }
 
 // LoadConfig reads agent configuration from environment variables.
 func LoadConfig() (*Config, error) {
 	cfg := &Config{
-		PodName:           os.Getenv("POD_NAME"),
-		PodNamespace:      os.Getenv("POD_NAMESPACE"),
-		GatewayName:       os.Getenv("GATEWAY_NAME"),
-		CRName:            os.Getenv("CR_NAME"),
-		CRNamespace:       os.Getenv("CR_NAMESPACE"),
-		RepoPath:          os.Getenv("REPO_PATH"),
-		DataPath:          os.Getenv("DATA_PATH"),
-		GatewayPort:       os.Getenv("GATEWAY_PORT"),
-		APIKeyFile:        os.Getenv("API_KEY_FILE"),
-		GitTokenFile:      os.Getenv("GIT_TOKEN_FILE"),
-		GitSSHKeyFile:     os.Getenv("GIT_SSH_KEY_FILE"),
-		GitKnownHostsFile: os.Getenv("GIT_KNOWN_HOSTS_FILE"),
-		ProfileName:       os.Getenv("PROFILE"),
+		PodName:            os.Getenv("POD_NAME"),
+		PodNamespace:       os.Getenv("POD_NAMESPACE"),
+		GatewayName:        os.Getenv("GATEWAY_NAME"),
+		CRName:             os.Getenv("CR_NAME"),
+		CRNamespace:        os.Getenv("CR_NAMESPACE"),
+		RepoPath:           os.Getenv("REPO_PATH"),
+		DataPath:           os.Getenv("DATA_PATH"),
+		GatewayPort:        os.Getenv("GATEWAY_PORT"),
+		GatewayCAFile:      os.Getenv("GATEWAY_CA_FILE"),
+		GatewayServerName:  os.Getenv("GATEWAY_TLS_SERVER_NAME"),
+		APIKeyFile:         os.Getenv("API_KEY_FILE"),
+		GitTokenFile:       os.Getenv("GIT_TOKEN_FILE"),
+		GitSSHKeyFile:      os.Getenv("GIT_SSH_KEY_FILE"),
+		GitKnownHostsFile:  os.Getenv("GIT_KNOWN_HOSTS_FILE"),
+		GitClient:          os.Getenv("GIT_CLIENT"),
+		GitProxyURL:        os.Getenv("GIT_PROXY_URL"),
+		GitCAFile:          os.Getenv("GIT_CA_FILE"),
+		ProfileName:        os.Getenv("PROFILE"),
+		PodAnnotationsFile: os.Getenv("POD_ANNOTATIONS_FILE"),
+		BackupDir:          os.Getenv("BACKUP_DIR"),
+		VerifyPolicy:       os.Getenv("VERIFY_POLICY"),
+		VerifyKeysDir:      os.Getenv("VERIFY_KEYS_DIR"),
+		HealthAddr:         defaultHealthAddr,
+		MetricsAddr:        defaultMetricsAddr,
+		SyncHistoryLimit:   defaultSyncHistoryLimit,
+		BackupMaxCount:     defaultBackupMaxCount,
 	}
 
 	// Defaults
@@ -54,6 +110,9 @@ func LoadConfig() (*Config, error) {
 	if cfg.GatewayPort == "" {
 		cfg.GatewayPort = "8088"
 	}
+	if cfg.GitClient == "" {
+		cfg.GitClient = git.ClientNative
+	}
 	if cfg.CRNamespace == "" {
 		cfg.CRNamespace = cfg.PodNamespace
 	}
@@ -63,6 +122,7 @@ func LoadConfig() (*Config, error) {
 
 	// Parse TLS
 	cfg.GatewayTLS, _ = strconv.ParseBool(os.Getenv("GATEWAY_TLS"))
+	cfg.GatewayInsecure, _ = strconv.ParseBool(os.Getenv("GATEWAY_TLS_INSECURE"))
 
 	// Parse sync period (default 30s)
 	cfg.SyncPeriod = 30
@@ -72,6 +132,56 @@ func LoadConfig() (*Config, error) {
 		}
 	}
 
+	if hl := os.Getenv("SYNC_HISTORY_LIMIT"); hl != "" {
+		if v, err := strconv.Atoi(hl); err == nil && v > 0 {
+			cfg.SyncHistoryLimit = v
+		}
+	}
+
+	// Parse backup settings
+	if bp := os.Getenv("BACKUP_PATHS"); bp != "" {
+		for p := range strings.SplitSeq(bp, ",") {
+			if p = strings.Trim(strings.TrimSpace(p), "/"); p != "" {
+				cfg.BackupPaths = append(cfg.BackupPaths, p)
+			}
+		}
+	}
+	if len(cfg.BackupPaths) == 0 {
+		cfg.BackupPaths = []string{"config"}
+	}
+	if mc := os.Getenv("BACKUP_MAX_COUNT"); mc != "" {
+		if v, err := strconv.Atoi(mc); err == nil && v > 0 {
+			cfg.BackupMaxCount = v
+		}
+	}
+	if ma := os.Getenv("BACKUP_MAX_AGE"); ma != "" {
+		d, err := time.ParseDuration(ma)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BACKUP_MAX_AGE %q: %w", ma, err)
+		}
+		cfg.BackupMaxAge = d
+	}
+
+	// Parse audit sinks
+	cfg.Audit = audit.Config{
+		File:           os.Getenv("AUDIT_FILE"),
+		SyslogAddress:  os.Getenv("AUDIT_SYSLOG_ADDRESS"),
+		SyslogProtocol: os.Getenv("AUDIT_SYSLOG_PROTOCOL"),
+		HTTPURL:        os.Getenv("AUDIT_HTTP_URL"),
+		HTTPTokenFile:  os.Getenv("AUDIT_HTTP_TOKEN_FILE"),
+	}
+	cfg.Audit.Stdout, _ = strconv.ParseBool(os.Getenv("AUDIT_STDOUT"))
+	if ms := os.Getenv("AUDIT_FILE_MAX_SIZE_MB"); ms != "" {
+		if v, err := strconv.Atoi(ms); err == nil && v > 0 {
+			cfg.Audit.FileMaxSizeMB = v
+		}
+	}
+	if mb := os.Getenv("AUDIT_FILE_MAX_BACKUPS"); mb != "" {
+		if v, err := strconv.Atoi(mb); err == nil && v > 0 {
+			cfg.Audit.FileMaxBackups = v
+		}
+	}
+
 	// Validate required fields
 	if cfg.CRName == "" {
 		return nil, fmt.Errorf("CR_NAME env var is required")
@@ -130,7 +240,26 @@ func (c *Config) GatewayScheme() string {
 	return "http"
 }
 
+// GatewayTLSConfig builds the TLS configuration for gateway API calls.
+// Returns nil when TLS is disabled.
+func (c *Config) GatewayTLSConfig() (*tls.Config, error) {
+	if !c.GatewayTLS {
+		return nil, nil
+	}
+	return ignition.NewTLSConfig(c.GatewayCAFile, c.GatewayServerName, c.GatewayInsecure)
+}
+
 // GatewayHost returns the gateway address for API calls (localhost:port).
 func (c *Config) GatewayHost() string {
 	return "localhost:" + c.GatewayPort
 }
+
+// statusConfigMapName returns the agent's status ConfigMap in CRNamespace.
+// An agent syncing from a CR in another namespace includes its own namespace.
+func (c *Config) statusConfigMapName() string {
+	gatewayNamespace := ""
+	if c.PodNamespace != c.CRNamespace {
+		gatewayNamespace = c.PodNamespace
+	}
+	return stokertypes.StatusConfigMapName(c.CRName, gatewayNamespace, c.GatewayName)
+}
@@ -10,60 +10,54 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
+	"github.com/ia-eknorr/stoker-operator/internal/schedule"
 	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
 )
 
-// MetadataConfigMapName returns the metadata ConfigMap name for a CR.
-func MetadataConfigMapName(crName string) string {
-	return fmt.Sprintf("stoker-metadata-%s", crName)
-}
-
-// StatusConfigMapName returns the status ConfigMap name for a CR.
-func StatusConfigMapName(crName string) string {
-	return fmt.Sprintf("stoker-status-%s", crName)
-}
-
 // Metadata holds the data read from the metadata ConfigMap.
 type Metadata struct {
-	Commit          string
-	Ref             string
-	Trigger         string
-	GitURL          string
-	Paused          string
-	ExcludePatterns string
-	Profiles        string
-	AuthType        string
-	GitToken        string
+	Commit           string
+	Ref              string
+	Trigger          string
+	GitURL           string
+	Paused           string
+	ExcludePatterns  string
+	Profiles         string
+	AuthType         string
+	GitToken         string
+	GatewayTargets   string
+	Schedule         string
+	ScheduleOverride string
+	Rollback         string
 }
 
 // ReadMetadataConfigMap reads the metadata ConfigMap and returns its data.
 func ReadMetadataConfigMap(ctx context.Context, c client.Client, namespace, crName string) (*Metadata, error) {
 	cm := &corev1.ConfigMap{}
 	key := types.NamespacedName{
-		Name:      MetadataConfigMapName(crName),
+		Name:      stokertypes.MetadataConfigMapName(crName),
 		Namespace: namespace,
 	}
 
 	if err := c.Get(ctx, key, cm); err != nil {
-		if errors.IsForbidden(err) {
-			logf.FromContext(ctx).Error(err, "RBAC permission denied — cannot read metadata ConfigMap",
-				"configmap", key.Name, "namespace", namespace)
-		}
 		return nil, fmt.Errorf("reading metadata ConfigMap %s: %w", key.Name, err)
 	}
 
 	return &Metadata{
-		Commit:          cm.Data["commit"],
-		Ref:             cm.Data["ref"],
-		Trigger:         cm.Data["trigger"],
-		GitURL:          cm.Data["gitURL"],
-		Paused:          cm.Data["paused"],
-		ExcludePatterns: cm.Data["excludePatterns"],
-		Profiles:        cm.Data["profiles"],
-		AuthType:        cm.Data["authType"],
-		GitToken:        cm.Data["gitToken"],
+		Commit:           cm.Data["commit"],
+		Ref:              cm.Data["ref"],
+		Trigger:          cm.Data["trigger"],
+		GitURL:           cm.Data["gitURL"],
+		Paused:           cm.Data["paused"],
+		ExcludePatterns:  cm.Data["excludePatterns"],
+		Profiles:         cm.Data["profiles"],
+		AuthType:         cm.Data["authType"],
+		GitToken:         cm.Data["gitToken"],
+		GatewayTargets:   cm.Data["gatewayTargets"],
+		Schedule:         cm.Data["schedule"],
+		ScheduleOverride: cm.Data["scheduleOverride"],
+		Rollback:         cm.Data["rollback"],
 	}, nil
 }
 
@@ -79,10 +73,57 @@ func ParseResolvedProfiles(raw string) (map[string]*stokertypes.ResolvedProfile,
 	return profiles, nil
 }
 
-// WriteStatusConfigMap writes the agent's status to the status ConfigMap.
-// Uses optimistic concurrency with retry on conflict.
-func WriteStatusConfigMap(ctx context.Context, c client.Client, namespace, crName, gatewayName string, status *stokertypes.GatewayStatus) error {
-	cmName := StatusConfigMapName(crName)
+// ParseGatewayTargets deserializes the per-gateway rollout targets from the
+// metadata ConfigMap.
+func ParseGatewayTargets(raw string) (map[string]stokertypes.GatewayTarget, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var targets map[string]stokertypes.GatewayTarget
+	if err := json.Unmarshal([]byte(raw), &targets); err != nil {
+		return nil, fmt.Errorf("parsing gateway targets: %w", err)
+	}
+	return targets, nil
+}
+
+// ParseSchedule deserializes spec.sync.schedule from the metadata ConfigMap.
+// Returns nil when no schedule is set.
+func ParseSchedule(raw string) (*schedule.Schedule, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var spec stokertypes.SyncSchedule
+	if err := json.Unmarshal([]byte(raw), &spec); err != nil {
+		return nil, fmt.Errorf("parsing schedule: %w", err)
+	}
+	return schedule.New(spec)
+}
+
+// ReadStatusConfigMap returns the gateway's status from the status ConfigMap
+// cmName, or nil if the ConfigMap or the gateway's entry does not exist.
+func ReadStatusConfigMap(ctx context.Context, c client.Client, namespace, cmName, gatewayName string) (*stokertypes.GatewayStatus, error) {
+	cm := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: cmName, Namespace: namespace}
+	if err := c.Get(ctx, key, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading status ConfigMap %s: %w", key.Name, err)
+	}
+	raw, ok := cm.Data[gatewayName]
+	if !ok {
+		return nil, nil
+	}
+	status := &stokertypes.GatewayStatus{}
+	if err := json.Unmarshal([]byte(raw), status); err != nil {
+		return nil, fmt.Errorf("decoding status ConfigMap %s: %w", key.Name, err)
+	}
+	return status, nil
+}
+
+// WriteStatusConfigMap writes the agent's status to its per-gateway status
+// ConfigMap cmName. Uses optimistic concurrency with retry on conflict.
+func WriteStatusConfigMap(ctx context.Context, c client.Client, namespace, cmName, crName, gatewayName string, status *stokertypes.GatewayStatus) error {
 	key := types.NamespacedName{Name: cmName, Namespace: namespace}
 
 	statusJSON, err := json.Marshal(status)
@@ -95,9 +136,6 @@ func WriteStatusConfigMap(ctx context.Context, c client.Client, namespace, crNam
 		err := c.Get(ctx, key, cm)
 
 		if errors.IsForbidden(err) {
-			logf.FromContext(ctx).Error(err, "RBAC permission denied — cannot write status ConfigMap",
-				"configmap", cmName, "namespace", namespace,
-				"hint", fmt.Sprintf("ensure agent RBAC: kubectl create rolebinding stoker-agent -n %s --clusterrole=stoker-agent --serviceaccount=%s:<service-account>", namespace, namespace))
 			return fmt.Errorf("writing status ConfigMap (permission denied): %w", err)
 		}
 		if errors.IsNotFound(err) {
@@ -109,6 +147,7 @@ func WriteStatusConfigMap(ctx context.Context, c client.Client, namespace, crNam
 					Labels: map[string]string{
 						"app.kubernetes.io/managed-by": "stoker-agent",
 						stokertypes.LabelCRName:        crName,
+						stokertypes.LabelGatewayStatus: "true",
 					},
 				},
 				Data: map[string]string{
@@ -117,7 +156,7 @@ func WriteStatusConfigMap(ctx context.Context, c client.Client, namespace, crNam
 			}
 			if createErr := c.Create(ctx, cm); createErr != nil {
 				if errors.IsAlreadyExists(createErr) {
-					continue // retry — another agent created it first
+					continue // retry — created concurrently
 				}
 				return fmt.Errorf("creating status ConfigMap: %w", createErr)
 			}
@@ -13,17 +13,25 @@ type HealthServer struct {
 	initialSyncDone atomic.Bool
 	shuttingDown    atomic.Bool
 	server          *http.Server
+	mux             *http.ServeMux
+
+	// Manual resync requests (see resync.go). resyncToken is nil until
+	// EnableResync is called, which keeps /resync disabled.
+	resyncToken func() string
+	resyncCh    chan ResyncMode
 }
 
 // NewHealthServer creates a health server on the given address (e.g., ":8082").
 func NewHealthServer(addr string) *HealthServer {
-	hs := &HealthServer{}
+	hs := &HealthServer{resyncCh: make(chan ResyncMode, 1)}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", hs.handleHealthz)
 	mux.HandleFunc("/readyz", hs.handleReadyz)
 	mux.HandleFunc("/startupz", hs.handleStartupz)
+	mux.HandleFunc("/resync", hs.handleResync)
 
+	hs.mux = mux
 	hs.server = &http.Server{
 		Addr:    addr,
 		Handler: mux,
@@ -31,6 +39,12 @@ func NewHealthServer(addr string) *HealthServer {
 	return hs
 }
 
+// Handle registers an additional endpoint on the health server.
+// Must be called before Start.
+func (hs *HealthServer) Handle(pattern string, handler http.Handler) {
+	hs.mux.Handle(pattern, handler)
+}
+
 // MarkReady signals that the initial sync has completed.
 func (hs *HealthServer) MarkReady() {
 	hs.initialSyncDone.Store(true)
@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// syncHistory is a bounded list of the most recent sync records, newest first.
+type syncHistory struct {
+	limit   int
+	records []stokertypes.SyncRecord
+}
+
+// add prepends rec, dropping the oldest record once the limit is reached.
+func (h *syncHistory) add(rec stokertypes.SyncRecord) {
+	limit := h.limit
+	if limit <= 0 {
+		limit = defaultSyncHistoryLimit
+	}
+	h.records = append([]stokertypes.SyncRecord{rec}, h.records...)
+	if len(h.records) > limit {
+		h.records = h.records[:limit]
+	}
+}
+
+// seed replaces the records with history, newest first, keeping at most the limit.
+func (h *syncHistory) seed(history []stokertypes.SyncRecord) {
+	h.records = nil
+	for i := len(history) - 1; i >= 0; i-- {
+		h.add(history[i])
+	}
+}
+
+// list returns a copy of the records, newest first.
+func (h *syncHistory) list() []stokertypes.SyncRecord {
+	return append([]stokertypes.SyncRecord(nil), h.records...)
+}
+
+// beginSync marks the start of a sync attempt so its history record carries
+// the trigger and start time.
+func (a *Agent) beginSync(trigger string) {
+	a.syncTrigger = trigger
+	a.syncStart = time.Now()
+}
+
+// recordSync completes rec from the status about to be written, adds it to
+// the history, and attaches the history to the status. rec carries the
+// fields the status does not (file counts by kind).
+func (a *Agent) recordSync(status *stokertypes.GatewayStatus, rec stokertypes.SyncRecord) {
+	end := time.Now().UTC()
+	start := a.syncStart
+	if start.IsZero() {
+		start = end
+	}
+
+	rec.Commit = status.SyncedCommit
+	rec.Ref = status.SyncedRef
+	rec.Profile = status.ProfileName
+	if rec.Profile == "" {
+		rec.Profile = a.activeProfileName()
+	}
+	rec.Trigger = a.syncTrigger
+	rec.StartTime = start.UTC().Format(time.RFC3339)
+	rec.EndTime = end.Format(time.RFC3339)
+	rec.Result = status.SyncStatus
+	rec.ScanResult = status.LastScanResult
+	rec.Error = status.ErrorMessage
+
+	a.history.limit = a.Config.SyncHistoryLimit
+	a.history.add(rec)
+	status.History = a.history.list()
+}
+
+// restoreHistory seeds the sync history from the status published before the
+// agent restarted, so a restart does not clear it.
+func (a *Agent) restoreHistory(ctx context.Context) {
+	status, err := a.Backend.ReadStatus(ctx)
+	if err != nil {
+		logf.FromContext(ctx).Info("could not read previous status, starting with empty sync history", "error", err)
+		return
+	}
+	if status == nil {
+		return
+	}
+	a.history.limit = a.Config.SyncHistoryLimit
+	a.history.seed(status.History)
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func TestSyncHistory_KeepsNewestRecords(t *testing.T) {
+	h := syncHistory{limit: 3}
+	for i := range 5 {
+		h.add(stokertypes.SyncRecord{Commit: fmt.Sprintf("c%d", i)})
+	}
+
+	records := h.list()
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	for i, want := range []string{"c4", "c3", "c2"} {
+		if records[i].Commit != want {
+			t.Errorf("record %d: got %s, want %s", i, records[i].Commit, want)
+		}
+	}
+}
+
+func TestRecordSync(t *testing.T) {
+	a := &Agent{Config: &Config{ProfileName: "site", SyncHistoryLimit: 10}}
+
+	a.beginSync(stokertypes.SyncTriggerCommit)
+	synced := &stokertypes.GatewayStatus{
+		SyncStatus:     stokertypes.SyncStatusSynced,
+		SyncedCommit:   "abc123",
+		SyncedRef:      "main",
+		LastScanResult: "projects=200 config=200",
+	}
+	a.recordSync(synced, stokertypes.SyncRecord{FilesAdded: 2, FilesDeleted: 1})
+
+	a.beginSync(stokertypes.SyncTriggerResync + ":full")
+	failed := &stokertypes.GatewayStatus{
+		SyncStatus:   stokertypes.SyncStatusError,
+		SyncedCommit: "abc123",
+		ErrorMessage: "sync engine: boom",
+	}
+	a.recordSync(failed, stokertypes.SyncRecord{})
+
+	if len(failed.History) != 2 {
+		t.Fatalf("expected 2 history records, got %d", len(failed.History))
+	}
+	latest, first := failed.History[0], failed.History[1]
+	if latest.Trigger != "resync:full" || latest.Result != stokertypes.SyncStatusError || latest.Error != "sync engine: boom" {
+		t.Errorf("unexpected latest record: %+v", latest)
+	}
+	if latest.Profile != "site" {
+		t.Errorf("error record should fall back to the active profile, got %q", latest.Profile)
+	}
+	if first.Trigger != stokertypes.SyncTriggerCommit || first.FilesAdded != 2 || first.FilesDeleted != 1 ||
+		first.ScanResult != "projects=200 config=200" || first.StartTime == "" || first.EndTime == "" {
+		t.Errorf("unexpected first record: %+v", first)
+	}
+}
+
+func TestRestoreHistory(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	k8s := fake.NewClientBuilder().WithScheme(scheme).Build()
+	cfg := &Config{CRName: "my-sync", CRNamespace: "default", GatewayName: "gw-0", SyncHistoryLimit: 2}
+	backend := &KubeBackend{Client: k8s, Config: cfg}
+
+	previous := &stokertypes.GatewayStatus{
+		SyncStatus: stokertypes.SyncStatusSynced,
+		History:    []stokertypes.SyncRecord{{Commit: "c3"}, {Commit: "c2"}, {Commit: "c1"}},
+	}
+	if err := backend.WriteStatus(context.Background(), previous); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &Agent{Config: cfg, Backend: backend}
+	a.restoreHistory(context.Background())
+	a.setDesignerBlocked(context.Background(), true)
+
+	status, err := backend.ReadStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.History) != 2 || status.History[0].Commit != "c3" || status.History[1].Commit != "c2" {
+		t.Errorf("expected the newest restored records to survive a status write, got %+v", status.History)
+	}
+}
+
+func TestUpdateSyncedRef(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	k8s := fake.NewClientBuilder().WithScheme(scheme).Build()
+	cfg := &Config{CRName: "my-sync", CRNamespace: "default", GatewayName: "gw-0"}
+	backend := &KubeBackend{Client: k8s, Config: cfg}
+	synced := &stokertypes.GatewayStatus{
+		SyncStatus:   stokertypes.SyncStatusSynced,
+		SyncedCommit: "abc123",
+		SyncedRef:    "v2.3.4",
+		History:      []stokertypes.SyncRecord{{Commit: "abc123"}},
+	}
+	if err := backend.WriteStatus(context.Background(), synced); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &Agent{Config: cfg, Backend: backend, lastSyncedCommit: "abc123", lastSyncedRef: "v2.3.4"}
+	a.updateSyncedRef(context.Background(), "v2.3.5")
+
+	status, err := backend.ReadStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.lastSyncedRef != "v2.3.5" || status.SyncedRef != "v2.3.5" || status.SyncedCommit != "abc123" || len(status.History) != 1 {
+		t.Errorf("expected only the ref to change, got %+v", status)
+	}
+}
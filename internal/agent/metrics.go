@@ -31,6 +31,11 @@ type AgentMetrics struct {
 	DesignerSessionsActive prometheus.Gauge
 	SyncSkippedTotal       *prometheus.CounterVec
 	GatewayStartupDuration prometheus.Histogram
+	ResyncTotal            *prometheus.CounterVec
+	BackupDuration         prometheus.Histogram
+	BackupTotal            *prometheus.CounterVec
+	GatewayInfo            *prometheus.GaugeVec
+	ProfileSwitchTotal     prometheus.Counter
 }
 
 // NewAgentMetrics creates and registers all agent metrics on a standalone registry.
@@ -185,6 +190,50 @@ func NewAgentMetrics() *AgentMetrics {
 				Buckets:   []float64{5, 10, 30, 60, 120, 300, 600},
 			},
 		),
+		ResyncTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "stoker",
+				Subsystem: "agent",
+				Name:      "resync_total",
+				Help:      "Total number of manually requested resyncs.",
+			},
+			[]string{"mode", "result"},
+		),
+		BackupDuration: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Namespace: "stoker",
+				Subsystem: "agent",
+				Name:      "backup_duration_seconds",
+				Help:      "Duration of pre-change gateway backups in seconds.",
+				Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600},
+			},
+		),
+		BackupTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "stoker",
+				Subsystem: "agent",
+				Name:      "backup_total",
+				Help:      "Total number of pre-change gateway backups.",
+			},
+			[]string{"result"},
+		),
+		GatewayInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "stoker",
+				Subsystem: "agent",
+				Name:      "gateway_info",
+				Help:      "Detected Ignition gateway version (always 1).",
+			},
+			[]string{"version"},
+		),
+		ProfileSwitchTotal: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "stoker",
+				Subsystem: "agent",
+				Name:      "profile_switch_total",
+				Help:      "Total number of live profile switches applied from the stoker.io/profile annotation.",
+			},
+		),
 	}
 
 	reg.MustRegister(
@@ -204,6 +253,11 @@ func NewAgentMetrics() *AgentMetrics {
 		m.DesignerSessionsActive,
 		m.SyncSkippedTotal,
 		m.GatewayStartupDuration,
+		m.ResyncTotal,
+		m.BackupDuration,
+		m.BackupTotal,
+		m.GatewayInfo,
+		m.ProfileSwitchTotal,
 	)
 
 	return m
@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// readPodAnnotations parses a Downward API annotations file. Each line has the
+// form key="value" with the value Go-quoted. The kubelet rewrites the file
+// when the pod's annotations change, so reading it picks up edits without a
+// pod restart.
+func readPodAnnotations(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	annotations := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		key, quoted, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, fmt.Errorf("parsing annotation %q: %w", key, err)
+		}
+		annotations[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return annotations, nil
+}
+
+// podAnnotations returns the agent pod's current annotations from the
+// Downward API file. ok is false when the file is not mounted or unreadable,
+// in which case callers fall back to the values fixed at injection time.
+func (a *Agent) podAnnotations(ctx context.Context) (map[string]string, bool) {
+	if a.Config.PodAnnotationsFile == "" {
+		return nil, false
+	}
+	annotations, err := readPodAnnotations(a.Config.PodAnnotationsFile)
+	if err != nil {
+		logf.FromContext(ctx).V(1).Info("pod annotations unavailable", "file", a.Config.PodAnnotationsFile, "error", err)
+		return nil, false
+	}
+	return annotations, true
+}
@@ -0,0 +1,27 @@
+package agent
+
+import (
+	"path/filepath"
+	"testing"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func TestReadPodAnnotations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "annotations")
+	writeAnnotations(t, path, `stoker.io/cr-name="my-sync"
+stoker.io/ref-override="feature/new-alarms"
+kubectl.kubernetes.io/last-applied-configuration="{\"kind\":\"Pod\"}\n"
+`)
+
+	got, err := readPodAnnotations(path)
+	if err != nil {
+		t.Fatalf("readPodAnnotations: %v", err)
+	}
+	if got[stokertypes.AnnotationRefOverride] != "feature/new-alarms" {
+		t.Errorf("ref-override: got %q", got[stokertypes.AnnotationRefOverride])
+	}
+	if got["kubectl.kubernetes.io/last-applied-configuration"] != "{\"kind\":\"Pod\"}\n" {
+		t.Errorf("quoted value not unescaped: %q", got["kubectl.kubernetes.io/last-applied-configuration"])
+	}
+}
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ia-eknorr/stoker-operator/internal/syncengine"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// refreshProfileName re-reads the stoker.io/profile annotation and returns the
+// profile this gateway should use. With the Downward API file mounted the
+// annotation is authoritative (unset means "default"), so profiles can be
+// switched without restarting the gateway; otherwise the PROFILE env var
+// captured at injection time is used.
+func (a *Agent) refreshProfileName(ctx context.Context) string {
+	name := a.Config.ProfileName
+	if annotations, ok := a.podAnnotations(ctx); ok {
+		name = strings.TrimSpace(annotations[stokertypes.AnnotationProfile])
+	}
+	if name == "" {
+		name = "default"
+	}
+	if a.profileName != "" && name != a.profileName {
+		logf.FromContext(ctx).Info("profile annotation changed", "from", a.profileName, "to", name)
+	}
+	a.profileName = name
+	return name
+}
+
+// activeProfileName returns the profile the agent is currently following.
+func (a *Agent) activeProfileName() string {
+	if a.profileName != "" {
+		return a.profileName
+	}
+	if a.Config.ProfileName != "" {
+		return a.Config.ProfileName
+	}
+	return "default"
+}
+
+// retiredRoots returns the destinations the previously applied plan managed
+// that the new plan no longer maps. Only the plan applied since the agent
+// started is known, so roots retired before a restart are not cleaned.
+func retiredRoots(previous []string, mappings []syncengine.ResolvedMapping) []string {
+	current := make(map[string]bool, len(mappings))
+	for _, m := range mappings {
+		current[filepath.ToSlash(m.Destination)] = true
+	}
+	var retired []string
+	for _, root := range previous {
+		if !current[root] {
+			retired = append(retired, root)
+		}
+	}
+	return retired
+}
+
+// managedRoots returns the slash-separated destinations of a plan's mappings.
+func managedRoots(mappings []syncengine.ResolvedMapping) []string {
+	roots := make([]string, 0, len(mappings))
+	for _, m := range mappings {
+		roots = append(roots, filepath.ToSlash(m.Destination))
+	}
+	return roots
+}
+
+// recordProfileSwitch notes a completed transition to profileName so it is
+// reported in status, and emits an event.
+func (a *Agent) recordProfileSwitch(ctx context.Context, profileName string, filesDeleted int) {
+	if a.lastSyncedProfileName == "" || a.lastSyncedProfileName == profileName {
+		return
+	}
+	logf.FromContext(ctx).Info("profile switched", "from", a.lastSyncedProfileName, "to", profileName)
+	a.previousProfileName = a.lastSyncedProfileName
+	a.profileChangedTime = time.Now().UTC().Format(time.RFC3339)
+	a.Metrics.ProfileSwitchTotal.Inc()
+	a.event(corev1.EventTypeNormal, conditions.ReasonProfileSwitched,
+		"Profile switched on %s from %q to %q (%d file(s) removed)", a.Config.GatewayName, a.previousProfileName, profileName, filesDeleted)
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ia-eknorr/stoker-operator/internal/syncengine"
+)
+
+func TestRefreshProfileName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "annotations")
+	writeAnnotations(t, path, `stoker.io/profile="site"`+"\n")
+
+	a := &Agent{Config: &Config{ProfileName: "site", PodAnnotationsFile: path}}
+	if got := a.refreshProfileName(context.Background()); got != "site" {
+		t.Errorf("initial: got %q, want site", got)
+	}
+
+	writeAnnotations(t, path, `stoker.io/profile="area"`+"\n")
+	if got := a.refreshProfileName(context.Background()); got != "area" {
+		t.Errorf("after annotation change: got %q, want area", got)
+	}
+
+	// Removing the annotation falls back to the default profile, not the
+	// PROFILE env var captured at injection time.
+	writeAnnotations(t, path, `stoker.io/cr-name="my-sync"`+"\n")
+	if got := a.refreshProfileName(context.Background()); got != "default" {
+		t.Errorf("after annotation removal: got %q, want default", got)
+	}
+}
+
+func TestRefreshProfileName_NoAnnotationsFile(t *testing.T) {
+	a := &Agent{Config: &Config{ProfileName: "site"}}
+	if got := a.refreshProfileName(context.Background()); got != "site" {
+		t.Errorf("got %q, want PROFILE env value site", got)
+	}
+
+	a = &Agent{Config: &Config{}}
+	if got := a.refreshProfileName(context.Background()); got != "default" {
+		t.Errorf("got %q, want default", got)
+	}
+}
+
+func TestRetiredRoots(t *testing.T) {
+	previous := []string{"projects/site", "config/resources/core", "modules"}
+	mappings := []syncengine.ResolvedMapping{
+		{Destination: "projects/area"},
+		{Destination: "config/resources/core"},
+	}
+
+	got := retiredRoots(previous, mappings)
+	want := []string{"projects/site", "modules"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := retiredRoots(nil, mappings); len(got) != 0 {
+		t.Errorf("no previous plan: got %v, want none", got)
+	}
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// podAnnotation returns one of the agent pod's annotations, or "" when the
+// annotations file is not mounted or the annotation is unset.
+func (a *Agent) podAnnotation(ctx context.Context, key string) string {
+	annotations, _ := a.podAnnotations(ctx)
+	return strings.TrimSpace(annotations[key])
+}
+
+// targetRef returns the ref this gateway should follow: the pod's
+// stoker.io/ref-override annotation when set, then this gateway's staged
+// rollout target, otherwise the metadata ref. Logs when the override is set,
+// changed, or cleared.
+func (a *Agent) targetRef(ctx context.Context, meta *Metadata) string {
+	override := a.podAnnotation(ctx, stokertypes.AnnotationRefOverride)
+	if override != a.refOverride {
+		log := logf.FromContext(ctx)
+		if override == "" {
+			log.Info("ref override cleared, following metadata ref", "ref", meta.Ref)
+		} else {
+			log.Info("ref override active", "override", override, "metadataRef", meta.Ref)
+		}
+		a.refOverride = override
+	}
+	if override != "" {
+		return override
+	}
+	if target, ok := a.gatewayTarget(ctx, meta); ok {
+		return target.Ref
+	}
+	return meta.Ref
+}
+
+// gatewayTarget returns the commit a staged rollout has published to this
+// gateway, if any. Gateways without a target follow the metadata commit.
+func (a *Agent) gatewayTarget(ctx context.Context, meta *Metadata) (stokertypes.GatewayTarget, bool) {
+	targets, err := ParseGatewayTargets(meta.GatewayTargets)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "ignoring gateway targets, following metadata commit")
+		return stokertypes.GatewayTarget{}, false
+	}
+	target, ok := targets[a.Config.GatewayName]
+	return target, ok
+}
+
+// resolveTarget returns the commit and ref to sync. Without an override this
+// is the controller-resolved commit from metadata, or this gateway's target
+// while a staged rollout has updated it; with one, the override ref (which
+// may be a semver: or glob: expression) is resolved independently via ls-remote.
+func (a *Agent) resolveTarget(ctx context.Context, meta *Metadata, gitURL string, auth transport.AuthMethod) (string, string, error) {
+	ref := a.targetRef(ctx, meta)
+	if a.refOverride == "" {
+		if target, ok := a.gatewayTarget(ctx, meta); ok {
+			return target.Commit, target.Ref, nil
+		}
+		return meta.Commit, meta.Ref, nil
+	}
+	result, err := a.RefResolver.LsRemote(ctx, gitURL, ref, auth)
+	if err != nil {
+		return "", ref, fmt.Errorf("resolving %s %q: %w", stokertypes.AnnotationRefOverride, ref, err)
+	}
+	// For a ref expression, result.Ref is the chosen tag.
+	return result.Commit, result.Ref, nil
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeAnnotations(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveTarget_RefOverride(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "annotations")
+	writeAnnotations(t, path, `stoker.io/ref-override="feature/new-alarms"`+"\n")
+
+	a := &Agent{
+		Config:      &Config{PodAnnotationsFile: path},
+		RefResolver: &lsRemoteClient{commit: "feed123"},
+	}
+	meta := &Metadata{Commit: "abc123", Ref: "main"}
+
+	commit, ref, err := a.resolveTarget(context.Background(), meta, "https://example.com/repo.git", nil)
+	if err != nil {
+		t.Fatalf("resolveTarget: %v", err)
+	}
+	if commit != "feed123" || ref != "feature/new-alarms" {
+		t.Errorf("override: got %s@%s, want feed123@feature/new-alarms", ref, commit)
+	}
+
+	// Removing the annotation returns the gateway to the metadata commit.
+	writeAnnotations(t, path, `stoker.io/cr-name="my-sync"`+"\n")
+	commit, ref, err = a.resolveTarget(context.Background(), meta, "https://example.com/repo.git", nil)
+	if err != nil {
+		t.Fatalf("resolveTarget: %v", err)
+	}
+	if commit != "abc123" || ref != "main" {
+		t.Errorf("no override: got %s@%s, want abc123@main", ref, commit)
+	}
+	if a.refOverride != "" {
+		t.Errorf("refOverride should be cleared, got %q", a.refOverride)
+	}
+}
+
+func TestResolveTarget_NoAnnotationsFile(t *testing.T) {
+	a := &Agent{Config: &Config{}}
+	commit, ref, err := a.resolveTarget(context.Background(), &Metadata{Commit: "abc123", Ref: "main"}, "", nil)
+	if err != nil || commit != "abc123" || ref != "main" {
+		t.Errorf("got %s@%s, %v; want metadata commit", ref, commit, err)
+	}
+}
+
+func TestResolveTarget_GatewayTargets(t *testing.T) {
+	a := &Agent{Config: &Config{GatewayName: "canary-gw"}}
+	meta := &Metadata{
+		Commit:         "abc123",
+		Ref:            "abc123",
+		GatewayTargets: `{"canary-gw":{"commit":"feed123","ref":"main"}}`,
+	}
+
+	commit, ref, err := a.resolveTarget(context.Background(), meta, "", nil)
+	if err != nil || commit != "feed123" || ref != "main" {
+		t.Errorf("updated gateway: got %s@%s, %v; want feed123@main", ref, commit, err)
+	}
+
+	a.Config.GatewayName = "other-gw"
+	commit, ref, err = a.resolveTarget(context.Background(), meta, "", nil)
+	if err != nil || commit != "abc123" || ref != "abc123" {
+		t.Errorf("gateway not yet updated: got %s@%s, %v; want stable commit", ref, commit, err)
+	}
+}
@@ -0,0 +1,229 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ia-eknorr/stoker-operator/internal/ignition"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// ResyncMode selects what a manual resync re-runs for the current commit.
+type ResyncMode string
+
+const (
+	// ResyncModeStage re-copies files from the local checkout without triggering a scan.
+	ResyncModeStage ResyncMode = "stage"
+	// ResyncModeScan triggers the Ignition scan APIs without touching files.
+	ResyncModeScan ResyncMode = "scan"
+	// ResyncModeFull re-copies files and triggers a scan.
+	ResyncModeFull ResyncMode = "full"
+)
+
+// parseResyncMode validates a mode query value. Empty defaults to full.
+func parseResyncMode(s string) (ResyncMode, error) {
+	switch ResyncMode(s) {
+	case "":
+		return ResyncModeFull, nil
+	case ResyncModeStage, ResyncModeScan, ResyncModeFull:
+		return ResyncMode(s), nil
+	default:
+		return "", fmt.Errorf("unknown resync mode %q (expected stage, scan, or full)", s)
+	}
+}
+
+// EnableResync turns on the /resync endpoint. Callers must present token as a
+// bearer token; token is re-read on every request so rotated secrets apply
+// without a restart.
+func (hs *HealthServer) EnableResync(token func() string) {
+	hs.resyncToken = token
+}
+
+// ResyncRequests returns the channel that receives accepted resync requests.
+func (hs *HealthServer) ResyncRequests() <-chan ResyncMode {
+	return hs.resyncCh
+}
+
+// handleResync accepts POST /resync?mode=stage|scan|full. Requests are queued
+// for the agent's main loop so they never race with a regular sync.
+func (hs *HealthServer) handleResync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if hs.resyncToken == nil {
+		http.Error(w, "resync disabled", http.StatusForbidden)
+		return
+	}
+	want := hs.resyncToken()
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if want == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	mode, err := parseResyncMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if hs.shuttingDown.Load() || !hs.initialSyncDone.Load() {
+		http.Error(w, "agent not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case hs.resyncCh <- mode:
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = fmt.Fprintf(w, "%s resync queued", mode)
+	default:
+		http.Error(w, "resync already pending", http.StatusConflict)
+	}
+}
+
+// handleResync re-runs part of the sync for the last synced commit. Unlike
+// handleSyncTrigger it does not skip unchanged commits and ignores backoff,
+// so an operator can recover from a failed scan without a new commit or a
+// gateway restart. Stage and full resyncs copy files from the last synced
+// commit, never from a newer one left checked out by a skipped sync.
+func (a *Agent) handleResync(ctx context.Context, mode ResyncMode, gitURL string, auth transport.AuthMethod) {
+	log := logf.FromContext(ctx).WithName("resync")
+
+	log.Info("manual resync requested", "mode", mode, "commit", a.lastSyncedCommit)
+	a.event(corev1.EventTypeNormal, conditions.ReasonResyncRequested,
+		"Manual %s resync requested on %s", mode, a.Config.GatewayName)
+
+	if a.lastSyncedCommit == "" {
+		log.Info("no synced commit yet, ignoring resync")
+		a.Metrics.ResyncTotal.WithLabelValues(string(mode), "skipped").Inc()
+		return
+	}
+
+	done := a.trackSync()
+	defer done()
+	a.beginSync(stokertypes.SyncTriggerResync + ":" + string(mode))
+
+	// Use a context that survives SIGTERM so in-flight syncs can complete.
+	syncCtx := context.WithoutCancel(ctx)
+
+	var err error
+	switch mode {
+	case ResyncModeScan:
+		err = a.rescan(syncCtx)
+	default:
+		// Stage and full resyncs change files, so they honor the designer
+		// session policy like any other sync.
+		var meta *Metadata
+		if meta, err = a.Backend.ReadMetadata(ctx); err != nil {
+			err = fmt.Errorf("reading metadata: %w", err)
+			break
+		}
+		var profile *stokertypes.ResolvedProfile
+		if profile, _, err = a.lookupProfile(meta); err != nil {
+			break
+		}
+		if a.designerBlocksSync(ctx, profile, a.lastSyncedCommit, a.lastSyncedRef) {
+			a.Metrics.ResyncTotal.WithLabelValues(string(mode), "skipped").Inc()
+			return
+		}
+		if err = a.restoreCheckout(syncCtx, meta, gitURL, auth); err != nil {
+			break
+		}
+		syncMode := syncNormal
+		if mode == ResyncModeStage {
+			syncMode = syncStageOnly
+		}
+		err = a.syncOnce(syncCtx, a.lastSyncedCommit, a.lastSyncedRef, syncMode, a.lastSyncedProfiles)
+	}
+
+	if err != nil {
+		log.Error(err, "manual resync failed", "mode", mode)
+		a.Metrics.ResyncTotal.WithLabelValues(string(mode), "error").Inc()
+		a.event(corev1.EventTypeWarning, conditions.ReasonResyncFailed,
+			"Manual %s resync failed on %s: %v", mode, a.Config.GatewayName, err)
+		return
+	}
+
+	log.Info("manual resync complete", "mode", mode)
+	a.Metrics.ResyncTotal.WithLabelValues(string(mode), "success").Inc()
+	a.event(corev1.EventTypeNormal, conditions.ReasonResyncCompleted,
+		"Manual %s resync completed on %s", mode, a.Config.GatewayName)
+}
+
+// restoreCheckout checks out the last synced commit again when RepoPath holds
+// another one: a commit fetched but not applied because a designer session,
+// the schedule, a sync error, or signature verification stopped it.
+func (a *Agent) restoreCheckout(ctx context.Context, meta *Metadata, gitURL string, auth transport.AuthMethod) error {
+	if a.lastSyncedCommit == "" || a.checkedOut == a.lastSyncedCommit {
+		return nil
+	}
+	sparse := a.sparsePaths(ctx, meta, a.lastSyncedCommit, a.lastSyncedRef)
+	result, err := a.GitClient.CloneOrFetch(ctx, gitURL, a.lastSyncedCommit, a.Config.RepoPath, sparse, auth)
+	if err != nil {
+		// The working tree is in an unknown state; retry on the next call.
+		a.checkedOut = ""
+		return fmt.Errorf("checking out synced commit %s: %w", a.lastSyncedCommit, err)
+	}
+	a.checkedOut = result.Commit
+	return nil
+}
+
+// rescan triggers the Ignition scan APIs for the files already on disk and
+// reports the outcome to the status ConfigMap.
+func (a *Agent) rescan(ctx context.Context) error {
+	scanStart := time.Now()
+	scanResult := a.IgnitionAPI.TriggerScan()
+	a.Metrics.ScanDuration.Observe(time.Since(scanStart).Seconds())
+
+	if scanResult.Skipped {
+		a.Metrics.ScanTotal.WithLabelValues("skipped").Inc()
+		return fmt.Errorf("scan: %w", ignition.ErrUnsupported)
+	}
+	if scanResult.Error != "" {
+		a.Metrics.ScanTotal.WithLabelValues("error").Inc()
+		a.reportError(ctx, a.lastSyncedCommit, a.lastSyncedRef, fmt.Sprintf("rescan: %s", scanResult.Error))
+		return fmt.Errorf("scan: %s", scanResult.Error)
+	}
+	a.Metrics.ScanTotal.WithLabelValues("success").Inc()
+
+	status := &stokertypes.GatewayStatus{
+		SyncStatus:     stokertypes.SyncStatusSynced,
+		SyncedCommit:   a.lastSyncedCommit,
+		SyncedRef:      a.lastSyncedRef,
+		LastSyncTime:   time.Now().UTC().Format(time.RFC3339),
+		AgentVersion:   agentVersion,
+		LastScanResult: scanResult.String(),
+		LastBackup:     a.lastBackup,
+		GatewayVersion: a.gatewayVersion,
+	}
+	if profile, profileName, err := a.lookupProfileFromMetadata(ctx); err == nil {
+		status.ProfileName = profileName
+		status.ProfileLibrary = profile.Library
+	}
+	a.recordSync(status, stokertypes.SyncRecord{})
+	if err := a.Backend.WriteStatus(ctx, status); err != nil {
+		return fmt.Errorf("writing status: %w", err)
+	}
+	return nil
+}
+
+// lookupProfileFromMetadata reads the metadata ConfigMap and resolves the agent's profile.
+func (a *Agent) lookupProfileFromMetadata(ctx context.Context) (*stokertypes.ResolvedProfile, string, error) {
+	meta, err := a.Backend.ReadMetadata(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("reading metadata: %w", err)
+	}
+	return a.lookupProfile(meta)
+}
@@ -0,0 +1,342 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/internal/ignition"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func newResyncServer(token string) *HealthServer {
+	hs := NewHealthServer(":0")
+	hs.EnableResync(func() string { return token })
+	hs.MarkReady()
+	return hs
+}
+
+func resyncRequest(method, mode, token string) *http.Request {
+	req := httptest.NewRequest(method, "/resync?mode="+mode, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestParseResyncMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ResyncMode
+		wantErr bool
+	}{
+		{in: "", want: ResyncModeFull},
+		{in: "stage", want: ResyncModeStage},
+		{in: "scan", want: ResyncModeScan},
+		{in: "full", want: ResyncModeFull},
+		{in: "restart", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseResyncMode(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseResyncMode(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("parseResyncMode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestHandleResync_Accepted(t *testing.T) {
+	hs := newResyncServer("secret")
+
+	rec := httptest.NewRecorder()
+	hs.handleResync(rec, resyncRequest(http.MethodPost, "scan", "secret"))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	select {
+	case mode := <-hs.ResyncRequests():
+		if mode != ResyncModeScan {
+			t.Errorf("queued mode: got %q, want %q", mode, ResyncModeScan)
+		}
+	default:
+		t.Fatal("expected resync request to be queued")
+	}
+}
+
+func TestHandleResync_Rejections(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(*HealthServer)
+		req   *http.Request
+		want  int
+	}{
+		{
+			name: "wrong method",
+			req:  resyncRequest(http.MethodGet, "full", "secret"),
+			want: http.StatusMethodNotAllowed,
+		},
+		{
+			name: "missing token",
+			req:  resyncRequest(http.MethodPost, "full", ""),
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "wrong token",
+			req:  resyncRequest(http.MethodPost, "full", "nope"),
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "unknown mode",
+			req:  resyncRequest(http.MethodPost, "restart", "secret"),
+			want: http.StatusBadRequest,
+		},
+		{
+			name:  "shutting down",
+			setup: func(hs *HealthServer) { hs.MarkNotReady() },
+			req:   resyncRequest(http.MethodPost, "full", "secret"),
+			want:  http.StatusServiceUnavailable,
+		},
+		{
+			name:  "already pending",
+			setup: func(hs *HealthServer) { hs.resyncCh <- ResyncModeFull },
+			req:   resyncRequest(http.MethodPost, "full", "secret"),
+			want:  http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hs := newResyncServer("secret")
+			if tt.setup != nil {
+				tt.setup(hs)
+			}
+			rec := httptest.NewRecorder()
+			hs.handleResync(rec, tt.req)
+			if rec.Code != tt.want {
+				t.Errorf("expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleResync_DisabledWithoutToken(t *testing.T) {
+	hs := NewHealthServer(":0")
+	hs.MarkReady()
+
+	rec := httptest.NewRecorder()
+	hs.handleResync(rec, resyncRequest(http.MethodPost, "full", "secret"))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 when resync is not enabled, got %d", rec.Code)
+	}
+
+	// An empty API key must never authenticate.
+	hs.EnableResync(func() string { return "" })
+	rec = httptest.NewRecorder()
+	hs.handleResync(rec, httptest.NewRequest(http.MethodPost, "/resync", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with empty API key, got %d", rec.Code)
+	}
+}
+
+func TestAgentHandleResync_Scan(t *testing.T) {
+	var scans []string
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scans = append(scans, r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gw.Close()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	k8s := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	cfg := &Config{CRName: "my-sync", CRNamespace: "default", GatewayName: "gw-0"}
+	a := &Agent{
+		Config:       cfg,
+		K8sClient:    k8s,
+		Backend:      &KubeBackend{Client: k8s, Config: cfg},
+		IgnitionAPI:  ignition.NewClient("http", strings.TrimPrefix(gw.URL, "http://"), "key", nil),
+		HealthServer: NewHealthServer(":0"),
+		Metrics:      NewAgentMetrics(),
+		shutdownCh:   make(chan struct{}, 1),
+
+		lastSyncedCommit: "abc123",
+		lastSyncedRef:    "main",
+	}
+
+	a.handleResync(context.Background(), ResyncModeScan, "", nil)
+
+	if len(scans) != 2 {
+		t.Fatalf("expected projects and config scans, got %v", scans)
+	}
+	if v := testutil.ToFloat64(a.Metrics.ResyncTotal.WithLabelValues("scan", "success")); v != 1 {
+		t.Errorf("expected resync_total{mode=scan,result=success}=1, got %f", v)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := k8s.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: stokertypes.StatusConfigMapName("my-sync", "", "gw-0")}, cm); err != nil {
+		t.Fatalf("status ConfigMap not written: %v", err)
+	}
+	var status stokertypes.GatewayStatus
+	if err := json.Unmarshal([]byte(cm.Data["gw-0"]), &status); err != nil {
+		t.Fatalf("decoding status: %v", err)
+	}
+	if status.SyncStatus != stokertypes.SyncStatusSynced || status.SyncedCommit != "abc123" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestAgentHandleResync_NoCommitSkipped(t *testing.T) {
+	a := &Agent{
+		Config:     &Config{GatewayName: "gw-0"},
+		Metrics:    NewAgentMetrics(),
+		shutdownCh: make(chan struct{}, 1),
+	}
+
+	a.handleResync(context.Background(), ResyncModeFull, "", nil)
+
+	if v := testutil.ToFloat64(a.Metrics.ResyncTotal.WithLabelValues("full", "skipped")); v != 1 {
+		t.Errorf("expected resync_total{mode=full,result=skipped}=1, got %f", v)
+	}
+}
+
+func TestAgentHandleResync_FullHonorsDesignerPolicy(t *testing.T) {
+	var scans int
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/data/api/v1/designers" {
+			_, _ = w.Write([]byte(`{"items":[{"user":"jane","project":"site"}]}`))
+			return
+		}
+		scans++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gw.Close()
+
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: stokertypes.MetadataConfigMapName("my-sync"), Namespace: "default"},
+		Data:       map[string]string{"profiles": `{"default":{"mappings":[],"designerSessionPolicy":"fail"}}`},
+	}).Build()
+
+	cfg := &Config{CRName: "my-sync", CRNamespace: "default", GatewayName: "gw-0"}
+	a := &Agent{
+		Config:       cfg,
+		K8sClient:    k8s,
+		Backend:      &KubeBackend{Client: k8s, Config: cfg},
+		IgnitionAPI:  ignition.NewClient("http", strings.TrimPrefix(gw.URL, "http://"), "key", nil),
+		HealthServer: NewHealthServer(":0"),
+		Metrics:      NewAgentMetrics(),
+		shutdownCh:   make(chan struct{}, 1),
+
+		lastSyncedCommit: "abc123",
+		lastSyncedRef:    "main",
+	}
+
+	a.handleResync(context.Background(), ResyncModeFull, "", nil)
+
+	if scans != 0 {
+		t.Errorf("blocked resync must not scan, got %d scan calls", scans)
+	}
+	if v := testutil.ToFloat64(a.Metrics.ResyncTotal.WithLabelValues("full", "skipped")); v != 1 {
+		t.Errorf("expected resync_total{mode=full,result=skipped}=1, got %f", v)
+	}
+	if v := testutil.ToFloat64(a.Metrics.SyncSkippedTotal.WithLabelValues("designer_blocked")); v != 1 {
+		t.Errorf("expected sync_skipped_total{reason=designer_blocked}=1, got %f", v)
+	}
+}
+
+// checkoutClient records the refs CloneOrFetch is asked for.
+type checkoutClient struct {
+	lsRemoteClient
+	refs []string
+	err  error
+}
+
+func (c *checkoutClient) CloneOrFetch(_ context.Context, _, ref, _ string, _ []string, _ transport.AuthMethod) (git.Result, error) {
+	c.refs = append(c.refs, ref)
+	if c.err != nil {
+		return git.Result{}, c.err
+	}
+	return git.Result{Commit: ref, Ref: ref}, nil
+}
+
+func TestAgentRestoreCheckout(t *testing.T) {
+	gc := &checkoutClient{}
+	a := &Agent{
+		Config:           &Config{RepoPath: t.TempDir()},
+		GitClient:        gc,
+		lastSyncedCommit: "abc123",
+		checkedOut:       "def456",
+	}
+
+	if err := a.restoreCheckout(context.Background(), &Metadata{}, "https://example.com/repo.git", nil); err != nil {
+		t.Fatal(err)
+	}
+	if a.checkedOut != "abc123" || strings.Join(gc.refs, ",") != "abc123" {
+		t.Errorf("expected the synced commit checked out, got %q after fetching %v", a.checkedOut, gc.refs)
+	}
+	if err := a.restoreCheckout(context.Background(), &Metadata{}, "https://example.com/repo.git", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(gc.refs) != 1 {
+		t.Errorf("an up-to-date checkout should not be fetched again, got %v", gc.refs)
+	}
+}
+
+func TestAgentHandleResync_StageRestoresSyncedCommit(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: stokertypes.MetadataConfigMapName("my-sync"), Namespace: "default"},
+		Data:       map[string]string{"profiles": `{"default":{"mappings":[]}}`},
+	}).Build()
+
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"items":[]}`))
+	}))
+	defer gw.Close()
+
+	// A newer commit was fetched but never applied; the restore fails, so
+	// nothing may be copied from the newer tree.
+	gc := &checkoutClient{err: errors.New("network down")}
+	cfg := &Config{CRName: "my-sync", CRNamespace: "default", GatewayName: "gw-0", RepoPath: t.TempDir()}
+	a := &Agent{
+		Config:      cfg,
+		K8sClient:   k8s,
+		Backend:     &KubeBackend{Client: k8s, Config: cfg},
+		GitClient:   gc,
+		IgnitionAPI: ignition.NewClient("http", strings.TrimPrefix(gw.URL, "http://"), "key", nil),
+		Metrics:     NewAgentMetrics(),
+		shutdownCh:  make(chan struct{}, 1),
+
+		lastSyncedCommit: "abc123",
+		lastSyncedRef:    "main",
+		checkedOut:       "def456",
+	}
+
+	a.handleResync(context.Background(), ResyncModeStage, "https://example.com/repo.git", nil)
+
+	if strings.Join(gc.refs, ",") != "abc123" {
+		t.Errorf("expected a checkout of the synced commit, got %v", gc.refs)
+	}
+	if v := testutil.ToFloat64(a.Metrics.ResyncTotal.WithLabelValues("stage", "error")); v != 1 {
+		t.Errorf("expected resync_total{mode=stage,result=error}=1, got %f", v)
+	}
+}
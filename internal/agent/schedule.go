@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// deferredBySchedule reports whether applying the new commit waits for the
+// next spec.sync.schedule window. The controller publishes new commits only
+// inside a window; this keeps a gateway that picks one up late (a restart,
+// a slow watch) from applying it after the window has closed. An automatic
+// rollback target is never deferred. An unreadable schedule is ignored since
+// the controller already gates publishing. Logs once per deferred commit.
+func (a *Agent) deferredBySchedule(ctx context.Context, meta *Metadata, commit string, now time.Time) bool {
+	log := logf.FromContext(ctx)
+	if meta.ScheduleOverride == "true" || (meta.Rollback == "true" && commit == meta.Commit) {
+		return false
+	}
+	sched, err := ParseSchedule(meta.Schedule)
+	if err != nil {
+		log.Error(err, "ignoring sync schedule")
+		return false
+	}
+	if sched == nil || sched.Open(now) {
+		a.deferredCommit = ""
+		return false
+	}
+	if commit != a.deferredCommit {
+		next, _ := sched.NextOpen(now)
+		log.Info("new commit deferred until the next maintenance window", "commit", commit, "nextWindow", next)
+		a.deferredCommit = commit
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeferredBySchedule(t *testing.T) {
+	// Nightly 02:00 UTC window, one hour long.
+	meta := &Metadata{Schedule: `{"windows":[{"start":"0 2 * * *","duration":"1h"}]}`}
+	inside := time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC)
+	outside := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	a := &Agent{}
+	if !a.deferredBySchedule(ctx, meta, "abc123", outside) {
+		t.Error("new commit outside the window should be deferred")
+	}
+	if a.deferredCommit != "abc123" {
+		t.Errorf("deferredCommit = %q, want abc123", a.deferredCommit)
+	}
+	if a.deferredBySchedule(ctx, meta, "abc123", inside) {
+		t.Error("new commit inside the window should be applied")
+	}
+
+	meta.ScheduleOverride = "true"
+	if a.deferredBySchedule(ctx, meta, "abc123", outside) {
+		t.Error("schedule override should apply immediately")
+	}
+
+	meta.ScheduleOverride = ""
+	meta.Commit, meta.Rollback = "old456", "true"
+	if a.deferredBySchedule(ctx, meta, "old456", outside) {
+		t.Error("a rollback target should apply outside the window")
+	}
+	if !a.deferredBySchedule(ctx, meta, "abc123", outside) {
+		t.Error("only the rollback target itself is exempt")
+	}
+
+	if a.deferredBySchedule(ctx, &Metadata{}, "abc123", outside) {
+		t.Error("no schedule should never defer")
+	}
+	if a.deferredBySchedule(ctx, &Metadata{Schedule: `{"timeZone":"Mars/Olympus"}`}, "abc123", outside) {
+		t.Error("an unreadable schedule should be ignored by the agent")
+	}
+}
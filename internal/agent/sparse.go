@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// mappingSources resolves the source templates of every mapping in profile.
+// These are the only repo paths a sync reads.
+func mappingSources(profile *stokertypes.ResolvedProfile, tmplCtx *TemplateContext) ([]string, error) {
+	sources := make([]string, 0, len(profile.Mappings))
+	for i, m := range profile.Mappings {
+		src, err := resolveTemplate(m.Source, tmplCtx)
+		if err != nil {
+			return nil, fmt.Errorf("mapping[%d].source: %w", i, err)
+		}
+		if err := validateResolvedPath(src, fmt.Sprintf("mapping[%d].source", i)); err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// sparsePaths returns the repo paths to check out for the active profile at
+// commit and ref. Returns nil (the whole tree) when the profile or its
+// templates cannot be resolved, so the sync plan reports the real error.
+func (a *Agent) sparsePaths(ctx context.Context, meta *Metadata, commit, ref string) []string {
+	log := logf.FromContext(ctx)
+	profile, _, err := a.lookupProfile(meta)
+	if err != nil {
+		return nil
+	}
+	labels, err := a.Backend.Labels(ctx)
+	if err != nil {
+		log.V(1).Info("labels unavailable, checking out the whole tree", "error", err.Error())
+		return nil
+	}
+
+	target := *meta
+	target.Commit, target.Ref = commit, ref
+	sources, err := mappingSources(profile, buildTemplateContext(a.Config, &target, profile.Vars, labels))
+	if err != nil {
+		log.V(1).Info("mapping sources unresolved, checking out the whole tree", "error", err.Error())
+		return nil
+	}
+	return sources
+}
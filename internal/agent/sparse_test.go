@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func TestMappingSources(t *testing.T) {
+	profile := &stokertypes.ResolvedProfile{Mappings: []stokertypes.ResolvedMapping{
+		{Source: "shared/config", Destination: "config"},
+		{Source: "sites/{{.Labels.site}}/projects", Destination: "projects"},
+		{Source: "gateways/{{.GatewayName}}/.versions.json", Destination: ".versions.json"},
+	}}
+	ctx := &TemplateContext{GatewayName: "gw-1", Labels: map[string]string{"site": "plant-a"}}
+
+	got, err := mappingSources(profile, ctx)
+	if err != nil {
+		t.Fatalf("mappingSources: %v", err)
+	}
+	want := []string{"shared/config", "sites/plant-a/projects", "gateways/gw-1/.versions.json"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	profile.Mappings = append(profile.Mappings, stokertypes.ResolvedMapping{Source: "{{.Vars.missing}}"})
+	if _, err := mappingSources(profile, ctx); err == nil {
+		t.Error("expected error for an unresolved template")
+	}
+
+	profile.Mappings = []stokertypes.ResolvedMapping{{Source: "../outside"}}
+	if _, err := mappingSources(profile, ctx); err == nil {
+		t.Error("expected error for path traversal")
+	}
+}
@@ -0,0 +1,477 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"sigs.k8s.io/yaml"
+
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// standaloneProfileName is the profile name reported in standalone status.
+const standaloneProfileName = "standalone"
+
+// StandaloneConfig is the config file for running the agent next to an
+// Ignition gateway outside Kubernetes (VM or bare metal). It replaces the
+// GatewaySync CR, metadata ConfigMap, and webhook-injected env vars.
+type StandaloneConfig struct {
+	// GatewayName identifies this gateway in status and templates. Defaults to the hostname.
+	GatewayName string `json:"gatewayName,omitempty"`
+	// RepoPath is where the repository is cloned. Default: /var/lib/stoker/repo.
+	RepoPath string `json:"repoPath,omitempty"`
+	// DataPath is the Ignition data directory. Default: /usr/local/bin/ignition/data.
+	DataPath string `json:"dataPath,omitempty"`
+
+	Git     StandaloneGitConfig     `json:"git"`
+	Gateway StandaloneGatewayConfig `json:"gateway,omitempty"`
+
+	// Profile holds the file mappings and sync options, in the same shape the
+	// controller resolves from spec.sync.
+	Profile stokertypes.ResolvedProfile `json:"profile"`
+	// Labels are exposed to templates as {{.Labels}}.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	Status StandaloneStatusConfig  `json:"status,omitempty"`
+	Backup *StandaloneBackupConfig `json:"backup,omitempty"`
+	Audit  *StandaloneAuditConfig  `json:"audit,omitempty"`
+}
+
+// StandaloneGitConfig configures the repository and credentials.
+type StandaloneGitConfig struct {
+	Repo string `json:"repo"`
+	Ref  string `json:"ref"`
+	// PollInterval is how often the remote is checked for new commits. Default: 60s.
+	PollInterval   string `json:"pollInterval,omitempty"`
+	TokenFile      string `json:"tokenFile,omitempty"`
+	SSHKeyFile     string `json:"sshKeyFile,omitempty"`
+	KnownHostsFile string `json:"knownHostsFile,omitempty"`
+	// Client selects the git implementation: native (default) or go-git.
+	Client string `json:"client,omitempty"`
+	// ProxyURL is an HTTP(S) proxy for https:// remotes.
+	ProxyURL string `json:"proxyURL,omitempty"`
+	// CAFile is a PEM CA bundle trusted for the remote, in addition to the system roots.
+	CAFile string `json:"caFile,omitempty"`
+	// Verification requires checked-out commits (or tags) to be signed by a trusted key.
+	Verification *StandaloneVerificationConfig `json:"verification,omitempty"`
+}
+
+// StandaloneVerificationConfig configures commit signature verification, in the
+// same shape as spec.git.verification with a directory in place of the Secret.
+type StandaloneVerificationConfig struct {
+	// Policy is SignedCommit (default) or SignedTag.
+	Policy string `json:"policy,omitempty"`
+	// TrustedKeysDir holds GPG and SSH public key files.
+	TrustedKeysDir string `json:"trustedKeysDir"`
+}
+
+// StandaloneGatewayConfig configures access to the local gateway API.
+type StandaloneGatewayConfig struct {
+	Port               int    `json:"port,omitempty"`
+	TLS                bool   `json:"tls,omitempty"`
+	CAFile             string `json:"caFile,omitempty"`
+	ServerName         string `json:"serverName,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	APIKeyFile         string `json:"apiKeyFile,omitempty"`
+}
+
+// StandaloneStatusConfig configures where status and metrics are published.
+type StandaloneStatusConfig struct {
+	// File receives the gateway status as JSON after every sync. Default: /var/lib/stoker/status.json.
+	File string `json:"file,omitempty"`
+	// ListenAddr serves /healthz, /readyz, /resync, and /status. Default: :8082.
+	ListenAddr string `json:"listenAddr,omitempty"`
+	// MetricsAddr serves Prometheus metrics. Default: :8083.
+	MetricsAddr string `json:"metricsAddr,omitempty"`
+	// HistoryLimit is the number of sync records kept in the status. Default: 20.
+	HistoryLimit int `json:"historyLimit,omitempty"`
+}
+
+// StandaloneBackupConfig enables pre-change gateway backups.
+type StandaloneBackupConfig struct {
+	Dir      string   `json:"dir"`
+	Paths    []string `json:"paths,omitempty"`
+	MaxCount int      `json:"maxCount,omitempty"` // default 10
+	MaxAge   string   `json:"maxAge,omitempty"`
+}
+
+// StandaloneAuditConfig configures the audit record sinks, in the same shape
+// as spec.agent.audit with file paths in place of claims and Secrets.
+type StandaloneAuditConfig struct {
+	Stdout bool                   `json:"stdout,omitempty"`
+	File   *StandaloneAuditFile   `json:"file,omitempty"`
+	Syslog *StandaloneAuditSyslog `json:"syslog,omitempty"`
+	HTTP   *StandaloneAuditHTTP   `json:"http,omitempty"`
+}
+
+// StandaloneAuditFile appends audit records to a size-rotated file.
+type StandaloneAuditFile struct {
+	Path       string `json:"path"`
+	MaxSizeMB  int    `json:"maxSizeMB,omitempty"`
+	MaxBackups int    `json:"maxBackups,omitempty"`
+}
+
+// StandaloneAuditSyslog sends audit records to an RFC 5424 syslog receiver.
+type StandaloneAuditSyslog struct {
+	Address  string `json:"address"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// StandaloneAuditHTTP POSTs audit records to an HTTP collector.
+type StandaloneAuditHTTP struct {
+	URL       string `json:"url"`
+	TokenFile string `json:"tokenFile,omitempty"`
+}
+
+// LoadStandaloneConfig reads and validates a standalone config file (YAML or JSON).
+func LoadStandaloneConfig(path string) (*StandaloneConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	sc := &StandaloneConfig{}
+	if err := yaml.UnmarshalStrict(data, sc); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	if err := sc.defaultAndValidate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return sc, nil
+}
+
+func (sc *StandaloneConfig) defaultAndValidate() error {
+	if sc.GatewayName == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("gatewayName is required: %w", err)
+		}
+		sc.GatewayName = host
+	}
+	if sc.RepoPath == "" {
+		sc.RepoPath = "/var/lib/stoker/repo"
+	}
+	if sc.DataPath == "" {
+		sc.DataPath = "/usr/local/bin/ignition/data"
+	}
+	if sc.Git.PollInterval == "" {
+		sc.Git.PollInterval = "60s"
+	}
+	if sc.Git.Client == "" {
+		sc.Git.Client = git.ClientNative
+	}
+	if sc.Gateway.Port == 0 {
+		sc.Gateway.Port = 8088
+	}
+	if sc.Status.File == "" {
+		sc.Status.File = "/var/lib/stoker/status.json"
+	}
+	if sc.Status.ListenAddr == "" {
+		sc.Status.ListenAddr = defaultHealthAddr
+	}
+	if sc.Status.MetricsAddr == "" {
+		sc.Status.MetricsAddr = defaultMetricsAddr
+	}
+	if sc.Status.HistoryLimit <= 0 {
+		sc.Status.HistoryLimit = defaultSyncHistoryLimit
+	}
+
+	if sc.Git.Repo == "" {
+		return fmt.Errorf("git.repo is required")
+	}
+	if sc.Git.Ref == "" {
+		return fmt.Errorf("git.ref is required")
+	}
+	if git.IsRefExpression(sc.Git.Ref) {
+		if err := git.ParseRefExpression(sc.Git.Ref); err != nil {
+			return fmt.Errorf("git.ref: %w", err)
+		}
+	}
+	if d, err := time.ParseDuration(sc.Git.PollInterval); err != nil || d < time.Second {
+		return fmt.Errorf("git.pollInterval %q must be a duration of at least 1s", sc.Git.PollInterval)
+	}
+	if sc.Git.Client != git.ClientNative && sc.Git.Client != git.ClientGoGit {
+		return fmt.Errorf("git.client %q must be %s or %s", sc.Git.Client, git.ClientNative, git.ClientGoGit)
+	}
+	if len(sc.Profile.Mappings) == 0 {
+		return fmt.Errorf("profile.mappings must contain at least one mapping")
+	}
+	if v := sc.Git.Verification; v != nil {
+		if v.Policy == "" {
+			v.Policy = git.VerifySignedCommit
+		}
+		if v.Policy != git.VerifySignedCommit && v.Policy != git.VerifySignedTag {
+			return fmt.Errorf("git.verification.policy %q must be %s or %s", v.Policy, git.VerifySignedCommit, git.VerifySignedTag)
+		}
+		if v.TrustedKeysDir == "" {
+			return fmt.Errorf("git.verification.trustedKeysDir is required when git.verification is set")
+		}
+	}
+	if sc.Backup != nil {
+		if sc.Backup.Dir == "" {
+			return fmt.Errorf("backup.dir is required when backup is set")
+		}
+		if sc.Backup.MaxAge != "" {
+			if _, err := time.ParseDuration(sc.Backup.MaxAge); err != nil {
+				return fmt.Errorf("invalid backup.maxAge %q: %w", sc.Backup.MaxAge, err)
+			}
+		}
+	}
+	if a := sc.Audit; a != nil {
+		if a.File != nil && a.File.Path == "" {
+			return fmt.Errorf("audit.file.path is required when audit.file is set")
+		}
+		if a.Syslog != nil {
+			if a.Syslog.Address == "" {
+				return fmt.Errorf("audit.syslog.address is required when audit.syslog is set")
+			}
+			if p := a.Syslog.Protocol; p != "" && p != "udp" && p != "tcp" {
+				return fmt.Errorf("audit.syslog.protocol %q must be udp or tcp", p)
+			}
+		}
+		if a.HTTP != nil && a.HTTP.URL == "" {
+			return fmt.Errorf("audit.http.url is required when audit.http is set")
+		}
+	}
+	return nil
+}
+
+// agentConfig maps the standalone config onto the agent runtime config so the
+// sync path is shared with the Kubernetes sidecar.
+func (sc *StandaloneConfig) agentConfig() *Config {
+	poll, _ := time.ParseDuration(sc.Git.PollInterval)
+	cfg := &Config{
+		PodName:           sc.GatewayName,
+		GatewayName:       sc.GatewayName,
+		RepoPath:          sc.RepoPath,
+		DataPath:          sc.DataPath,
+		GatewayPort:       strconv.Itoa(sc.Gateway.Port),
+		GatewayTLS:        sc.Gateway.TLS,
+		GatewayCAFile:     sc.Gateway.CAFile,
+		GatewayServerName: sc.Gateway.ServerName,
+		GatewayInsecure:   sc.Gateway.InsecureSkipVerify,
+		APIKeyFile:        sc.Gateway.APIKeyFile,
+		SyncPeriod:        max(int(poll/time.Second), 1),
+		GitTokenFile:      sc.Git.TokenFile,
+		GitSSHKeyFile:     sc.Git.SSHKeyFile,
+		GitKnownHostsFile: sc.Git.KnownHostsFile,
+		GitClient:         sc.Git.Client,
+		GitProxyURL:       sc.Git.ProxyURL,
+		GitCAFile:         sc.Git.CAFile,
+		ProfileName:       standaloneProfileName,
+		HealthAddr:        sc.Status.ListenAddr,
+		MetricsAddr:       sc.Status.MetricsAddr,
+		SyncHistoryLimit:  sc.Status.HistoryLimit,
+	}
+	if v := sc.Git.Verification; v != nil {
+		cfg.VerifyPolicy = v.Policy
+		cfg.VerifyKeysDir = v.TrustedKeysDir
+	}
+	if b := sc.Backup; b != nil {
+		cfg.BackupDir = b.Dir
+		cfg.BackupMaxCount = defaultBackupMaxCount
+		if b.MaxCount > 0 {
+			cfg.BackupMaxCount = b.MaxCount
+		}
+		cfg.BackupMaxAge, _ = time.ParseDuration(b.MaxAge)
+		for _, p := range b.Paths {
+			if p = strings.Trim(strings.TrimSpace(p), "/"); p != "" {
+				cfg.BackupPaths = append(cfg.BackupPaths, p)
+			}
+		}
+		if len(cfg.BackupPaths) == 0 {
+			cfg.BackupPaths = []string{"config"}
+		}
+	}
+	if a := sc.Audit; a != nil {
+		cfg.Audit.Stdout = a.Stdout
+		if a.File != nil {
+			cfg.Audit.File = a.File.Path
+			cfg.Audit.FileMaxSizeMB = a.File.MaxSizeMB
+			cfg.Audit.FileMaxBackups = a.File.MaxBackups
+		}
+		if a.Syslog != nil {
+			cfg.Audit.SyslogAddress = a.Syslog.Address
+			cfg.Audit.SyslogProtocol = a.Syslog.Protocol
+		}
+		if a.HTTP != nil {
+			cfg.Audit.HTTPURL = a.HTTP.URL
+			cfg.Audit.HTTPTokenFile = a.HTTP.TokenFile
+		}
+	}
+	return cfg
+}
+
+// NewStandalone creates an Agent that polls git directly and publishes status
+// to a local file and the /status endpoint, without any Kubernetes API access.
+func NewStandalone(sc *StandaloneConfig) (*Agent, error) {
+	profiles, err := json.Marshal(map[string]*stokertypes.ResolvedProfile{standaloneProfileName: &sc.Profile})
+	if err != nil {
+		return nil, fmt.Errorf("encoding profile: %w", err)
+	}
+
+	cfg := sc.agentConfig()
+	backend := &StandaloneBackend{
+		repoURL:    sc.Git.Repo,
+		ref:        sc.Git.Ref,
+		profiles:   string(profiles),
+		labels:     sc.Labels,
+		statusFile: sc.Status.File,
+	}
+
+	a, err := newAgent(cfg, backend)
+	if err != nil {
+		return nil, err
+	}
+	backend.git = a.RefResolver
+	// Re-read credential files on every poll so rotated secrets are picked up.
+	backend.auth = a.resolveFileAuth
+	a.HealthServer.Handle("/status", backend)
+	a.Watcher = NewWatcher(nil, "", "", time.Duration(cfg.SyncPeriod)*time.Second)
+	return a, nil
+}
+
+// StandaloneBackend resolves the sync target with git ls-remote and keeps the
+// latest status in a local JSON file.
+type StandaloneBackend struct {
+	git        git.Client
+	auth       func() transport.AuthMethod
+	repoURL    string
+	ref        string
+	profiles   string // profiles JSON, same shape as the metadata ConfigMap
+	labels     map[string]string
+	statusFile string
+
+	mu     sync.RWMutex
+	status []byte    // last written status JSON
+	meta   *Metadata // resolved target for the current sync trigger
+}
+
+var (
+	_ Backend       = (*StandaloneBackend)(nil)
+	_ metadataCache = (*StandaloneBackend)(nil)
+)
+
+// ReadMetadata resolves the configured ref to a commit on the remote. The
+// result is reused until InvalidateMetadata, so every read during one sync
+// sees the same commit even if the ref moves meanwhile.
+func (b *StandaloneBackend) ReadMetadata(ctx context.Context) (*Metadata, error) {
+	b.mu.RLock()
+	cached := b.meta
+	b.mu.RUnlock()
+	if cached != nil {
+		meta := *cached
+		return &meta, nil
+	}
+
+	var auth transport.AuthMethod
+	if b.auth != nil {
+		auth = b.auth()
+	}
+	result, err := b.git.LsRemote(ctx, b.repoURL, b.ref, auth)
+	if err != nil {
+		return nil, fmt.Errorf("resolving ref %q: %w", b.ref, err)
+	}
+	meta := &Metadata{
+		Commit:   result.Commit,
+		Ref:      result.Ref,
+		GitURL:   b.repoURL,
+		Profiles: b.profiles,
+	}
+	b.mu.Lock()
+	b.meta = meta
+	b.mu.Unlock()
+	copied := *meta
+	return &copied, nil
+}
+
+// InvalidateMetadata makes the next ReadMetadata resolve the ref again.
+func (b *StandaloneBackend) InvalidateMetadata() {
+	b.mu.Lock()
+	b.meta = nil
+	b.mu.Unlock()
+}
+
+// WriteStatus atomically replaces the status file and updates /status.
+func (b *StandaloneBackend) WriteStatus(_ context.Context, status *stokertypes.GatewayStatus) error {
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding status: %w", err)
+	}
+
+	b.mu.Lock()
+	b.status = data
+	b.mu.Unlock()
+
+	if b.statusFile == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(b.statusFile), 0o755); err != nil {
+		return fmt.Errorf("creating status directory: %w", err)
+	}
+	tmp := b.statusFile + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing status file: %w", err)
+	}
+	if err := os.Rename(tmp, b.statusFile); err != nil {
+		return fmt.Errorf("replacing status file: %w", err)
+	}
+	return nil
+}
+
+// ReadStatus reads the status file left by a previous run and serves it on
+// /status until the next write.
+func (b *StandaloneBackend) ReadStatus(context.Context) (*stokertypes.GatewayStatus, error) {
+	if b.statusFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(b.statusFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading status file: %w", err)
+	}
+	status := &stokertypes.GatewayStatus{}
+	if err := json.Unmarshal(data, status); err != nil {
+		return nil, fmt.Errorf("decoding status file: %w", err)
+	}
+	b.mu.Lock()
+	if b.status == nil {
+		b.status = data
+	}
+	b.mu.Unlock()
+	return status, nil
+}
+
+// Labels returns the labels from the config file.
+func (b *StandaloneBackend) Labels(context.Context) (map[string]string, error) {
+	return b.labels, nil
+}
+
+// ServeHTTP serves the latest status as JSON on GET /status.
+func (b *StandaloneBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	b.mu.RLock()
+	data := b.status
+	b.mu.RUnlock()
+	if data == nil {
+		http.Error(w, "no status yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
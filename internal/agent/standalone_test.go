@@ -0,0 +1,241 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// lsRemoteClient is a git.Client stub that resolves every ref to a fixed commit.
+type lsRemoteClient struct {
+	commit string
+}
+
+func (c *lsRemoteClient) CloneOrFetch(context.Context, string, string, string, []string, transport.AuthMethod) (git.Result, error) {
+	return git.Result{Commit: c.commit}, nil
+}
+
+func (c *lsRemoteClient) LsRemote(_ context.Context, _, ref string, _ transport.AuthMethod) (git.Result, error) {
+	return git.Result{Commit: c.commit, Ref: ref}, nil
+}
+
+func (c *lsRemoteClient) FetchCommit(context.Context, string, string, transport.AuthMethod) (git.CommitInfo, error) {
+	return git.CommitInfo{}, nil
+}
+
+func (c *lsRemoteClient) DiffCommits(context.Context, string, string, string, transport.AuthMethod) (git.DiffSummary, error) {
+	return git.DiffSummary{}, nil
+}
+
+func (c *lsRemoteClient) VerifyRemote(context.Context, string, git.Result, string, *git.TrustedKeys, transport.AuthMethod) (string, error) {
+	return "", nil
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stoker.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadStandaloneConfig(t *testing.T) {
+	path := writeConfigFile(t, `
+gatewayName: plant-a
+git:
+  repo: https://example.com/acme/ignition.git
+  ref: main
+  pollInterval: 2m
+  tokenFile: /etc/stoker/git-token
+  verification:
+    trustedKeysDir: /etc/stoker/trusted-keys
+gateway:
+  tls: true
+  apiKeyFile: /etc/stoker/api-key
+profile:
+  mappings:
+    - source: gateways/plant-a/config
+      destination: config
+  vars:
+    site: a
+backup:
+  dir: /var/backups/stoker
+  maxAge: 168h
+audit:
+  file:
+    path: /var/log/stoker/audit.log
+  syslog:
+    address: siem.example.com:514
+`)
+
+	sc, err := LoadStandaloneConfig(path)
+	if err != nil {
+		t.Fatalf("LoadStandaloneConfig: %v", err)
+	}
+
+	cfg := sc.agentConfig()
+	if cfg.GatewayName != "plant-a" || cfg.GatewayPort != "8088" || !cfg.GatewayTLS {
+		t.Errorf("unexpected gateway config: %+v", cfg)
+	}
+	if cfg.SyncPeriod != 120 {
+		t.Errorf("SyncPeriod: got %d, want 120", cfg.SyncPeriod)
+	}
+	if cfg.HealthAddr != defaultHealthAddr || cfg.MetricsAddr != defaultMetricsAddr {
+		t.Errorf("unexpected listen addresses: %q %q", cfg.HealthAddr, cfg.MetricsAddr)
+	}
+	if cfg.BackupDir != "/var/backups/stoker" || len(cfg.BackupPaths) != 1 || cfg.BackupPaths[0] != "config" {
+		t.Errorf("unexpected backup config: dir=%q paths=%v", cfg.BackupDir, cfg.BackupPaths)
+	}
+	if cfg.BackupMaxCount != defaultBackupMaxCount {
+		t.Errorf("BackupMaxCount: got %d, want default %d", cfg.BackupMaxCount, defaultBackupMaxCount)
+	}
+	if cfg.Audit.File != "/var/log/stoker/audit.log" || cfg.Audit.SyslogAddress != "siem.example.com:514" || cfg.Audit.Stdout {
+		t.Errorf("unexpected audit config: %+v", cfg.Audit)
+	}
+	if cfg.VerifyPolicy != git.VerifySignedCommit || cfg.VerifyKeysDir != "/etc/stoker/trusted-keys" {
+		t.Errorf("unexpected verification config: policy=%q dir=%q", cfg.VerifyPolicy, cfg.VerifyKeysDir)
+	}
+	if sc.Status.File != "/var/lib/stoker/status.json" {
+		t.Errorf("status file default: got %q", sc.Status.File)
+	}
+}
+
+func TestLoadStandaloneConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "missing repo",
+			content: "gatewayName: gw\ngit:\n  ref: main\nprofile:\n  mappings: [{source: a, destination: b}]\n",
+			wantErr: "git.repo",
+		},
+		{
+			name:    "no mappings",
+			content: "gatewayName: gw\ngit:\n  repo: r\n  ref: main\n",
+			wantErr: "profile.mappings",
+		},
+		{
+			name:    "bad ref expression",
+			content: "gatewayName: gw\ngit:\n  repo: r\n  ref: 'semver:latest'\nprofile:\n  mappings: [{source: a, destination: b}]\n",
+			wantErr: "git.ref",
+		},
+		{
+			name:    "bad poll interval",
+			content: "gatewayName: gw\ngit:\n  repo: r\n  ref: main\n  pollInterval: soon\nprofile:\n  mappings: [{source: a, destination: b}]\n",
+			wantErr: "pollInterval",
+		},
+		{
+			name:    "bad syslog protocol",
+			content: "gatewayName: gw\ngit:\n  repo: r\n  ref: main\nprofile:\n  mappings: [{source: a, destination: b}]\naudit:\n  syslog: {address: 'h:514', protocol: tls}\n",
+			wantErr: "audit.syslog.protocol",
+		},
+		{
+			name:    "bad verification policy",
+			content: "gatewayName: gw\ngit:\n  repo: r\n  ref: main\n  verification: {policy: Any, trustedKeysDir: /k}\nprofile:\n  mappings: [{source: a, destination: b}]\n",
+			wantErr: "git.verification.policy",
+		},
+		{
+			name:    "unknown field",
+			content: "gatewayName: gw\nrepo: r\n",
+			wantErr: "unknown field",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadStandaloneConfig(writeConfigFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestStandaloneBackend_ReadMetadata(t *testing.T) {
+	gc := &lsRemoteClient{commit: "abc123"}
+	b := &StandaloneBackend{
+		git:      gc,
+		repoURL:  "https://example.com/repo.git",
+		ref:      "v1.2.0",
+		profiles: `{"standalone":{"mappings":[{"source":"a","destination":"b"}]}}`,
+	}
+
+	meta, err := b.ReadMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("ReadMetadata: %v", err)
+	}
+	if meta.Commit != "abc123" || meta.Ref != "v1.2.0" || meta.GitURL != b.repoURL {
+		t.Errorf("unexpected metadata: %+v", meta)
+	}
+	profiles, err := ParseResolvedProfiles(meta.Profiles)
+	if err != nil {
+		t.Fatalf("ParseResolvedProfiles: %v", err)
+	}
+	if _, ok := profiles[standaloneProfileName]; !ok {
+		t.Errorf("expected %q profile, got %v", standaloneProfileName, profiles)
+	}
+
+	// The ref moving mid-sync must not change what later reads report.
+	gc.commit = "def456"
+	if meta, _ = b.ReadMetadata(context.Background()); meta.Commit != "abc123" {
+		t.Errorf("expected cached commit abc123 within a trigger, got %s", meta.Commit)
+	}
+	b.InvalidateMetadata()
+	if meta, _ = b.ReadMetadata(context.Background()); meta.Commit != "def456" {
+		t.Errorf("expected def456 after invalidation, got %s", meta.Commit)
+	}
+}
+
+func TestStandaloneBackend_WriteStatus(t *testing.T) {
+	statusFile := filepath.Join(t.TempDir(), "state", "status.json")
+	b := &StandaloneBackend{statusFile: statusFile}
+
+	// /status is unavailable until the first write.
+	rec := httptest.NewRecorder()
+	b.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 before first status, got %d", rec.Code)
+	}
+
+	status := &stokertypes.GatewayStatus{SyncStatus: stokertypes.SyncStatusSynced, SyncedCommit: "abc123"}
+	if err := b.WriteStatus(context.Background(), status); err != nil {
+		t.Fatalf("WriteStatus: %v", err)
+	}
+
+	data, err := os.ReadFile(statusFile)
+	if err != nil {
+		t.Fatalf("status file not written: %v", err)
+	}
+	var fromFile stokertypes.GatewayStatus
+	if err := json.Unmarshal(data, &fromFile); err != nil {
+		t.Fatalf("decoding status file: %v", err)
+	}
+	if fromFile.SyncedCommit != "abc123" {
+		t.Errorf("status file commit: got %q", fromFile.SyncedCommit)
+	}
+
+	rec = httptest.NewRecorder()
+	b.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var fromHTTP stokertypes.GatewayStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &fromHTTP); err != nil {
+		t.Fatalf("decoding /status: %v", err)
+	}
+	if fromHTTP.SyncStatus != stokertypes.SyncStatusSynced {
+		t.Errorf("/status sync status: got %q", fromHTTP.SyncStatus)
+	}
+}
@@ -0,0 +1,20 @@
+package agent
+
+import (
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+)
+
+// verifyCheckout re-verifies the signature of the commit (or tag) just checked
+// out, since a ref override bypasses the controller's verification. Keys are
+// reloaded each time so Secret rotations take effect without a restart.
+// Returns the signer, or "" when verification is disabled.
+func (a *Agent) verifyCheckout(result git.Result) (string, error) {
+	if a.Config.VerifyPolicy == "" {
+		return "", nil
+	}
+	keys, err := git.LoadTrustedKeys(a.Config.VerifyKeysDir)
+	if err != nil {
+		return "", err
+	}
+	return git.VerifyClone(a.Config.RepoPath, result, a.Config.VerifyPolicy, keys)
+}
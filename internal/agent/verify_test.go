@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func TestHandleSyncTrigger_VerificationFailureRestoresCheckout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	k8s := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: stokertypes.MetadataConfigMapName("my-sync"), Namespace: "default"},
+		Data: map[string]string{
+			"commit":   "def456",
+			"ref":      "def456",
+			"gitURL":   "https://example.com/repo.git",
+			"profiles": `{"default":{"mappings":[]}}`,
+		},
+	}).Build()
+
+	gc := &checkoutClient{}
+	cfg := &Config{
+		CRName: "my-sync", CRNamespace: "default", GatewayName: "gw-0", RepoPath: t.TempDir(),
+		// No keys can be loaded, so every checkout fails verification.
+		VerifyPolicy: git.VerifySignedCommit, VerifyKeysDir: t.TempDir() + "/missing",
+	}
+	a := &Agent{
+		Config:     cfg,
+		K8sClient:  k8s,
+		Backend:    &KubeBackend{Client: k8s, Config: cfg},
+		GitClient:  gc,
+		Metrics:    NewAgentMetrics(),
+		shutdownCh: make(chan struct{}, 1),
+
+		lastSyncedCommit:   "abc123",
+		lastSyncedRef:      "main",
+		lastSyncedProfiles: `{"default":{"mappings":[]}}`,
+		checkedOut:         "abc123",
+	}
+
+	a.handleSyncTrigger(context.Background(), "https://example.com/repo.git", nil)
+
+	if strings.Join(gc.refs, ",") != "def456,abc123" {
+		t.Errorf("expected a fetch of the new commit and a restore of the synced one, got %v", gc.refs)
+	}
+	if a.checkedOut != "abc123" || a.lastSyncedCommit != "abc123" {
+		t.Errorf("unverified commit left checked out: checkedOut=%q lastSynced=%q", a.checkedOut, a.lastSyncedCommit)
+	}
+}
@@ -5,13 +5,20 @@ import (
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	toolscache "k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
 )
 
 // Watcher watches the metadata ConfigMap for changes and emits events on a channel.
 type Watcher struct {
-	client    client.Client
+	client    client.WithWatch
 	namespace string
 	cmName    string
 	syncCh    chan struct{}
@@ -20,11 +27,11 @@ type Watcher struct {
 }
 
 // NewWatcher creates a Watcher for the metadata ConfigMap.
-func NewWatcher(c client.Client, namespace, crName string, syncPeriod time.Duration) *Watcher {
+func NewWatcher(c client.WithWatch, namespace, crName string, syncPeriod time.Duration) *Watcher {
 	return &Watcher{
 		client:    c,
 		namespace: namespace,
-		cmName:    MetadataConfigMapName(crName),
+		cmName:    stokertypes.MetadataConfigMapName(crName),
 		syncCh:    make(chan struct{}, 1),
 		period:    syncPeriod,
 		resetCh:   make(chan time.Duration, 1),
@@ -65,15 +72,18 @@ func (w *Watcher) trigger() {
 	}
 }
 
-// Run starts both the ConfigMap poller and fallback timer.
+// Run starts both the ConfigMap watch and fallback timer. Without a
+// Kubernetes client (standalone mode) only the timer runs.
 // Blocks until ctx is cancelled.
 func (w *Watcher) Run(ctx context.Context) {
 	log := logf.FromContext(ctx).WithName("watcher")
 
-	// Start ConfigMap poller in background.
-	go w.pollConfigMap(ctx)
+	// Start ConfigMap watch in background.
+	if w.client != nil {
+		go w.watchConfigMap(ctx)
+	}
 
-	// Fallback timer — ensures sync even if poller misses events.
+	// Fallback timer — ensures sync even if the watch misses events.
 	ticker := time.NewTicker(w.period)
 	defer ticker.Stop()
 
@@ -93,33 +103,74 @@ func (w *Watcher) Run(ctx context.Context) {
 	}
 }
 
-// pollConfigMap periodically reads the metadata ConfigMap and triggers sync on change.
-func (w *Watcher) pollConfigMap(ctx context.Context) {
-	log := logf.FromContext(ctx).WithName("cm-poller")
+// watchConfigMap runs a single-object informer on the metadata ConfigMap and
+// triggers a sync whenever its resourceVersion changes. The informer's
+// reflector re-lists and re-watches with backoff when the watch drops, so an
+// idle agent holds one open watch instead of polling the API server.
+func (w *Watcher) watchConfigMap(ctx context.Context) {
+	log := logf.FromContext(ctx).WithName("cm-watcher")
+
+	nameSelector := fields.OneTermEqualSelector("metadata.name", w.cmName).String()
+	lw := &toolscache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			opts.FieldSelector = nameSelector
+			list := &corev1.ConfigMapList{}
+			err := w.client.List(ctx, list, &client.ListOptions{Namespace: w.namespace, Raw: &opts})
+			return list, err
+		},
+		WatchFuncWithContext: func(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+			opts.FieldSelector = nameSelector
+			return w.client.Watch(ctx, &corev1.ConfigMapList{}, &client.ListOptions{Namespace: w.namespace, Raw: &opts})
+		},
+	}
 
-	var lastVersion string
-	ticker := time.NewTicker(3 * time.Second)
-	defer ticker.Stop()
+	// No informer resync: the fallback ticker in Run already re-triggers
+	// syncs periodically, and resyncs would not carry a new resourceVersion.
+	informer := toolscache.NewSharedIndexInformerWithOptions(plainListWatch{lw}, &corev1.ConfigMap{},
+		toolscache.SharedIndexInformerOptions{ObjectDescription: "metadata ConfigMap " + w.cmName})
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			cm := &corev1.ConfigMap{}
-			key := client.ObjectKey{Name: w.cmName, Namespace: w.namespace}
-			if err := w.client.Get(ctx, key, cm); err != nil {
-				log.V(1).Info("metadata ConfigMap not yet available", "error", err)
-				continue
-			}
-
-			if cm.ResourceVersion != lastVersion {
-				if lastVersion != "" {
-					log.Info("metadata ConfigMap changed", "version", cm.ResourceVersion)
+	// The field selector already scopes the watch; the name check guards
+	// against clients that do not apply it.
+	isMetadata := func(obj any) bool {
+		cm, ok := obj.(*corev1.ConfigMap)
+		return ok && cm.Name == w.cmName
+	}
+	_, err := informer.AddEventHandler(toolscache.FilteringResourceEventHandler{
+		FilterFunc: isMetadata,
+		Handler: toolscache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(_ any, isInInitialList bool) {
+				// The agent has already read the ConfigMap before the watcher starts;
+				// only a ConfigMap created later (e.g. after deletion) is news.
+				if !isInInitialList {
+					log.Info("metadata ConfigMap created")
 					w.trigger()
 				}
-				lastVersion = cm.ResourceVersion
-			}
-		}
+			},
+			UpdateFunc: func(oldObj, newObj any) {
+				oldCM := oldObj.(*corev1.ConfigMap)
+				newCM := newObj.(*corev1.ConfigMap)
+				if oldCM.ResourceVersion == newCM.ResourceVersion {
+					return
+				}
+				log.Info("metadata ConfigMap changed", "version", newCM.ResourceVersion)
+				w.trigger()
+			},
+		},
+	})
+	if err != nil {
+		log.Error(err, "registering metadata ConfigMap handler; relying on fallback timer")
+		return
 	}
+
+	informer.RunWithContext(ctx)
+}
+
+// plainListWatch opts the informer out of streaming WatchList. The initial list
+// is a single object, so streaming saves nothing, and a plain list+watch works
+// against every API server version and client implementation.
+type plainListWatch struct {
+	*toolscache.ListWatch
 }
+
+// IsWatchListSemanticsUnSupported is consulted by the client-go reflector.
+func (plainListWatch) IsWatchListSemanticsUnSupported() bool { return true }
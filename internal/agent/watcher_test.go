@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func TestWatcher_ConfigMapChangeTriggersSync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: stokertypes.MetadataConfigMapName("my-sync"), Namespace: "default"},
+		Data:       map[string]string{"commit": "abc123"},
+	}
+	other := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "default"},
+	}
+	k8s := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(cm, other).
+		WithIndex(&corev1.ConfigMap{}, "metadata.name", func(o client.Object) []string {
+			return []string{o.GetName()}
+		}).
+		Build()
+
+	// Long fallback period so only the watch can trigger within the test.
+	w := NewWatcher(k8s, "default", "my-sync", time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	// The initial list must not trigger: the agent already read the ConfigMap.
+	select {
+	case <-w.Events():
+		t.Fatal("unexpected sync trigger from initial list")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// Changes to other ConfigMaps are ignored.
+	other.Data = map[string]string{"k": "v"}
+	if err := k8s.Update(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Events():
+		t.Fatal("unexpected sync trigger from unrelated ConfigMap")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	cm.Data["commit"] = "def456"
+	if err := k8s.Update(ctx, cm); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Events():
+	case <-time.After(time.Second):
+		t.Fatal("expected sync trigger within 1s of ConfigMap update")
+	}
+}
@@ -0,0 +1,155 @@
+// Package audit emits one structured record per agent sync to pluggable
+// sinks (stdout, rotating file, syslog, HTTP) for change-audit trails.
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Record is the audit entry for one sync. It is serialized as a single JSON
+// object per sink write.
+type Record struct {
+	// Type identifies stoker audit records in shared log streams.
+	Type string `json:"type"`
+	// Time is when the sync finished.
+	Time time.Time `json:"time"`
+
+	Gateway   string `json:"gateway"`
+	Namespace string `json:"namespace,omitempty"`
+	CRName    string `json:"crName,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+	Trigger   string `json:"trigger,omitempty"`
+
+	Commit        string    `json:"commit"`
+	Ref           string    `json:"ref,omitempty"`
+	CommitAuthor  string    `json:"commitAuthor,omitempty"`
+	CommitEmail   string    `json:"commitEmail,omitempty"`
+	CommitMessage string    `json:"commitMessage,omitempty"`
+	CommitTime    time.Time `json:"commitTime,omitzero"`
+
+	// Result is the sync status reported for the gateway (Pending, Synced, Error).
+	Result string `json:"result"`
+	DryRun bool   `json:"dryRun,omitempty"`
+	Files  Files  `json:"files"`
+
+	ScanResult string `json:"scanResult,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Duration is the file sync duration in milliseconds.
+	DurationMS int64 `json:"durationMs"`
+}
+
+// RecordType is the value of Record.Type.
+const RecordType = "stoker.sync"
+
+// Files lists the paths, relative to the gateway data directory, a sync
+// changed (or would change, for dry-run syncs).
+type Files struct {
+	Added    []string `json:"added"`
+	Modified []string `json:"modified"`
+	Deleted  []string `json:"deleted"`
+}
+
+// Sink receives audit records.
+type Sink interface {
+	Write(ctx context.Context, rec *Record) error
+	Close() error
+}
+
+// Config selects and configures sinks. Zero values disable a sink.
+type Config struct {
+	Stdout bool
+
+	File           string // path of the audit log file
+	FileMaxSizeMB  int    // rotate when the file would exceed this size; default 10
+	FileMaxBackups int    // rotated files kept; default 5
+
+	SyslogAddress  string // host:port of an RFC 5424 syslog receiver
+	SyslogProtocol string // "udp" (default) or "tcp"
+
+	HTTPURL       string // records are POSTed as JSON to this URL
+	HTTPTokenFile string // optional bearer token file, re-read on every request
+}
+
+// Enabled reports whether any sink is configured.
+func (c Config) Enabled() bool {
+	return c.Stdout || c.File != "" || c.SyslogAddress != "" || c.HTTPURL != ""
+}
+
+// Logger fans records out to every configured sink. A nil *Logger discards records.
+type Logger struct {
+	sinks []Sink
+}
+
+// NewLogger returns a Logger that writes to sinks.
+func NewLogger(sinks ...Sink) *Logger {
+	return &Logger{sinks: sinks}
+}
+
+// New builds a Logger for cfg. Returns nil when no sink is configured.
+func New(cfg Config, gatewayName string) (*Logger, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	var sinks []Sink
+	if cfg.Stdout {
+		sinks = append(sinks, NewWriterSink(os.Stdout))
+	}
+	if cfg.File != "" {
+		s, err := NewFileSink(cfg.File, cfg.FileMaxSizeMB, cfg.FileMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if cfg.SyslogAddress != "" {
+		s, err := NewSyslogSink(cfg.SyslogProtocol, cfg.SyslogAddress, gatewayName)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if cfg.HTTPURL != "" {
+		sinks = append(sinks, NewHTTPSink(cfg.HTTPURL, cfg.HTTPTokenFile))
+	}
+	return NewLogger(sinks...), nil
+}
+
+// Log writes rec to every sink. A failing sink is logged and does not stop
+// the others or fail the sync.
+func (l *Logger) Log(ctx context.Context, rec *Record) {
+	if l == nil {
+		return
+	}
+	if rec.Type == "" {
+		rec.Type = RecordType
+	}
+	// Empty lists serialize as [] rather than null for consumers with strict schemas.
+	for _, files := range []*[]string{&rec.Files.Added, &rec.Files.Modified, &rec.Files.Deleted} {
+		if *files == nil {
+			*files = []string{}
+		}
+	}
+	for _, s := range l.sinks {
+		if err := s.Write(ctx, rec); err != nil {
+			logf.FromContext(ctx).Error(err, "failed to write audit record", "sink", fmt.Sprintf("%T", s), "commit", rec.Commit)
+		}
+	}
+}
+
+// Close closes every sink.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	var errs []error
+	for _, s := range l.sinks {
+		errs = append(errs, s.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRecord() *Record {
+	return &Record{
+		Type:    RecordType,
+		Time:    time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Gateway: "plant-a",
+		Commit:  "abc123",
+		Result:  "Synced",
+		Files:   Files{Added: []string{"config/a.json"}},
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf strings.Builder
+	s := NewWriterSink(&buf)
+	if err := s.Write(context.Background(), testRecord()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var got Record
+	if err := json.Unmarshal([]byte(buf.String()), &got); err != nil {
+		t.Fatalf("decoding record: %v", err)
+	}
+	if got.Commit != "abc123" || len(got.Files.Added) != 1 || !strings.HasSuffix(buf.String(), "\n") {
+		t.Errorf("unexpected record line: %q", buf.String())
+	}
+}
+
+func TestFileSink_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	s, err := NewFileSink(path, 1, 2)
+	if err != nil {
+		t.Fatalf("NewFileSink: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	s.maxSize = 300 // force rotation every couple of records
+
+	for range 10 {
+		if err := s.Write(context.Background(), testRecord()); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 backups, found %s.3", path)
+	}
+	info, _ := os.Stat(path)
+	if info.Size() > s.maxSize {
+		t.Errorf("active file exceeds max size: %d", info.Size())
+	}
+}
+
+var rfc5424 = regexp.MustCompile(`^<(\d+)>1 (\S+) (\S+) stoker-agent \d+ sync - (\{.*\})$`)
+
+func TestFormatRFC5424(t *testing.T) {
+	rec := testRecord()
+	msg, err := formatRFC5424(rec, "plant-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rfc5424.FindStringSubmatch(string(msg))
+	if m == nil {
+		t.Fatalf("message does not match RFC 5424 layout: %q", msg)
+	}
+	if m[1] != "134" || m[2] != "2026-03-01T12:00:00Z" || m[3] != "plant-a" {
+		t.Errorf("unexpected header fields: pri=%s time=%s host=%s", m[1], m[2], m[3])
+	}
+
+	rec.Error = "boom"
+	msg, _ = formatRFC5424(rec, "plant-a")
+	if !strings.HasPrefix(string(msg), "<132>1 ") {
+		t.Errorf("failed sync should use warning severity: %q", msg)
+	}
+}
+
+func TestSyslogSink_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		line, _ := bufio.NewReader(conn).ReadString('}')
+		received <- line
+	}()
+
+	s, err := NewSyslogSink("tcp", ln.Addr().String(), "plant-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	if err := s.Write(context.Background(), testRecord()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		// Octet-counting framing: "<len> <msg>".
+		length, msg, ok := strings.Cut(got, " ")
+		if !ok || !strings.HasPrefix(msg, "<134>1 ") || length == "" {
+			t.Errorf("unexpected framed message: %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("syslog receiver got nothing")
+	}
+}
+
+func TestSyslogSink_InvalidProtocol(t *testing.T) {
+	if _, err := NewSyslogSink("tls", "localhost:514", ""); err == nil {
+		t.Error("expected error for unsupported protocol")
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var gotAuth string
+	var got Record
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	s := NewHTTPSink(srv.URL, tokenFile)
+	if err := s.Write(context.Background(), testRecord()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if gotAuth != "Bearer s3cret" {
+		t.Errorf("Authorization: got %q", gotAuth)
+	}
+	if got.Gateway != "plant-a" {
+		t.Errorf("posted record gateway: got %q", got.Gateway)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := NewHTTPSink(failing.URL, "").Write(context.Background(), testRecord()); err == nil {
+		t.Error("expected error for non-2xx response")
+	}
+}
+
+func TestNew_NoSinks(t *testing.T) {
+	l, err := New(Config{}, "gw")
+	if err != nil || l != nil {
+		t.Fatalf("expected nil logger without sinks, got %v, %v", l, err)
+	}
+	// A nil logger discards records.
+	l.Log(context.Background(), testRecord())
+	if err := l.Close(); err != nil {
+		t.Errorf("Close on nil logger: %v", err)
+	}
+}
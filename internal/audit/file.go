@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	defaultFileMaxSizeMB  = 10
+	defaultFileMaxBackups = 5
+)
+
+// FileSink appends JSON lines to a file, rotating it by size. Rotated files
+// are renamed path.1 (newest) through path.N (oldest). Every record is synced
+// to disk before Write returns.
+type FileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	f          *os.File
+	size       int64
+}
+
+// NewFileSink opens (or creates) the audit file at path.
+func NewFileSink(path string, maxSizeMB, maxBackups int) (*FileSink, error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultFileMaxSizeMB
+	}
+	if maxBackups <= 0 {
+		maxBackups = defaultFileMaxBackups
+	}
+	s := &FileSink{path: path, maxSize: int64(maxSizeMB) << 20, maxBackups: maxBackups}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating audit log directory: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("opening audit log %s: %w", s.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat audit log %s: %w", s.path, err)
+	}
+	s.f, s.size = f, info.Size()
+	return nil
+}
+
+// Write appends rec as a JSON line, rotating first if it would overflow the file.
+func (s *FileSink) Write(_ context.Context, rec *Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size > 0 && s.size+int64(len(data)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.f.Write(data)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return s.f.Sync()
+}
+
+// rotate shifts path.N-1 → path.N, …, path → path.1 and reopens path.
+func (s *FileSink) rotate() error {
+	if err := s.f.Close(); err != nil {
+		return fmt.Errorf("closing audit log: %w", err)
+	}
+	_ = os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+	}
+	if err := os.Rename(s.path, s.path+".1"); err != nil {
+		return fmt.Errorf("rotating audit log: %w", err)
+	}
+	return s.open()
+}
+
+// Close closes the audit file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
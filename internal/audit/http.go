@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const httpSinkTimeout = 10 * time.Second
+
+// HTTPSink POSTs each record as JSON to a collector endpoint (e.g. a SIEM
+// HTTP event collector). Non-2xx responses are reported as errors.
+type HTTPSink struct {
+	url       string
+	tokenFile string
+	client    *http.Client
+}
+
+// NewHTTPSink returns a sink that POSTs to url. When tokenFile is set its
+// contents are sent as a bearer token.
+func NewHTTPSink(url, tokenFile string) *HTTPSink {
+	return &HTTPSink{url: url, tokenFile: tokenFile, client: &http.Client{Timeout: httpSinkTimeout}}
+}
+
+// Write POSTs rec to the collector.
+func (s *HTTPSink) Write(ctx context.Context, rec *Record) error {
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building audit request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.tokenFile != "" {
+		token, err := os.ReadFile(s.tokenFile)
+		if err != nil {
+			return fmt.Errorf("reading audit token file: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting audit record: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("posting audit record: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close is a no-op.
+func (s *HTTPSink) Close() error { return nil }
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// syslogFacility is local0; records carry severity info, or warning for failed syncs.
+	syslogFacility        = 16
+	syslogSeverityInfo    = 6
+	syslogSeverityWarning = 4
+
+	syslogAppName = "stoker-agent"
+	syslogMsgID   = "sync"
+
+	syslogDialTimeout = 5 * time.Second
+)
+
+// SyslogSink sends records as RFC 5424 messages with the JSON record as the
+// message body. TCP uses octet-counting framing (RFC 6587) and reconnects
+// after a failed write.
+type SyslogSink struct {
+	mu       sync.Mutex
+	protocol string
+	address  string
+	hostname string
+	conn     net.Conn
+}
+
+// NewSyslogSink returns a sink for the syslog receiver at address. protocol
+// is "udp" (default) or "tcp"; hostname is reported in the HOSTNAME field.
+func NewSyslogSink(protocol, address, hostname string) (*SyslogSink, error) {
+	switch protocol {
+	case "":
+		protocol = "udp"
+	case "udp", "tcp":
+	default:
+		return nil, fmt.Errorf("unsupported syslog protocol %q (want udp or tcp)", protocol)
+	}
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	return &SyslogSink{protocol: protocol, address: address, hostname: hostname}, nil
+}
+
+// Write sends rec as one syslog message.
+func (s *SyslogSink) Write(_ context.Context, rec *Record) error {
+	msg, err := formatRFC5424(rec, s.hostname)
+	if err != nil {
+		return err
+	}
+	if s.protocol == "tcp" {
+		msg = []byte(fmt.Sprintf("%d %s", len(msg), msg))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.protocol, s.address, syslogDialTimeout)
+		if err != nil {
+			return fmt.Errorf("connecting to syslog %s: %w", s.address, err)
+		}
+		s.conn = conn
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(syslogDialTimeout))
+	if _, err := s.conn.Write(msg); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("writing to syslog %s: %w", s.address, err)
+	}
+	return nil
+}
+
+// Close closes the connection to the syslog receiver.
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// formatRFC5424 renders rec as
+// <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG.
+func formatRFC5424(rec *Record, hostname string) ([]byte, error) {
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	severity := syslogSeverityInfo
+	if rec.Error != "" {
+		severity = syslogSeverityWarning
+	}
+	if hostname == "" {
+		hostname = "-"
+	}
+	return fmt.Appendf(nil, "<%d>1 %s %s %s %d %s - %s",
+		syslogFacility*8+severity,
+		rec.Time.UTC().Format(time.RFC3339Nano),
+		hostname, syslogAppName, os.Getpid(), syslogMsgID, body), nil
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// maxDiffFiles caps the paths listed in status.pendingCommit.diff.files.
+const maxDiffFiles = 20
+
+// gateApproval holds a newly resolved commit in status.pendingCommit when
+// spec.approval is set, and releases it once the approval annotations name
+// it. Returns the commit to continue with: the resolved commit when approved,
+// otherwise the last approved one (status.lastSyncCommit). ok is false when
+// no commit has been approved yet.
+func (r *GatewaySyncReconciler) gateApproval(ctx context.Context, gs *stokerv1alpha1.GatewaySync, result git.Result) (git.Result, bool) {
+	if gs.Spec.Approval == nil {
+		gs.Status.PendingCommit = nil
+		meta.RemoveStatusCondition(&gs.Status.Conditions, conditions.TypeAwaitingApproval)
+		return result, true
+	}
+
+	approved := git.Result{Commit: gs.Status.LastSyncCommit, Ref: gs.Status.LastSyncRef}
+	// An approved commit deferred by spec.sync.schedule stays approved.
+	if result.Commit == approved.Commit || (gs.Status.LastApproval != nil && gs.Status.LastApproval.Commit == result.Commit) {
+		gs.Status.PendingCommit = nil
+		r.setCondition(ctx, gs, conditions.TypeAwaitingApproval, metav1.ConditionFalse, conditions.ReasonApproved,
+			fmt.Sprintf("%s is approved", git.ShortCommit(result.Commit)))
+		return result, true
+	}
+
+	if pending := gs.Status.PendingCommit; pending == nil || pending.Commit != result.Commit {
+		gs.Status.PendingCommit = &stokerv1alpha1.PendingCommit{
+			Commit:     result.Commit,
+			Ref:        result.Ref,
+			CommitInfo: agentCommitInfo(result.Info.Status()),
+			Since:      metav1.Now(),
+		}
+		changes := ""
+		if approved.Commit != "" {
+			diff := r.diffSummary(ctx, gs, approved.Commit, result.Commit)
+			gs.Status.PendingCommit.Diff = diff
+			if diff.Error == "" {
+				changes = fmt.Sprintf(" (%d added, %d modified, %d deleted)", diff.Added, diff.Modified, diff.Deleted)
+			}
+		}
+		r.Recorder.Eventf(gs, corev1.EventTypeNormal, conditions.ReasonApprovalRequested,
+			"%s awaiting approval%s", git.DescribeCommit(result.Commit, result.Info), changes)
+	}
+
+	approver, err := approverFor(gs, result.Commit)
+	switch {
+	case err != nil:
+		wasRejected := conditionHasReason(gs.Status.Conditions, conditions.TypeAwaitingApproval, conditions.ReasonApprovalRejected)
+		r.setCondition(ctx, gs, conditions.TypeAwaitingApproval, metav1.ConditionTrue, conditions.ReasonApprovalRejected,
+			fmt.Sprintf("Approval of %s rejected: %s", git.ShortCommit(result.Commit), err.Error()))
+		if !wasRejected {
+			r.Recorder.Eventf(gs, corev1.EventTypeWarning, conditions.ReasonApprovalRejected,
+				"Approval of %s rejected: %s", git.ShortCommit(result.Commit), err.Error())
+		}
+	case approver != "":
+		gs.Status.LastApproval = &stokerv1alpha1.ApprovalRecord{Commit: result.Commit, Approver: approver, Time: metav1.Now()}
+		gs.Status.PendingCommit = nil
+		r.setCondition(ctx, gs, conditions.TypeAwaitingApproval, metav1.ConditionFalse, conditions.ReasonApproved,
+			fmt.Sprintf("%s approved by %s", git.ShortCommit(result.Commit), approver))
+		r.Recorder.Eventf(gs, corev1.EventTypeNormal, conditions.ReasonCommitApproved,
+			"%s approved by %s", git.DescribeCommit(result.Commit, result.Info), approver)
+		return result, true
+	default:
+		r.setCondition(ctx, gs, conditions.TypeAwaitingApproval, metav1.ConditionTrue, conditions.ReasonAwaitingApproval,
+			fmt.Sprintf("%s awaiting approval: set %s and %s=%s", git.ShortCommit(result.Commit),
+				stokertypes.AnnotationApprovedBy, stokertypes.AnnotationApprovedCommit, git.ShortCommit(result.Commit)))
+	}
+	return approved, approved.Commit != ""
+}
+
+// approverFor returns the approver named by the approval annotations when
+// they approve commit, "" when they are unset, or an error describing why
+// they do not approve it.
+func approverFor(gs *stokerv1alpha1.GatewaySync, commit string) (string, error) {
+	by := strings.TrimSpace(gs.Annotations[stokertypes.AnnotationApprovedBy])
+	sha := strings.ToLower(strings.TrimSpace(gs.Annotations[stokertypes.AnnotationApprovedCommit]))
+	if by == "" && sha == "" {
+		return "", nil
+	}
+	if by == "" {
+		return "", fmt.Errorf("%s is not set", stokertypes.AnnotationApprovedBy)
+	}
+	if len(sha) < 7 || !strings.HasPrefix(commit, sha) {
+		return "", fmt.Errorf("%s %q does not name the pending commit %s", stokertypes.AnnotationApprovedCommit, sha, git.ShortCommit(commit))
+	}
+	if approvers := gs.Spec.Approval.Approvers; len(approvers) > 0 && !slices.Contains(approvers, by) {
+		return "", fmt.Errorf("%q is not in spec.approval.approvers", by)
+	}
+	return by, nil
+}
+
+// diffSummary compares two commits on the remote for status. A failure is
+// reported in the summary's error field and never blocks approval.
+func (r *GatewaySyncReconciler) diffSummary(ctx context.Context, gs *stokerv1alpha1.GatewaySync, from, to string) *stokerv1alpha1.DiffSummary {
+	auth, err := r.resolveGitAuth(ctx, gs)
+	if err != nil {
+		return &stokerv1alpha1.DiffSummary{Error: err.Error()}
+	}
+	diffCtx, cancel := context.WithTimeout(ctx, lsRemoteTimeout)
+	defer cancel()
+	diff, err := r.GitClient.DiffCommits(diffCtx, gs.Spec.Git.Repo, from, to, auth)
+	if err != nil {
+		logf.FromContext(ctx).Info("diff unavailable", "from", from, "to", to, "error", err.Error())
+		return &stokerv1alpha1.DiffSummary{Error: err.Error()}
+	}
+
+	summary := &stokerv1alpha1.DiffSummary{
+		Added:    int32(len(diff.Added)),
+		Modified: int32(len(diff.Modified)),
+		Deleted:  int32(len(diff.Deleted)),
+	}
+	for prefix, paths := range map[string][]string{"A ": diff.Added, "M ": diff.Modified, "D ": diff.Deleted} {
+		for _, p := range paths {
+			summary.Files = append(summary.Files, prefix+p)
+		}
+	}
+	slices.SortFunc(summary.Files, func(a, b string) int { return strings.Compare(a[2:], b[2:]) })
+	if len(summary.Files) > maxDiffFiles {
+		summary.Files = summary.Files[:maxDiffFiles]
+	}
+	return summary
+}
+
+// removeApprovalIfConsumed removes the approval annotations once the commit
+// they name has been approved.
+func (r *GatewaySyncReconciler) removeApprovalIfConsumed(ctx context.Context, req ctrl.Request, gs *stokerv1alpha1.GatewaySync) {
+	sha := strings.ToLower(strings.TrimSpace(gs.Annotations[stokertypes.AnnotationApprovedCommit]))
+	if gs.Status.LastApproval == nil || len(sha) < 7 || !strings.HasPrefix(gs.Status.LastApproval.Commit, sha) {
+		return
+	}
+	r.removeAnnotations(ctx, req, gs, stokertypes.AnnotationApprovedBy, stokertypes.AnnotationApprovedCommit)
+}
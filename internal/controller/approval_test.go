@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+const (
+	approvedSHA = "1111111111111111111111111111111111111111"
+	pendingSHA  = "2222222222222222222222222222222222222222"
+)
+
+func approvalFixture(approvers ...string) *stokerv1alpha1.GatewaySync {
+	return &stokerv1alpha1.GatewaySync{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "default"},
+		Spec: stokerv1alpha1.GatewaySyncSpec{
+			Git:      stokerv1alpha1.GitSpec{Repo: "https://example.com/repo.git", Ref: "main"},
+			Approval: &stokerv1alpha1.ApprovalSpec{Approvers: approvers},
+		},
+		Status: stokerv1alpha1.GatewaySyncStatus{LastSyncCommit: approvedSHA, LastSyncRef: "main"},
+	}
+}
+
+func TestGateApproval_HoldsUntilApproved(t *testing.T) {
+	gs := approvalFixture("jane")
+	recorder := record.NewFakeRecorder(10)
+	r := &GatewaySyncReconciler{
+		GitClient: &fakeGitClient{diff: git.DiffSummary{Added: []string{"b.json"}, Modified: []string{"a.json"}}},
+		Recorder:  recorder,
+	}
+	ctx := context.Background()
+	resolved := git.Result{Commit: pendingSHA, Ref: "main"}
+
+	got, ok := r.gateApproval(ctx, gs, resolved)
+	if !ok || got.Commit != approvedSHA {
+		t.Fatalf("pending commit should not be published, got %s (ok=%v)", got.Commit, ok)
+	}
+	pending := gs.Status.PendingCommit
+	if pending == nil || pending.Commit != pendingSHA {
+		t.Fatalf("pendingCommit = %+v", pending)
+	}
+	if pending.Diff.Added != 1 || pending.Diff.Modified != 1 || !slices.Equal(pending.Diff.Files, []string{"M a.json", "A b.json"}) {
+		t.Errorf("unexpected diff summary: %+v", pending.Diff)
+	}
+	if !apimeta.IsStatusConditionTrue(gs.Status.Conditions, conditions.TypeAwaitingApproval) {
+		t.Error("expected AwaitingApproval=True")
+	}
+
+	// An approver outside spec.approval.approvers is rejected.
+	gs.Annotations = map[string]string{
+		stokertypes.AnnotationApprovedBy:     "mallory",
+		stokertypes.AnnotationApprovedCommit: pendingSHA[:7],
+	}
+	if got, _ := r.gateApproval(ctx, gs, resolved); got.Commit != approvedSHA {
+		t.Fatalf("unlisted approver published %s", got.Commit)
+	}
+	if !conditionHasReason(gs.Status.Conditions, conditions.TypeAwaitingApproval, conditions.ReasonApprovalRejected) {
+		t.Error("expected ApprovalRejected reason")
+	}
+
+	gs.Annotations[stokertypes.AnnotationApprovedBy] = "jane"
+	got, ok = r.gateApproval(ctx, gs, resolved)
+	if !ok || got.Commit != pendingSHA {
+		t.Fatalf("approved commit should be published, got %s", got.Commit)
+	}
+	if gs.Status.PendingCommit != nil || gs.Status.LastApproval == nil || gs.Status.LastApproval.Approver != "jane" {
+		t.Errorf("approval not recorded: pending=%+v last=%+v", gs.Status.PendingCommit, gs.Status.LastApproval)
+	}
+
+	var approvalEvent bool
+	for len(recorder.Events) > 0 {
+		if e := <-recorder.Events; strings.Contains(e, conditions.ReasonCommitApproved) && strings.Contains(e, "jane") {
+			approvalEvent = true
+		}
+	}
+	if !approvalEvent {
+		t.Error("expected a CommitApproved event naming the approver")
+	}
+}
+
+func TestGateApproval_FirstCommit(t *testing.T) {
+	gs := approvalFixture()
+	gs.Status = stokerv1alpha1.GatewaySyncStatus{}
+	r := &GatewaySyncReconciler{GitClient: &fakeGitClient{}, Recorder: record.NewFakeRecorder(10)}
+
+	if _, ok := r.gateApproval(context.Background(), gs, git.Result{Commit: pendingSHA, Ref: "main"}); ok {
+		t.Error("nothing should be published before the first approval")
+	}
+	if gs.Status.PendingCommit.Diff != nil {
+		t.Error("first commit has nothing to diff against")
+	}
+}
+
+func TestApproverFor(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		approvers   []string
+		want        string
+		wantErr     string
+	}{
+		{name: "no annotations"},
+		{
+			name:        "full SHA",
+			annotations: map[string]string{stokertypes.AnnotationApprovedBy: "jane", stokertypes.AnnotationApprovedCommit: pendingSHA},
+			want:        "jane",
+		},
+		{
+			name:        "short SHA, any approver",
+			annotations: map[string]string{stokertypes.AnnotationApprovedBy: "ops-lead", stokertypes.AnnotationApprovedCommit: "2222222"},
+			want:        "ops-lead",
+		},
+		{
+			name:        "missing approver",
+			annotations: map[string]string{stokertypes.AnnotationApprovedCommit: pendingSHA},
+			wantErr:     "approved-by",
+		},
+		{
+			name:        "other commit",
+			annotations: map[string]string{stokertypes.AnnotationApprovedBy: "jane", stokertypes.AnnotationApprovedCommit: approvedSHA[:7]},
+			wantErr:     "does not name",
+		},
+		{
+			name:        "SHA too short",
+			annotations: map[string]string{stokertypes.AnnotationApprovedBy: "jane", stokertypes.AnnotationApprovedCommit: "2222"},
+			wantErr:     "does not name",
+		},
+		{
+			name:        "approver not listed",
+			annotations: map[string]string{stokertypes.AnnotationApprovedBy: "bob", stokertypes.AnnotationApprovedCommit: pendingSHA},
+			approvers:   []string{"jane"},
+			wantErr:     "not in spec.approval.approvers",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gs := approvalFixture(tc.approvers...)
+			gs.Annotations = tc.annotations
+			got, err := approverFor(gs, pendingSHA)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("got %q, %v; want %q", got, err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,449 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// Gateway pods in spec.allowedNamespaces reference a GatewaySync in another
+// namespace. Their agents read the metadata ConfigMap and write status in the
+// CR's namespace, but mount git Secrets and read their own pod in the pod's
+// namespace. In the CR's namespace each remote ServiceAccount gets a Role
+// limited by resourceNames to those ConfigMaps. The objects in the pod's
+// namespace cannot carry owner references across namespaces, so they are
+// labeled with the CR's name and namespace and pruned by label.
+
+// remoteLabels identifies objects the controller creates for gs outside its namespace.
+func remoteLabels(gs *stokerv1alpha1.GatewaySync) map[string]string {
+	return map[string]string{
+		stokertypes.LabelCRName:      gs.Name,
+		stokertypes.LabelCRNamespace: gs.Namespace,
+	}
+}
+
+// isRemoteObjectOf reports whether obj was created for gs by the controller.
+func isRemoteObjectOf(obj client.Object, gs *stokerv1alpha1.GatewaySync) bool {
+	l := obj.GetLabels()
+	return l[stokertypes.LabelCRName] == gs.Name && l[stokertypes.LabelCRNamespace] == gs.Namespace
+}
+
+// remoteAgentRoleBindingName names the agent RoleBinding for gs in a pod
+// namespace, distinct from the RoleBinding of a same-named local CR.
+func remoteAgentRoleBindingName(gs *stokerv1alpha1.GatewaySync) string {
+	return fmt.Sprintf("stoker-agent-%s-%s", gs.Namespace, gs.Name)
+}
+
+// ensureRemoteAgentRoleBindings binds the stoker-agent ClusterRole to the
+// gateway ServiceAccounts in each namespace other than the CR's, and deletes
+// the bindings in namespaces that no longer run gateways for gs.
+func (r *GatewaySyncReconciler) ensureRemoteAgentRoleBindings(ctx context.Context, gs *stokerv1alpha1.GatewaySync, saByNamespace map[string][]string) error {
+	log := logf.FromContext(ctx).WithName("auto-rbac")
+	name := remoteAgentRoleBindingName(gs)
+	var errs []error
+
+	for _, ns := range slices.Sorted(maps.Keys(saByNamespace)) {
+		if ns == gs.Namespace {
+			continue
+		}
+		rb := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns}}
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, rb, func() error {
+			if rb.ResourceVersion != "" && !isRemoteObjectOf(rb, gs) {
+				return fmt.Errorf("RoleBinding %s/%s exists and is not managed for GatewaySync %s/%s", ns, name, gs.Namespace, gs.Name)
+			}
+			rb.Labels = mergeStringMaps(rb.Labels, remoteLabels(gs))
+			rb.Labels["app.kubernetes.io/managed-by"] = "stoker-operator"
+			rb.RoleRef = rbacv1.RoleRef{
+				APIGroup: "rbac.authorization.k8s.io",
+				Kind:     "ClusterRole",
+				Name:     agentClusterRoleName,
+			}
+			rb.Subjects = buildSubjects(saByNamespace[ns], ns)
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("agent RoleBinding in %s: %w", ns, err))
+			continue
+		}
+		if op != controllerutil.OperationResultNone {
+			log.Info("ensured remote agent RoleBinding", "namespace", ns, "name", name, "operation", op)
+		}
+	}
+
+	if err := r.pruneRemoteAgentRoleBindings(ctx, gs, saByNamespace); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// pruneRemoteAgentRoleBindings deletes the remote agent RoleBindings for gs
+// in namespaces without gateway ServiceAccounts in saByNamespace.
+func (r *GatewaySyncReconciler) pruneRemoteAgentRoleBindings(ctx context.Context, gs *stokerv1alpha1.GatewaySync, saByNamespace map[string][]string) error {
+	var list rbacv1.RoleBindingList
+	if err := r.List(ctx, &list, client.MatchingLabels(remoteLabels(gs))); err != nil {
+		return fmt.Errorf("listing remote agent RoleBindings: %w", err)
+	}
+	var errs []error
+	for i := range list.Items {
+		rb := &list.Items[i]
+		if _, ok := saByNamespace[rb.Namespace]; ok && rb.Namespace != gs.Namespace {
+			continue
+		}
+		if err := r.Delete(ctx, rb); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("deleting RoleBinding %s/%s: %w", rb.Namespace, rb.Name, err))
+			continue
+		}
+		logf.FromContext(ctx).Info("deleted remote agent RoleBinding", "namespace", rb.Namespace, "name", rb.Name)
+	}
+	return errors.Join(errs...)
+}
+
+// remoteAgent is a gateway ServiceAccount outside the CR's namespace.
+type remoteAgent struct {
+	Namespace      string
+	ServiceAccount string
+}
+
+// collectRemoteAgents returns, for each gateway ServiceAccount outside the
+// CR's namespace, the status ConfigMaps its agents write.
+func (r *GatewaySyncReconciler) collectRemoteAgents(ctx context.Context, gs *stokerv1alpha1.GatewaySync) (map[remoteAgent][]string, error) {
+	pods, err := r.listGatewayPods(ctx, gs)
+	if err != nil {
+		return nil, err
+	}
+	agents := make(map[remoteAgent][]string)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Namespace == gs.Namespace {
+			continue
+		}
+		key := remoteAgent{Namespace: pod.Namespace, ServiceAccount: pod.Spec.ServiceAccountName}
+		if key.ServiceAccount == "" {
+			key.ServiceAccount = "default"
+		}
+		name := statusConfigMapForPod(gs, pod)
+		if !slices.Contains(agents[key], name) {
+			agents[key] = append(agents[key], name)
+		}
+	}
+	for _, names := range agents {
+		slices.Sort(names)
+	}
+	return agents, nil
+}
+
+// remoteAgentAccessName names the Role and RoleBinding in the CR's namespace
+// for one remote ServiceAccount.
+func remoteAgentAccessName(gs *stokerv1alpha1.GatewaySync, a remoteAgent) string {
+	return stokertypes.BoundedName(fmt.Sprintf("stoker-agent-remote-%s-%s-%s", gs.Name, a.Namespace, a.ServiceAccount))
+}
+
+// remoteAgentAccessLabels identifies the Roles and RoleBindings created by
+// ensureRemoteAgentAccess for gs.
+func remoteAgentAccessLabels(gs *stokerv1alpha1.GatewaySync) map[string]string {
+	return map[string]string{
+		stokertypes.LabelCRName:      gs.Name,
+		stokertypes.LabelRemoteAgent: "true",
+	}
+}
+
+// remoteAgentRules lets an agent read the metadata ConfigMap, update the
+// given status ConfigMaps, and emit events on gs.
+func remoteAgentRules(gs *stokerv1alpha1.GatewaySync, statusConfigMaps []string) []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: []string{stokertypes.MetadataConfigMapName(gs.Name)},
+			Verbs:         []string{"get", "list", "watch"},
+		},
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: statusConfigMaps,
+			Verbs:         []string{"get", "update", "patch"},
+		},
+		{
+			APIGroups:     []string{stokerv1alpha1.GroupVersion.Group},
+			Resources:     []string{"gatewaysyncs"},
+			ResourceNames: []string{gs.Name},
+			Verbs:         []string{"get"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"events"},
+			Verbs:     []string{"create", "patch"},
+		},
+	}
+}
+
+// ensureRemoteAgentAccess gives each remote gateway ServiceAccount a Role and
+// RoleBinding in the CR's namespace limited to the CR's metadata ConfigMap
+// and the status ConfigMaps of its own gateways. create cannot be limited by
+// name, so the controller creates those status ConfigMaps empty and the
+// agents only update them. Access for ServiceAccounts that no longer run
+// gateways is deleted, along with status ConfigMaps no agent wrote.
+func (r *GatewaySyncReconciler) ensureRemoteAgentAccess(ctx context.Context, gs *stokerv1alpha1.GatewaySync, agents map[remoteAgent][]string) error {
+	log := logf.FromContext(ctx).WithName("auto-rbac")
+	desired := make(map[string]bool, len(agents))
+	reserved := make(map[string]bool)
+	var errs []error
+
+	keys := slices.SortedFunc(maps.Keys(agents), func(a, b remoteAgent) int {
+		return strings.Compare(a.Namespace+"/"+a.ServiceAccount, b.Namespace+"/"+b.ServiceAccount)
+	})
+	for _, a := range keys {
+		name := remoteAgentAccessName(gs, a)
+		desired[name] = true
+		for _, cmName := range agents[a] {
+			reserved[cmName] = true
+			if err := r.reserveStatusConfigMap(ctx, gs, cmName); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		accessLabels := remoteAgentAccessLabels(gs)
+		accessLabels["app.kubernetes.io/managed-by"] = "stoker-operator"
+		role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: gs.Namespace}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, role, func() error {
+			if role.ResourceVersion != "" && !metav1.IsControlledBy(role, gs) {
+				return fmt.Errorf("role %s/%s exists and is not managed for GatewaySync %s", gs.Namespace, name, gs.Name)
+			}
+			role.Labels = mergeStringMaps(role.Labels, accessLabels)
+			role.Rules = remoteAgentRules(gs, agents[a])
+			return controllerutil.SetControllerReference(gs, role, r.Scheme)
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("remote agent Role %s: %w", name, err))
+			continue
+		}
+
+		rb := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: gs.Namespace}}
+		op, err := controllerutil.CreateOrUpdate(ctx, r.Client, rb, func() error {
+			if rb.ResourceVersion != "" && !metav1.IsControlledBy(rb, gs) {
+				return fmt.Errorf("RoleBinding %s/%s exists and is not managed for GatewaySync %s", gs.Namespace, name, gs.Name)
+			}
+			rb.Labels = mergeStringMaps(rb.Labels, accessLabels)
+			rb.RoleRef = rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "Role", Name: name}
+			rb.Subjects = buildSubjects([]string{a.ServiceAccount}, a.Namespace)
+			return controllerutil.SetControllerReference(gs, rb, r.Scheme)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("remote agent RoleBinding %s: %w", name, err))
+			continue
+		}
+		if op != controllerutil.OperationResultNone {
+			log.Info("ensured remote agent access", "name", name, "serviceAccount", a.Namespace+"/"+a.ServiceAccount, "operation", op)
+		}
+	}
+
+	if err := r.pruneRemoteAgentAccess(ctx, gs, desired, reserved); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// reserveStatusConfigMap creates an empty status ConfigMap for a remote agent
+// if it does not exist yet.
+func (r *GatewaySyncReconciler) reserveStatusConfigMap(ctx context.Context, gs *stokerv1alpha1.GatewaySync, name string) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: gs.Namespace,
+		Labels: map[string]string{
+			"app.kubernetes.io/managed-by": "stoker-operator",
+			stokertypes.LabelCRName:        gs.Name,
+			stokertypes.LabelGatewayStatus: "true",
+		},
+	}}
+	if err := r.Create(ctx, cm); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("creating status ConfigMap %s: %w", name, err)
+	}
+	return nil
+}
+
+// pruneRemoteAgentAccess deletes the remote agent Roles and RoleBindings for
+// gs not named in desired, and the empty status ConfigMaps not in reserved.
+func (r *GatewaySyncReconciler) pruneRemoteAgentAccess(ctx context.Context, gs *stokerv1alpha1.GatewaySync, desired, reserved map[string]bool) error {
+	opts := []client.ListOption{client.InNamespace(gs.Namespace), client.MatchingLabels(remoteAgentAccessLabels(gs))}
+	var roles rbacv1.RoleList
+	var bindings rbacv1.RoleBindingList
+	if err := r.List(ctx, &roles, opts...); err != nil {
+		return fmt.Errorf("listing remote agent Roles: %w", err)
+	}
+	if err := r.List(ctx, &bindings, opts...); err != nil {
+		return fmt.Errorf("listing remote agent RoleBindings: %w", err)
+	}
+	var stale []client.Object
+	for i := range roles.Items {
+		if !desired[roles.Items[i].Name] {
+			stale = append(stale, &roles.Items[i])
+		}
+	}
+	for i := range bindings.Items {
+		if !desired[bindings.Items[i].Name] {
+			stale = append(stale, &bindings.Items[i])
+		}
+	}
+
+	cms, err := r.listGatewayStatusConfigMaps(ctx, gs)
+	if err != nil {
+		return err
+	}
+	for i := range cms {
+		if len(cms[i].Data) == 0 && !reserved[cms[i].Name] {
+			stale = append(stale, &cms[i])
+		}
+	}
+
+	var errs []error
+	for _, obj := range stale {
+		if err := r.Delete(ctx, obj); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("deleting %s: %w", obj.GetName(), err))
+			continue
+		}
+		logf.FromContext(ctx).Info("deleted remote agent access", "name", obj.GetName())
+	}
+	return errors.Join(errs...)
+}
+
+// mirroredSecretNames returns the Secrets in the CR's namespace that the
+// injected agent mounts from its own namespace: the git credentials, SSH
+// known_hosts, trusted signing keys, and the GitHub App token.
+func mirroredSecretNames(gs *stokerv1alpha1.GatewaySync) []string {
+	var names []string
+	if auth := gs.Spec.Git.Auth; auth != nil {
+		switch {
+		case auth.SSHKey != nil:
+			names = append(names, auth.SSHKey.SecretRef.Name)
+			if auth.SSHKey.KnownHosts != nil {
+				names = append(names, auth.SSHKey.KnownHosts.SecretRef.Name)
+			}
+		case auth.Token != nil:
+			names = append(names, auth.Token.SecretRef.Name)
+		case auth.GitHubApp != nil:
+			names = append(names, fmt.Sprintf("stoker-github-token-%s", gs.Name))
+		}
+	}
+	if v := gs.Spec.Git.Verification; v != nil && v.TrustedKeysSecretName != "" {
+		names = append(names, v.TrustedKeysSecretName)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// ensureMirroredSecrets copies the Secrets from mirroredSecretNames into every
+// other namespace running gateways for gs, and deletes copies that are no
+// longer needed. A Secret of the same name that the controller did not
+// create is left untouched and reported in the returned error.
+func (r *GatewaySyncReconciler) ensureMirroredSecrets(ctx context.Context, gs *stokerv1alpha1.GatewaySync) error {
+	log := logf.FromContext(ctx)
+
+	saByNamespace, err := r.collectServiceAccountsFromPods(ctx, gs)
+	if err != nil {
+		return err
+	}
+	delete(saByNamespace, gs.Namespace)
+	names := mirroredSecretNames(gs)
+	var errs []error
+
+	for _, secretName := range names {
+		src := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: gs.Namespace}, src); err != nil {
+			// A GitHub App token Secret appears after the first token exchange.
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("reading Secret %s/%s: %w", gs.Namespace, secretName, err))
+			}
+			continue
+		}
+		for _, ns := range slices.Sorted(maps.Keys(saByNamespace)) {
+			dst := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: ns}}
+			op, err := controllerutil.CreateOrUpdate(ctx, r.Client, dst, func() error {
+				if dst.ResourceVersion != "" && !isRemoteObjectOf(dst, gs) {
+					return fmt.Errorf("secret %s/%s exists and is not mirrored from GatewaySync %s/%s", ns, secretName, gs.Namespace, gs.Name)
+				}
+				dst.Labels = mergeStringMaps(dst.Labels, remoteLabels(gs))
+				dst.Labels["app.kubernetes.io/managed-by"] = "stoker-controller"
+				dst.Type = src.Type
+				dst.Data = src.Data
+				return nil
+			})
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if op == controllerutil.OperationResultCreated {
+				log.Info("mirrored Secret", "name", secretName, "namespace", ns)
+			}
+		}
+	}
+
+	if err := r.pruneMirroredSecrets(ctx, gs, saByNamespace, names); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// findGatewaySyncsForSecret maps a Secret change to the GatewaySyncs in its
+// namespace that mirror it, so rotated credentials reach the copies.
+func (r *GatewaySyncReconciler) findGatewaySyncsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list stokerv1alpha1.GatewaySyncList
+	if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for i := range list.Items {
+		gs := &list.Items[i]
+		if len(gs.Spec.AllowedNamespaces) > 0 && slices.Contains(mirroredSecretNames(gs), obj.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: gs.Name, Namespace: gs.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// pruneMirroredSecrets deletes mirrored Secrets for gs that are not named in
+// names or live in a namespace missing from namespaces.
+func (r *GatewaySyncReconciler) pruneMirroredSecrets(ctx context.Context, gs *stokerv1alpha1.GatewaySync, namespaces map[string][]string, names []string) error {
+	var list corev1.SecretList
+	if err := r.List(ctx, &list, client.MatchingLabels(remoteLabels(gs))); err != nil {
+		return fmt.Errorf("listing mirrored Secrets: %w", err)
+	}
+	var errs []error
+	for i := range list.Items {
+		secret := &list.Items[i]
+		if _, ok := namespaces[secret.Namespace]; ok && slices.Contains(names, secret.Name) {
+			continue
+		}
+		if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("deleting mirrored Secret %s/%s: %w", secret.Namespace, secret.Name, err))
+			continue
+		}
+		logf.FromContext(ctx).Info("deleted mirrored Secret", "name", secret.Name, "namespace", secret.Namespace)
+	}
+	return errors.Join(errs...)
+}
+
+// cleanupRemoteResources deletes the RoleBindings and Secrets created for gs
+// outside its namespace.
+func (r *GatewaySyncReconciler) cleanupRemoteResources(ctx context.Context, gs *stokerv1alpha1.GatewaySync) error {
+	return errors.Join(
+		r.pruneRemoteAgentRoleBindings(ctx, gs, nil),
+		r.pruneMirroredSecrets(ctx, gs, nil, nil),
+	)
+}
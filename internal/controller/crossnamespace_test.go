@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func crossNamespaceReconciler(t *testing.T, objs ...client.Object) *GatewaySyncReconciler {
+	t.Helper()
+	c, s := newFakeClient(t, objs...)
+	return &GatewaySyncReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(20)}
+}
+
+func crossNamespaceGatewaySync() *stokerv1alpha1.GatewaySync {
+	return &stokerv1alpha1.GatewaySync{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "stoker", UID: "gs-uid"},
+		Spec: stokerv1alpha1.GatewaySyncSpec{
+			Git: stokerv1alpha1.GitSpec{
+				Repo: "https://example.com/repo.git",
+				Ref:  "main",
+				Auth: &stokerv1alpha1.GitAuthSpec{Token: &stokerv1alpha1.TokenAuth{
+					SecretRef: stokerv1alpha1.SecretKeyRef{Name: "git-token", Key: "token"},
+				}},
+			},
+			AllowedNamespaces: []string{"plant-1"},
+		},
+	}
+}
+
+// gatewayPod returns an injected pod referencing the "site" CR; crNamespace is empty for a same-namespace pod.
+func gatewayPod(namespace, name, crNamespace, serviceAccount string) *corev1.Pod {
+	annotations := map[string]string{stokertypes.AnnotationCRName: "site", stokertypes.AnnotationInjected: "true"}
+	if crNamespace != "" {
+		annotations[stokertypes.AnnotationCRNamespace] = crNamespace
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Annotations: annotations},
+		Spec:       corev1.PodSpec{ServiceAccountName: serviceAccount},
+	}
+}
+
+func TestListGatewayPods_AllowedNamespaces(t *testing.T) {
+	gs := crossNamespaceGatewaySync()
+	uninjected := gatewayPod("plant-1", "uninjected-0", "stoker", "")
+	delete(uninjected.Annotations, stokertypes.AnnotationInjected)
+	r := crossNamespaceReconciler(t,
+		gatewayPod("stoker", "local-0", "", ""),
+		gatewayPod("plant-1", "remote-0", "stoker", "ignition"),
+		// References a same-named CR in its own namespace.
+		gatewayPod("plant-1", "other-0", "", ""),
+		// Not listed in allowedNamespaces.
+		gatewayPod("plant-2", "denied-0", "stoker", ""),
+		// Claims the CR without having been injected.
+		uninjected,
+	)
+
+	pods, err := r.listGatewayPods(context.Background(), gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, pod := range pods {
+		got = append(got, pod.Namespace+"/"+pod.Name)
+	}
+	if strings.Join(got, ",") != "stoker/local-0,plant-1/remote-0" {
+		t.Errorf("unexpected pods %v", got)
+	}
+
+	gs.Spec.AllowedNamespaces = []string{"*"}
+	pods, err = r.listGatewayPods(context.Background(), gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods) != 3 {
+		t.Errorf("\"*\" should allow every namespace, got %d pods", len(pods))
+	}
+}
+
+func TestFindGatewaySyncForPod_CRNamespace(t *testing.T) {
+	r := &GatewaySyncReconciler{}
+	reqs := r.findGatewaySyncForPod(context.Background(), gatewayPod("plant-1", "remote-0", "stoker", ""))
+	if len(reqs) != 1 || reqs[0].NamespacedName != (types.NamespacedName{Namespace: "stoker", Name: "site"}) {
+		t.Errorf("expected stoker/site, got %v", reqs)
+	}
+}
+
+func TestEnsureAgentRoleBinding_RemoteNamespaces(t *testing.T) {
+	gs := crossNamespaceGatewaySync()
+	remote := gatewayPod("plant-1", "remote-0", "stoker", "ignition")
+	r := crossNamespaceReconciler(t, gs, gatewayPod("stoker", "local-0", "", ""), remote)
+	ctx := context.Background()
+
+	if err := r.ensureAgentRoleBinding(ctx, gs); err != nil {
+		t.Fatal(err)
+	}
+
+	local := &rbacv1.RoleBinding{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "stoker", Name: agentRoleBindingName("site")}, local); err != nil {
+		t.Fatal(err)
+	}
+	if formatSubjects(local.Subjects) != "[stoker/default]" {
+		t.Errorf("local RoleBinding should only bind the CR's namespace, got %s", formatSubjects(local.Subjects))
+	}
+
+	accessKey := types.NamespacedName{Namespace: "stoker", Name: remoteAgentAccessName(gs, remoteAgent{Namespace: "plant-1", ServiceAccount: "ignition"})}
+	role := &rbacv1.Role{}
+	if err := r.Get(ctx, accessKey, role); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, rule := range role.Rules {
+		names = append(names, rule.ResourceNames...)
+	}
+	statusName := stokertypes.StatusConfigMapName("site", "plant-1", "remote-0")
+	if strings.Join(names, ",") != "stoker-metadata-site,"+statusName+",site" {
+		t.Errorf("remote agent Role should be limited to the CR's ConfigMaps, got %v", names)
+	}
+	access := &rbacv1.RoleBinding{}
+	if err := r.Get(ctx, accessKey, access); err != nil {
+		t.Fatal(err)
+	}
+	if formatSubjects(access.Subjects) != "[plant-1/ignition]" || access.RoleRef.Kind != "Role" || access.RoleRef.Name != accessKey.Name {
+		t.Errorf("unexpected remote agent RoleBinding %+v", access)
+	}
+	statusKey := types.NamespacedName{Namespace: "stoker", Name: statusName}
+	if err := r.Get(ctx, statusKey, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("status ConfigMap should be created for the remote agent: %v", err)
+	}
+
+	rb := &rbacv1.RoleBinding{}
+	key := types.NamespacedName{Namespace: "plant-1", Name: remoteAgentRoleBindingName(gs)}
+	if err := r.Get(ctx, key, rb); err != nil {
+		t.Fatal(err)
+	}
+	if formatSubjects(rb.Subjects) != "[plant-1/ignition]" || !isRemoteObjectOf(rb, gs) {
+		t.Errorf("unexpected remote RoleBinding %+v", rb)
+	}
+
+	if err := r.Delete(ctx, remote); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ensureAgentRoleBinding(ctx, gs); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, key, &rbacv1.RoleBinding{}); !apierrors.IsNotFound(err) {
+		t.Errorf("remote RoleBinding should be pruned, got %v", err)
+	}
+	if err := r.Get(ctx, accessKey, &rbacv1.Role{}); !apierrors.IsNotFound(err) {
+		t.Errorf("remote agent Role should be pruned, got %v", err)
+	}
+	if err := r.Get(ctx, accessKey, &rbacv1.RoleBinding{}); !apierrors.IsNotFound(err) {
+		t.Errorf("remote agent RoleBinding should be pruned, got %v", err)
+	}
+	if err := r.Get(ctx, statusKey, &corev1.ConfigMap{}); !apierrors.IsNotFound(err) {
+		t.Errorf("unused status ConfigMap should be pruned, got %v", err)
+	}
+}
+
+func TestEnsureMirroredSecrets(t *testing.T) {
+	gs := crossNamespaceGatewaySync()
+	gs.Spec.AllowedNamespaces = []string{"plant-1", "plant-2"}
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "git-token", Namespace: "stoker"},
+		Data:       map[string][]byte{"token": []byte("s3cret")},
+	}
+	unmanaged := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "git-token", Namespace: "plant-2"},
+		Data:       map[string][]byte{"token": []byte("theirs")},
+	}
+	r := crossNamespaceReconciler(t, gs, source, unmanaged,
+		gatewayPod("plant-1", "remote-0", "stoker", ""),
+		gatewayPod("plant-2", "remote-0", "stoker", ""),
+	)
+	ctx := context.Background()
+
+	err := r.ensureMirroredSecrets(ctx, gs)
+	if err == nil || !strings.Contains(err.Error(), "plant-2/git-token") {
+		t.Errorf("expected a conflict for the unmanaged Secret, got %v", err)
+	}
+
+	mirrored := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "plant-1", Name: "git-token"}, mirrored); err != nil {
+		t.Fatal(err)
+	}
+	if string(mirrored.Data["token"]) != "s3cret" || !isRemoteObjectOf(mirrored, gs) {
+		t.Errorf("unexpected mirrored Secret %+v", mirrored)
+	}
+
+	got := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "plant-2", Name: "git-token"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Data["token"]) != "theirs" {
+		t.Errorf("unmanaged Secret should be left untouched, got %q", got.Data["token"])
+	}
+
+	if err := r.cleanupRemoteResources(ctx, gs); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "plant-1", Name: "git-token"}, &corev1.Secret{}); !apierrors.IsNotFound(err) {
+		t.Errorf("mirrored Secret should be deleted on cleanup, got %v", err)
+	}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "plant-2", Name: "git-token"}, &corev1.Secret{}); err != nil {
+		t.Errorf("cleanup should not delete the unmanaged Secret: %v", err)
+	}
+}
+
+func TestFindGatewaySyncsForSecret(t *testing.T) {
+	gs := crossNamespaceGatewaySync()
+	local := crossNamespaceGatewaySync()
+	local.Name = "local-only"
+	local.Spec.AllowedNamespaces = nil
+	r := crossNamespaceReconciler(t, gs, local)
+	ctx := context.Background()
+
+	reqs := r.findGatewaySyncsForSecret(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "git-token", Namespace: "stoker"}})
+	if len(reqs) != 1 || reqs[0].NamespacedName != (types.NamespacedName{Namespace: "stoker", Name: "site"}) {
+		t.Errorf("expected stoker/site, got %v", reqs)
+	}
+	if reqs := r.findGatewaySyncsForSecret(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "stoker"}}); len(reqs) != 0 {
+		t.Errorf("unrelated Secret should not enqueue, got %v", reqs)
+	}
+}
+
+func TestCollectGatewayStatus_RemoteNamespaces(t *testing.T) {
+	gs := crossNamespaceGatewaySync()
+	gs.Spec.AllowedNamespaces = []string{"plant-1", "plant-2"}
+	status := func(namespace, commit string) *corev1.ConfigMap {
+		data, _ := json.Marshal(stokertypes.GatewayStatus{SyncStatus: stokertypes.SyncStatusSynced, SyncedCommit: commit})
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      stokertypes.StatusConfigMapName("site", namespace, "ignition-0"),
+				Namespace: "stoker",
+				Labels:    map[string]string{stokertypes.LabelCRName: "site", stokertypes.LabelGatewayStatus: "true"},
+			},
+			Data: map[string]string{"ignition-0": string(data)},
+		}
+	}
+	r := crossNamespaceReconciler(t, status("plant-1", "abc123"))
+
+	gateways := r.collectGatewayStatus(context.Background(), gs, []stokerv1alpha1.DiscoveredGateway{
+		{Name: "ignition-0", PodName: "ignition-0", Namespace: "plant-1", SyncStatus: stokertypes.SyncStatusPending},
+		{Name: "ignition-0", PodName: "ignition-0", Namespace: "plant-2", SyncStatus: stokertypes.SyncStatusPending},
+	})
+
+	if gateways[0].SyncStatus != stokertypes.SyncStatusSynced || gateways[0].SyncedCommit != "abc123" {
+		t.Errorf("plant-1 should read its own status, got %s@%s", gateways[0].SyncStatus, gateways[0].SyncedCommit)
+	}
+	if gateways[1].SyncStatus != stokertypes.SyncStatusPending {
+		t.Errorf("plant-2 must not report plant-1's status, got %s", gateways[1].SyncStatus)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -15,13 +17,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
 	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
 	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
 )
 
-// findGatewaySyncForPod reads the stoker.io/cr-name annotation from a pod
-// and returns a reconcile.Request for the matching GatewaySync CR in the same namespace.
-// Returns nil if the annotation is not present.
+// findGatewaySyncForPod reads the stoker.io/cr-name and stoker.io/cr-namespace
+// annotations from a pod and returns a reconcile.Request for the matching
+// GatewaySync CR. Returns nil if the cr-name annotation is not present.
 func (r *GatewaySyncReconciler) findGatewaySyncForPod(ctx context.Context, pod client.Object) []reconcile.Request {
 	crName, ok := pod.GetAnnotations()[stokertypes.AnnotationCRName]
 	if !ok || crName == "" {
@@ -32,47 +35,96 @@ func (r *GatewaySyncReconciler) findGatewaySyncForPod(ctx context.Context, pod c
 		{
 			NamespacedName: types.NamespacedName{
 				Name:      crName,
-				Namespace: pod.GetNamespace(),
+				Namespace: podCRNamespace(pod),
 			},
 		},
 	}
 }
 
-// discoverGateways lists all pods in the CR's namespace with annotation stoker.io/cr-name
-// matching gs.Name. For each matching pod in Running phase, it builds a DiscoveredGateway.
-func (r *GatewaySyncReconciler) discoverGateways(ctx context.Context, gs *stokerv1alpha1.GatewaySync) ([]stokerv1alpha1.DiscoveredGateway, error) {
-	log := logf.FromContext(ctx)
+// podCRNamespace returns the namespace of the GatewaySync a pod references:
+// the stoker.io/cr-namespace annotation, or the pod's own namespace.
+func podCRNamespace(pod client.Object) string {
+	if ns := pod.GetAnnotations()[stokertypes.AnnotationCRNamespace]; ns != "" {
+		return ns
+	}
+	return pod.GetNamespace()
+}
 
-	// List all pods in the namespace
-	var podList corev1.PodList
-	if err := r.List(ctx, &podList, client.InNamespace(gs.Namespace)); err != nil {
-		return nil, fmt.Errorf("listing pods: %w", err)
+// podTargetsGatewaySync reports whether pod references gs and runs in a
+// namespace gs allows. Pods outside the CR's namespace count only once the
+// injector has marked them injected, since they are granted RBAC and
+// mirrored Secrets.
+func podTargetsGatewaySync(pod *corev1.Pod, gs *stokerv1alpha1.GatewaySync) bool {
+	if pod.Annotations[stokertypes.AnnotationCRName] != gs.Name || podCRNamespace(pod) != gs.Namespace {
+		return false
+	}
+	if pod.Namespace == gs.Namespace {
+		return true
 	}
+	return gs.AllowsNamespace(pod.Namespace) && pod.Annotations[stokertypes.AnnotationInjected] == "true"
+}
 
-	discovered := make([]stokerv1alpha1.DiscoveredGateway, 0, len(podList.Items))
+// listGatewayPods returns the pods in any phase that reference gs, from the
+// CR's namespace and spec.allowedNamespaces.
+func (r *GatewaySyncReconciler) listGatewayPods(ctx context.Context, gs *stokerv1alpha1.GatewaySync) ([]corev1.Pod, error) {
+	namespaces := []string{gs.Namespace}
+	for _, ns := range gs.Spec.AllowedNamespaces {
+		if ns == "*" {
+			namespaces = []string{metav1.NamespaceAll}
+			break
+		}
+		if ns != gs.Namespace {
+			namespaces = append(namespaces, ns)
+		}
+	}
 
-	for _, pod := range podList.Items {
-		// Filter by annotation
-		crName, ok := pod.Annotations[stokertypes.AnnotationCRName]
-		if !ok || crName != gs.Name {
-			continue
+	var pods []corev1.Pod
+	for _, ns := range namespaces {
+		var podList corev1.PodList
+		if err := r.List(ctx, &podList, client.InNamespace(ns)); err != nil {
+			return nil, fmt.Errorf("listing pods: %w", err)
+		}
+		for i := range podList.Items {
+			if podTargetsGatewaySync(&podList.Items[i], gs) {
+				pods = append(pods, podList.Items[i])
+			}
 		}
+	}
+	return pods, nil
+}
+
+// discoverGateways lists the pods that reference gs (see listGatewayPods).
+// For each matching pod in Running phase, it builds a DiscoveredGateway.
+func (r *GatewaySyncReconciler) discoverGateways(ctx context.Context, gs *stokerv1alpha1.GatewaySync) ([]stokerv1alpha1.DiscoveredGateway, error) {
+	log := logf.FromContext(ctx)
 
+	pods, err := r.listGatewayPods(ctx, gs)
+	if err != nil {
+		return nil, err
+	}
+
+	discovered := make([]stokerv1alpha1.DiscoveredGateway, 0, len(pods))
+	// Rollout targets name gateways without their namespace,
+	// so names should stay unique across namespaces.
+	seen := make(map[string]string, len(pods))
+
+	for _, pod := range pods {
 		// Only include Running pods
 		if pod.Status.Phase != corev1.PodRunning {
 			continue
 		}
 
-		// Determine gateway name
-		gatewayName := pod.Name
-		if nameFromAnnotation, ok := pod.Annotations[stokertypes.AnnotationGatewayName]; ok && nameFromAnnotation != "" {
-			gatewayName = nameFromAnnotation
-		} else if nameFromLabel, ok := pod.Labels["app.kubernetes.io/name"]; ok && nameFromLabel != "" {
-			gatewayName = nameFromLabel
+		gatewayName := gatewayNameForPod(&pod)
+		if ns, dup := seen[gatewayName]; dup && ns != pod.Namespace {
+			r.Recorder.Eventf(gs, corev1.EventTypeWarning, "DuplicateGatewayName",
+				"Gateway name %q is used in namespaces %s and %s — set %s to tell them apart",
+				gatewayName, ns, pod.Namespace, stokertypes.AnnotationGatewayName)
 		}
+		seen[gatewayName] = pod.Namespace
 
-		// Get profile from annotation
+		// Get profile and per-pod ref pin from annotations
 		profile := pod.Annotations[stokertypes.AnnotationProfile]
+		refOverride := strings.TrimSpace(pod.Annotations[stokertypes.AnnotationRefOverride])
 
 		// Detect missing sidecar: pod has inject annotation but no stoker-agent container
 		syncStatus := stokertypes.SyncStatusPending
@@ -94,6 +146,7 @@ func (r *GatewaySyncReconciler) discoverGateways(ctx context.Context, gs *stoker
 			PodName:            pod.Name,
 			ServiceAccountName: saName,
 			Profile:            profile,
+			RefOverride:        refOverride,
 			SyncStatus:         syncStatus,
 		}
 
@@ -104,6 +157,43 @@ func (r *GatewaySyncReconciler) discoverGateways(ctx context.Context, gs *stoker
 	return discovered, nil
 }
 
+// gatewayNameForPod returns the gateway name for a pod: the
+// stoker.io/gateway-name annotation, then the app.kubernetes.io/name label,
+// then the pod name.
+func gatewayNameForPod(pod *corev1.Pod) string {
+	if name := pod.Annotations[stokertypes.AnnotationGatewayName]; name != "" {
+		return name
+	}
+	if name := pod.Labels["app.kubernetes.io/name"]; name != "" {
+		return name
+	}
+	return pod.Name
+}
+
+// agentGatewayName returns the name an injected agent reports status under:
+// the stoker.io/gateway-name annotation, or the pod name.
+func agentGatewayName(pod *corev1.Pod) string {
+	if name := pod.Annotations[stokertypes.AnnotationGatewayName]; name != "" {
+		return name
+	}
+	return pod.Name
+}
+
+// gatewayStatusNamespace returns the namespace part of a gateway's status
+// ConfigMap name: the pod's namespace for a gateway outside the CR's
+// namespace, and empty otherwise.
+func gatewayStatusNamespace(gs *stokerv1alpha1.GatewaySync, podNamespace string) string {
+	if podNamespace == "" || podNamespace == gs.Namespace {
+		return ""
+	}
+	return podNamespace
+}
+
+// statusConfigMapForPod returns the status ConfigMap the agent in pod writes.
+func statusConfigMapForPod(gs *stokerv1alpha1.GatewaySync, pod *corev1.Pod) string {
+	return stokertypes.StatusConfigMapName(gs.Name, gatewayStatusNamespace(gs, pod.Namespace), agentGatewayName(pod))
+}
+
 // hasSyncAgent checks if a pod has the stoker-agent sidecar container.
 func hasSyncAgent(pod *corev1.Pod) bool {
 	for _, c := range pod.Spec.InitContainers {
@@ -114,32 +204,88 @@ func hasSyncAgent(pod *corev1.Pod) bool {
 	return false
 }
 
-// collectGatewayStatus reads the ConfigMap stoker-status-{gs.Name} in gs.Namespace
-// and enriches each gateway with its sync status data. If the ConfigMap doesn't exist or a
-// gateway's status key is missing, the gateway remains with SyncStatus="Pending".
+// listGatewayStatusConfigMaps returns the per-gateway status ConfigMaps agents
+// have written for gs.
+func (r *GatewaySyncReconciler) listGatewayStatusConfigMaps(ctx context.Context, gs *stokerv1alpha1.GatewaySync) ([]corev1.ConfigMap, error) {
+	var cmList corev1.ConfigMapList
+	if err := r.List(ctx, &cmList,
+		client.InNamespace(gs.Namespace),
+		client.MatchingLabels{
+			stokertypes.LabelCRName:        gs.Name,
+			stokertypes.LabelGatewayStatus: "true",
+		},
+	); err != nil {
+		return nil, fmt.Errorf("listing gateway status ConfigMaps: %w", err)
+	}
+	return cmList.Items, nil
+}
+
+// collectGatewayStatus reads the per-gateway status ConfigMaps
+// (see StatusConfigMapName) in gs.Namespace and enriches each gateway
+// with its sync status data. The shared stoker-status-{gs.Name} ConfigMap
+// written by older agents is still read, with per-gateway entries taking
+// precedence. Gateways without a status entry remain with SyncStatus="Pending".
 func (r *GatewaySyncReconciler) collectGatewayStatus(ctx context.Context, gs *stokerv1alpha1.GatewaySync, gateways []stokerv1alpha1.DiscoveredGateway) []stokerv1alpha1.DiscoveredGateway {
 	log := logf.FromContext(ctx)
 
-	cmName := fmt.Sprintf("stoker-status-%s", gs.Name)
-	cm := &corev1.ConfigMap{}
-	key := types.NamespacedName{Name: cmName, Namespace: gs.Namespace}
+	statuses := make(map[string]string)
 
-	if err := r.Get(ctx, key, cm); err != nil {
-		if errors.IsNotFound(err) {
-			log.V(1).Info("status ConfigMap not found, gateways remain Pending", "configmap", cmName)
-		} else {
-			log.Error(err, "failed to get status ConfigMap", "configmap", cmName)
+	legacyName := fmt.Sprintf("stoker-status-%s", gs.Name)
+	legacy := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: legacyName, Namespace: gs.Namespace}, legacy); err != nil {
+		if !errors.IsNotFound(err) {
+			log.Error(err, "failed to get status ConfigMap", "configmap", legacyName)
 		}
+	} else {
+		maps.Copy(statuses, legacy.Data)
+	}
+
+	cms, err := r.listGatewayStatusConfigMaps(ctx, gs)
+	if err != nil {
+		log.Error(err, "failed to collect gateway status")
+	}
+	// Remote gateways' ConfigMaps are read by name only, never merged in as
+	// a fallback for a same-named gateway elsewhere.
+	remote := make(map[string]bool)
+	for _, gw := range gateways {
+		if ns := gatewayStatusNamespace(gs, gw.Namespace); ns != "" {
+			remote[stokertypes.StatusConfigMapName(gs.Name, ns, gw.PodName)] = true
+			remote[stokertypes.StatusConfigMapName(gs.Name, ns, gw.Name)] = true
+		}
+	}
+	byName := make(map[string]map[string]string, len(cms))
+	for _, cm := range cms {
+		byName[cm.Name] = cm.Data
+		if !remote[cm.Name] {
+			maps.Copy(statuses, cm.Data)
+		}
+	}
+
+	if len(byName) == 0 && len(statuses) == 0 {
+		log.V(1).Info("no gateway status reported yet, gateways remain Pending")
 		return gateways
 	}
 
 	// Enrich each gateway with its status.
 	// The agent writes status keyed by its GATEWAY_NAME env, which defaults to the
-	// pod name when unset. Look up by PodName first, then fall back to Name.
+	// pod name when unset. Look up by PodName first, then fall back to Name,
+	// preferring the gateway's own ConfigMap over ones older agents wrote.
+	// Gateways in other namespaces only read their own ConfigMap, so another
+	// namespace's gateway of the same name cannot stand in for them.
 	for i := range gateways {
-		statusJSON, ok := cm.Data[gateways[i].PodName]
-		if !ok {
-			statusJSON, ok = cm.Data[gateways[i].Name]
+		var statusJSON string
+		var ok bool
+		namespace := gatewayStatusNamespace(gs, gateways[i].Namespace)
+		for _, key := range []string{gateways[i].PodName, gateways[i].Name} {
+			if statusJSON, ok = byName[stokertypes.StatusConfigMapName(gs.Name, namespace, key)][key]; ok {
+				break
+			}
+		}
+		if !ok && namespace == "" {
+			statusJSON, ok = statuses[gateways[i].PodName]
+			if !ok {
+				statusJSON, ok = statuses[gateways[i].Name]
+			}
 		}
 		if !ok || statusJSON == "" {
 			continue
@@ -155,11 +301,16 @@ func (r *GatewaySyncReconciler) collectGatewayStatus(ctx context.Context, gs *st
 		gateways[i].SyncStatus = status.SyncStatus
 		gateways[i].SyncedCommit = status.SyncedCommit
 		gateways[i].SyncedRef = status.SyncedRef
+		gateways[i].SyncedCommitInfo = agentCommitInfo(status.SyncedCommitInfo)
 		gateways[i].LastSyncDuration = status.LastSyncDuration
 		gateways[i].AgentVersion = status.AgentVersion
+		gateways[i].ProfileLibrary = status.ProfileLibrary
 		gateways[i].LastScanResult = status.LastScanResult
 		gateways[i].FilesChanged = status.FilesChanged
 		gateways[i].ProjectsSynced = status.ProjectsSynced
+		gateways[i].LastBackup = status.LastBackup
+		gateways[i].GatewayVersion = status.GatewayVersion
+		gateways[i].RecentSyncs = condenseHistory(status.History)
 
 		// Parse lastSyncTime as RFC3339
 		if status.LastSyncTime != "" {
@@ -176,6 +327,99 @@ func (r *GatewaySyncReconciler) collectGatewayStatus(ctx context.Context, gs *st
 	return gateways
 }
 
+// recentSyncsLen is how many history records are surfaced per gateway on the
+// CR. The agent keeps more; GET /history on the webhook receiver serves them all.
+const recentSyncsLen = 5
+
+// condenseHistory summarizes the newest agent sync records for the CR status.
+func condenseHistory(history []stokertypes.SyncRecord) []stokerv1alpha1.SyncHistoryEntry {
+	if len(history) == 0 {
+		return nil
+	}
+	history = history[:min(recentSyncsLen, len(history))]
+	entries := make([]stokerv1alpha1.SyncHistoryEntry, 0, len(history))
+	for _, rec := range history {
+		entry := stokerv1alpha1.SyncHistoryEntry{
+			Commit:       rec.Commit[:min(7, len(rec.Commit))],
+			Trigger:      rec.Trigger,
+			Result:       rec.Result,
+			FilesChanged: rec.FilesAdded + rec.FilesModified + rec.FilesDeleted,
+		}
+		if t, err := time.Parse(time.RFC3339, rec.EndTime); err == nil {
+			mt := metav1.NewTime(t)
+			entry.Time = &mt
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// agentCommitInfo converts the commit details an agent reports for the CR status.
+func agentCommitInfo(info *stokertypes.CommitInfo) *stokerv1alpha1.CommitInfo {
+	if info == nil {
+		return nil
+	}
+	out := &stokerv1alpha1.CommitInfo{Author: info.Author, Committer: info.Committer, Subject: info.Subject}
+	if t, err := time.Parse(time.RFC3339, info.Time); err == nil {
+		mt := metav1.NewTime(t)
+		out.Time = &mt
+	}
+	return out
+}
+
+// pruneGatewayStatus deletes per-gateway status ConfigMaps once no pod in any
+// phase runs their gateway, e.g. after a scale-down. Pods that are Pending
+// during a rolling update or drain keep their status and sync history. A
+// ConfigMap under a name older agents used is kept until the gateway's agent
+// writes its current one. Empty ConfigMaps reserved for remote agents are
+// left to ensureRemoteAgentAccess.
+func (r *GatewaySyncReconciler) pruneGatewayStatus(ctx context.Context, gs *stokerv1alpha1.GatewaySync) error {
+	pods, err := r.listGatewayPods(ctx, gs)
+	if err != nil {
+		return err
+	}
+	cms, err := r.listGatewayStatusConfigMaps(ctx, gs)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(cms))
+	for i := range cms {
+		existing[cms[i].Name] = true
+	}
+
+	current := make(map[string]bool, len(pods))
+	// Gateways whose status is still under a name older agents used.
+	pending := make(map[string]bool)
+	for i := range pods {
+		name := statusConfigMapForPod(gs, &pods[i])
+		current[name] = true
+		if !existing[name] && pods[i].Namespace == gs.Namespace {
+			pending[agentGatewayName(&pods[i])] = true
+		}
+	}
+
+	for i := range cms {
+		if len(cms[i].Data) == 0 || current[cms[i].Name] {
+			continue
+		}
+		stale := true
+		for gatewayName := range cms[i].Data {
+			if pending[gatewayName] {
+				stale = false
+				break
+			}
+		}
+		if !stale {
+			continue
+		}
+		if err := r.Delete(ctx, &cms[i]); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deleting status ConfigMap %s: %w", cms[i].Name, err)
+		}
+		logf.FromContext(ctx).Info("deleted status ConfigMap for departed gateway", "name", cms[i].Name)
+	}
+	return nil
+}
+
 // updateAllGatewaysSyncedCondition counts how many gateways are synced and sets
 // the AllGatewaysSynced condition accordingly.
 func (r *GatewaySyncReconciler) updateAllGatewaysSyncedCondition(ctx context.Context, gs *stokerv1alpha1.GatewaySync) {
@@ -221,6 +465,26 @@ func (r *GatewaySyncReconciler) updateAllGatewaysSyncedCondition(ctx context.Con
 	}
 }
 
+// updateRefSkewCondition sets RefSkew=True while any gateway is pinned to its
+// own ref by stoker.io/ref-override, listing the pinned gateways. Skew is a
+// warning only; it does not affect Ready.
+func (r *GatewaySyncReconciler) updateRefSkewCondition(ctx context.Context, gs *stokerv1alpha1.GatewaySync) {
+	var pinned []string
+	for _, gw := range gs.Status.DiscoveredGateways {
+		if gw.RefOverride != "" {
+			pinned = append(pinned, fmt.Sprintf("%s (%s)", gw.Name, gw.RefOverride))
+		}
+	}
+
+	if len(pinned) == 0 {
+		r.setCondition(ctx, gs, conditions.TypeRefSkew, metav1.ConditionFalse,
+			conditions.ReasonNoRefSkew, "All gateways follow spec.git.ref")
+		return
+	}
+	r.setCondition(ctx, gs, conditions.TypeRefSkew, metav1.ConditionTrue, conditions.ReasonRefOverrideActive,
+		fmt.Sprintf("%d gateway(s) pinned by %s: %s", len(pinned), stokertypes.AnnotationRefOverride, strings.Join(pinned, ", ")))
+}
+
 // updateReadyCondition sets the Ready condition based on RefResolved, ProfilesValid, and AllGatewaysSynced.
 // Ready=True only when all three are True.
 func (r *GatewaySyncReconciler) updateReadyCondition(ctx context.Context, gs *stokerv1alpha1.GatewaySync) {
@@ -240,7 +504,16 @@ func (r *GatewaySyncReconciler) updateReadyCondition(ctx context.Context, gs *st
 		}
 	}
 
-	if refResolved && allGatewaysSynced && profilesValid {
+	if rollbackActive(gs) {
+		r.setCondition(ctx, gs, conditions.TypeReady, metav1.ConditionFalse, conditions.ReasonRolledBack,
+			fmt.Sprintf("Rolled back %s to %s", git.ShortCommit(gs.Status.Rollback.FailedCommit), git.ShortCommit(gs.Status.Rollback.Commit)))
+	} else if ro := gs.Status.Rollout; gs.Spec.Rollout != nil && ro != nil && ro.Phase != rolloutComplete && refResolved && profilesValid {
+		reason := conditions.ReasonRolloutProgressing
+		if ro.Phase == rolloutHalted {
+			reason = conditions.ReasonRolloutHalted
+		}
+		r.setCondition(ctx, gs, conditions.TypeReady, metav1.ConditionFalse, reason, ro.Message)
+	} else if refResolved && allGatewaysSynced && profilesValid {
 		r.setCondition(ctx, gs, conditions.TypeReady, metav1.ConditionTrue,
 			conditions.ReasonSyncSucceeded, "All gateways synced")
 	} else if !profilesValid {
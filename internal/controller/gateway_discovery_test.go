@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func statusConfigMap(t *testing.T, name, crName string, perGateway bool, statuses map[string]stokertypes.GatewayStatus) *corev1.ConfigMap {
+	t.Helper()
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       map[string]string{},
+	}
+	if perGateway {
+		cm.Labels = map[string]string{
+			stokertypes.LabelCRName:        crName,
+			stokertypes.LabelGatewayStatus: "true",
+		}
+	}
+	for gateway, status := range statuses {
+		data, err := json.Marshal(status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cm.Data[gateway] = string(data)
+	}
+	return cm
+}
+
+func TestCollectGatewayStatus_PerGatewayConfigMaps(t *testing.T) {
+	gs := &stokerv1alpha1.GatewaySync{ObjectMeta: metav1.ObjectMeta{Name: "my-sync", Namespace: "default"}}
+	c := fake.NewClientBuilder().WithObjects(
+		// Shared ConfigMap from older agents; gw-a has since moved to its own.
+		statusConfigMap(t, "stoker-status-my-sync", "", false, map[string]stokertypes.GatewayStatus{
+			"gw-a": {SyncStatus: stokertypes.SyncStatusError, SyncedCommit: "old"},
+			"gw-b": {SyncStatus: stokertypes.SyncStatusSynced, SyncedCommit: "abc123"},
+		}),
+		statusConfigMap(t, stokertypes.StatusConfigMapName("my-sync", "", "gw-a"), "my-sync", true, map[string]stokertypes.GatewayStatus{
+			"gw-a": {SyncStatus: stokertypes.SyncStatusSynced, SyncedCommit: "abc123", SyncedCommitInfo: &stokertypes.CommitInfo{
+				Author: "Jane Doe", Subject: "Raise tank alarm limits", Time: "2026-03-01T12:00:00Z",
+			}},
+		}),
+		// Another CR's gateway must not leak in.
+		statusConfigMap(t, "stoker-status-other-gw-c", "other", true, map[string]stokertypes.GatewayStatus{
+			"gw-c": {SyncStatus: stokertypes.SyncStatusSynced},
+		}),
+	).Build()
+	r := &GatewaySyncReconciler{Client: c}
+
+	gateways := r.collectGatewayStatus(context.Background(), gs, []stokerv1alpha1.DiscoveredGateway{
+		{Name: "gw-a", PodName: "gw-a-0", SyncStatus: stokertypes.SyncStatusPending},
+		{Name: "gw-b", PodName: "gw-b-0", SyncStatus: stokertypes.SyncStatusPending},
+		{Name: "gw-c", PodName: "gw-c-0", SyncStatus: stokertypes.SyncStatusPending},
+	})
+
+	if gateways[0].SyncStatus != stokertypes.SyncStatusSynced || gateways[0].SyncedCommit != "abc123" {
+		t.Errorf("gw-a should use its per-gateway status, got %s@%s", gateways[0].SyncStatus, gateways[0].SyncedCommit)
+	}
+	if info := gateways[0].SyncedCommitInfo; info == nil || info.Author != "Jane Doe" || info.Time == nil {
+		t.Errorf("gw-a should report the synced commit's details, got %+v", info)
+	}
+	if gateways[1].SyncStatus != stokertypes.SyncStatusSynced {
+		t.Errorf("gw-b should fall back to the shared status, got %s", gateways[1].SyncStatus)
+	}
+	if gateways[2].SyncStatus != stokertypes.SyncStatusPending {
+		t.Errorf("gw-c belongs to another CR and should stay Pending, got %s", gateways[2].SyncStatus)
+	}
+}
+
+func TestPruneGatewayStatus(t *testing.T) {
+	gs := &stokerv1alpha1.GatewaySync{ObjectMeta: metav1.ObjectMeta{Name: "my-sync", Namespace: "default"}}
+	pod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: map[string]string{stokertypes.AnnotationCRName: "my-sync"}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	current := func(gateway string) string { return stokertypes.StatusConfigMapName("my-sync", "", gateway) }
+	c := fake.NewClientBuilder().WithObjects(
+		pod("gw-a", corev1.PodRunning),
+		// Rescheduled during a rolling update.
+		pod("gw-c", corev1.PodPending),
+		// Its agent still writes under the name older agents used.
+		pod("gw-d", corev1.PodRunning),
+		statusConfigMap(t, current("gw-a"), "my-sync", true, map[string]stokertypes.GatewayStatus{"gw-a": {}}),
+		statusConfigMap(t, "stoker-status-my-sync-gw-a", "my-sync", true, map[string]stokertypes.GatewayStatus{"gw-a": {}}),
+		statusConfigMap(t, current("gw-b"), "my-sync", true, map[string]stokertypes.GatewayStatus{"gw-b": {}}),
+		statusConfigMap(t, current("gw-c"), "my-sync", true, map[string]stokertypes.GatewayStatus{"gw-c": {}}),
+		statusConfigMap(t, "stoker-status-my-sync-gw-d", "my-sync", true, map[string]stokertypes.GatewayStatus{"gw-d": {}}),
+	).Build()
+	r := &GatewaySyncReconciler{Client: c}
+
+	if err := r.pruneGatewayStatus(context.Background(), gs); err != nil {
+		t.Fatalf("pruneGatewayStatus: %v", err)
+	}
+
+	for name, keep := range map[string]bool{
+		current("gw-a"):              true,
+		"stoker-status-my-sync-gw-a": false, // superseded by the current name
+		current("gw-b"):              false, // no pod left
+		current("gw-c"):              true,
+		"stoker-status-my-sync-gw-d": true,
+	} {
+		err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "default"}, &corev1.ConfigMap{})
+		if keep && err != nil {
+			t.Errorf("%s should be kept: %v", name, err)
+		}
+		if !keep && err == nil {
+			t.Errorf("%s should be deleted", name)
+		}
+	}
+}
+
+func TestUpdateRefSkewCondition(t *testing.T) {
+	r := &GatewaySyncReconciler{}
+	gs := &stokerv1alpha1.GatewaySync{}
+	gs.Status.DiscoveredGateways = []stokerv1alpha1.DiscoveredGateway{
+		{Name: "prod-a"},
+		{Name: "dev-b", RefOverride: "feature/new-alarms"},
+	}
+
+	r.updateRefSkewCondition(context.Background(), gs)
+
+	cond := apimeta.FindStatusCondition(gs.Status.Conditions, conditions.TypeRefSkew)
+	if cond == nil {
+		t.Fatal("RefSkew condition not set")
+	}
+	if cond.Status != metav1.ConditionTrue || cond.Reason != conditions.ReasonRefOverrideActive {
+		t.Errorf("expected RefSkew=True/%s, got %s/%s", conditions.ReasonRefOverrideActive, cond.Status, cond.Reason)
+	}
+	if !strings.Contains(cond.Message, "dev-b (feature/new-alarms)") || strings.Contains(cond.Message, "prod-a") {
+		t.Errorf("message should list only pinned gateways, got %q", cond.Message)
+	}
+
+	// Removing the override clears the skew.
+	gs.Status.DiscoveredGateways[1].RefOverride = ""
+	r.updateRefSkewCondition(context.Background(), gs)
+	cond = apimeta.FindStatusCondition(gs.Status.Conditions, conditions.TypeRefSkew)
+	if cond.Status != metav1.ConditionFalse || cond.Reason != conditions.ReasonNoRefSkew {
+		t.Errorf("expected RefSkew=False/%s, got %s/%s", conditions.ReasonNoRefSkew, cond.Status, cond.Reason)
+	}
+}
+
+func TestCondenseHistory(t *testing.T) {
+	var history []stokertypes.SyncRecord
+	for range 8 {
+		history = append(history, stokertypes.SyncRecord{
+			Commit:        "abc123def456",
+			Trigger:       stokertypes.SyncTriggerCommit,
+			Result:        stokertypes.SyncStatusSynced,
+			EndTime:       "2026-01-15T10:30:00Z",
+			FilesAdded:    1,
+			FilesModified: 2,
+		})
+	}
+
+	entries := condenseHistory(history)
+	if len(entries) != recentSyncsLen {
+		t.Fatalf("expected %d entries, got %d", recentSyncsLen, len(entries))
+	}
+	e := entries[0]
+	if e.Commit != "abc123d" || e.FilesChanged != 3 || e.Time == nil || e.Result != stokertypes.SyncStatusSynced {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if condenseHistory(nil) != nil {
+		t.Error("expected nil for empty history")
+	}
+}
@@ -7,14 +7,14 @@ import (
 	"maps"
 	"path/filepath"
 	"reflect"
-	"regexp"
-	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -34,6 +34,8 @@ import (
 
 	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
 	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/internal/profilespec"
+	"github.com/ia-eknorr/stoker-operator/internal/schedule"
 	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
 	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
 )
@@ -43,6 +45,10 @@ const (
 
 	// tokenRefreshBuffer is how long before expiry the controller refreshes a GitHub App token.
 	tokenRefreshBuffer = 5 * time.Minute
+
+	// rolloutRequeueInterval bounds the requeue interval while a staged rollout
+	// progresses, since agent status ConfigMaps are not watched.
+	rolloutRequeueInterval = 10 * time.Second
 )
 
 // cachedToken holds a GitHub App installation token and its expiry time.
@@ -70,16 +76,22 @@ type GatewaySyncReconciler struct {
 
 	// backoff tracks consecutive failures per CR for exponential backoff.
 	backoff map[types.NamespacedName]*backoffState
+
+	// commitInfoMisses holds, per CR, the commit whose details could not be
+	// fetched, so a server refusing fetch-by-SHA is asked once per commit.
+	commitInfoMisses sync.Map // types.NamespacedName -> string
 }
 
 // +kubebuilder:rbac:groups=stoker.io,resources=gatewaysyncs,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=stoker.io,resources=gatewaysyncs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=stoker.io,resources=gatewaysyncs/finalizers,verbs=update
+// +kubebuilder:rbac:groups=stoker.io,resources=syncprofiles;clustersyncprofiles,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;create;update;delete;watch
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=get;list;create;update;delete;watch
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=bind,resourceNames=stoker-agent
 
 func (r *GatewaySyncReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -144,12 +156,29 @@ func (r *GatewaySyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	}
 	crPaused.WithLabelValues(gs.Name, gs.Namespace).Set(0)
 
-	// --- Step 1: Validate profiles ---
-
-	if err := r.validateProfiles(&gs); err != nil {
-		r.setCondition(ctx, &gs, conditions.TypeProfilesValid, metav1.ConditionFalse, conditions.ReasonProfilesInvalid, err.Error())
-		r.Recorder.Eventf(&gs, corev1.EventTypeWarning, conditions.ReasonProfilesInvalid, "Profile validation failed: %s", err.Error())
-	} else {
+	// --- Step 1: Collect and validate profiles ---
+	// Library profiles from spec.sync.profileRefs are merged with the inline
+	// ones; an unresolved reference leaves only that profile unpublished.
+	// The profiles that did resolve are validated either way, and an invalid
+	// profile takes precedence over an unresolved reference in the condition.
+
+	profiles, refErr := r.collectProfiles(ctx, &gs)
+	validErr := r.validateProfiles(&gs, profiles)
+	switch {
+	case validErr != nil:
+		msg := validErr.Error()
+		if refErr != nil {
+			msg += "; " + refErr.Error()
+		}
+		r.setCondition(ctx, &gs, conditions.TypeProfilesValid, metav1.ConditionFalse, conditions.ReasonProfilesInvalid, msg)
+		r.Recorder.Eventf(&gs, corev1.EventTypeWarning, conditions.ReasonProfilesInvalid, "Profile validation failed: %s", validErr.Error())
+	case refErr != nil:
+		wasUnresolved := conditionHasReason(gs.Status.Conditions, conditions.TypeProfilesValid, conditions.ReasonProfileRefsUnresolved)
+		if !wasUnresolved {
+			r.Recorder.Eventf(&gs, corev1.EventTypeWarning, conditions.ReasonProfileRefsUnresolved, "Profile references unresolved: %s", refErr.Error())
+		}
+		r.setCondition(ctx, &gs, conditions.TypeProfilesValid, metav1.ConditionFalse, conditions.ReasonProfileRefsUnresolved, refErr.Error())
+	default:
 		r.setCondition(ctx, &gs, conditions.TypeProfilesValid, metav1.ConditionTrue, conditions.ReasonProfilesValid, "All profiles valid")
 	}
 
@@ -178,6 +207,22 @@ func (r *GatewaySyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// --- Step 2.6: Gateway TLS certificate verification warning ---
+
+	if gs.Spec.Gateway.TLS == nil || *gs.Spec.Gateway.TLS {
+		if gs.Spec.Gateway.TLSTrust != nil && gs.Spec.Gateway.TLSTrust.InsecureSkipVerify {
+			r.setCondition(ctx, &gs, conditions.TypeGatewayTLSVerification, metav1.ConditionFalse,
+				conditions.ReasonTLSVerificationDisabled,
+				"Gateway TLS certificate verification disabled — set spec.gateway.tlsTrust.caBundle to enable verification")
+		} else {
+			r.setCondition(ctx, &gs, conditions.TypeGatewayTLSVerification, metav1.ConditionTrue,
+				conditions.ReasonTLSVerificationEnabled, "Gateway TLS certificate verification enabled")
+		}
+	} else {
+		// Plain HTTP has no certificate to verify.
+		meta.RemoveStatusCondition(&gs.Status.Conditions, conditions.TypeGatewayTLSVerification)
+	}
+
 	// --- Step 3: Resolve git ref via ls-remote ---
 
 	refStart := time.Now()
@@ -203,37 +248,110 @@ func (r *GatewaySyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{RequeueAfter: delay}, nil
 	}
 
+	// --- Step 3.1: Verify commit signature ---
+	// An unverified commit never reaches status or the metadata ConfigMap, so
+	// agents keep serving the last verified commit.
+
+	if gs.Spec.Git.Verification == nil {
+		meta.RemoveStatusCondition(&gs.Status.Conditions, conditions.TypeCommitVerified)
+	} else if r.needsVerification(&gs, result.Commit) {
+		signer, err := r.verifyCommit(ctx, &gs, result)
+		if err != nil {
+			r.recordFailure(req.NamespacedName)
+			delay := r.backoffDelay(req.NamespacedName)
+			wasAlreadyFailed := conditionHasStatus(gs.Status.Conditions, conditions.TypeCommitVerified, metav1.ConditionFalse)
+			r.setCondition(ctx, &gs, conditions.TypeCommitVerified, metav1.ConditionFalse, conditions.ReasonSignatureVerificationFailed,
+				fmt.Sprintf("Commit %s not verified (retry in %s): %s", git.ShortCommit(result.Commit), delay.Round(time.Second), err.Error()))
+			r.setCondition(ctx, &gs, conditions.TypeReady, metav1.ConditionFalse, conditions.ReasonSignatureVerificationFailed,
+				fmt.Sprintf("Commit %s failed signature verification", git.ShortCommit(result.Commit)))
+			if !wasAlreadyFailed {
+				r.Recorder.Eventf(&gs, corev1.EventTypeWarning, conditions.ReasonSignatureVerificationFailed,
+					"Refusing to publish %s: %s", git.ShortCommit(result.Commit), err.Error())
+			}
+			_ = r.patchStatus(ctx, &gs, base)
+			reconcileResult = resultRequeue
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
+		r.setCondition(ctx, &gs, conditions.TypeCommitVerified, metav1.ConditionTrue, conditions.ReasonSignatureVerified,
+			fmt.Sprintf("%s %s signed by %s", verificationSubject(&gs), git.ShortCommit(result.Commit), signer))
+	}
+
 	// Ref resolved successfully
 	r.resetBackoff(req.NamespacedName)
 	r.setCondition(ctx, &gs, conditions.TypeRefResolved, metav1.ConditionTrue, conditions.ReasonRefResolved, result.Commit)
-	gs.Status.RefResolutionStatus = "Resolved"
-	if gs.Status.LastSyncCommit != result.Commit {
-		gs.Status.LastSyncCommit = result.Commit
-		gs.Status.LastSyncCommitShort = shortCommit(result.Commit)
-		gs.Status.LastSyncRef = result.Ref
-		now := metav1.Now()
-		gs.Status.LastSyncTime = &now
-	}
 
-	// --- Step 3.5: Validate gateway API key secret ---
+	// --- Step 3.2: Approval gate ---
+	// A commit awaiting approval is recorded in status.pendingCommit only;
+	// everything below continues with the last approved commit.
 
-	if err := r.validateAPIKeySecret(ctx, &gs); err != nil {
-		r.recordFailure(req.NamespacedName)
-		delay := r.backoffDelay(req.NamespacedName)
-		r.setCondition(ctx, &gs, conditions.TypeReady, metav1.ConditionFalse, conditions.ReasonReconciling,
-			fmt.Sprintf("%s (retry in %s)", err.Error(), delay.Round(time.Second)))
+	result, approved := r.gateApproval(ctx, &gs, result)
+	if !approved {
+		r.setCondition(ctx, &gs, conditions.TypeReady, metav1.ConditionFalse, conditions.ReasonAwaitingApproval,
+			"No commit approved yet")
+		gs.Status.RefResolutionStatus = "Resolved"
 		_ = r.patchStatus(ctx, &gs, base)
 		reconcileResult = resultRequeue
-		return ctrl.Result{RequeueAfter: delay}, nil
+		return ctrl.Result{RequeueAfter: r.requeueInterval(&gs)}, nil
 	}
+	gs.Status.RefResolutionStatus = "Resolved"
 
-	// --- Step 4: Create/update metadata ConfigMap ---
+	// --- Step 3.3: Maintenance windows ---
+	// Outside spec.sync.schedule a new commit is recorded in
+	// status.deferredCommit only and the published commit stays in place.
 
-	if err := r.ensureMetadataConfigMap(ctx, &gs, result); err != nil {
-		log.Error(err, "failed to update metadata ConfigMap")
+	result = r.gateSchedule(ctx, &gs, result, time.Now())
+
+	expression := ""
+	if git.IsRefExpression(gs.Spec.Git.Ref) {
+		expression = gs.Spec.Git.Ref
+	}
+	gs.Status.RefExpression = expression
+	// A new tag on the published commit changes only the ref; it is still
+	// reported, keeping commit details already fetched.
+	if gs.Status.LastSyncCommit != result.Commit || gs.Status.LastSyncRef != result.Ref {
+		if gs.Status.LastSyncCommit != result.Commit || !result.Info.IsZero() {
+			gs.Status.LastSyncCommitInfo = agentCommitInfo(result.Info.Status())
+		}
+		gs.Status.LastSyncCommit = result.Commit
+		gs.Status.LastSyncCommitShort = git.ShortCommit(result.Commit)
+		gs.Status.LastSyncRef = result.Ref
+		now := metav1.Now()
+		gs.Status.LastSyncTime = &now
+		resolved := result.Ref
+		if expression != "" {
+			resolved = fmt.Sprintf("%s (tag %s)", expression, result.Ref)
+		}
+		r.Recorder.Eventf(&gs, corev1.EventTypeNormal, conditions.ReasonNewCommit,
+			"Resolved %s to %s", resolved, git.DescribeCommit(result.Commit, result.Info))
+	} else if gs.Status.LastSyncCommitInfo == nil {
+		gs.Status.LastSyncCommitInfo = agentCommitInfo(result.Info.Status())
+	}
+
+	// --- Step 3.5: Validate gateway API key secret and CA bundle ---
+	// With spec.allowedNamespaces they are read from each gateway's own
+	// namespace, which may not run gateways yet, so they are not checked here.
+
+	if len(gs.Spec.AllowedNamespaces) == 0 {
+		if err := r.validateGatewaySecrets(ctx, &gs); err != nil {
+			r.recordFailure(req.NamespacedName)
+			delay := r.backoffDelay(req.NamespacedName)
+			r.setCondition(ctx, &gs, conditions.TypeReady, metav1.ConditionFalse, conditions.ReasonReconciling,
+				fmt.Sprintf("%s (retry in %s)", err.Error(), delay.Round(time.Second)))
+			_ = r.patchStatus(ctx, &gs, base)
+			reconcileResult = resultRequeue
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
 	}
 
-	// --- Step 5: Discover gateways ---
+	// Under an approval gate or schedule agents fetch the published commit by
+	// SHA, never a newer commit the ref has moved to.
+	if gs.Spec.Approval != nil || gs.Spec.Sync.Schedule != nil {
+		result.Ref = result.Commit
+	}
+
+	// --- Step 4: Discover gateways ---
+	// Discovery runs before the metadata ConfigMap is written so a staged
+	// rollout can publish per-gateway targets from the latest sync status.
 
 	prevGatewayCount := len(gs.Status.DiscoveredGateways)
 	gateways, err := r.discoverGateways(ctx, &gs)
@@ -242,6 +360,9 @@ func (r *GatewaySyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	} else {
 		gateways = r.collectGatewayStatus(ctx, &gs, gateways)
 		gs.Status.DiscoveredGateways = gateways
+		if err := r.pruneGatewayStatus(ctx, &gs); err != nil {
+			log.Error(err, "failed to prune gateway status ConfigMaps")
+		}
 
 		if len(gateways) != prevGatewayCount {
 			r.Recorder.Eventf(&gs, corev1.EventTypeNormal, "GatewaysDiscovered",
@@ -249,6 +370,26 @@ func (r *GatewaySyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// --- Step 4.5: Automatic rollback ---
+	// While a rollback holds, the last fully synced commit is published
+	// instead of the resolved one and any staged rollout is frozen.
+
+	published := r.updateRollback(ctx, &gs, result)
+
+	// --- Step 4.6: Advance staged rollout ---
+
+	if !rollbackActive(&gs) {
+		if err := r.updateRollout(ctx, &gs, result); err != nil {
+			log.Error(err, "failed to advance rollout")
+		}
+	}
+
+	// --- Step 5: Create/update metadata ConfigMap ---
+
+	if err := r.ensureMetadataConfigMap(ctx, &gs, published, profiles); err != nil {
+		log.Error(err, "failed to update metadata ConfigMap")
+	}
+
 	// --- Step 5.5: Auto-RBAC ---
 
 	if r.AutoBindAgentRBAC {
@@ -258,9 +399,18 @@ func (r *GatewaySyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// --- Step 5.6: Mirror git Secrets to gateway namespaces ---
+	// Agents in spec.allowedNamespaces mount them from their own namespace.
+
+	if err := r.ensureMirroredSecrets(ctx, &gs); err != nil {
+		log.Error(err, "failed to mirror Secrets")
+		r.Recorder.Eventf(&gs, corev1.EventTypeWarning, "SecretMirrorError", "Failed to mirror Secrets: %v", err)
+	}
+
 	// --- Step 6: Update conditions ---
 
 	r.updateAllGatewaysSyncedCondition(ctx, &gs)
+	r.updateRefSkewCondition(ctx, &gs)
 	r.updateReadyCondition(ctx, &gs)
 
 	// --- Step 6.5: Update metrics ---
@@ -270,14 +420,18 @@ func (r *GatewaySyncReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	// --- Step 7: Update status ---
 
 	gs.Status.ObservedGeneration = gs.Generation
-	gs.Status.ProfileCount = int32(len(gs.Spec.Sync.Profiles))
+	gs.Status.ProfileCount = int32(len(profiles))
 	if err := r.patchStatus(ctx, &gs, base); err != nil {
 		reconcileResult = resultError
 		return ctrl.Result{}, err
 	}
 
-	// --- Step 7.5: Clear stale requested-ref annotation ---
+	// --- Step 7.5: Clear consumed annotations ---
 	r.clearRequestedRefIfCaughtUp(ctx, req, &gs)
+	if gs.Annotations[stokertypes.AnnotationRollbackAcknowledged] != "" {
+		r.removeAnnotations(ctx, req, &gs, stokertypes.AnnotationRollbackAcknowledged)
+	}
+	r.removeApprovalIfConsumed(ctx, req, &gs)
 
 	// --- Step 8: Requeue ---
 
@@ -330,15 +484,14 @@ func (r *GatewaySyncReconciler) resetBackoff(key types.NamespacedName) {
 	}
 }
 
-// validVarKey matches Go identifiers: letters, digits, underscores; must start with letter or underscore.
-var validVarKey = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
-
-// validateProfiles validates all embedded profiles for path safety and var key naming.
-func (r *GatewaySyncReconciler) validateProfiles(gs *stokerv1alpha1.GatewaySync) error {
+// validateProfiles validates all embedded and referenced profiles for path
+// safety and var key naming.
+func (r *GatewaySyncReconciler) validateProfiles(gs *stokerv1alpha1.GatewaySync, profiles map[string]syncProfile) error {
 	if err := validateVarKeys(gs.Spec.Sync.Defaults.Vars, "sync.defaults.vars"); err != nil {
 		return err
 	}
-	for name, profile := range gs.Spec.Sync.Profiles {
+	for _, name := range sortedProfileNames(profiles) {
+		profile := profiles[name].spec
 		if err := validateVarKeys(profile.Vars, fmt.Sprintf("profiles[%s].vars", name)); err != nil {
 			return err
 		}
@@ -358,7 +511,7 @@ func (r *GatewaySyncReconciler) validateProfiles(gs *stokerv1alpha1.GatewaySync)
 // dashes, dots, or slashes cannot be accessed via {{.Vars.key}} in templates.
 func validateVarKeys(vars map[string]string, field string) error {
 	for k := range vars {
-		if !validVarKey.MatchString(k) {
+		if !profilespec.ValidVarKey(k) {
 			return fmt.Errorf("%s: key %q is not a valid identifier (use letters, digits, underscores only — dashes are not supported in template variable names)", field, k)
 		}
 	}
@@ -370,24 +523,20 @@ func validatePath(p, field string) error {
 	if filepath.IsAbs(p) {
 		return fmt.Errorf("%s: absolute paths not allowed (%q)", field, p)
 	}
-	if containsTraversal(p) {
+	if profilespec.ContainsTraversal(p) {
 		return fmt.Errorf("%s: path traversal (..) not allowed (%q)", field, p)
 	}
 	return nil
 }
 
-// containsTraversal checks for ".." path components.
-func containsTraversal(p string) bool {
-	return slices.Contains(strings.Split(filepath.ToSlash(p), "/"), "..")
-}
-
 // resolveProfiles merges defaults into each profile, returning fully-resolved profiles.
-func (r *GatewaySyncReconciler) resolveProfiles(gs *stokerv1alpha1.GatewaySync) map[string]stokertypes.ResolvedProfile {
+func (r *GatewaySyncReconciler) resolveProfiles(gs *stokerv1alpha1.GatewaySync, profiles map[string]syncProfile) map[string]stokertypes.ResolvedProfile {
 	defaults := gs.Spec.Sync.Defaults
-	resolved := make(map[string]stokertypes.ResolvedProfile, len(gs.Spec.Sync.Profiles))
+	resolved := make(map[string]stokertypes.ResolvedProfile, len(profiles))
 
-	for name, p := range gs.Spec.Sync.Profiles {
-		rp := stokertypes.ResolvedProfile{}
+	for name, sp := range profiles {
+		p := sp.spec
+		rp := stokertypes.ResolvedProfile{Library: sp.library}
 
 		// Merge vars: defaults first, then profile overrides per-key.
 		merged := make(map[string]string, len(defaults.Vars)+len(p.Vars))
@@ -459,44 +608,126 @@ func (r *GatewaySyncReconciler) resolveProfiles(gs *stokerv1alpha1.GatewaySync)
 func (r *GatewaySyncReconciler) resolveRef(ctx context.Context, gs *stokerv1alpha1.GatewaySync) (git.Result, error) {
 	ref := gs.Spec.Git.Ref
 
-	// Check for webhook-requested ref override
-	if requested, ok := gs.Annotations[stokertypes.AnnotationRequestedRef]; ok && requested != "" {
+	// Check for webhook-requested ref override. A ref expression is never
+	// overridden; the webhook only triggers its re-evaluation.
+	if requested, ok := gs.Annotations[stokertypes.AnnotationRequestedRef]; ok && requested != "" && !git.IsRefExpression(ref) {
 		ref = requested
 	}
 
 	// If the ref is already resolved at the desired ref and was resolved recently,
 	// return cached result to avoid redundant ls-remote calls on status-triggered reconciles.
-	if gs.Status.RefResolutionStatus == "Resolved" && gs.Status.LastSyncRef == ref &&
-		gs.Status.LastSyncCommit != "" && gs.Status.LastSyncTime != nil {
+	// A commit still awaiting signature verification always goes to the remote,
+	// since the cached result lacks the tag object, and so does a CR with a
+	// commit pending approval or deferred by the schedule, which would
+	// otherwise hide it.
+	if gs.Status.RefResolutionStatus == "Resolved" && resolvedFrom(gs) == ref &&
+		gs.Status.LastSyncCommit != "" && gs.Status.LastSyncTime != nil &&
+		!r.needsVerification(gs, gs.Status.LastSyncCommit) && gs.Status.PendingCommit == nil &&
+		gs.Status.DeferredCommit == "" {
 		sinceLastSync := time.Since(gs.Status.LastSyncTime.Time)
 		if sinceLastSync < r.pollingInterval(gs) {
 			return git.Result{Commit: gs.Status.LastSyncCommit, Ref: gs.Status.LastSyncRef}, nil
 		}
 	}
 
-	// Resolve auth — GitHub App uses cached tokens; other methods go through ResolveAuth.
-	var auth transport.AuthMethod
+	auth, err := r.resolveGitAuth(ctx, gs)
+	if err != nil {
+		return git.Result{}, err
+	}
+
+	lsCtx, cancel := context.WithTimeout(ctx, lsRemoteTimeout)
+	defer cancel()
+
+	result, err := r.GitClient.LsRemote(lsCtx, gs.Spec.Git.Repo, ref, auth)
+	if err != nil {
+		return result, err
+	}
+
+	// Commit details are fetched once per commit; a failure leaves them empty,
+	// is not retried until the commit changes, and never fails ref resolution.
+	key := types.NamespacedName{Namespace: gs.Namespace, Name: gs.Name}
+	missed, _ := r.commitInfoMisses.Load(key)
+	if (result.Commit != gs.Status.LastSyncCommit || gs.Status.LastSyncCommitInfo == nil) && missed != result.Commit {
+		info, err := r.GitClient.FetchCommit(lsCtx, gs.Spec.Git.Repo, result.Commit, auth)
+		if err != nil {
+			logf.FromContext(ctx).Info("commit details unavailable", "commit", result.Commit, "error", err.Error())
+			r.commitInfoMisses.Store(key, result.Commit)
+		} else {
+			result.Info = info
+			r.commitInfoMisses.Delete(key)
+		}
+	}
+	return result, nil
+}
+
+// resolvedFrom returns the ref (or ref expression) the last resolution was for.
+func resolvedFrom(gs *stokerv1alpha1.GatewaySync) string {
+	if gs.Status.RefExpression != "" {
+		return gs.Status.RefExpression
+	}
+	return gs.Status.LastSyncRef
+}
+
+// resolveGitAuth returns the transport auth for spec.git. GitHub App uses
+// cached tokens; other methods go through ResolveAuth.
+func (r *GatewaySyncReconciler) resolveGitAuth(ctx context.Context, gs *stokerv1alpha1.GatewaySync) (transport.AuthMethod, error) {
 	if gs.Spec.Git.Auth != nil && gs.Spec.Git.Auth.GitHubApp != nil {
 		token, err := r.resolveGitHubAppToken(ctx, gs)
 		if err != nil {
-			return git.Result{}, fmt.Errorf("resolving GitHub App auth: %w", err)
+			return nil, fmt.Errorf("resolving GitHub App auth: %w", err)
 		}
-		auth = &gogithttp.BasicAuth{
+		return &gogithttp.BasicAuth{
 			Username: "x-access-token",
 			Password: token,
-		}
-	} else {
-		var err error
-		auth, err = git.ResolveAuth(ctx, r.Client, gs.Namespace, gs.Spec.Git.Auth)
-		if err != nil {
-			return git.Result{}, fmt.Errorf("resolving git auth: %w", err)
-		}
+		}, nil
+	}
+	auth, err := git.ResolveAuth(ctx, r.Client, gs.Namespace, gs.Spec.Git.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("resolving git auth: %w", err)
 	}
+	return auth, nil
+}
 
-	lsCtx, cancel := context.WithTimeout(ctx, lsRemoteTimeout)
+// needsVerification reports whether commit must be (re)verified: verification is
+// configured and commit is not the one last verified for the current generation.
+func (r *GatewaySyncReconciler) needsVerification(gs *stokerv1alpha1.GatewaySync, commit string) bool {
+	if gs.Spec.Git.Verification == nil {
+		return false
+	}
+	c := meta.FindStatusCondition(gs.Status.Conditions, conditions.TypeCommitVerified)
+	return c == nil || c.Status != metav1.ConditionTrue || c.ObservedGeneration != gs.Generation ||
+		commit != gs.Status.LastSyncCommit
+}
+
+// verifyCommit checks the signature of the resolved commit (or tag) against the
+// trusted keys Secret and returns the signer.
+func (r *GatewaySyncReconciler) verifyCommit(ctx context.Context, gs *stokerv1alpha1.GatewaySync, result git.Result) (string, error) {
+	v := gs.Spec.Git.Verification
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Name: v.TrustedKeysSecretName, Namespace: gs.Namespace}
+	if err := r.Get(ctx, key, secret); err != nil {
+		return "", fmt.Errorf("trusted keys secret %q not found: %w", key.Name, err)
+	}
+	keys, err := git.ParseTrustedKeys(secret.Data)
+	if err != nil {
+		return "", fmt.Errorf("trusted keys secret %q: %w", key.Name, err)
+	}
+
+	auth, err := r.resolveGitAuth(ctx, gs)
+	if err != nil {
+		return "", err
+	}
+	verifyCtx, cancel := context.WithTimeout(ctx, lsRemoteTimeout)
 	defer cancel()
+	return r.GitClient.VerifyRemote(verifyCtx, gs.Spec.Git.Repo, result, verificationPolicy(gs), keys, auth)
+}
 
-	return r.GitClient.LsRemote(lsCtx, gs.Spec.Git.Repo, ref, auth)
+// verificationPolicy returns spec.git.verification.policy, defaulting to SignedCommit.
+func verificationPolicy(gs *stokerv1alpha1.GatewaySync) string {
+	if gs.Spec.Git.Verification == nil || gs.Spec.Git.Verification.Policy == "" {
+		return git.VerifySignedCommit
+	}
+	return gs.Spec.Git.Verification.Policy
 }
 
 // resolveGitHubAppToken returns a cached GitHub App installation token, exchanging
@@ -550,7 +781,7 @@ func (r *GatewaySyncReconciler) cleanupOwnedResources(ctx context.Context, gs *s
 
 	// Clean up metadata, status, and changes ConfigMaps
 	cmNames := []string{
-		fmt.Sprintf("stoker-metadata-%s", gs.Name),
+		stokertypes.MetadataConfigMapName(gs.Name),
 		fmt.Sprintf("stoker-status-%s", gs.Name),
 		fmt.Sprintf("stoker-changes-%s", gs.Name),
 	}
@@ -570,10 +801,22 @@ func (r *GatewaySyncReconciler) cleanupOwnedResources(ctx context.Context, gs *s
 		log.Info("deleted ConfigMap", "name", name)
 	}
 
+	// Clean up per-gateway status ConfigMaps written by agents.
+	statusCMs, err := r.listGatewayStatusConfigMaps(ctx, gs)
+	if err != nil {
+		return err
+	}
+	for i := range statusCMs {
+		if err := r.Delete(ctx, &statusCMs[i]); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("deleting ConfigMap %s: %w", statusCMs[i].Name, err)
+		}
+		log.Info("deleted ConfigMap", "name", statusCMs[i].Name)
+	}
+
 	// Clean up GitHub App token Secret (if one was created for this CR).
 	tokenSecretName := fmt.Sprintf("stoker-github-token-%s", gs.Name)
 	tokenSecret := &corev1.Secret{}
-	err := r.Get(ctx, types.NamespacedName{Name: tokenSecretName, Namespace: gs.Namespace}, tokenSecret)
+	err = r.Get(ctx, types.NamespacedName{Name: tokenSecretName, Namespace: gs.Namespace}, tokenSecret)
 	if err == nil {
 		if err := r.Delete(ctx, tokenSecret); err != nil && !errors.IsNotFound(err) {
 			return fmt.Errorf("deleting token Secret %s: %w", tokenSecretName, err)
@@ -583,7 +826,8 @@ func (r *GatewaySyncReconciler) cleanupOwnedResources(ctx context.Context, gs *s
 		return fmt.Errorf("getting token Secret %s: %w", tokenSecretName, err)
 	}
 
-	return nil
+	// Clean up RoleBindings and mirrored Secrets in gateway namespaces.
+	return r.cleanupRemoteResources(ctx, gs)
 }
 
 // validateGitSecrets checks that git auth secrets exist (if configured).
@@ -630,27 +874,84 @@ func (r *GatewaySyncReconciler) validateAPIKeySecret(ctx context.Context, gs *st
 	return nil
 }
 
-// shortCommit returns the first 7 characters of a commit SHA, or the full string if shorter.
-func shortCommit(sha string) string {
-	if len(sha) > 7 {
-		return sha[:7]
+// validateGatewayCABundle checks that the referenced gateway CA bundle Secret or ConfigMap exists.
+func (r *GatewaySyncReconciler) validateGatewayCABundle(ctx context.Context, gs *stokerv1alpha1.GatewaySync) error {
+	if gs.Spec.Gateway.TLSTrust == nil || gs.Spec.Gateway.TLSTrust.CABundle == nil {
+		return nil
+	}
+	src := gs.Spec.Gateway.TLSTrust.CABundle
+	key := types.NamespacedName{Namespace: gs.Namespace}
+
+	if src.SecretRef != nil {
+		key.Name = src.SecretRef.Name
+		if err := r.Get(ctx, key, &corev1.Secret{}); err != nil {
+			return fmt.Errorf("gateway CA bundle secret %q not found: %w", key.Name, err)
+		}
+	}
+	if src.ConfigMapRef != nil {
+		key.Name = src.ConfigMapRef.Name
+		if err := r.Get(ctx, key, &corev1.ConfigMap{}); err != nil {
+			return fmt.Errorf("gateway CA bundle configmap %q not found: %w", key.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateGatewaySecrets checks the API key secret and any gateway CA bundle source.
+func (r *GatewaySyncReconciler) validateGatewaySecrets(ctx context.Context, gs *stokerv1alpha1.GatewaySync) error {
+	if err := r.validateAPIKeySecret(ctx, gs); err != nil {
+		return err
+	}
+	return r.validateGatewayCABundle(ctx, gs)
+}
+
+// verificationSubject names what the verification policy checks, for condition messages.
+func verificationSubject(gs *stokerv1alpha1.GatewaySync) string {
+	if verificationPolicy(gs) == git.VerifySignedTag {
+		return "Tag for commit"
 	}
-	return sha
+	return "Commit"
 }
 
 // ensureMetadataConfigMap creates or updates the metadata ConfigMap that signals agents.
-func (r *GatewaySyncReconciler) ensureMetadataConfigMap(ctx context.Context, gs *stokerv1alpha1.GatewaySync, result git.Result) error {
-	cmName := fmt.Sprintf("stoker-metadata-%s", gs.Name)
+func (r *GatewaySyncReconciler) ensureMetadataConfigMap(ctx context.Context, gs *stokerv1alpha1.GatewaySync, result git.Result, profiles map[string]syncProfile) error {
+	cmName := stokertypes.MetadataConfigMapName(gs.Name)
 	cm := &corev1.ConfigMap{}
 	key := types.NamespacedName{Name: cmName, Namespace: gs.Namespace}
 
+	commit, ref, targets := rolloutTargets(gs, result)
 	data := map[string]string{
-		"commit": result.Commit,
-		"ref":    result.Ref,
+		"commit": commit,
+		"ref":    ref,
 		"gitURL": gs.Spec.Git.Repo,
 		"paused": fmt.Sprintf("%t", gs.Spec.Paused),
 	}
 
+	// Gateways already updated by an unfinished rollout follow their own target.
+	if len(targets) > 0 {
+		targetsJSON, err := json.Marshal(targets)
+		if err != nil {
+			return fmt.Errorf("serializing gateway targets: %w", err)
+		}
+		data["gatewayTargets"] = string(targetsJSON)
+	}
+
+	// Agents defer applying new commits on the same schedule, unless overridden.
+	if gs.Spec.Sync.Schedule != nil {
+		scheduleJSON, err := json.Marshal(schedule.Spec(gs.Spec.Sync.Schedule))
+		if err != nil {
+			return fmt.Errorf("serializing schedule: %w", err)
+		}
+		data["schedule"] = string(scheduleJSON)
+		if gs.Annotations[stokertypes.AnnotationScheduleOverride] != "" {
+			data["scheduleOverride"] = "true"
+		}
+	}
+	// A rollback target is an emergency revert; agents apply it outside windows too.
+	if rollbackActive(gs) {
+		data["rollback"] = "true"
+	}
+
 	// Include auth type so agent knows which credential source to use.
 	data["authType"] = resolveAuthType(gs.Spec.Git.Auth)
 
@@ -667,8 +968,7 @@ func (r *GatewaySyncReconciler) ensureMetadataConfigMap(ctx context.Context, gs
 	}
 
 	// Serialize resolved profiles as JSON.
-	profiles := r.resolveProfiles(gs)
-	profilesJSON, err := json.Marshal(profiles)
+	profilesJSON, err := json.Marshal(r.resolveProfiles(gs, profiles))
 	if err != nil {
 		return fmt.Errorf("serializing profiles: %w", err)
 	}
@@ -837,6 +1137,18 @@ func (r *GatewaySyncReconciler) requeueInterval(gs *stokerv1alpha1.GatewaySync)
 		}
 	}
 
+	if ro := gs.Status.Rollout; ro != nil && ro.Phase == rolloutProgressing &&
+		(interval == 0 || rolloutRequeueInterval < interval) {
+		interval = rolloutRequeueInterval
+	}
+
+	// Publish a deferred commit as soon as the next window opens.
+	if gs.Status.DeferredCommit != "" && gs.Status.NextWindow != nil {
+		if untilOpen := time.Until(gs.Status.NextWindow.Time) + time.Second; untilOpen > 0 && (interval == 0 || untilOpen < interval) {
+			interval = untilOpen
+		}
+	}
+
 	return interval
 }
 
@@ -849,13 +1161,17 @@ func (r *GatewaySyncReconciler) requeueInterval(gs *stokerv1alpha1.GatewaySync)
 // pinning the controller to an old ref if a future webhook fails to fire.
 //
 // Comparison uses "v"-prefix normalization so "v2.2.3" (git tag) matches "2.2.3"
-// (values.yaml convention).
+// (values.yaml convention). With a ref expression the annotation is always
+// cleared, since it only triggers re-evaluation.
 func (r *GatewaySyncReconciler) clearRequestedRefIfCaughtUp(ctx context.Context, req ctrl.Request, gs *stokerv1alpha1.GatewaySync) {
 	annRef, ok := gs.Annotations[stokertypes.AnnotationRequestedRef]
 	if !ok || annRef == "" {
 		return
 	}
-	if strings.TrimPrefix(annRef, "v") != strings.TrimPrefix(gs.Spec.Git.Ref, "v") {
+	// A ref expression ignores the annotation, so it has done its job once
+	// the expression is re-evaluated.
+	if !git.IsRefExpression(gs.Spec.Git.Ref) &&
+		strings.TrimPrefix(annRef, "v") != strings.TrimPrefix(gs.Spec.Git.Ref, "v") {
 		return
 	}
 	log := logf.FromContext(ctx)
@@ -876,6 +1192,32 @@ func (r *GatewaySyncReconciler) clearRequestedRefIfCaughtUp(ctx context.Context,
 	}
 }
 
+// removeAnnotations removes keys from the CR's annotations once the
+// reconcile that read them has patched status. Values changed since gs was
+// read are left for the next reconcile.
+func (r *GatewaySyncReconciler) removeAnnotations(ctx context.Context, req ctrl.Request, gs *stokerv1alpha1.GatewaySync, keys ...string) {
+	log := logf.FromContext(ctx)
+	// Re-fetch to get latest resourceVersion and avoid a conflict with the status patch.
+	var fresh stokerv1alpha1.GatewaySync
+	if err := r.Get(ctx, req.NamespacedName, &fresh); err != nil {
+		return
+	}
+	freshBase := fresh.DeepCopy()
+	for _, key := range keys {
+		if v, ok := fresh.Annotations[key]; ok && v == gs.Annotations[key] {
+			delete(fresh.Annotations, key)
+		}
+	}
+	if len(fresh.Annotations) == len(freshBase.Annotations) {
+		return
+	}
+	if err := r.Patch(ctx, &fresh, client.MergeFrom(freshBase)); err != nil {
+		log.Error(err, "failed to clear annotations", "annotations", keys)
+	} else {
+		log.Info("cleared annotations", "annotations", keys)
+	}
+}
+
 // conditionHasStatus returns true if the conditions slice already contains
 // a condition of the given type with the given status.
 func conditionHasStatus(conds []metav1.Condition, condType string, status metav1.ConditionStatus) bool {
@@ -919,7 +1261,11 @@ func (r *GatewaySyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Secret{}).
 		Owns(&rbacv1.RoleBinding{}).
+		Owns(&rbacv1.Role{}).
 		Watches(&corev1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.findGatewaySyncForPod)).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.findGatewaySyncsForSecret)).
+		Watches(&stokerv1alpha1.SyncProfile{}, handler.EnqueueRequestsFromMapFunc(r.findGatewaySyncsForProfile)).
+		Watches(&stokerv1alpha1.ClusterSyncProfile{}, handler.EnqueueRequestsFromMapFunc(r.findGatewaySyncsForProfile)).
 		WithOptions(controller.Options{MaxConcurrentReconciles: 5}).
 		Named("gatewaysync").
 		Complete(r)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"testing"
 	"time"
 
 	"github.com/go-git/go-git/v5/plumbing/transport"
@@ -11,6 +12,7 @@ import (
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -27,19 +29,46 @@ import (
 type fakeGitClient struct {
 	result git.Result
 	err    error
-	calls  int
+	calls  int    // LsRemote and CloneOrFetch calls
+	ref    string // last ref passed to LsRemote
+
+	verifyErr error
+	verified  int // VerifyRemote calls
+
+	diff git.DiffSummary // returned by DiffCommits
+
+	fetchErr error // returned by FetchCommit
+	fetches  int   // FetchCommit calls
 }
 
-func (f *fakeGitClient) LsRemote(_ context.Context, _, _ string, _ transport.AuthMethod) (git.Result, error) {
+func (f *fakeGitClient) LsRemote(_ context.Context, _, ref string, _ transport.AuthMethod) (git.Result, error) {
 	f.calls++
+	f.ref = ref
 	return f.result, f.err
 }
 
-func (f *fakeGitClient) CloneOrFetch(_ context.Context, _, _, _ string, _ transport.AuthMethod) (git.Result, error) {
+func (f *fakeGitClient) CloneOrFetch(_ context.Context, _, _, _ string, _ []string, _ transport.AuthMethod) (git.Result, error) {
 	f.calls++
 	return f.result, f.err
 }
 
+func (f *fakeGitClient) FetchCommit(_ context.Context, _, _ string, _ transport.AuthMethod) (git.CommitInfo, error) {
+	f.fetches++
+	if f.fetchErr != nil {
+		return git.CommitInfo{}, f.fetchErr
+	}
+	return f.result.Info, f.err
+}
+
+func (f *fakeGitClient) DiffCommits(_ context.Context, _, _, _ string, _ transport.AuthMethod) (git.DiffSummary, error) {
+	return f.diff, f.err
+}
+
+func (f *fakeGitClient) VerifyRemote(_ context.Context, _ string, _ git.Result, _ string, _ *git.TrustedKeys, _ transport.AuthMethod) (string, error) {
+	f.verified++
+	return "ci@example.com", f.verifyErr
+}
+
 // helper to create the reconciler with a fake git client and event recorder
 func newReconciler(gitClient git.Client) *GatewaySyncReconciler {
 	return &GatewaySyncReconciler{
@@ -229,7 +258,9 @@ var _ = Describe("GatewaySync Controller", func() {
 		})
 
 		It("should set RefResolved condition and create metadata ConfigMap after ref resolution", func() {
-			gitClient := &fakeGitClient{result: git.Result{Commit: "abc123def", Ref: "main"}}
+			gitClient := &fakeGitClient{result: git.Result{Commit: "abc123def", Ref: "main", Info: git.CommitInfo{
+				Author: "Jane Doe", Committer: "CI Bot", Subject: "Raise tank alarm limits", Time: time.Now(),
+			}}}
 			r := newReconciler(gitClient)
 
 			// Reconcile 1: add finalizer
@@ -249,6 +280,9 @@ var _ = Describe("GatewaySync Controller", func() {
 			Expect(cr.Status.RefResolutionStatus).To(Equal("Resolved"))
 			Expect(cr.Status.LastSyncTime).NotTo(BeNil())
 			Expect(cr.Status.ProfileCount).To(Equal(int32(1)))
+			Expect(cr.Status.LastSyncCommitInfo).NotTo(BeNil())
+			Expect(cr.Status.LastSyncCommitInfo.Author).To(Equal("Jane Doe"))
+			Expect(cr.Status.LastSyncCommitInfo.Subject).To(Equal("Raise tank alarm limits"))
 
 			// Verify RefResolved condition
 			var refResolvedCond *metav1.Condition
@@ -316,6 +350,124 @@ var _ = Describe("GatewaySync Controller", func() {
 				"requested-ref annotation should be cleared once spec.git.ref matches (v-prefix normalized)")
 		})
 
+		It("should validate inline profiles while a profile reference is unresolved", func() {
+			cr := &stokerv1alpha1.GatewaySync{}
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			cr.Spec.Sync.ProfileRefs = []stokerv1alpha1.ProfileRef{{Name: "missing-profile", As: "site"}}
+			cr.Spec.Sync.Profiles["default"] = stokerv1alpha1.SyncProfileSpec{
+				Mappings: []stokerv1alpha1.SyncMapping{{Source: "../secrets", Destination: "config"}},
+			}
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+
+			r := newReconciler(&fakeGitClient{result: git.Result{Commit: "abc123", Ref: "main"}})
+			for range 2 {
+				_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			cond := meta.FindStatusCondition(cr.Status.Conditions, conditions.TypeProfilesValid)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(conditions.ReasonProfilesInvalid))
+			Expect(cond.Message).To(ContainSubstring("profiles[default].mappings[0].source"))
+			Expect(cond.Message).To(ContainSubstring("missing-profile"))
+		})
+
+		It("should report the expression and chosen tag for a ref expression", func() {
+			cr := &stokerv1alpha1.GatewaySync{}
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			cr.Spec.Git.Ref = "semver:~2.3"
+			if cr.Annotations == nil {
+				cr.Annotations = make(map[string]string)
+			}
+			// A webhook push only triggers re-evaluation of the expression.
+			cr.Annotations[stokertypes.AnnotationRequestedRef] = "v9.9.9"
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+
+			gitClient := &fakeGitClient{result: git.Result{Commit: "cafe2345", Ref: "v2.3.4"}}
+			r := newReconciler(gitClient)
+			for range 2 {
+				_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(gitClient.ref).To(Equal("semver:~2.3"))
+
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			Expect(cr.Status.RefExpression).To(Equal("semver:~2.3"))
+			Expect(cr.Status.LastSyncRef).To(Equal("v2.3.4"))
+			Expect(cr.Annotations).NotTo(HaveKey(stokertypes.AnnotationRequestedRef))
+
+			cm := &corev1.ConfigMap{}
+			cmNN := types.NamespacedName{Name: fmt.Sprintf("stoker-metadata-%s", resourceName), Namespace: "default"}
+			Expect(k8sClient.Get(ctx, cmNN, cm)).To(Succeed())
+			Expect(cm.Data["ref"]).To(Equal("v2.3.4"))
+
+			// A status-triggered reconcile within the polling interval uses the cache.
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gitClient.calls).To(Equal(1))
+
+			// A new tag on the same commit is reported once the cache expires.
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			cr.Status.LastSyncTime = &metav1.Time{Time: time.Now().Add(-2 * time.Minute)}
+			Expect(k8sClient.Status().Update(ctx, cr)).To(Succeed())
+			gitClient.result.Ref = "v2.3.5"
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			Expect(cr.Status.LastSyncCommit).To(Equal("cafe2345"))
+			Expect(cr.Status.LastSyncRef).To(Equal("v2.3.5"))
+			Expect(k8sClient.Get(ctx, cmNN, cm)).To(Succeed())
+			Expect(cm.Data["ref"]).To(Equal("v2.3.5"))
+		})
+
+		It("should hold a new commit until it is approved", func() {
+			gitClient := &fakeGitClient{result: git.Result{Commit: approvedSHA, Ref: "main"}}
+			r := newReconciler(gitClient)
+			for range 2 {
+				_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			cr := &stokerv1alpha1.GatewaySync{}
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			cr.Spec.Approval = &stokerv1alpha1.ApprovalSpec{}
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+
+			gitClient.result = git.Result{Commit: pendingSHA, Ref: "main"}
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			Expect(cr.Status.LastSyncCommit).To(Equal(approvedSHA))
+			Expect(cr.Status.PendingCommit).NotTo(BeNil())
+			Expect(cr.Status.PendingCommit.Commit).To(Equal(pendingSHA))
+
+			cm := &corev1.ConfigMap{}
+			cmNN := types.NamespacedName{Name: fmt.Sprintf("stoker-metadata-%s", resourceName), Namespace: "default"}
+			Expect(k8sClient.Get(ctx, cmNN, cm)).To(Succeed())
+			Expect(cm.Data["commit"]).To(Equal(approvedSHA))
+			Expect(cm.Data["ref"]).To(Equal(approvedSHA))
+
+			if cr.Annotations == nil {
+				cr.Annotations = make(map[string]string)
+			}
+			cr.Annotations[stokertypes.AnnotationApprovedBy] = "jane"
+			cr.Annotations[stokertypes.AnnotationApprovedCommit] = pendingSHA[:7]
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			Expect(cr.Status.LastSyncCommit).To(Equal(pendingSHA))
+			Expect(cr.Status.PendingCommit).To(BeNil())
+			Expect(cr.Status.LastApproval.Approver).To(Equal("jane"))
+			Expect(cr.Annotations).NotTo(HaveKey(stokertypes.AnnotationApprovedBy))
+			Expect(k8sClient.Get(ctx, cmNN, cm)).To(Succeed())
+			Expect(cm.Data["commit"]).To(Equal(pendingSHA))
+		})
+
 		It("should set error condition when ref resolution fails", func() {
 			gitClient := &fakeGitClient{err: fmt.Errorf("authentication failed")}
 			r := newReconciler(gitClient)
@@ -347,6 +499,91 @@ var _ = Describe("GatewaySync Controller", func() {
 		})
 	})
 
+	Context("Signature verification", func() {
+		const resourceName = "test-verify"
+		const secretName = "test-secret-verify"
+		const keysSecretName = "test-trusted-keys"
+		ctx := context.Background()
+		nn := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			createAPIKeySecret(ctx, secretName)
+			keys := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: keysSecretName, Namespace: "default"},
+				Data: map[string][]byte{
+					"allowed_signers": []byte("ci@example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIC7xaAhUOnqkAj0MxzuhPNm0jqT9Xgl0Ld+8aLzusHHz"),
+				},
+			}
+			if err := k8sClient.Create(ctx, keys); err != nil && !errors.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			createCR(ctx, resourceName, secretName)
+			cr := &stokerv1alpha1.GatewaySync{}
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			cr.Spec.Git.Verification = &stokerv1alpha1.GitVerificationSpec{TrustedKeysSecretName: keysSecretName}
+			Expect(k8sClient.Update(ctx, cr)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			cr := &stokerv1alpha1.GatewaySync{}
+			if err := k8sClient.Get(ctx, nn, cr); err == nil {
+				controllerutil.RemoveFinalizer(cr, stokertypes.Finalizer)
+				_ = k8sClient.Update(ctx, cr)
+				_ = k8sClient.Delete(ctx, cr)
+			}
+			cm := &corev1.ConfigMap{}
+			cmNN := types.NamespacedName{Name: fmt.Sprintf("stoker-metadata-%s", resourceName), Namespace: "default"}
+			if err := k8sClient.Get(ctx, cmNN, cm); err == nil {
+				_ = k8sClient.Delete(ctx, cm)
+			}
+		})
+
+		It("should publish a verified commit and not re-verify it", func() {
+			gitClient := &fakeGitClient{result: git.Result{Commit: "abc123def", Ref: "main"}}
+			r := newReconciler(gitClient)
+
+			for range 3 {
+				_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			cr := &stokerv1alpha1.GatewaySync{}
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			Expect(cr.Status.LastSyncCommit).To(Equal("abc123def"))
+			cond := meta.FindStatusCondition(cr.Status.Conditions, conditions.TypeCommitVerified)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Message).To(ContainSubstring("ci@example.com"))
+			Expect(gitClient.verified).To(Equal(1))
+		})
+
+		It("should refuse to publish an unverified commit", func() {
+			gitClient := &fakeGitClient{
+				result:    git.Result{Commit: "abc123def", Ref: "main"},
+				verifyErr: fmt.Errorf("commit abc123def: %w", git.ErrUnsigned),
+			}
+			r := newReconciler(gitClient)
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+			Expect(err).NotTo(HaveOccurred())
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+
+			cr := &stokerv1alpha1.GatewaySync{}
+			Expect(k8sClient.Get(ctx, nn, cr)).To(Succeed())
+			Expect(cr.Status.LastSyncCommit).To(BeEmpty())
+			cond := meta.FindStatusCondition(cr.Status.Conditions, conditions.TypeCommitVerified)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(conditions.ReasonSignatureVerificationFailed))
+
+			cm := &corev1.ConfigMap{}
+			cmNN := types.NamespacedName{Name: fmt.Sprintf("stoker-metadata-%s", resourceName), Namespace: "default"}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, cmNN, cm))).To(BeTrue())
+		})
+	})
+
 	Context("Secret validation", func() {
 		const resourceName = "test-validation"
 		ctx := context.Background()
@@ -999,6 +1236,62 @@ var _ = Describe("GatewaySync Controller", func() {
 		})
 	})
 
+	Context("Gateway TLS verification condition", func() {
+		const resourceName = "test-tls-condition"
+		const secretName = "test-secret-tls-condition"
+		ctx := context.Background()
+		nn := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			createAPIKeySecret(ctx, secretName)
+		})
+
+		AfterEach(func() {
+			cr := &stokerv1alpha1.GatewaySync{}
+			if err := k8sClient.Get(ctx, nn, cr); err == nil {
+				controllerutil.RemoveFinalizer(cr, stokertypes.Finalizer)
+				_ = k8sClient.Update(ctx, cr)
+				_ = k8sClient.Delete(ctx, cr)
+			}
+			cm := &corev1.ConfigMap{}
+			if err := k8sClient.Get(ctx, types.NamespacedName{Name: "stoker-metadata-" + resourceName, Namespace: "default"}, cm); err == nil {
+				_ = k8sClient.Delete(ctx, cm)
+			}
+		})
+
+		It("should remove GatewayTLSVerification when TLS is turned off", func() {
+			cr := &stokerv1alpha1.GatewaySync{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: stokerv1alpha1.GatewaySyncSpec{
+					Git:     stokerv1alpha1.GitSpec{Repo: "https://github.com/example/test.git", Ref: "main"},
+					Gateway: stokerv1alpha1.GatewaySpec{API: stokerv1alpha1.GatewayAPISpec{SecretName: secretName, SecretKey: "apiKey"}},
+					Sync: stokerv1alpha1.SyncSpec{
+						Profiles: map[string]stokerv1alpha1.SyncProfileSpec{
+							"default": {Mappings: []stokerv1alpha1.SyncMapping{{Source: "config", Destination: "config"}}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+
+			r := newReconciler(&fakeGitClient{result: git.Result{Commit: "abc123", Ref: "main"}})
+			reconcileToSteadyState(ctx, nn, r)
+
+			updated := &stokerv1alpha1.GatewaySync{}
+			Expect(k8sClient.Get(ctx, nn, updated)).To(Succeed())
+			Expect(meta.FindStatusCondition(updated.Status.Conditions, conditions.TypeGatewayTLSVerification)).NotTo(BeNil())
+
+			tls := false
+			updated.Spec.Gateway.TLS = &tls
+			Expect(k8sClient.Update(ctx, updated)).To(Succeed())
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: nn})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, nn, updated)).To(Succeed())
+			Expect(meta.FindStatusCondition(updated.Status.Conditions, conditions.TypeGatewayTLSVerification)).To(BeNil())
+		})
+	})
+
 	Context("Pod to CR mapping", func() {
 		It("should map annotated pod to GatewaySync reconcile request", func() {
 			r := newReconciler(&fakeGitClient{})
@@ -1157,3 +1450,32 @@ var _ = Describe("GatewaySync Controller", func() {
 		})
 	})
 })
+
+func TestResolveRef_CommitInfoFailureNotRetried(t *testing.T) {
+	gs := approvalFixture()
+	gs.Spec.Approval = nil
+	fake := &fakeGitClient{result: git.Result{Commit: pendingSHA, Ref: "main"}, fetchErr: fmt.Errorf("fetch by SHA refused")}
+	r := &GatewaySyncReconciler{GitClient: fake}
+	ctx := context.Background()
+
+	for range 3 {
+		result, err := r.resolveRef(ctx, gs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Info.IsZero() {
+			t.Errorf("expected no commit details, got %+v", result.Info)
+		}
+	}
+	if fake.fetches != 1 {
+		t.Errorf("a refused commit should be fetched once, got %d fetches", fake.fetches)
+	}
+
+	// A new commit is fetched again.
+	fake.result.Commit = approvedSHA
+	fake.fetchErr = nil
+	fake.result.Info = git.CommitInfo{Author: "Jane Doe"}
+	if result, err := r.resolveRef(ctx, gs); err != nil || result.Info.Author != "Jane Doe" || fake.fetches != 2 {
+		t.Errorf("expected details for the new commit, got %+v (err=%v, fetches=%d)", result.Info, err, fake.fetches)
+	}
+}
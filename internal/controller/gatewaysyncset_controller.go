@@ -0,0 +1,335 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// GatewaySyncSetReconciler reconciles a GatewaySyncSet object: it creates a
+// GatewaySync in each selected namespace, prunes children whose namespace is
+// no longer selected, and aggregates the children's conditions.
+type GatewaySyncSetReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=stoker.io,resources=gatewaysyncsets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=stoker.io,resources=gatewaysyncsets/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=stoker.io,resources=gatewaysyncsets/finalizers,verbs=update
+// +kubebuilder:rbac:groups=stoker.io,resources=gatewaysyncs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+func (r *GatewaySyncSetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	var set stokerv1alpha1.GatewaySyncSet
+	if err := r.Get(ctx, req.NamespacedName, &set); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !set.DeletionTimestamp.IsZero() {
+		// Children carry an owner reference and are garbage collected.
+		return ctrl.Result{}, nil
+	}
+	base := set.DeepCopy()
+
+	selector, err := metav1.LabelSelectorAsSelector(&set.Spec.NamespaceSelector)
+	if err != nil {
+		// Retrying cannot help; the spec update that fixes it triggers a reconcile.
+		// Existing children are kept rather than pruned against a selector
+		// that cannot be evaluated.
+		set.Status.ObservedGeneration = set.Generation
+		meta.SetStatusCondition(&set.Status.Conditions, metav1.Condition{
+			Type:               conditions.TypeReady,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: set.Generation,
+			Reason:             conditions.ReasonNamespaceSelectorInvalid,
+			Message:            fmt.Sprintf("spec.namespaceSelector: %v", err),
+		})
+		if err := r.Status().Patch(ctx, &set, client.MergeFrom(base)); err != nil {
+			return ctrl.Result{}, fmt.Errorf("patching status: %w", err)
+		}
+		return ctrl.Result{}, nil
+	}
+	var namespaces corev1.NamespaceList
+	if err := r.List(ctx, &namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return ctrl.Result{}, fmt.Errorf("listing namespaces: %w", err)
+	}
+
+	previous := make(map[string]stokerv1alpha1.GatewaySyncSetChild, len(set.Status.Children))
+	for _, c := range set.Status.Children {
+		previous[c.Namespace] = c
+	}
+
+	name := childName(&set)
+	desired := make(map[types.NamespacedName]bool, len(namespaces.Items))
+	children := make([]stokerv1alpha1.GatewaySyncSetChild, 0, len(namespaces.Items))
+	var errs []error
+
+	for i := range namespaces.Items {
+		ns := &namespaces.Items[i]
+		if ns.Status.Phase == corev1.NamespaceTerminating {
+			continue
+		}
+		key := types.NamespacedName{Namespace: ns.Name, Name: name}
+		desired[key] = true
+
+		child, err := r.applyChild(ctx, &set, ns, key)
+		entry := stokerv1alpha1.GatewaySyncSetChild{Namespace: ns.Name, Name: name}
+		switch {
+		case errors.Is(err, errChildConflict):
+			entry.Message = err.Error()
+			if previous[ns.Name].Message != entry.Message {
+				r.Recorder.Eventf(&set, corev1.EventTypeWarning, conditions.ReasonChildConflict,
+					"GatewaySync %s already exists and is not owned by this set", key)
+			}
+		case err != nil:
+			entry.Message = err.Error()
+			errs = append(errs, fmt.Errorf("namespace %s: %w", ns.Name, err))
+		default:
+			childStatus(child, &entry)
+		}
+		children = append(children, entry)
+	}
+
+	if err := r.pruneChildren(ctx, &set, desired); err != nil {
+		errs = append(errs, err)
+	}
+
+	slices.SortFunc(children, func(a, b stokerv1alpha1.GatewaySyncSetChild) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+	set.Status.Children = children
+	set.Status.ObservedGeneration = set.Generation
+	aggregateChildConditions(&set)
+
+	if err := r.Status().Patch(ctx, &set, client.MergeFrom(base)); err != nil {
+		return ctrl.Result{}, fmt.Errorf("patching status: %w", err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		log.Error(err, "failed to apply GatewaySyncSet children")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// errChildConflict marks a GatewaySync that exists but is not controlled by the set.
+var errChildConflict = errors.New("GatewaySync exists and is not owned by this GatewaySyncSet")
+
+// childName is the name of the GatewaySync stamped into each namespace.
+func childName(set *stokerv1alpha1.GatewaySyncSet) string {
+	if set.Spec.Template.Metadata.Name != "" {
+		return set.Spec.Template.Metadata.Name
+	}
+	return set.Name
+}
+
+// applyChild creates or updates the set's GatewaySync in ns. A GatewaySync of
+// the same name that the set does not control is left untouched.
+func (r *GatewaySyncSetReconciler) applyChild(ctx context.Context, set *stokerv1alpha1.GatewaySyncSet, ns *corev1.Namespace, key types.NamespacedName) (*stokerv1alpha1.GatewaySync, error) {
+	child := &stokerv1alpha1.GatewaySync{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, child, func() error {
+		if child.ResourceVersion != "" && !metav1.IsControlledBy(child, set) {
+			return errChildConflict
+		}
+		tmpl := set.Spec.Template
+		child.Labels = mergeStringMaps(child.Labels, tmpl.Metadata.Labels)
+		child.Labels[stokertypes.LabelGatewaySyncSet] = set.Name
+		if len(tmpl.Metadata.Annotations) > 0 {
+			child.Annotations = mergeStringMaps(child.Annotations, tmpl.Metadata.Annotations)
+		}
+		child.Spec = *tmpl.Spec.DeepCopy()
+		child.Spec.Sync.Defaults.Vars = namespaceVars(set, ns)
+		return controllerutil.SetControllerReference(set, child, r.Scheme)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if op == controllerutil.OperationResultCreated {
+		r.Recorder.Eventf(set, corev1.EventTypeNormal, conditions.ReasonChildCreated,
+			"Created GatewaySync %s", key)
+	}
+	return child, nil
+}
+
+// namespaceVars merges, in increasing precedence, the template's
+// spec.sync.defaults.vars, the parameters read from ns, and
+// spec.namespaceVars[ns].
+func namespaceVars(set *stokerv1alpha1.GatewaySyncSet, ns *corev1.Namespace) map[string]string {
+	vars := maps.Clone(set.Spec.Template.Spec.Sync.Defaults.Vars)
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	for _, p := range set.Spec.Parameters {
+		var v string
+		var ok bool
+		if p.FromLabel != "" {
+			v, ok = ns.Labels[p.FromLabel]
+		} else {
+			v, ok = ns.Annotations[p.FromAnnotation]
+		}
+		switch {
+		case ok:
+			vars[p.Name] = v
+		case p.Default != "":
+			vars[p.Name] = p.Default
+		}
+	}
+	maps.Copy(vars, set.Spec.NamespaceVars[ns.Name])
+	if len(vars) == 0 {
+		return nil
+	}
+	return vars
+}
+
+// mergeStringMaps copies src over dst, allocating dst if needed.
+func mergeStringMaps(dst, src map[string]string) map[string]string {
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	maps.Copy(dst, src)
+	return dst
+}
+
+// pruneChildren deletes GatewaySyncs controlled by set that are not in desired:
+// the namespace is no longer selected, or the template name changed.
+func (r *GatewaySyncSetReconciler) pruneChildren(ctx context.Context, set *stokerv1alpha1.GatewaySyncSet, desired map[types.NamespacedName]bool) error {
+	var list stokerv1alpha1.GatewaySyncList
+	if err := r.List(ctx, &list, client.MatchingLabels{stokertypes.LabelGatewaySyncSet: set.Name}); err != nil {
+		return fmt.Errorf("listing children: %w", err)
+	}
+	var errs []error
+	for i := range list.Items {
+		child := &list.Items[i]
+		key := types.NamespacedName{Namespace: child.Namespace, Name: child.Name}
+		if desired[key] || !metav1.IsControlledBy(child, set) {
+			continue
+		}
+		if err := r.Delete(ctx, child); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("deleting GatewaySync %s: %w", key, err))
+			continue
+		}
+		r.Recorder.Eventf(set, corev1.EventTypeNormal, conditions.ReasonChildDeleted,
+			"Deleted GatewaySync %s", key)
+	}
+	return errors.Join(errs...)
+}
+
+// childStatus copies the child's Ready and AllGatewaysSynced conditions into entry.
+func childStatus(child *stokerv1alpha1.GatewaySync, entry *stokerv1alpha1.GatewaySyncSetChild) {
+	entry.Ready, entry.AllGatewaysSynced = metav1.ConditionUnknown, metav1.ConditionUnknown
+	if c := meta.FindStatusCondition(child.Status.Conditions, conditions.TypeReady); c != nil {
+		entry.Ready, entry.Message = c.Status, c.Message
+	}
+	if c := meta.FindStatusCondition(child.Status.Conditions, conditions.TypeAllGatewaysSynced); c != nil {
+		entry.AllGatewaysSynced = c.Status
+	}
+	entry.Commit = child.Status.LastSyncCommitShort
+}
+
+// aggregateChildConditions sets the fleet counters and the Ready and
+// AllGatewaysSynced conditions from status.children. Ready=True only when
+// every selected namespace has a Ready child.
+func aggregateChildConditions(set *stokerv1alpha1.GatewaySyncSet) {
+	var ready, synced int32
+	var notReady []string
+	for _, c := range set.Status.Children {
+		if c.Ready == metav1.ConditionTrue {
+			ready++
+		} else {
+			notReady = append(notReady, c.Namespace)
+		}
+		if c.AllGatewaysSynced == metav1.ConditionTrue {
+			synced++
+		}
+	}
+	total := int32(len(set.Status.Children))
+	set.Status.Namespaces, set.Status.ReadyNamespaces, set.Status.SyncedNamespaces = total, ready, synced
+
+	setCond := func(condType string, status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&set.Status.Conditions, metav1.Condition{
+			Type:               condType,
+			Status:             status,
+			ObservedGeneration: set.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	if total == 0 {
+		setCond(conditions.TypeReady, metav1.ConditionFalse, conditions.ReasonNoNamespacesSelected,
+			"No namespaces match spec.namespaceSelector")
+		setCond(conditions.TypeAllGatewaysSynced, metav1.ConditionFalse, conditions.ReasonNoNamespacesSelected,
+			"0/0 namespaces synced")
+		return
+	}
+
+	if ready == total {
+		setCond(conditions.TypeReady, metav1.ConditionTrue, conditions.ReasonChildrenReady,
+			fmt.Sprintf("%d/%d namespaces ready", ready, total))
+	} else {
+		setCond(conditions.TypeReady, metav1.ConditionFalse, conditions.ReasonChildrenNotReady,
+			fmt.Sprintf("%d/%d namespaces ready (waiting on %s)", ready, total, strings.Join(notReady, ", ")))
+	}
+
+	message := fmt.Sprintf("%d/%d namespaces synced", synced, total)
+	if synced == total {
+		setCond(conditions.TypeAllGatewaysSynced, metav1.ConditionTrue, conditions.ReasonSyncSucceeded, message)
+	} else {
+		setCond(conditions.TypeAllGatewaysSynced, metav1.ConditionFalse, conditions.ReasonSyncInProgress, message)
+	}
+}
+
+// findSetsForNamespace maps a namespace change to the GatewaySyncSets it
+// affects: those whose selector matches the namespace's labels, and those
+// that still have a child in it, since a label change may have deselected it.
+func (r *GatewaySyncSetReconciler) findSetsForNamespace(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list stokerv1alpha1.GatewaySyncSetList
+	if err := r.List(ctx, &list); err != nil {
+		return nil
+	}
+	nsLabels := labels.Set(obj.GetLabels())
+	var requests []reconcile.Request
+	for _, set := range list.Items {
+		hasChild := slices.ContainsFunc(set.Status.Children, func(c stokerv1alpha1.GatewaySyncSetChild) bool {
+			return c.Namespace == obj.GetName()
+		})
+		selector, err := metav1.LabelSelectorAsSelector(&set.Spec.NamespaceSelector)
+		if hasChild || (err == nil && selector.Matches(nsLabels)) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: set.Name}})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GatewaySyncSetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&stokerv1alpha1.GatewaySyncSet{}).
+		Owns(&stokerv1alpha1.GatewaySync{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.findSetsForNamespace)).
+		Named("gatewaysyncset").
+		Complete(r)
+}
@@ -0,0 +1,244 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func setReconciler(t *testing.T, objs ...client.Object) *GatewaySyncSetReconciler {
+	t.Helper()
+	c, s := newFakeClient(t, objs...)
+	return &GatewaySyncSetReconciler{Client: c, Scheme: s, Recorder: record.NewFakeRecorder(20)}
+}
+
+func fleetNamespace(name string, labels, annotations map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, Annotations: annotations}}
+}
+
+func testGatewaySyncSet() *stokerv1alpha1.GatewaySyncSet {
+	return &stokerv1alpha1.GatewaySyncSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "plants", UID: "set-uid", Generation: 1},
+		Spec: stokerv1alpha1.GatewaySyncSetSpec{
+			NamespaceSelector: metav1.LabelSelector{MatchLabels: map[string]string{"fleet": "plants"}},
+			Parameters: []stokerv1alpha1.NamespaceParameter{
+				{Name: "siteCode", FromLabel: "site-code"},
+				{Name: "area", FromAnnotation: "area", Default: "main"},
+			},
+			NamespaceVars: map[string]map[string]string{"plant-2": {"area": "north"}},
+			Template: stokerv1alpha1.GatewaySyncTemplate{
+				Metadata: stokerv1alpha1.GatewaySyncTemplateMeta{Name: "site", Labels: map[string]string{"tier": "plant"}},
+				Spec: stokerv1alpha1.GatewaySyncSpec{
+					Git: stokerv1alpha1.GitSpec{Repo: "https://example.com/repo.git", Ref: "main"},
+					Sync: stokerv1alpha1.SyncSpec{
+						Defaults: stokerv1alpha1.SyncDefaults{Vars: map[string]string{"env": "prod", "area": "none"}},
+						Profiles: map[string]stokerv1alpha1.SyncProfileSpec{"default": {Mappings: libraryMappings("site/")}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func reconcileSet(t *testing.T, r *GatewaySyncSetReconciler) *stokerv1alpha1.GatewaySyncSet {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: "plants"}}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	set := &stokerv1alpha1.GatewaySyncSet{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "plants"}, set); err != nil {
+		t.Fatal(err)
+	}
+	return set
+}
+
+func TestGatewaySyncSet_CreatesChildrenWithVars(t *testing.T) {
+	r := setReconciler(t,
+		testGatewaySyncSet(),
+		fleetNamespace("plant-1", map[string]string{"fleet": "plants", "site-code": "P1"}, map[string]string{"area": "south"}),
+		fleetNamespace("plant-2", map[string]string{"fleet": "plants", "site-code": "P2"}, nil),
+		fleetNamespace("office", nil, nil),
+	)
+	set := reconcileSet(t, r)
+
+	ctx := context.Background()
+	want := map[string]map[string]string{
+		"plant-1": {"env": "prod", "siteCode": "P1", "area": "south"},
+		"plant-2": {"env": "prod", "siteCode": "P2", "area": "north"},
+	}
+	for ns, vars := range want {
+		child := &stokerv1alpha1.GatewaySync{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "site"}, child); err != nil {
+			t.Fatalf("child in %s: %v", ns, err)
+		}
+		for k, v := range vars {
+			if child.Spec.Sync.Defaults.Vars[k] != v {
+				t.Errorf("%s: var %s = %q, want %q", ns, k, child.Spec.Sync.Defaults.Vars[k], v)
+			}
+		}
+		if child.Labels[stokertypes.LabelGatewaySyncSet] != "plants" || child.Labels["tier"] != "plant" {
+			t.Errorf("%s: unexpected labels %v", ns, child.Labels)
+		}
+		if !metav1.IsControlledBy(child, set) {
+			t.Errorf("%s: child should be controlled by the set", ns)
+		}
+	}
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "office", Name: "site"}, &stokerv1alpha1.GatewaySync{}); !apierrors.IsNotFound(err) {
+		t.Errorf("unselected namespace should have no child, got %v", err)
+	}
+
+	if set.Status.Namespaces != 2 || len(set.Status.Children) != 2 || set.Status.Children[0].Namespace != "plant-1" {
+		t.Errorf("unexpected status %+v", set.Status)
+	}
+	if !conditionHasReason(set.Status.Conditions, conditions.TypeReady, conditions.ReasonChildrenNotReady) {
+		t.Errorf("children have not reconciled yet; expected Ready=False, got %+v", set.Status.Conditions)
+	}
+}
+
+func TestGatewaySyncSet_AggregatesChildConditions(t *testing.T) {
+	r := setReconciler(t,
+		testGatewaySyncSet(),
+		fleetNamespace("plant-1", map[string]string{"fleet": "plants"}, nil),
+		fleetNamespace("plant-2", map[string]string{"fleet": "plants"}, nil),
+	)
+	reconcileSet(t, r)
+
+	ctx := context.Background()
+	setChildConditions := func(ns string, ready, synced metav1.ConditionStatus, message string) {
+		child := &stokerv1alpha1.GatewaySync{}
+		if err := r.Get(ctx, types.NamespacedName{Namespace: ns, Name: "site"}, child); err != nil {
+			t.Fatal(err)
+		}
+		child.Status.LastSyncCommitShort = "abc1234"
+		child.Status.Conditions = []metav1.Condition{
+			{Type: conditions.TypeReady, Status: ready, Reason: "Test", Message: message, LastTransitionTime: metav1.Now()},
+			{Type: conditions.TypeAllGatewaysSynced, Status: synced, Reason: "Test", LastTransitionTime: metav1.Now()},
+		}
+		if err := r.Status().Update(ctx, child); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setChildConditions("plant-1", metav1.ConditionTrue, metav1.ConditionTrue, "All gateways synced")
+	setChildConditions("plant-2", metav1.ConditionFalse, metav1.ConditionTrue, "Ref not resolved")
+
+	set := reconcileSet(t, r)
+	if set.Status.ReadyNamespaces != 1 || set.Status.SyncedNamespaces != 2 {
+		t.Errorf("expected 1 ready and 2 synced, got %+v", set.Status)
+	}
+	if c := set.Status.Children[1]; c.Message != "Ref not resolved" || c.Commit != "abc1234" {
+		t.Errorf("unexpected child status %+v", c)
+	}
+	if !conditionHasStatus(set.Status.Conditions, conditions.TypeAllGatewaysSynced, metav1.ConditionTrue) {
+		t.Errorf("expected AllGatewaysSynced=True, got %+v", set.Status.Conditions)
+	}
+	for _, c := range set.Status.Conditions {
+		if c.Type == conditions.TypeReady && (c.Status != metav1.ConditionFalse || !strings.Contains(c.Message, "plant-2")) {
+			t.Errorf("expected Ready=False naming plant-2, got %+v", c)
+		}
+	}
+
+	setChildConditions("plant-2", metav1.ConditionTrue, metav1.ConditionTrue, "All gateways synced")
+	set = reconcileSet(t, r)
+	if !conditionHasStatus(set.Status.Conditions, conditions.TypeReady, metav1.ConditionTrue) {
+		t.Errorf("expected Ready=True, got %+v", set.Status.Conditions)
+	}
+}
+
+func TestGatewaySyncSet_PrunesDeselectedNamespaces(t *testing.T) {
+	r := setReconciler(t,
+		testGatewaySyncSet(),
+		fleetNamespace("plant-1", map[string]string{"fleet": "plants"}, nil),
+	)
+	reconcileSet(t, r)
+
+	ctx := context.Background()
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "plant-1"}, ns); err != nil {
+		t.Fatal(err)
+	}
+	ns.Labels = nil
+	if err := r.Update(ctx, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	set := reconcileSet(t, r)
+	if err := r.Get(ctx, types.NamespacedName{Namespace: "plant-1", Name: "site"}, &stokerv1alpha1.GatewaySync{}); !apierrors.IsNotFound(err) {
+		t.Errorf("child should be pruned, got %v", err)
+	}
+	if !conditionHasReason(set.Status.Conditions, conditions.TypeReady, conditions.ReasonNoNamespacesSelected) {
+		t.Errorf("expected NoNamespacesSelected, got %+v", set.Status.Conditions)
+	}
+}
+
+func TestGatewaySyncSet_LeavesUnownedGatewaySync(t *testing.T) {
+	existing := &stokerv1alpha1.GatewaySync{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "plant-1"},
+		Spec:       stokerv1alpha1.GatewaySyncSpec{Git: stokerv1alpha1.GitSpec{Repo: "https://example.com/other.git", Ref: "dev"}},
+	}
+	r := setReconciler(t,
+		testGatewaySyncSet(),
+		fleetNamespace("plant-1", map[string]string{"fleet": "plants"}, nil),
+		existing,
+	)
+	set := reconcileSet(t, r)
+
+	got := &stokerv1alpha1.GatewaySync{}
+	if err := r.Get(context.Background(), types.NamespacedName{Namespace: "plant-1", Name: "site"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.Git.Ref != "dev" || len(got.OwnerReferences) != 0 {
+		t.Errorf("unowned GatewaySync should be left untouched, got %+v", got)
+	}
+	if c := set.Status.Children[0]; c.Message != errChildConflict.Error() {
+		t.Errorf("expected a conflict message, got %+v", c)
+	}
+}
+
+func TestGatewaySyncSet_InvalidSelector(t *testing.T) {
+	set := testGatewaySyncSet()
+	set.Spec.NamespaceSelector = metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "fleet", Operator: metav1.LabelSelectorOpIn},
+	}}
+	r := setReconciler(t, set, fleetNamespace("plant-1", map[string]string{"fleet": "plants"}, nil))
+
+	got := reconcileSet(t, r)
+	if !conditionHasReason(got.Status.Conditions, conditions.TypeReady, conditions.ReasonNamespaceSelectorInvalid) {
+		t.Errorf("expected NamespaceSelectorInvalid, got %+v", got.Status.Conditions)
+	}
+	if got.Status.ObservedGeneration != 1 {
+		t.Errorf("expected observedGeneration 1, got %d", got.Status.ObservedGeneration)
+	}
+}
+
+func TestGatewaySyncSet_FindSetsForNamespace(t *testing.T) {
+	other := testGatewaySyncSet()
+	other.Name = "offices"
+	other.Spec.NamespaceSelector = metav1.LabelSelector{MatchLabels: map[string]string{"fleet": "offices"}}
+	deselected := testGatewaySyncSet()
+	deselected.Name = "legacy"
+	deselected.Spec.NamespaceSelector = metav1.LabelSelector{MatchLabels: map[string]string{"fleet": "legacy"}}
+	deselected.Status.Children = []stokerv1alpha1.GatewaySyncSetChild{{Namespace: "plant-1", Name: "site"}}
+	r := setReconciler(t, testGatewaySyncSet(), other, deselected)
+
+	requests := r.findSetsForNamespace(context.Background(), fleetNamespace("plant-1", map[string]string{"fleet": "plants"}, nil))
+	var names []string
+	for _, req := range requests {
+		names = append(names, req.Name)
+	}
+	if strings.Join(names, ",") != "legacy,plants" {
+		t.Errorf("expected the matching set and the set with a child there, got %v", names)
+	}
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+)
+
+// syncProfile is a profile published to gateways: an inline profile, or one
+// from the SyncProfile/ClusterSyncProfile library.
+type syncProfile struct {
+	spec stokerv1alpha1.SyncProfileSpec
+	// library is the library version (Kind/name@generation); empty for inline profiles.
+	library string
+}
+
+// libraryVersion identifies a library profile's spec for status reporting.
+func libraryVersion(kind, name string, generation int64) string {
+	return fmt.Sprintf("%s/%s@%d", kind, name, generation)
+}
+
+// collectProfiles merges spec.sync.profileRefs with the inline profiles and
+// records the library profiles in status.profileLibrary. An inline profile
+// shadows a referenced one of the same name. A reference that cannot be
+// read is left out and reported in the returned error, so the remaining
+// profiles are still published.
+func (r *GatewaySyncReconciler) collectProfiles(ctx context.Context, gs *stokerv1alpha1.GatewaySync) (map[string]syncProfile, error) {
+	profiles := make(map[string]syncProfile, len(gs.Spec.Sync.Profiles)+len(gs.Spec.Sync.ProfileRefs))
+	var library []stokerv1alpha1.ProfileLibraryStatus
+	var errs []error
+
+	for _, ref := range gs.Spec.Sync.ProfileRefs {
+		name, kind := ref.ProfileName(), ref.ProfileKind()
+		spec, generation, err := r.getLibraryProfile(ctx, kind, ref.Name, gs.Namespace)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("profileRefs[%s/%s]: %w", kind, ref.Name, err))
+			continue
+		}
+		if _, dup := profiles[name]; dup {
+			errs = append(errs, fmt.Errorf("profileRefs[%s/%s]: profile %q is already referenced", kind, ref.Name, name))
+			continue
+		}
+		version := libraryVersion(kind, ref.Name, generation)
+		_, shadowed := gs.Spec.Sync.Profiles[name]
+		library = append(library, stokerv1alpha1.ProfileLibraryStatus{
+			Profile:    name,
+			Kind:       kind,
+			Name:       ref.Name,
+			Generation: generation,
+			Version:    version,
+			Shadowed:   shadowed,
+		})
+		profiles[name] = syncProfile{spec: spec, library: version}
+	}
+	for name, p := range gs.Spec.Sync.Profiles {
+		profiles[name] = syncProfile{spec: p}
+	}
+
+	gs.Status.ProfileLibrary = library
+	return profiles, errors.Join(errs...)
+}
+
+// getLibraryProfile reads a SyncProfile (in namespace) or ClusterSyncProfile.
+func (r *GatewaySyncReconciler) getLibraryProfile(ctx context.Context, kind, name, namespace string) (stokerv1alpha1.SyncProfileSpec, int64, error) {
+	var (
+		obj  client.Object
+		spec *stokerv1alpha1.SyncProfileSpec
+		key  = types.NamespacedName{Name: name}
+	)
+	switch kind {
+	case stokerv1alpha1.KindClusterSyncProfile:
+		csp := &stokerv1alpha1.ClusterSyncProfile{}
+		obj, spec = csp, &csp.Spec
+	default:
+		sp := &stokerv1alpha1.SyncProfile{}
+		obj, spec, key.Namespace = sp, &sp.Spec, namespace
+	}
+	if err := r.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return stokerv1alpha1.SyncProfileSpec{}, 0, fmt.Errorf("%s %q not found", kind, name)
+		}
+		return stokerv1alpha1.SyncProfileSpec{}, 0, err
+	}
+	return *spec, obj.GetGeneration(), nil
+}
+
+// findGatewaySyncsForProfile maps a SyncProfile or ClusterSyncProfile change
+// to the GatewaySyncs that reference it.
+func (r *GatewaySyncReconciler) findGatewaySyncsForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	kind := stokerv1alpha1.KindSyncProfile
+	var opts []client.ListOption
+	if _, ok := obj.(*stokerv1alpha1.ClusterSyncProfile); ok {
+		kind = stokerv1alpha1.KindClusterSyncProfile
+	} else {
+		opts = append(opts, client.InNamespace(obj.GetNamespace()))
+	}
+
+	var list stokerv1alpha1.GatewaySyncList
+	if err := r.List(ctx, &list, opts...); err != nil {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, gs := range list.Items {
+		if slices.ContainsFunc(gs.Spec.Sync.ProfileRefs, func(ref stokerv1alpha1.ProfileRef) bool {
+			return ref.Name == obj.GetName() && ref.ProfileKind() == kind
+		}) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: gs.Name, Namespace: gs.Namespace},
+			})
+		}
+	}
+	return requests
+}
+
+// sortedProfileNames returns the names of profiles in order.
+func sortedProfileNames(profiles map[string]syncProfile) []string {
+	return slices.Sorted(maps.Keys(profiles))
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+)
+
+func libraryClient(t *testing.T, objs ...client.Object) *GatewaySyncReconciler {
+	t.Helper()
+	c, _ := newFakeClient(t, objs...)
+	return &GatewaySyncReconciler{Client: c}
+}
+
+func libraryMappings(src string) []stokerv1alpha1.SyncMapping {
+	return []stokerv1alpha1.SyncMapping{{Source: src, Destination: "config/"}}
+}
+
+func TestCollectProfiles_MergesLibraryAndInline(t *testing.T) {
+	r := libraryClient(t,
+		&stokerv1alpha1.ClusterSyncProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "standard-site", Generation: 3},
+			Spec:       stokerv1alpha1.SyncProfileSpec{Mappings: libraryMappings("standard/")},
+		},
+		&stokerv1alpha1.SyncProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "area", Namespace: "default", Generation: 1},
+			Spec:       stokerv1alpha1.SyncProfileSpec{Mappings: libraryMappings("area/")},
+		},
+	)
+	gs := &stokerv1alpha1.GatewaySync{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "default"},
+		Spec: stokerv1alpha1.GatewaySyncSpec{Sync: stokerv1alpha1.SyncSpec{
+			Defaults: stokerv1alpha1.SyncDefaults{Vars: map[string]string{"site": "a"}},
+			ProfileRefs: []stokerv1alpha1.ProfileRef{
+				{Kind: stokerv1alpha1.KindClusterSyncProfile, Name: "standard-site", As: "default"},
+				{Name: "area"},
+			},
+			Profiles: map[string]stokerv1alpha1.SyncProfileSpec{
+				"area": {Mappings: libraryMappings("local/")},
+			},
+		}},
+	}
+
+	profiles, err := r.collectProfiles(context.Background(), gs)
+	if err != nil {
+		t.Fatalf("collectProfiles: %v", err)
+	}
+	resolved := r.resolveProfiles(gs, profiles)
+
+	def := resolved["default"]
+	if def.Library != "ClusterSyncProfile/standard-site@3" || def.Mappings[0].Source != "standard/" {
+		t.Errorf("default should come from the library, got %s %+v", def.Library, def.Mappings)
+	}
+	if def.Vars["site"] != "a" {
+		t.Errorf("defaults should apply to library profiles, got vars %v", def.Vars)
+	}
+	if area := resolved["area"]; area.Library != "" || area.Mappings[0].Source != "local/" {
+		t.Errorf("inline area should shadow the SyncProfile, got %s %+v", area.Library, area.Mappings)
+	}
+
+	if len(gs.Status.ProfileLibrary) != 2 {
+		t.Fatalf("expected 2 library entries, got %+v", gs.Status.ProfileLibrary)
+	}
+	if lib := gs.Status.ProfileLibrary[1]; lib.Profile != "area" || !lib.Shadowed || lib.Version != "SyncProfile/area@1" {
+		t.Errorf("unexpected area library status %+v", lib)
+	}
+}
+
+func TestCollectProfiles_MissingRef(t *testing.T) {
+	r := libraryClient(t,
+		// A SyncProfile in another namespace is not visible.
+		&stokerv1alpha1.SyncProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "area", Namespace: "other"},
+			Spec:       stokerv1alpha1.SyncProfileSpec{Mappings: libraryMappings("area/")},
+		},
+	)
+	gs := &stokerv1alpha1.GatewaySync{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "default"},
+		Spec: stokerv1alpha1.GatewaySyncSpec{Sync: stokerv1alpha1.SyncSpec{
+			ProfileRefs: []stokerv1alpha1.ProfileRef{{Name: "area"}},
+			Profiles:    map[string]stokerv1alpha1.SyncProfileSpec{"default": {Mappings: libraryMappings("x/")}},
+		}},
+	}
+
+	profiles, err := r.collectProfiles(context.Background(), gs)
+	if err == nil || !strings.Contains(err.Error(), `SyncProfile "area" not found`) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+	if _, ok := profiles["default"]; !ok || len(profiles) != 1 {
+		t.Errorf("inline profiles should still be published, got %v", sortedProfileNames(profiles))
+	}
+}
+
+func TestFindGatewaySyncsForProfile(t *testing.T) {
+	ref := func(kind, name string) stokerv1alpha1.SyncSpec {
+		return stokerv1alpha1.SyncSpec{ProfileRefs: []stokerv1alpha1.ProfileRef{{Kind: kind, Name: name}}}
+	}
+	r := libraryClient(t,
+		&stokerv1alpha1.GatewaySync{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "plant-1"},
+			Spec: stokerv1alpha1.GatewaySyncSpec{Sync: ref(stokerv1alpha1.KindClusterSyncProfile, "standard-site")}},
+		&stokerv1alpha1.GatewaySync{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "plant-2"},
+			Spec: stokerv1alpha1.GatewaySyncSpec{Sync: ref(stokerv1alpha1.KindClusterSyncProfile, "standard-site")}},
+		&stokerv1alpha1.GatewaySync{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "plant-1"},
+			Spec: stokerv1alpha1.GatewaySyncSpec{Sync: ref("", "standard-site")}},
+	)
+	ctx := context.Background()
+
+	csp := &stokerv1alpha1.ClusterSyncProfile{ObjectMeta: metav1.ObjectMeta{Name: "standard-site"}}
+	if got := r.findGatewaySyncsForProfile(ctx, csp); len(got) != 2 {
+		t.Errorf("ClusterSyncProfile should enqueue a and b, got %v", got)
+	}
+	sp := &stokerv1alpha1.SyncProfile{ObjectMeta: metav1.ObjectMeta{Name: "standard-site", Namespace: "plant-1"}}
+	if got := r.findGatewaySyncsForProfile(ctx, sp); len(got) != 1 || got[0].Name != "c" {
+		t.Errorf("SyncProfile should enqueue only c, got %v", got)
+	}
+}
@@ -6,17 +6,14 @@ import (
 	"slices"
 	"sort"
 
-	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
-	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
 )
 
 const (
@@ -31,29 +28,46 @@ func agentRoleBindingName(crName string) string {
 }
 
 // ensureAgentRoleBinding creates or updates a RoleBinding in the CR's namespace
-// that binds the stoker-agent ClusterRole to the ServiceAccounts of discovered gateway pods.
-// The RoleBinding is owned by the GatewaySync CR via SetControllerReference for automatic GC.
+// that binds the stoker-agent ClusterRole to the ServiceAccounts of discovered gateway pods
+// in that namespace. ServiceAccounts from spec.allowedNamespaces get the narrower access of
+// ensureRemoteAgentAccess instead. The RoleBinding is owned by the GatewaySync CR via
+// SetControllerReference for automatic GC.
 func (r *GatewaySyncReconciler) ensureAgentRoleBinding(ctx context.Context, gs *stokerv1alpha1.GatewaySync) error {
 	log := logf.FromContext(ctx).WithName("auto-rbac")
 
 	// Collect unique ServiceAccount names from ALL pods that reference this CR,
 	// regardless of pod phase. This avoids the chicken-and-egg problem where pods
 	// are stuck in Init waiting for RBAC but we only grant RBAC to Running pods.
-	saNames := r.collectServiceAccountsFromPods(ctx, gs)
-	if len(saNames) == 0 {
+	saByNamespace, err := r.collectServiceAccountsFromPods(ctx, gs)
+	if err != nil {
+		return err
+	}
+
+	// Pods in other namespaces also need to read their own pod object there.
+	if err := r.ensureRemoteAgentRoleBindings(ctx, gs, saByNamespace); err != nil {
+		return err
+	}
+	agents, err := r.collectRemoteAgents(ctx, gs)
+	if err != nil {
+		return err
+	}
+	if err := r.ensureRemoteAgentAccess(ctx, gs, agents); err != nil {
+		return err
+	}
+
+	localSAs := saByNamespace[gs.Namespace]
+	if len(localSAs) == 0 {
 		// No matching pods exist yet — use "default" as a baseline subject.
-		saNames = []string{"default"}
+		localSAs = []string{"default"}
 	}
 
 	rbName := agentRoleBindingName(gs.Name)
 	key := types.NamespacedName{Name: rbName, Namespace: gs.Namespace}
-
-	// Build desired subjects.
-	desired := buildSubjects(saNames, gs.Namespace)
+	desired := buildSubjects(localSAs, gs.Namespace)
 
 	// Try to get existing RoleBinding.
 	existing := &rbacv1.RoleBinding{}
-	err := r.Get(ctx, key, existing)
+	err = r.Get(ctx, key, existing)
 
 	if errors.IsNotFound(err) {
 		// Create new RoleBinding.
@@ -82,7 +96,7 @@ func (r *GatewaySyncReconciler) ensureAgentRoleBinding(ctx context.Context, gs *
 			return fmt.Errorf("creating agent RoleBinding: %w", err)
 		}
 
-		log.Info("created agent RoleBinding", "name", rbName, "subjects", formatSANames(saNames))
+		log.Info("created agent RoleBinding", "name", rbName, "subjects", formatSubjects(desired))
 		return nil
 	}
 	if err != nil {
@@ -106,41 +120,36 @@ func (r *GatewaySyncReconciler) ensureAgentRoleBinding(ctx context.Context, gs *
 		if err := r.Update(ctx, existing); err != nil {
 			return fmt.Errorf("updating agent RoleBinding subjects: %w", err)
 		}
-		log.Info("updated agent RoleBinding subjects", "name", rbName, "subjects", formatSANames(saNames))
+		log.Info("updated agent RoleBinding subjects", "name", rbName, "subjects", formatSubjects(desired))
 	}
 
 	return nil
 }
 
-// collectServiceAccountsFromPods lists ALL pods in the namespace that reference this CR
-// (via stoker.io/cr-name annotation) regardless of phase, and returns their unique SA names.
+// collectServiceAccountsFromPods lists ALL pods that reference this CR (see listGatewayPods)
+// regardless of phase, and returns their unique SA names keyed by namespace.
 // This is critical: pods may be in Init/Pending state waiting for RBAC before they can reach
 // Running phase, so we must grant RBAC based on ALL matching pods, not just Running ones.
-func (r *GatewaySyncReconciler) collectServiceAccountsFromPods(ctx context.Context, gs *stokerv1alpha1.GatewaySync) []string {
-	var podList corev1.PodList
-	if err := r.List(ctx, &podList, client.InNamespace(gs.Namespace)); err != nil {
-		return nil
+func (r *GatewaySyncReconciler) collectServiceAccountsFromPods(ctx context.Context, gs *stokerv1alpha1.GatewaySync) (map[string][]string, error) {
+	pods, err := r.listGatewayPods(ctx, gs)
+	if err != nil {
+		return nil, err
 	}
 
-	seen := make(map[string]bool)
-	for _, pod := range podList.Items {
-		crName, ok := pod.Annotations[stokertypes.AnnotationCRName]
-		if !ok || crName != gs.Name {
-			continue
-		}
+	byNamespace := make(map[string][]string)
+	for _, pod := range pods {
 		sa := pod.Spec.ServiceAccountName
 		if sa == "" {
 			sa = "default"
 		}
-		seen[sa] = true
+		if !slices.Contains(byNamespace[pod.Namespace], sa) {
+			byNamespace[pod.Namespace] = append(byNamespace[pod.Namespace], sa)
+		}
 	}
-
-	names := make([]string, 0, len(seen))
-	for name := range seen {
-		names = append(names, name)
+	for _, names := range byNamespace {
+		sort.Strings(names)
 	}
-	sort.Strings(names)
-	return names
+	return byNamespace, nil
 }
 
 // buildSubjects creates RoleBinding subjects for the given ServiceAccount names.
@@ -164,18 +173,22 @@ func subjectsEqual(a, b []rbacv1.Subject) bool {
 	aNames := make([]string, len(a))
 	bNames := make([]string, len(b))
 	for i := range a {
-		aNames[i] = a[i].Name
-		bNames[i] = b[i].Name
+		aNames[i] = a[i].Namespace + "/" + a[i].Name
+		bNames[i] = b[i].Namespace + "/" + b[i].Name
 	}
 	sort.Strings(aNames)
 	sort.Strings(bNames)
 	return slices.Equal(aNames, bNames)
 }
 
-// formatSANames formats SA names for log output.
-func formatSANames(names []string) string {
-	if len(names) == 0 {
+// formatSubjects formats RoleBinding subjects as namespace/name for log output.
+func formatSubjects(subjects []rbacv1.Subject) string {
+	if len(subjects) == 0 {
 		return "(none)"
 	}
+	names := make([]string, len(subjects))
+	for i, s := range subjects {
+		names[i] = s.Namespace + "/" + s.Name
+	}
 	return fmt.Sprintf("%v", names)
 }
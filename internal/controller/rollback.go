@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// defaultRollbackWindow is used when spec.rollback.window is unset.
+const defaultRollbackWindow = 10 * time.Minute
+
+// updateRollback records the last fully synced commit, rolls back a new
+// commit whose gateway errors exceed spec.rollback.errorThreshold, and
+// releases an acknowledged rollback. Returns the commit to publish: the
+// resolved one, or the last fully synced commit (pinned by SHA) while a
+// rollback holds.
+func (r *GatewaySyncReconciler) updateRollback(ctx context.Context, gs *stokerv1alpha1.GatewaySync, result git.Result) git.Result {
+	if fullySynced(gs.Status.DiscoveredGateways, result.Commit) {
+		gs.Status.LastFullySyncedCommit = result.Commit
+	}
+	if gs.Spec.Rollback == nil {
+		gs.Status.Rollback = nil
+		meta.RemoveStatusCondition(&gs.Status.Conditions, conditions.TypeRolledBack)
+		return result
+	}
+
+	rb := gs.Status.Rollback
+	if rb != nil && rb.FailedCommit != result.Commit && rb.AcknowledgedTime != nil {
+		gs.Status.Rollback, rb = nil, nil
+	}
+
+	if rb != nil && rb.AcknowledgedTime == nil {
+		if gs.Annotations[stokertypes.AnnotationRollbackAcknowledged] == "" {
+			return rolledBackResult(rb)
+		}
+		now := metav1.Now()
+		rb.AcknowledgedTime = &now
+		r.Recorder.Eventf(gs, corev1.EventTypeNormal, conditions.ReasonRollbackAcknowledged,
+			"Rollback of %s acknowledged; following %s again", git.ShortCommit(rb.FailedCommit), gs.Spec.Git.Ref)
+		r.setCondition(ctx, gs, conditions.TypeRolledBack, metav1.ConditionFalse, conditions.ReasonRollbackAcknowledged,
+			fmt.Sprintf("Rollback of %s acknowledged", git.ShortCommit(rb.FailedCommit)))
+		return result
+	}
+	if rb != nil {
+		// Acknowledged: the failed commit is the operator's call now.
+		return result
+	}
+
+	window, err := rollbackWindow(gs)
+	if err != nil {
+		r.setCondition(ctx, gs, conditions.TypeRolledBack, metav1.ConditionFalse, conditions.ReasonRollbackWindowInvalid,
+			fmt.Sprintf("Automatic rollback disabled: %v", err))
+		return result
+	}
+	target := gs.Status.LastFullySyncedCommit
+	failed := rollbackFailures(gs, result.Commit, window, time.Now())
+	if target == "" || target == result.Commit || len(failed) <= rollbackThreshold(gs) {
+		r.setCondition(ctx, gs, conditions.TypeRolledBack, metav1.ConditionFalse, conditions.ReasonNotRolledBack,
+			fmt.Sprintf("%d gateway(s) failing, threshold %d", len(failed), rollbackThreshold(gs)))
+		return result
+	}
+
+	gs.Status.Rollback = &stokerv1alpha1.RollbackStatus{
+		FailedCommit:   result.Commit,
+		Commit:         target,
+		FailedGateways: failed,
+		Time:           metav1.Now(),
+	}
+	message := fmt.Sprintf("Rolled back %s to %s: %d gateway(s) failed (%s); set %s to resume",
+		git.ShortCommit(result.Commit), git.ShortCommit(target), len(failed), strings.Join(failed, ", "), stokertypes.AnnotationRollbackAcknowledged)
+	r.setCondition(ctx, gs, conditions.TypeRolledBack, metav1.ConditionTrue, conditions.ReasonErrorThresholdExceeded, message)
+	r.Recorder.Event(gs, corev1.EventTypeWarning, conditions.ReasonRolledBack, message)
+	return rolledBackResult(gs.Status.Rollback)
+}
+
+// rolledBackResult is the commit published while a rollback holds, pinned
+// by SHA so agents do not follow the ref.
+func rolledBackResult(rb *stokerv1alpha1.RollbackStatus) git.Result {
+	return git.Result{Commit: rb.Commit, Ref: rb.Commit}
+}
+
+// rollbackActive reports whether an unacknowledged rollback pins gateways.
+func rollbackActive(gs *stokerv1alpha1.GatewaySync) bool {
+	return gs.Spec.Rollback != nil && gs.Status.Rollback != nil && gs.Status.Rollback.AcknowledgedTime == nil
+}
+
+// fullySynced reports whether every gateway following spec.git.ref (at
+// least one) is Synced at commit.
+func fullySynced(gateways []stokerv1alpha1.DiscoveredGateway, commit string) bool {
+	following := 0
+	for _, gw := range gateways {
+		if gw.RefOverride != "" {
+			continue
+		}
+		if gw.SyncStatus != stokertypes.SyncStatusSynced || gw.SyncedCommit != commit {
+			return false
+		}
+		following++
+	}
+	return following > 0
+}
+
+// rollbackFailures returns the gateways following spec.git.ref that report
+// Error on commit, or nil once window has passed since the commit was
+// resolved. Errors left over from an earlier commit do not count.
+func rollbackFailures(gs *stokerv1alpha1.GatewaySync, commit string, window time.Duration, now time.Time) []string {
+	if gs.Status.LastSyncTime == nil || now.Sub(gs.Status.LastSyncTime.Time) > window {
+		return nil
+	}
+	var failed []string
+	for _, gw := range gs.Status.DiscoveredGateways {
+		if gw.RefOverride == "" && gw.SyncStatus == stokertypes.SyncStatusError && gw.SyncedCommit == commit {
+			failed = append(failed, gw.Name)
+		}
+	}
+	return failed
+}
+
+// rollbackThreshold returns how many failing gateways are tolerated.
+func rollbackThreshold(gs *stokerv1alpha1.GatewaySync) int {
+	if gs.Spec.Rollback.ErrorThreshold == nil {
+		return 0
+	}
+	total := 0
+	for _, gw := range gs.Status.DiscoveredGateways {
+		if gw.RefOverride == "" {
+			total++
+		}
+	}
+	n, err := intstr.GetScaledValueFromIntOrPercent(gs.Spec.Rollback.ErrorThreshold, total, false)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// rollbackWindow parses spec.rollback.window. The validating webhook rejects
+// invalid windows; one that got past it disables rollback rather than
+// falling back to the default.
+func rollbackWindow(gs *stokerv1alpha1.GatewaySync) (time.Duration, error) {
+	window := gs.Spec.Rollback.Window
+	if window == "" {
+		return defaultRollbackWindow, nil
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("spec.rollback.window %q must be a positive duration", window)
+	}
+	return d, nil
+}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// rollbackFixture returns a CR that resolved "new" a minute ago, with four
+// gateways synced at "old" (the last fully synced commit) except those failing.
+func rollbackFixture(threshold intstr.IntOrString, failing ...string) *stokerv1alpha1.GatewaySync {
+	resolved := metav1.NewTime(time.Now().Add(-time.Minute))
+	gs := &stokerv1alpha1.GatewaySync{
+		Spec: stokerv1alpha1.GatewaySyncSpec{
+			Git:      stokerv1alpha1.GitSpec{Ref: "main"},
+			Rollback: &stokerv1alpha1.RollbackSpec{ErrorThreshold: &threshold, Window: "10m"},
+		},
+		Status: stokerv1alpha1.GatewaySyncStatus{
+			LastSyncCommit:        "new",
+			LastSyncTime:          &resolved,
+			LastFullySyncedCommit: "old",
+		},
+	}
+	for _, name := range []string{"gw-a", "gw-b", "gw-c", "gw-d"} {
+		gw := stokerv1alpha1.DiscoveredGateway{Name: name, SyncStatus: stokertypes.SyncStatusSynced, SyncedCommit: "old"}
+		if slices.Contains(failing, name) {
+			gw.SyncStatus, gw.SyncedCommit = stokertypes.SyncStatusError, "new"
+		}
+		gs.Status.DiscoveredGateways = append(gs.Status.DiscoveredGateways, gw)
+	}
+	return gs
+}
+
+func TestUpdateRollback_Threshold(t *testing.T) {
+	cases := []struct {
+		name      string
+		threshold intstr.IntOrString
+		failing   []string
+		rollback  bool
+	}{
+		{name: "no errors", threshold: intstr.FromInt32(0)},
+		{name: "any error with zero threshold", threshold: intstr.FromInt32(0), failing: []string{"gw-b"}, rollback: true},
+		{name: "at count threshold", threshold: intstr.FromInt32(2), failing: []string{"gw-a", "gw-b"}},
+		{name: "over count threshold", threshold: intstr.FromInt32(1), failing: []string{"gw-a", "gw-b"}, rollback: true},
+		{name: "at percent threshold", threshold: intstr.FromString("25%"), failing: []string{"gw-c"}},
+		{name: "over percent threshold", threshold: intstr.FromString("25%"), failing: []string{"gw-c", "gw-d"}, rollback: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gs := rollbackFixture(tc.threshold, tc.failing...)
+			r := &GatewaySyncReconciler{Recorder: record.NewFakeRecorder(10)}
+
+			published := r.updateRollback(context.Background(), gs, git.Result{Commit: "new", Ref: "main"})
+			if rollbackActive(gs) != tc.rollback {
+				t.Fatalf("rollbackActive = %v, want %v", rollbackActive(gs), tc.rollback)
+			}
+			if !tc.rollback {
+				if published.Commit != "new" {
+					t.Errorf("published %s, want new", published.Commit)
+				}
+				return
+			}
+			if published != (git.Result{Commit: "old", Ref: "old"}) {
+				t.Errorf("published %+v, want old pinned by SHA", published)
+			}
+			if !slices.Equal(gs.Status.Rollback.FailedGateways, tc.failing) {
+				t.Errorf("failed gateways %v, want %v", gs.Status.Rollback.FailedGateways, tc.failing)
+			}
+			if !apimeta.IsStatusConditionTrue(gs.Status.Conditions, conditions.TypeRolledBack) {
+				t.Error("expected RolledBack=True")
+			}
+		})
+	}
+}
+
+func TestUpdateRollback_OutsideWindow(t *testing.T) {
+	gs := rollbackFixture(intstr.FromInt32(0), "gw-a")
+	gs.Status.LastSyncTime = ptr.To(metav1.NewTime(time.Now().Add(-time.Hour)))
+	r := &GatewaySyncReconciler{Recorder: record.NewFakeRecorder(10)}
+
+	if published := r.updateRollback(context.Background(), gs, git.Result{Commit: "new", Ref: "main"}); published.Commit != "new" {
+		t.Errorf("errors after the window should not roll back, published %s", published.Commit)
+	}
+}
+
+func TestUpdateRollback_IgnoresErrorsOnOtherCommits(t *testing.T) {
+	gs := rollbackFixture(intstr.FromInt32(0), "gw-a")
+	// gw-b is still failing on the commit before the new one.
+	gs.Status.DiscoveredGateways[1].SyncStatus = stokertypes.SyncStatusError
+	r := &GatewaySyncReconciler{Recorder: record.NewFakeRecorder(10)}
+
+	gs.Status.DiscoveredGateways[0].SyncStatus = stokertypes.SyncStatusSynced
+	if published := r.updateRollback(context.Background(), gs, git.Result{Commit: "new", Ref: "main"}); published.Commit != "new" {
+		t.Errorf("an error on an earlier commit should not roll back, published %s", published.Commit)
+	}
+}
+
+func TestUpdateRollback_InvalidWindow(t *testing.T) {
+	gs := rollbackFixture(intstr.FromInt32(0), "gw-a")
+	gs.Spec.Rollback.Window = "ten minutes"
+	r := &GatewaySyncReconciler{Recorder: record.NewFakeRecorder(10)}
+
+	if published := r.updateRollback(context.Background(), gs, git.Result{Commit: "new", Ref: "main"}); published.Commit != "new" {
+		t.Errorf("an invalid window should disable rollback, published %s", published.Commit)
+	}
+	cond := apimeta.FindStatusCondition(gs.Status.Conditions, conditions.TypeRolledBack)
+	if cond == nil || cond.Reason != conditions.ReasonRollbackWindowInvalid {
+		t.Errorf("expected reason %s, got %+v", conditions.ReasonRollbackWindowInvalid, cond)
+	}
+}
+
+func TestUpdateRollback_HoldsUntilAcknowledged(t *testing.T) {
+	gs := rollbackFixture(intstr.FromInt32(0), "gw-a")
+	r := &GatewaySyncReconciler{Recorder: record.NewFakeRecorder(10)}
+	ctx := context.Background()
+	result := git.Result{Commit: "new", Ref: "main"}
+
+	r.updateRollback(ctx, gs, result)
+
+	// The failing gateway recovers on the old commit; the pin still holds.
+	gs.Status.DiscoveredGateways[0].SyncStatus = stokertypes.SyncStatusSynced
+	if published := r.updateRollback(ctx, gs, result); published.Commit != "old" {
+		t.Fatalf("rollback should hold until acknowledged, published %s", published.Commit)
+	}
+
+	gs.Annotations = map[string]string{stokertypes.AnnotationRollbackAcknowledged: "jane"}
+	if published := r.updateRollback(ctx, gs, result); published.Commit != "new" {
+		t.Fatalf("acknowledged rollback should publish the resolved commit, got %s", published.Commit)
+	}
+	if rollbackActive(gs) || gs.Status.Rollback.AcknowledgedTime == nil {
+		t.Fatal("rollback should be recorded as acknowledged")
+	}
+
+	// The acknowledged commit is not rolled back again.
+	gs.Annotations = nil
+	gs.Status.DiscoveredGateways[1].SyncStatus = stokertypes.SyncStatusError
+	if published := r.updateRollback(ctx, gs, result); published.Commit != "new" {
+		t.Errorf("acknowledged commit rolled back again, published %s", published.Commit)
+	}
+
+	// A new commit clears the record.
+	r.updateRollback(ctx, gs, git.Result{Commit: "newer", Ref: "main"})
+	if gs.Status.Rollback != nil && gs.Status.Rollback.FailedCommit != "newer" {
+		t.Errorf("stale rollback record kept: %+v", gs.Status.Rollback)
+	}
+}
+
+func TestUpdateRollback_TracksLastFullySynced(t *testing.T) {
+	gs := rollbackFixture(intstr.FromInt32(0))
+	for i := range gs.Status.DiscoveredGateways {
+		gs.Status.DiscoveredGateways[i].SyncedCommit = "new"
+	}
+	r := &GatewaySyncReconciler{Recorder: record.NewFakeRecorder(10)}
+
+	r.updateRollback(context.Background(), gs, git.Result{Commit: "new", Ref: "main"})
+	if gs.Status.LastFullySyncedCommit != "new" {
+		t.Errorf("lastFullySyncedCommit = %s, want new", gs.Status.LastFullySyncedCommit)
+	}
+}
+
+func TestEnsureMetadataConfigMap_FlagsRollback(t *testing.T) {
+	gs := rollbackFixture(intstr.FromInt32(0), "gw-a")
+	gs.ObjectMeta = metav1.ObjectMeta{Name: "site", Namespace: "stoker", UID: "gs-uid"}
+	r := crossNamespaceReconciler(t)
+	r.updateRollback(context.Background(), gs, git.Result{Commit: "new", Ref: "main"})
+	if !rollbackActive(gs) {
+		t.Fatal("expected an active rollback")
+	}
+
+	if err := r.ensureMetadataConfigMap(context.Background(), gs, rolledBackResult(gs.Status.Rollback), nil); err != nil {
+		t.Fatal(err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(context.Background(), types.NamespacedName{Namespace: "stoker", Name: "stoker-metadata-site"}, cm); err != nil {
+		t.Fatal(err)
+	}
+	if cm.Data["commit"] != "old" || cm.Data["rollback"] != "true" {
+		t.Errorf("expected the rollback target flagged for agents, got %v", cm.Data)
+	}
+}
@@ -0,0 +1,354 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// Rollout phases reported in status.rollout.phase.
+const (
+	rolloutProgressing = "Progressing"
+	rolloutHalted      = "Halted"
+	rolloutComplete    = "Complete"
+)
+
+// rolloutGateway is the view of a discovered gateway used to plan a rollout.
+type rolloutGateway struct {
+	name         string
+	profile      string
+	labels       labels.Set
+	syncStatus   string
+	syncedCommit string
+}
+
+// rolloutStep is one step of a rollout: the cumulative set of gateways that
+// must be updated once the step completes.
+type rolloutStep struct {
+	name    string
+	members map[string]bool
+}
+
+// updateRollout starts a rollout when the resolved commit changes, advances
+// it against the discovered gateways' sync status, and sets the
+// RolloutProgressing condition. The first commit seen is published directly.
+func (r *GatewaySyncReconciler) updateRollout(ctx context.Context, gs *stokerv1alpha1.GatewaySync, result git.Result) error {
+	if gs.Spec.Rollout == nil {
+		gs.Status.Rollout = nil
+		meta.RemoveStatusCondition(&gs.Status.Conditions, conditions.TypeRolloutProgressing)
+		return nil
+	}
+
+	now := metav1.Now()
+	st := gs.Status.Rollout
+	switch {
+	case st == nil:
+		gs.Status.Rollout = &stokerv1alpha1.RolloutStatus{
+			Commit:    result.Commit,
+			Ref:       result.Ref,
+			Phase:     rolloutComplete,
+			Message:   "No previous commit to roll out from; published to all gateways",
+			StartTime: &now,
+		}
+	case st.Commit != result.Commit:
+		// A superseded rollout never became stable, so gateways fall back
+		// to the last commit that completed.
+		stable := st.Commit
+		if st.Phase != rolloutComplete {
+			stable = st.StableCommit
+		}
+		gs.Status.Rollout = &stokerv1alpha1.RolloutStatus{
+			Commit:       result.Commit,
+			Ref:          result.Ref,
+			StableCommit: stable,
+			Phase:        rolloutProgressing,
+			StartTime:    &now,
+		}
+		if stable == "" {
+			gs.Status.Rollout.Phase = rolloutComplete
+			gs.Status.Rollout.Message = "No stable commit to roll out from; published to all gateways"
+		} else {
+			r.Recorder.Eventf(gs, corev1.EventTypeNormal, conditions.ReasonRolloutStarted,
+				"Rolling out %s (stable %s)", git.ShortCommit(result.Commit), git.ShortCommit(stable))
+		}
+	default:
+		st.Ref = result.Ref
+	}
+	st = gs.Status.Rollout
+
+	gateways, err := r.rolloutGateways(ctx, gs)
+	if err != nil {
+		return err
+	}
+	prevPhase, prevStep, prevStepName := st.Phase, st.Step, st.StepName
+	advanceRollout(gs.Spec.Rollout, st, gateways)
+
+	switch {
+	case st.Phase == rolloutHalted && prevPhase != rolloutHalted:
+		r.Recorder.Event(gs, corev1.EventTypeWarning, conditions.ReasonRolloutHalted, st.Message)
+	case st.Phase == rolloutComplete && prevPhase == rolloutProgressing:
+		r.Recorder.Eventf(gs, corev1.EventTypeNormal, conditions.ReasonRolloutCompleted,
+			"Rolled out %s to %d gateway(s)", git.ShortCommit(st.Commit), len(st.UpdatedGateways))
+	case st.Step != prevStep && prevPhase == rolloutProgressing:
+		r.Recorder.Eventf(gs, corev1.EventTypeNormal, conditions.ReasonRolloutStepCompleted,
+			"Rollout of %s: step %s complete, starting %s", git.ShortCommit(st.Commit), prevStepName, st.StepName)
+	}
+
+	updated := make(map[string]bool, len(st.UpdatedGateways))
+	for _, name := range st.UpdatedGateways {
+		updated[name] = true
+	}
+	for i := range gs.Status.DiscoveredGateways {
+		gw := &gs.Status.DiscoveredGateways[i]
+		gw.TargetCommit = ""
+		if st.Phase != rolloutComplete && gw.RefOverride == "" && !updated[gw.Name] {
+			gw.TargetCommit = st.StableCommit
+		}
+	}
+
+	switch st.Phase {
+	case rolloutProgressing:
+		r.setCondition(ctx, gs, conditions.TypeRolloutProgressing, metav1.ConditionTrue, conditions.ReasonRolloutProgressing, st.Message)
+	case rolloutHalted:
+		r.setCondition(ctx, gs, conditions.TypeRolloutProgressing, metav1.ConditionFalse, conditions.ReasonRolloutHalted, st.Message)
+	default:
+		r.setCondition(ctx, gs, conditions.TypeRolloutProgressing, metav1.ConditionFalse, conditions.ReasonRolloutComplete, st.Message)
+	}
+	return nil
+}
+
+// rolloutGateways returns the discovered gateways that take part in a
+// rollout, with their pod labels. Gateways pinned by stoker.io/ref-override
+// follow their own ref and are left out.
+func (r *GatewaySyncReconciler) rolloutGateways(ctx context.Context, gs *stokerv1alpha1.GatewaySync) ([]rolloutGateway, error) {
+	pods, err := r.listGatewayPods(ctx, gs)
+	if err != nil {
+		return nil, err
+	}
+	podLabels := make(map[string]labels.Set)
+	for i := range pods {
+		podLabels[gatewayNameForPod(&pods[i])] = pods[i].Labels
+	}
+
+	gateways := make([]rolloutGateway, 0, len(gs.Status.DiscoveredGateways))
+	for _, gw := range gs.Status.DiscoveredGateways {
+		if gw.RefOverride != "" {
+			continue
+		}
+		gateways = append(gateways, rolloutGateway{
+			name:         gw.Name,
+			profile:      gw.Profile,
+			labels:       podLabels[gw.Name],
+			syncStatus:   gw.SyncStatus,
+			syncedCommit: gw.SyncedCommit,
+		})
+	}
+	return gateways, nil
+}
+
+// advanceRollout admits gateways to the current step up to maxUnavailable,
+// moves to the next step once every gateway admitted so far is synced at the
+// new commit, and halts when an updated gateway reports an error.
+func advanceRollout(spec *stokerv1alpha1.RolloutSpec, st *stokerv1alpha1.RolloutStatus, gateways []rolloutGateway) {
+	if st.Phase != rolloutProgressing {
+		return
+	}
+
+	sortRolloutGateways(spec, gateways)
+	steps := planRolloutSteps(spec, gateways)
+	updated := make(map[string]bool, len(st.UpdatedGateways))
+	for _, name := range st.UpdatedGateways {
+		updated[name] = true
+	}
+
+	if spec.HaltOnError == nil || *spec.HaltOnError {
+		var failed []string
+		for _, gw := range gateways {
+			if updated[gw.name] && gw.syncStatus == stokertypes.SyncStatusError {
+				failed = append(failed, gw.name)
+			}
+		}
+		if len(failed) > 0 {
+			st.Phase = rolloutHalted
+			st.Message = fmt.Sprintf("Rollout of %s halted at step %s: %s reported an error",
+				git.ShortCommit(st.Commit), st.StepName, strings.Join(failed, ", "))
+			return
+		}
+	}
+
+	limit := maxUnavailable(spec, len(gateways))
+	st.Step = min(st.Step, int32(len(steps)-1))
+	for {
+		step := steps[st.Step]
+		st.StepName = step.name
+
+		unavailable := 0
+		for _, gw := range gateways {
+			if updated[gw.name] && !rolloutSynced(gw, st.Commit) {
+				unavailable++
+			}
+		}
+		done := true
+		for _, gw := range gateways {
+			if !step.members[gw.name] {
+				continue
+			}
+			if !updated[gw.name] {
+				if unavailable >= limit {
+					done = false
+					continue
+				}
+				updated[gw.name] = true
+				st.UpdatedGateways = append(st.UpdatedGateways, gw.name)
+				unavailable++
+			}
+			if !rolloutSynced(gw, st.Commit) {
+				done = false
+			}
+		}
+
+		if !done {
+			synced := 0
+			for _, gw := range gateways {
+				if step.members[gw.name] && updated[gw.name] && rolloutSynced(gw, st.Commit) {
+					synced++
+				}
+			}
+			st.Message = fmt.Sprintf("Rolling out %s: step %d/%d (%s), %d/%d gateway(s) synced",
+				git.ShortCommit(st.Commit), st.Step+1, len(steps), step.name, synced, len(step.members))
+			return
+		}
+		if int(st.Step) == len(steps)-1 {
+			st.Phase = rolloutComplete
+			st.Message = fmt.Sprintf("Rolled out %s to all %d gateway(s)", git.ShortCommit(st.Commit), len(gateways))
+			return
+		}
+		st.Step++
+	}
+}
+
+// rolloutSynced reports whether a gateway has applied commit.
+func rolloutSynced(gw rolloutGateway, commit string) bool {
+	return gw.syncStatus == stokertypes.SyncStatusSynced && gw.syncedCommit == commit
+}
+
+// sortRolloutGateways orders gateways canaries first, then by name. Percent
+// waves take gateways in this order.
+func sortRolloutGateways(spec *stokerv1alpha1.RolloutSpec, gateways []rolloutGateway) {
+	slices.SortFunc(gateways, func(a, b rolloutGateway) int {
+		ca, cb := isCanary(spec, a), isCanary(spec, b)
+		if ca != cb {
+			if ca {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.name, b.name)
+	})
+}
+
+// planRolloutSteps builds the cumulative steps of a rollout over gateways
+// (sorted by sortRolloutGateways): the canary when configured, each wave,
+// and a final step covering every gateway.
+func planRolloutSteps(spec *stokerv1alpha1.RolloutSpec, gateways []rolloutGateway) []rolloutStep {
+	var steps []rolloutStep
+	members := map[string]bool{}
+	add := func(name string, match func(i int, gw rolloutGateway) bool) {
+		for i, gw := range gateways {
+			if match(i, gw) {
+				members[gw.name] = true
+			}
+		}
+		step := rolloutStep{name: name, members: make(map[string]bool, len(members))}
+		for name := range members {
+			step.members[name] = true
+		}
+		steps = append(steps, step)
+	}
+
+	if spec.Canary != nil {
+		add("canary", func(_ int, gw rolloutGateway) bool { return isCanary(spec, gw) })
+	}
+	for i, wave := range spec.Waves {
+		name := wave.Name
+		if name == "" {
+			name = fmt.Sprintf("wave-%d", i+1)
+		}
+		switch {
+		case wave.Percent != nil:
+			// Round up so any non-zero percent updates at least one gateway.
+			n := (int(*wave.Percent)*len(gateways) + 99) / 100
+			add(name, func(i int, _ rolloutGateway) bool { return i < n })
+		default:
+			sel := selectorFor(wave.Selector)
+			add(name, func(_ int, gw rolloutGateway) bool { return sel.Matches(gw.labels) })
+		}
+	}
+	add("all", func(int, rolloutGateway) bool { return true })
+	return steps
+}
+
+// isCanary reports whether gw matches the canary selector or profiles.
+func isCanary(spec *stokerv1alpha1.RolloutSpec, gw rolloutGateway) bool {
+	if spec.Canary == nil {
+		return false
+	}
+	if spec.Canary.Selector != nil && selectorFor(spec.Canary.Selector).Matches(gw.labels) {
+		return true
+	}
+	return gw.profile != "" && slices.Contains(spec.Canary.Profiles, gw.profile)
+}
+
+// selectorFor converts a label selector, matching nothing when it is unset
+// or invalid.
+func selectorFor(ls *metav1.LabelSelector) labels.Selector {
+	if ls == nil {
+		return labels.Nothing()
+	}
+	sel, err := metav1.LabelSelectorAsSelector(ls)
+	if err != nil {
+		return labels.Nothing()
+	}
+	return sel
+}
+
+// maxUnavailable returns how many gateways may be updating at once: all of
+// them when unset, and never fewer than one.
+func maxUnavailable(spec *stokerv1alpha1.RolloutSpec, total int) int {
+	if spec.MaxUnavailable == nil {
+		return max(total, 1)
+	}
+	n, err := intstr.GetScaledValueFromIntOrPercent(spec.MaxUnavailable, total, false)
+	if err != nil {
+		return 1
+	}
+	return max(n, 1)
+}
+
+// rolloutTargets returns the commit and ref published to every gateway in
+// the metadata ConfigMap, and the per-gateway targets of an unfinished
+// rollout. A rollback publishes its commit to every gateway. Gateways not yet updated stay on the stable commit, pinned by SHA
+// so a restart does not pick up a newer commit on the ref.
+func rolloutTargets(gs *stokerv1alpha1.GatewaySync, result git.Result) (string, string, map[string]stokertypes.GatewayTarget) {
+	st := gs.Status.Rollout
+	if gs.Spec.Rollout == nil || st == nil || st.Phase == rolloutComplete || st.StableCommit == "" || rollbackActive(gs) {
+		return result.Commit, result.Ref, nil
+	}
+	targets := make(map[string]stokertypes.GatewayTarget, len(st.UpdatedGateways))
+	for _, name := range st.UpdatedGateways {
+		targets[name] = stokertypes.GatewayTarget{Commit: st.Commit, Ref: st.Ref}
+	}
+	return st.StableCommit, st.StableCommit, targets
+}
@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"slices"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// fleet returns gateways gw-0..gw-(n-1), all synced at commit.
+func fleet(n int, commit string) []rolloutGateway {
+	gateways := make([]rolloutGateway, n)
+	for i := range gateways {
+		gateways[i] = rolloutGateway{
+			name:         "gw-" + string(rune('0'+i)),
+			labels:       labels.Set{},
+			syncStatus:   stokertypes.SyncStatusSynced,
+			syncedCommit: commit,
+		}
+	}
+	return gateways
+}
+
+// syncUpdated marks every updated gateway synced at the rollout commit.
+func syncUpdated(gateways []rolloutGateway, st *stokerv1alpha1.RolloutStatus) {
+	for i := range gateways {
+		if slices.Contains(st.UpdatedGateways, gateways[i].name) {
+			gateways[i].syncStatus = stokertypes.SyncStatusSynced
+			gateways[i].syncedCommit = st.Commit
+		}
+	}
+}
+
+func TestAdvanceRollout_CanaryThenWaves(t *testing.T) {
+	spec := &stokerv1alpha1.RolloutSpec{
+		Canary: &stokerv1alpha1.RolloutCanary{Profiles: []string{"canary"}},
+		Waves:  []stokerv1alpha1.RolloutWave{{Name: "half", Percent: ptr.To[int32](50)}},
+	}
+	gateways := fleet(6, "old")
+	gateways[4].profile = "canary"
+	st := &stokerv1alpha1.RolloutStatus{Commit: "new", StableCommit: "old", Phase: rolloutProgressing}
+
+	advanceRollout(spec, st, gateways)
+	if st.StepName != "canary" || !slices.Equal(st.UpdatedGateways, []string{"gw-4"}) {
+		t.Fatalf("canary step: got %s %v", st.StepName, st.UpdatedGateways)
+	}
+
+	// The canary has not synced yet, so the rollout waits.
+	advanceRollout(spec, st, gateways)
+	if st.StepName != "canary" || len(st.UpdatedGateways) != 1 {
+		t.Fatalf("should wait for canary: got %s %v", st.StepName, st.UpdatedGateways)
+	}
+
+	// 50% of 6 gateways, canary first: gw-4, gw-0, gw-1.
+	syncUpdated(gateways, st)
+	advanceRollout(spec, st, gateways)
+	if st.StepName != "half" || !slices.Equal(st.UpdatedGateways, []string{"gw-4", "gw-0", "gw-1"}) {
+		t.Fatalf("half step: got %s %v", st.StepName, st.UpdatedGateways)
+	}
+
+	syncUpdated(gateways, st)
+	advanceRollout(spec, st, gateways)
+	if st.StepName != "all" || len(st.UpdatedGateways) != 6 {
+		t.Fatalf("all step: got %s %v", st.StepName, st.UpdatedGateways)
+	}
+
+	syncUpdated(gateways, st)
+	advanceRollout(spec, st, gateways)
+	if st.Phase != rolloutComplete {
+		t.Errorf("expected Complete, got %s (%s)", st.Phase, st.Message)
+	}
+}
+
+func TestAdvanceRollout_SelectorWave(t *testing.T) {
+	spec := &stokerv1alpha1.RolloutSpec{
+		Waves: []stokerv1alpha1.RolloutWave{{
+			Name:     "site-a",
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"site": "a"}},
+		}},
+	}
+	gateways := fleet(3, "old")
+	gateways[2].labels = labels.Set{"site": "a"}
+	st := &stokerv1alpha1.RolloutStatus{Commit: "new", StableCommit: "old", Phase: rolloutProgressing}
+
+	advanceRollout(spec, st, gateways)
+	if st.StepName != "site-a" || !slices.Equal(st.UpdatedGateways, []string{"gw-2"}) {
+		t.Errorf("site-a step: got %s %v", st.StepName, st.UpdatedGateways)
+	}
+}
+
+func TestAdvanceRollout_MaxUnavailable(t *testing.T) {
+	spec := &stokerv1alpha1.RolloutSpec{MaxUnavailable: ptr.To(intstr.FromInt32(2))}
+	gateways := fleet(5, "old")
+	st := &stokerv1alpha1.RolloutStatus{Commit: "new", StableCommit: "old", Phase: rolloutProgressing}
+
+	advanceRollout(spec, st, gateways)
+	if !slices.Equal(st.UpdatedGateways, []string{"gw-0", "gw-1"}) {
+		t.Fatalf("first batch: got %v", st.UpdatedGateways)
+	}
+
+	// One gateway synced frees one slot.
+	gateways[0].syncedCommit = "new"
+	advanceRollout(spec, st, gateways)
+	if !slices.Equal(st.UpdatedGateways, []string{"gw-0", "gw-1", "gw-2"}) {
+		t.Errorf("second batch: got %v", st.UpdatedGateways)
+	}
+}
+
+func TestAdvanceRollout_HaltOnError(t *testing.T) {
+	gateways := fleet(3, "old")
+	st := &stokerv1alpha1.RolloutStatus{Commit: "new", StableCommit: "old", Phase: rolloutProgressing, UpdatedGateways: []string{"gw-0"}}
+	gateways[0].syncStatus = stokertypes.SyncStatusError
+
+	advanceRollout(&stokerv1alpha1.RolloutSpec{}, st, gateways)
+	if st.Phase != rolloutHalted {
+		t.Fatalf("expected Halted, got %s", st.Phase)
+	}
+
+	// A halted rollout admits no more gateways.
+	advanceRollout(&stokerv1alpha1.RolloutSpec{}, st, gateways)
+	if len(st.UpdatedGateways) != 1 {
+		t.Errorf("halted rollout admitted gateways: %v", st.UpdatedGateways)
+	}
+
+	// With haltOnError=false the rollout keeps going.
+	st = &stokerv1alpha1.RolloutStatus{Commit: "new", StableCommit: "old", Phase: rolloutProgressing, UpdatedGateways: []string{"gw-0"}}
+	advanceRollout(&stokerv1alpha1.RolloutSpec{HaltOnError: ptr.To(false)}, st, gateways)
+	if st.Phase != rolloutProgressing || len(st.UpdatedGateways) != 3 {
+		t.Errorf("expected Progressing with all gateways admitted, got %s %v", st.Phase, st.UpdatedGateways)
+	}
+}
+
+func TestRolloutTargets(t *testing.T) {
+	result := git.Result{Commit: "new", Ref: "main"}
+	gs := &stokerv1alpha1.GatewaySync{
+		Spec: stokerv1alpha1.GatewaySyncSpec{Rollout: &stokerv1alpha1.RolloutSpec{}},
+		Status: stokerv1alpha1.GatewaySyncStatus{Rollout: &stokerv1alpha1.RolloutStatus{
+			Commit: "new", Ref: "main", StableCommit: "old", Phase: rolloutProgressing, UpdatedGateways: []string{"gw-0"},
+		}},
+	}
+
+	commit, ref, targets := rolloutTargets(gs, result)
+	if commit != "old" || ref != "old" {
+		t.Errorf("stable: got %s@%s, want old pinned by SHA", ref, commit)
+	}
+	if targets["gw-0"] != (stokertypes.GatewayTarget{Commit: "new", Ref: "main"}) || len(targets) != 1 {
+		t.Errorf("unexpected targets: %v", targets)
+	}
+
+	gs.Status.Rollout.Phase = rolloutComplete
+	if commit, ref, targets := rolloutTargets(gs, result); commit != "new" || ref != "main" || targets != nil {
+		t.Errorf("complete: got %s@%s %v", ref, commit, targets)
+	}
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/internal/schedule"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// gateSchedule defers a newly resolved commit outside the spec.sync.schedule
+// windows, or during a blackout, by recording it in status.deferredCommit.
+// Returns the commit to continue with: the resolved commit when the
+// schedule allows it, otherwise the published one (status.lastSyncCommit).
+// The first commit is never deferred, and the schedule-override annotation
+// bypasses the schedule.
+func (r *GatewaySyncReconciler) gateSchedule(ctx context.Context, gs *stokerv1alpha1.GatewaySync, result git.Result, now time.Time) git.Result {
+	spec := gs.Spec.Sync.Schedule
+	if spec == nil {
+		gs.Status.DeferredCommit, gs.Status.NextWindow = "", nil
+		meta.RemoveStatusCondition(&gs.Status.Conditions, conditions.TypeDeferred)
+		return result
+	}
+
+	published := git.Result{Commit: gs.Status.LastSyncCommit, Ref: gs.Status.LastSyncRef}
+	if published.Commit == "" || result.Commit == published.Commit {
+		gs.Status.DeferredCommit, gs.Status.NextWindow = "", nil
+		r.setCondition(ctx, gs, conditions.TypeDeferred, metav1.ConditionFalse, conditions.ReasonWindowOpen, "No commit deferred")
+		return result
+	}
+
+	if by := gs.Annotations[stokertypes.AnnotationScheduleOverride]; by != "" {
+		if !conditionHasReason(gs.Status.Conditions, conditions.TypeDeferred, conditions.ReasonScheduleOverridden) {
+			r.Recorder.Eventf(gs, corev1.EventTypeWarning, conditions.ReasonScheduleOverridden,
+				"Schedule overridden by %s (%s): publishing %s", stokertypes.AnnotationScheduleOverride, by, git.DescribeCommit(result.Commit, result.Info))
+		}
+		gs.Status.DeferredCommit, gs.Status.NextWindow = "", nil
+		r.setCondition(ctx, gs, conditions.TypeDeferred, metav1.ConditionFalse, conditions.ReasonScheduleOverridden,
+			fmt.Sprintf("Schedule overridden: %s", by))
+		return result
+	}
+
+	var reason, message string
+	sched, err := schedule.New(schedule.Spec(spec))
+	switch {
+	case err != nil:
+		// A broken freeze must not let changes through.
+		gs.Status.NextWindow = nil
+		reason = conditions.ReasonScheduleInvalid
+		message = fmt.Sprintf("%s deferred: invalid spec.sync.schedule: %v", git.ShortCommit(result.Commit), err)
+	case sched.Open(now):
+		gs.Status.DeferredCommit, gs.Status.NextWindow = "", nil
+		r.setCondition(ctx, gs, conditions.TypeDeferred, metav1.ConditionFalse, conditions.ReasonWindowOpen, "Maintenance window open")
+		return result
+	default:
+		reason = conditions.ReasonOutsideWindow
+		message = fmt.Sprintf("%s deferred: outside maintenance windows", git.ShortCommit(result.Commit))
+		if why, frozen := sched.Blackout(now); frozen {
+			reason = conditions.ReasonBlackout
+			message = fmt.Sprintf("%s deferred: blackout", git.ShortCommit(result.Commit))
+			if why != "" {
+				message += " (" + why + ")"
+			}
+		}
+		if next, ok := sched.NextOpen(now); ok {
+			gs.Status.NextWindow = &metav1.Time{Time: next}
+			message += fmt.Sprintf("; next window opens %s", next.Format(time.RFC3339))
+		} else {
+			gs.Status.NextWindow = nil
+			message += "; no window opens again"
+		}
+	}
+
+	if gs.Status.DeferredCommit != result.Commit {
+		r.Recorder.Event(gs, corev1.EventTypeNormal, conditions.ReasonCommitDeferred, message)
+	}
+	gs.Status.DeferredCommit = result.Commit
+	r.setCondition(ctx, gs, conditions.TypeDeferred, metav1.ConditionTrue, reason, message)
+	return published
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/pkg/conditions"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// scheduleFixture returns a CR publishing approvedSHA with a nightly 02:00
+// UTC window and a holiday blackout.
+func scheduleFixture() *stokerv1alpha1.GatewaySync {
+	gs := approvalFixture()
+	gs.Spec.Approval = nil
+	gs.Spec.Sync.Schedule = &stokerv1alpha1.SyncSchedule{
+		Windows:   []stokerv1alpha1.ScheduleWindow{{Start: "0 2 * * *", Duration: "1h"}},
+		Blackouts: []stokerv1alpha1.ScheduleBlackout{{Start: "2026-12-24", End: "2026-12-26", Reason: "holiday freeze"}},
+	}
+	return gs
+}
+
+func TestGateSchedule(t *testing.T) {
+	resolved := git.Result{Commit: pendingSHA, Ref: "main"}
+	cases := []struct {
+		name      string
+		now       time.Time
+		override  string
+		published string
+		reason    string
+		next      time.Time
+	}{
+		{
+			name:      "inside window",
+			now:       time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC),
+			published: pendingSHA,
+			reason:    conditions.ReasonWindowOpen,
+		},
+		{
+			name:      "outside window",
+			now:       time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+			published: approvedSHA,
+			reason:    conditions.ReasonOutsideWindow,
+			next:      time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "blackout",
+			now:       time.Date(2026, 12, 25, 2, 30, 0, 0, time.UTC),
+			published: approvedSHA,
+			reason:    conditions.ReasonBlackout,
+			next:      time.Date(2026, 12, 27, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "override",
+			now:       time.Date(2026, 12, 25, 2, 30, 0, 0, time.UTC),
+			override:  "jane: line 3 alarm fix",
+			published: pendingSHA,
+			reason:    conditions.ReasonScheduleOverridden,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gs := scheduleFixture()
+			if tc.override != "" {
+				gs.Annotations = map[string]string{stokertypes.AnnotationScheduleOverride: tc.override}
+			}
+			r := &GatewaySyncReconciler{Recorder: record.NewFakeRecorder(10)}
+
+			got := r.gateSchedule(context.Background(), gs, resolved, tc.now)
+			if got.Commit != tc.published {
+				t.Fatalf("published %s, want %s", got.Commit, tc.published)
+			}
+			if !conditionHasReason(gs.Status.Conditions, conditions.TypeDeferred, tc.reason) {
+				t.Errorf("expected Deferred reason %s, got %+v", tc.reason, apimeta.FindStatusCondition(gs.Status.Conditions, conditions.TypeDeferred))
+			}
+			deferred := tc.published != pendingSHA
+			if deferred != (gs.Status.DeferredCommit == pendingSHA) {
+				t.Errorf("deferredCommit = %q", gs.Status.DeferredCommit)
+			}
+			if !tc.next.IsZero() && (gs.Status.NextWindow == nil || !gs.Status.NextWindow.Time.Equal(tc.next)) {
+				t.Errorf("nextWindow = %v, want %s", gs.Status.NextWindow, tc.next)
+			}
+		})
+	}
+}
+
+func TestGateSchedule_FirstCommit(t *testing.T) {
+	gs := scheduleFixture()
+	gs.Status = stokerv1alpha1.GatewaySyncStatus{}
+	r := &GatewaySyncReconciler{Recorder: record.NewFakeRecorder(10)}
+
+	got := r.gateSchedule(context.Background(), gs, git.Result{Commit: pendingSHA, Ref: "main"}, time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	if got.Commit != pendingSHA {
+		t.Errorf("the first commit should never be deferred, published %s", got.Commit)
+	}
+}
+
+func TestGateSchedule_InvalidDefers(t *testing.T) {
+	gs := scheduleFixture()
+	gs.Spec.Sync.Schedule.TimeZone = "Mars/Olympus"
+	r := &GatewaySyncReconciler{Recorder: record.NewFakeRecorder(10)}
+
+	got := r.gateSchedule(context.Background(), gs, git.Result{Commit: pendingSHA, Ref: "main"}, time.Date(2026, 3, 1, 2, 30, 0, 0, time.UTC))
+	if got.Commit != approvedSHA || !conditionHasReason(gs.Status.Conditions, conditions.TypeDeferred, conditions.ReasonScheduleInvalid) {
+		t.Errorf("an invalid schedule should defer, published %s", got.Commit)
+	}
+}
@@ -10,9 +10,13 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -100,3 +104,19 @@ func getFirstFoundEnvTestBinaryDir() string {
 	}
 	return ""
 }
+
+// newFakeClient returns a fake client seeded with objs and the scheme it uses,
+// which registers the core, RBAC, and stoker types. GatewaySync and
+// GatewaySyncSet status is a subresource, as on a real API server.
+func newFakeClient(t *testing.T, objs ...client.Object) (client.Client, *runtime.Scheme) {
+	t.Helper()
+	s := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, rbacv1.AddToScheme, stokerv1alpha1.AddToScheme} {
+		if err := add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).
+		WithStatusSubresource(&stokerv1alpha1.GatewaySyncSet{}, &stokerv1alpha1.GatewaySync{}).Build()
+	return c, s
+}
@@ -71,13 +71,35 @@ func resolveSSHAuth(ctx context.Context, c client.Client, namespace string, sshA
 		return nil, fmt.Errorf("key %q not found in secret %s/%s", sshAuth.SecretRef.Key, namespace, sshAuth.SecretRef.Name)
 	}
 
+	var knownHosts []byte
+	if sshAuth.KnownHosts != nil {
+		var err error
+		if knownHosts, err = readKnownHosts(ctx, c, namespace, sshAuth.KnownHosts); err != nil {
+			return nil, err
+		}
+	}
+	return NewSSHAuth(pemBytes, knownHosts)
+}
+
+// SSHAuth is SSH public key auth that keeps the raw key and known_hosts
+// data, so NativeGitClient can hand them to the ssh binary.
+type SSHAuth struct {
+	*gogitssh.PublicKeys
+	PrivateKey []byte
+	KnownHosts []byte
+}
+
+// NewSSHAuth builds SSH auth for user "git" from a PEM private key. With
+// known_hosts data the host key is checked against it; without, any host
+// key is accepted.
+func NewSSHAuth(pemBytes, knownHosts []byte) (*SSHAuth, error) {
 	publicKey, err := gogitssh.NewPublicKeys("git", pemBytes, "")
 	if err != nil {
 		return nil, fmt.Errorf("parsing SSH private key: %w", err)
 	}
 
-	if sshAuth.KnownHosts != nil {
-		hostKeyCallback, err := resolveKnownHosts(ctx, c, namespace, sshAuth.KnownHosts)
+	if len(knownHosts) > 0 {
+		hostKeyCallback, err := knownHostsCallback(knownHosts)
 		if err != nil {
 			return nil, err
 		}
@@ -86,12 +108,11 @@ func resolveSSHAuth(ctx context.Context, c client.Client, namespace string, sshA
 		publicKey.HostKeyCallback = ssh.InsecureIgnoreHostKey()
 	}
 
-	return publicKey, nil
+	return &SSHAuth{PublicKeys: publicKey, PrivateKey: pemBytes, KnownHosts: knownHosts}, nil
 }
 
-// resolveKnownHosts reads a known_hosts Secret and returns a HostKeyCallback.
-// Uses a temp file because knownhosts.New() requires a file path.
-func resolveKnownHosts(ctx context.Context, c client.Client, namespace string, kh *stokerv1alpha1.KnownHosts) (ssh.HostKeyCallback, error) {
+// readKnownHosts reads the known_hosts data from its Secret.
+func readKnownHosts(ctx context.Context, c client.Client, namespace string, kh *stokerv1alpha1.KnownHosts) ([]byte, error) {
 	khSecret := &corev1.Secret{}
 	khKey := types.NamespacedName{Name: kh.SecretRef.Name, Namespace: namespace}
 	if err := c.Get(ctx, khKey, khSecret); err != nil {
@@ -101,7 +122,12 @@ func resolveKnownHosts(ctx context.Context, c client.Client, namespace string, k
 	if !ok {
 		return nil, fmt.Errorf("key %q not found in known_hosts secret %s/%s", kh.SecretRef.Key, namespace, kh.SecretRef.Name)
 	}
+	return khData, nil
+}
 
+// knownHostsCallback returns a HostKeyCallback for known_hosts data.
+// Uses a temp file because knownhosts.New() requires a file path.
+func knownHostsCallback(khData []byte) (ssh.HostKeyCallback, error) {
 	tmpFile, err := os.CreateTemp("", "stoker-known-hosts-*")
 	if err != nil {
 		return nil, fmt.Errorf("creating temp known_hosts file: %w", err)
@@ -11,7 +11,6 @@ import (
 
 	"crypto/x509"
 
-	gogitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"golang.org/x/crypto/ssh"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -73,9 +72,9 @@ func TestResolveSSHAuth_WithoutKnownHosts(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	pk, ok := auth.(*gogitssh.PublicKeys)
+	pk, ok := auth.(*SSHAuth)
 	if !ok {
-		t.Fatalf("expected *gogitssh.PublicKeys, got %T", auth)
+		t.Fatalf("expected *SSHAuth, got %T", auth)
 	}
 
 	// Without knownHosts, should accept any host key (InsecureIgnoreHostKey).
@@ -112,9 +111,9 @@ func TestResolveSSHAuth_WithKnownHosts(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	pk, ok := auth.(*gogitssh.PublicKeys)
+	pk, ok := auth.(*SSHAuth)
 	if !ok {
-		t.Fatalf("expected *gogitssh.PublicKeys, got %T", auth)
+		t.Fatalf("expected *SSHAuth, got %T", auth)
 	}
 
 	// Should accept the matching host key.
@@ -1,6 +1,14 @@
 package git
 
 import (
+	"context"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/go-git/go-git/v5/plumbing"
@@ -117,3 +125,169 @@ func TestMatchRef_NotFound(t *testing.T) {
 		t.Fatal("expected error for nonexistent ref")
 	}
 }
+
+func TestSparsePatterns(t *testing.T) {
+	got := sparsePatterns([]string{"sites/a/", "shared/config", "./sites/a", "odd[1]/*.json"})
+	want := []string{`/odd\[1]/\*.json`, "/shared/config", "/sites/a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := sparsePatterns([]string{"config", "."}); got != nil {
+		t.Errorf("repo root should disable sparse checkout, got %v", got)
+	}
+	if got := sparsePatterns(nil); got != nil {
+		t.Errorf("no paths should disable sparse checkout, got %v", got)
+	}
+}
+
+// sparseTestRepo creates a repo with files under sites/a, sites/b, and shared
+// that serves partial clones, and returns a function running git in it.
+func sparseTestRepo(t *testing.T) (string, func(args ...string) string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=CI Bot", "-c", "user.email=ci@example.com"}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	for _, f := range []string{"sites/a/config.json", "sites/b/config.json", "shared/common.json", "README"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(f)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, f), []byte(f), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "uploadpack.allowFilter", "true")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+	return dir, run
+}
+
+// checkedOut returns the files in a clone outside .git, sorted.
+func checkedOut(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			rel, _ := filepath.Rel(dir, p)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestNativeGitClient_SparseCheckout(t *testing.T) {
+	src, run := sparseTestRepo(t)
+	dst := filepath.Join(t.TempDir(), "repo")
+	client := &NativeGitClient{}
+	ctx := context.Background()
+
+	result, err := client.CloneOrFetch(ctx, "file://"+src, "main", dst, []string{"sites/a", "shared/common.json"}, nil)
+	if err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	if result.Commit != run("rev-parse", "HEAD") {
+		t.Errorf("commit: got %s", result.Commit)
+	}
+	if got, want := checkedOut(t, dst), []string{"shared/common.json", "sites/a/config.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sparse clone: got %v, want %v", got, want)
+	}
+	if out, err := runGit(ctx, []string{"config", "remote.origin.partialclonefilter"}, dst, nil); err != nil || out != "blob:none" {
+		t.Errorf("expected a blobless partial clone, got %q (%v)", out, err)
+	}
+
+	// A new commit with different paths removes files that no longer match.
+	if err := os.WriteFile(filepath.Join(src, "sites/b/extra.json"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-q", "-m", "second")
+	if _, err := client.CloneOrFetch(ctx, "file://"+src, "main", dst, []string{"sites/b"}, nil); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if got, want := checkedOut(t, dst), []string{"sites/b/config.json", "sites/b/extra.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sparse fetch: got %v, want %v", got, want)
+	}
+
+	// No paths restores the whole tree.
+	if _, err := client.CloneOrFetch(ctx, "file://"+src, "main", dst, nil, nil); err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if got := checkedOut(t, dst); len(got) != 5 {
+		t.Errorf("full checkout: got %v", got)
+	}
+}
+
+func TestGoGitClient_SparseCheckout(t *testing.T) {
+	src, _ := sparseTestRepo(t)
+	dst := filepath.Join(t.TempDir(), "repo")
+
+	if _, err := (&GoGitClient{}).CloneOrFetch(context.Background(), "file://"+src, "main", dst, []string{"sites/a"}, nil); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+	if got, want := checkedOut(t, dst), []string{"sites/a/config.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Widening the sparse set, then dropping it, writes the newly included paths.
+	if _, err := (&GoGitClient{}).CloneOrFetch(context.Background(), "file://"+src, "main", dst, []string{"sites/a", "sites/b"}, nil); err != nil {
+		t.Fatalf("fetch widened: %v", err)
+	}
+	if got, want := checkedOut(t, dst), []string{"sites/a/config.json", "sites/b/config.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("widened: got %v, want %v", got, want)
+	}
+	if _, err := (&GoGitClient{}).CloneOrFetch(context.Background(), "file://"+src, "main", dst, nil, nil); err != nil {
+		t.Fatalf("fetch full: %v", err)
+	}
+	if got, want := checkedOut(t, dst), []string{"README", "shared/common.json", "sites/a/config.json", "sites/b/config.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("full: got %v, want %v", got, want)
+	}
+}
+
+func TestGoGitClient_SparseCheckoutNarrows(t *testing.T) {
+	src, run := sparseTestRepo(t)
+	if err := os.MkdirAll(filepath.Join(src, "sites/ab"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sites/ab/config.json"), []byte("ab"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-q", "-m", "sibling")
+	dst := filepath.Join(t.TempDir(), "repo")
+	ctx := context.Background()
+
+	if _, err := (&GoGitClient{}).CloneOrFetch(ctx, "file://"+src, "main", dst, nil, nil); err != nil {
+		t.Fatalf("clone: %v", err)
+	}
+
+	// Narrowing to one directory and one file drops everything else, including
+	// the sibling directory that shares the "sites/a" prefix.
+	if _, err := (&GoGitClient{}).CloneOrFetch(ctx, "file://"+src, "main", dst, []string{"sites/a", "README"}, nil); err != nil {
+		t.Fatalf("fetch narrowed: %v", err)
+	}
+	if got, want := checkedOut(t, dst), []string{"README", "sites/a/config.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("narrowed: got %v, want %v", got, want)
+	}
+}
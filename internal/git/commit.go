@@ -0,0 +1,166 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	transportclient "github.com/go-git/go-git/v5/plumbing/transport/client"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// CommitInfo describes a commit: who made it, what it says, and when.
+type CommitInfo struct {
+	Author      string
+	AuthorEmail string
+	Committer   string
+	Subject     string
+	Message     string
+	Time        time.Time // committer time
+}
+
+// IsZero reports whether no commit details are known.
+func (c CommitInfo) IsZero() bool {
+	return c.Author == "" && c.Subject == "" && c.Time.IsZero()
+}
+
+// Status converts the details for a status ConfigMap. Returns nil when
+// nothing is known about the commit.
+func (c CommitInfo) Status() *stokertypes.CommitInfo {
+	if c.IsZero() {
+		return nil
+	}
+	out := &stokertypes.CommitInfo{Author: c.Author, Committer: c.Committer, Subject: c.Subject}
+	if !c.Time.IsZero() {
+		out.Time = c.Time.UTC().Format(time.RFC3339)
+	}
+	return out
+}
+
+// ShortCommit returns the first 7 characters of a commit SHA, or the full string if shorter.
+func ShortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// DescribeCommit formats a commit for events, e.g. `abc1234 "Fix tags" by Jane Doe`.
+func DescribeCommit(sha string, info CommitInfo) string {
+	desc := ShortCommit(sha)
+	if info.Subject != "" {
+		desc += fmt.Sprintf(" %q", info.Subject)
+	}
+	if info.Author != "" {
+		desc += " by " + info.Author
+	}
+	return desc
+}
+
+// ReadCommit reads commit metadata from the clone at path. It works on
+// clones made by either client, including shallow clones.
+func ReadCommit(path, commit string) (CommitInfo, error) {
+	repo, err := gogit.PlainOpen(path)
+	if err != nil {
+		return CommitInfo{}, fmt.Errorf("opening repo at %s: %w", path, err)
+	}
+	c, err := repo.CommitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return CommitInfo{}, fmt.Errorf("reading commit %s: %w", commit, err)
+	}
+	return commitInfo(c), nil
+}
+
+// FetchCommit downloads a single commit object from the remote and returns
+// its metadata without cloning. The request is shallow (depth 1) and, when
+// the server supports partial clone, filtered to omit trees and blobs, so
+// the cost is independent of repository size. The commit must be one the
+// server allows fetching by SHA; branch and tag tips always are.
+func (g *GoGitClient) FetchCommit(ctx context.Context, repoURL, commit string, auth transport.AuthMethod) (CommitInfo, error) {
+	hash := plumbing.NewHash(commit)
+	st, err := g.fetchObject(ctx, repoURL, hash, auth)
+	if err != nil {
+		return CommitInfo{}, err
+	}
+	c, err := object.GetCommit(st, hash)
+	if err != nil {
+		return CommitInfo{}, fmt.Errorf("reading commit %s: %w", commit, err)
+	}
+	return commitInfo(c), nil
+}
+
+// fetchObject downloads the object hash (a commit or annotated tag) and, for
+// a tag, the commit it points to, into memory. Trees and blobs are omitted
+// when the server supports partial clone.
+func (g *GoGitClient) fetchObject(ctx context.Context, repoURL string, hash plumbing.Hash, auth transport.AuthMethod) (*memory.Storage, error) {
+	return g.fetchObjects(ctx, repoURL, []plumbing.Hash{hash}, packp.FilterTreeDepth(0), auth)
+}
+
+// fetchObjects downloads the objects wants at depth 1 into memory, applying
+// filter when the server supports partial clone.
+func (g *GoGitClient) fetchObjects(ctx context.Context, repoURL string, wants []plumbing.Hash, filter packp.Filter, auth transport.AuthMethod) (*memory.Storage, error) {
+	ep, err := g.endpoint(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	cli, err := transportclient.NewClient(ep)
+	if err != nil {
+		return nil, fmt.Errorf("creating transport for %s: %w", repoURL, err)
+	}
+	sess, err := cli.NewUploadPackSession(ep, auth)
+	if err != nil {
+		return nil, fmt.Errorf("opening session for %s: %w", repoURL, err)
+	}
+	defer func() { _ = sess.Close() }()
+
+	ar, err := sess.AdvertisedReferencesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ls-remote %s: %w", repoURL, err)
+	}
+
+	req := packp.NewUploadPackRequest()
+	req.Wants = wants
+	if ar.Capabilities.Supports(capability.Shallow) {
+		_ = req.Capabilities.Set(capability.Shallow)
+		req.Depth = packp.DepthCommits(1)
+	}
+	if ar.Capabilities.Supports(capability.Filter) {
+		_ = req.Capabilities.Set(capability.Filter)
+		req.Filter = filter
+	}
+
+	resp, err := sess.UploadPack(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching objects %v: %w", wants, err)
+	}
+	defer func() { _ = resp.Close() }()
+
+	st := memory.NewStorage()
+	if err := packfile.UpdateObjectStorage(st, resp); err != nil {
+		return nil, fmt.Errorf("reading pack for objects %v: %w", wants, err)
+	}
+	return st, nil
+}
+
+func commitInfo(c *object.Commit) CommitInfo {
+	message := strings.TrimSpace(c.Message)
+	subject, _, _ := strings.Cut(message, "\n")
+	return CommitInfo{
+		Author:      c.Author.Name,
+		AuthorEmail: c.Author.Email,
+		Committer:   c.Committer.Name,
+		Subject:     strings.TrimSpace(subject),
+		Message:     message,
+		Time:        c.Committer.When.UTC(),
+	}
+}
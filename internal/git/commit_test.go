@@ -0,0 +1,106 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitTestRepo creates a repo at a temp path with one commit and returns
+// the path and commit SHA.
+func commitTestRepo(t *testing.T, message string, when time.Time) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("README"); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := wt.Commit(message, &gogit.CommitOptions{
+		Author:    &object.Signature{Name: "Jane Doe", Email: "jane@example.com", When: when},
+		Committer: &object.Signature{Name: "CI Bot", Email: "ci@example.com", When: when},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, hash.String()
+}
+
+func TestReadCommit(t *testing.T) {
+	when := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	dir, sha := commitTestRepo(t, "Raise tank alarm limits\n\nPer change request 42.\n", when)
+
+	info, err := ReadCommit(dir, sha)
+	if err != nil {
+		t.Fatalf("ReadCommit: %v", err)
+	}
+	if info.Author != "Jane Doe" || info.AuthorEmail != "jane@example.com" || info.Committer != "CI Bot" {
+		t.Errorf("unexpected identities: %+v", info)
+	}
+	if info.Subject != "Raise tank alarm limits" || info.Message != "Raise tank alarm limits\n\nPer change request 42." {
+		t.Errorf("unexpected message: subject=%q message=%q", info.Subject, info.Message)
+	}
+	if !info.Time.Equal(when) {
+		t.Errorf("time: got %v, want %v", info.Time, when)
+	}
+
+	if _, err := ReadCommit(dir, "0000000000000000000000000000000000000000"); err == nil {
+		t.Error("expected error for unknown commit")
+	}
+}
+
+func TestFetchCommit(t *testing.T) {
+	when := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	dir, sha := commitTestRepo(t, "Raise tank alarm limits\n", when)
+
+	check := func(t *testing.T) {
+		info, err := (&GoGitClient{}).FetchCommit(context.Background(), "file://"+dir, sha, nil)
+		if err != nil {
+			t.Fatalf("FetchCommit: %v", err)
+		}
+		if info.Author != "Jane Doe" || info.Committer != "CI Bot" || info.Subject != "Raise tank alarm limits" || !info.Time.Equal(when) {
+			t.Errorf("unexpected commit info: %+v", info)
+		}
+	}
+	t.Run("full pack", check)
+
+	// With partial clone enabled the server omits the tree.
+	repo, err := gogit.PlainOpen(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Raw.Section("uploadpack").SetOption("allowFilter", "true")
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Run("filtered", check)
+}
+
+func TestDescribeCommit(t *testing.T) {
+	sha := "4f2a9c1e0b7d4f2a9c1e0b7d4f2a9c1e0b7d4f2a"
+	if got := DescribeCommit(sha, CommitInfo{}); got != "4f2a9c1" {
+		t.Errorf("without details: got %q", got)
+	}
+	got := DescribeCommit(sha, CommitInfo{Author: "Jane Doe", Subject: "Raise tank alarm limits"})
+	if want := `4f2a9c1 "Raise tank alarm limits" by Jane Doe`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,255 @@
+package git
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gogithttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// conformanceRepo is a served test repository: main is one commit ahead of
+// the annotated tag v1.0.0.
+type conformanceRepo struct {
+	dir       string
+	main      string
+	tagCommit string
+	tagObject string
+}
+
+func newConformanceRepo(t *testing.T) conformanceRepo {
+	t.Helper()
+	dir, run := sparseTestRepo(t)
+	run("config", "uploadpack.allowReachableSHA1InWant", "true")
+	run("tag", "-a", "-m", "Release 1.0.0", "v1.0.0")
+	tagCommit := run("rev-parse", "HEAD")
+	tagObject := run("rev-parse", "v1.0.0")
+	run("rm", "-q", "README")
+	run("commit", "-q", "-m", "Remove README")
+	return conformanceRepo{dir: dir, main: run("rev-parse", "HEAD"), tagCommit: tagCommit, tagObject: tagObject}
+}
+
+// serveHTTPS serves the repository over smart HTTPS with git http-backend,
+// requiring basic auth user:pass. Returns the repo URL and the server's CA.
+func serveHTTPS(t *testing.T, dir, user, pass string) (string, []byte) {
+	t.Helper()
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not available")
+	}
+	backend := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + filepath.Dir(dir), "GIT_HTTP_EXPORT_ALL=1"},
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != user || p != pass {
+			w.Header().Set("WWW-Authenticate", `Basic realm="git"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	return srv.URL + "/" + filepath.Base(dir), ca
+}
+
+// TestClientConformance runs the same checks against both Client
+// implementations over file:// and authenticated HTTPS with a private CA.
+func TestClientConformance(t *testing.T) {
+	repo := newConformanceRepo(t)
+	httpsURL, ca := serveHTTPS(t, repo.dir, "x-access-token", "s3cret")
+
+	transports := []struct {
+		name string
+		url  string
+		opts TransportOptions
+		auth transport.AuthMethod
+	}{
+		{name: "file", url: "file://" + repo.dir},
+		{
+			name: "https",
+			url:  httpsURL,
+			opts: TransportOptions{CABundle: ca},
+			auth: &gogithttp.BasicAuth{Username: "x-access-token", Password: "s3cret"},
+		},
+	}
+
+	for _, kind := range []string{ClientGoGit, ClientNative} {
+		for _, tr := range transports {
+			t.Run(kind+"/"+tr.name, func(t *testing.T) {
+				ctx := context.Background()
+				c, err := NewClient(kind, tr.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for ref, want := range map[string]Result{
+					"main":      {Commit: repo.main, Ref: "main"},
+					"v1.0.0":    {Commit: repo.tagCommit, Ref: "v1.0.0", Tag: repo.tagObject},
+					repo.main:   {Commit: repo.main, Ref: repo.main},
+					"semver:^1": {Commit: repo.tagCommit, Ref: "v1.0.0", Tag: repo.tagObject},
+					"glob:v1.*": {Commit: repo.tagCommit, Ref: "v1.0.0", Tag: repo.tagObject},
+				} {
+					got, err := c.LsRemote(ctx, tr.url, ref, tr.auth)
+					if err != nil {
+						t.Fatalf("LsRemote(%s): %v", ref, err)
+					}
+					if got != want {
+						t.Errorf("LsRemote(%s) = %+v, want %+v", ref, got, want)
+					}
+				}
+				if _, err := c.LsRemote(ctx, tr.url, "missing", tr.auth); err == nil {
+					t.Error("LsRemote(missing) should fail")
+				}
+
+				path := filepath.Join(t.TempDir(), "clone")
+				res, err := c.CloneOrFetch(ctx, tr.url, "v1.0.0", path, nil, tr.auth)
+				if err != nil {
+					t.Fatalf("clone: %v", err)
+				}
+				if res.Commit != repo.tagCommit {
+					t.Errorf("clone checked out %s, want %s", res.Commit, repo.tagCommit)
+				}
+				res, err = c.CloneOrFetch(ctx, tr.url, "main", path, nil, tr.auth)
+				if err != nil {
+					t.Fatalf("fetch: %v", err)
+				}
+				if res.Commit != repo.main || res.Info.Subject != "Remove README" {
+					t.Errorf("fetch checked out %s (%q), want %s", res.Commit, res.Info.Subject, repo.main)
+				}
+				res, err = c.CloneOrFetch(ctx, tr.url, repo.tagCommit, path, nil, tr.auth)
+				if err != nil {
+					t.Fatalf("fetch by SHA: %v", err)
+				}
+				if res.Commit != repo.tagCommit {
+					t.Errorf("fetch by SHA checked out %s, want %s", res.Commit, repo.tagCommit)
+				}
+
+				// A commit behind the branch heads can also start a clone.
+				pinned := filepath.Join(t.TempDir(), "pinned")
+				for _, sparse := range [][]string{nil, {"README"}} {
+					res, err = c.CloneOrFetch(ctx, tr.url, repo.tagCommit, pinned, sparse, tr.auth)
+					if err != nil {
+						t.Fatalf("clone by SHA (sparse %v): %v", sparse, err)
+					}
+					if res.Commit != repo.tagCommit {
+						t.Errorf("clone by SHA checked out %s, want %s", res.Commit, repo.tagCommit)
+					}
+					pinned = filepath.Join(t.TempDir(), "pinned-sparse")
+				}
+
+				info, err := c.FetchCommit(ctx, tr.url, repo.main, tr.auth)
+				if err != nil {
+					t.Fatalf("FetchCommit: %v", err)
+				}
+				if info.Subject != "Remove README" || info.Committer != "CI Bot" {
+					t.Errorf("unexpected commit info: %+v", info)
+				}
+
+				diff, err := c.DiffCommits(ctx, tr.url, repo.tagCommit, repo.main, tr.auth)
+				if err != nil {
+					t.Fatalf("DiffCommits: %v", err)
+				}
+				if diff.Files() != 1 || len(diff.Deleted) != 1 || diff.Deleted[0] != "README" {
+					t.Errorf("DiffCommits = %+v, want README deleted", diff)
+				}
+
+				_, pub := pgpKey(t, "release@example.com")
+				keys, err := ParseTrustedKeys(map[string][]byte{"release.asc": pub})
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, err = c.VerifyRemote(ctx, tr.url, Result{Commit: repo.main, Ref: "main"}, VerifySignedCommit, keys, tr.auth)
+				if !errors.Is(err, ErrUnsigned) {
+					t.Errorf("VerifyRemote(commit): expected ErrUnsigned, got %v", err)
+				}
+				tag := Result{Commit: repo.tagCommit, Ref: "v1.0.0", Tag: repo.tagObject}
+				_, err = c.VerifyRemote(ctx, tr.url, tag, VerifySignedTag, keys, tr.auth)
+				if !errors.Is(err, ErrUnsigned) {
+					t.Errorf("VerifyRemote(tag): expected ErrUnsigned, got %v", err)
+				}
+			})
+		}
+	}
+}
+
+// TestClientConformance_HTTPSFailures checks that both clients reject a
+// wrong password and an untrusted server certificate without leaking the
+// credentials in the error.
+func TestClientConformance_HTTPSFailures(t *testing.T) {
+	repo := newConformanceRepo(t)
+	url, ca := serveHTTPS(t, repo.dir, "x-access-token", "s3cret")
+
+	for _, kind := range []string{ClientGoGit, ClientNative} {
+		t.Run(kind, func(t *testing.T) {
+			ctx := context.Background()
+
+			trusted, err := NewClient(kind, TransportOptions{CABundle: ca})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = trusted.LsRemote(ctx, url, "main", &gogithttp.BasicAuth{Username: "x-access-token", Password: "wrong-pass"})
+			if err == nil {
+				t.Fatal("expected an error with a wrong password")
+			}
+			if strings.Contains(err.Error(), "wrong-pass") {
+				t.Errorf("error leaks the password: %v", err)
+			}
+
+			untrusted, err := NewClient(kind, TransportOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := untrusted.LsRemote(ctx, url, "main", &gogithttp.BasicAuth{Username: "x-access-token", Password: "s3cret"}); err == nil {
+				t.Error("expected an error for a certificate outside the CA bundle")
+			}
+		})
+	}
+}
+
+// TestNativeGitClient_OptionLikeArguments checks that a repo URL or ref
+// starting with "-" is never parsed as a git option such as --upload-pack.
+func TestNativeGitClient_OptionLikeArguments(t *testing.T) {
+	repo := newConformanceRepo(t)
+	marker := filepath.Join(t.TempDir(), "pwned")
+	inject := "--upload-pack=touch " + marker + "; git-upload-pack"
+	ctx := context.Background()
+	client := &NativeGitClient{}
+
+	if _, err := client.LsRemote(ctx, inject, "main", nil); err == nil {
+		t.Error("expected ls-remote of an option-like URL to fail")
+	}
+	if _, err := client.FetchCommit(ctx, inject, repo.main, nil); err == nil {
+		t.Error("expected fetch from an option-like URL to fail")
+	}
+	if _, err := client.CloneOrFetch(ctx, inject, "main", filepath.Join(t.TempDir(), "clone"), nil, nil); err == nil {
+		t.Error("expected clone of an option-like URL to fail")
+	}
+	path := filepath.Join(t.TempDir(), "repo")
+	if _, err := client.CloneOrFetch(ctx, "file://"+repo.dir, "main", path, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CloneOrFetch(ctx, "file://"+repo.dir, inject, path, nil, nil); err == nil {
+		t.Error("expected fetch of an option-like ref to fail")
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatal("git ran the injected upload-pack command")
+	}
+}
+
+func TestNewClient_Unknown(t *testing.T) {
+	if _, err := NewClient("libgit2", TransportOptions{}); err == nil {
+		t.Error("expected an error for an unknown client")
+	}
+}
@@ -0,0 +1,73 @@
+package git
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+)
+
+// DiffSummary lists the repo-relative paths that differ between two
+// commits. Renames appear as a deletion and an addition.
+type DiffSummary struct {
+	Added    []string
+	Modified []string
+	Deleted  []string
+}
+
+// Files returns the number of changed paths.
+func (d DiffSummary) Files() int {
+	return len(d.Added) + len(d.Modified) + len(d.Deleted)
+}
+
+// DiffCommits fetches both commits with their trees but no blobs (when the
+// server supports partial clone) and compares them.
+func (g *GoGitClient) DiffCommits(ctx context.Context, repoURL, from, to string, auth transport.AuthMethod) (DiffSummary, error) {
+	fromHash, toHash := plumbing.NewHash(from), plumbing.NewHash(to)
+	st, err := g.fetchObjects(ctx, repoURL, []plumbing.Hash{fromHash, toHash}, packp.FilterBlobNone(), auth)
+	if err != nil {
+		return DiffSummary{}, err
+	}
+	return diffCommits(st, fromHash, toHash)
+}
+
+// diffCommits compares the trees of two commits in s. Only tree entries are
+// read, so blobs need not be present.
+func diffCommits(s storer.EncodedObjectStorer, from, to plumbing.Hash) (DiffSummary, error) {
+	var trees [2]*object.Tree
+	for i, hash := range []plumbing.Hash{from, to} {
+		c, err := object.GetCommit(s, hash)
+		if err != nil {
+			return DiffSummary{}, fmt.Errorf("reading commit %s: %w", hash, err)
+		}
+		if trees[i], err = c.Tree(); err != nil {
+			return DiffSummary{}, fmt.Errorf("reading tree of %s: %w", hash, err)
+		}
+	}
+	changes, err := object.DiffTree(trees[0], trees[1])
+	if err != nil {
+		return DiffSummary{}, fmt.Errorf("diffing %s..%s: %w", from, to, err)
+	}
+
+	var d DiffSummary
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return DiffSummary{}, err
+		}
+		switch action {
+		case merkletrie.Insert:
+			d.Added = append(d.Added, change.To.Name)
+		case merkletrie.Delete:
+			d.Deleted = append(d.Deleted, change.From.Name)
+		default:
+			d.Modified = append(d.Modified, change.To.Name)
+		}
+	}
+	return d, nil
+}
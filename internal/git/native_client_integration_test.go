@@ -10,15 +10,31 @@ import (
 	"time"
 )
 
+// integrationSSHAuth builds SSH auth from the key in GIT_SSH_KEY_FILE,
+// skipping the test when it is not set.
+func integrationSSHAuth(t *testing.T) *SSHAuth {
+	t.Helper()
+	keyFile := os.Getenv("GIT_SSH_KEY_FILE")
+	if keyFile == "" {
+		t.Skip("GIT_SSH_KEY_FILE not set")
+	}
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		t.Fatalf("reading %s: %v", keyFile, err)
+	}
+	auth, err := NewSSHAuth(key, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
 // TestNativeGitClientClone_PublicDemo validates that NativeGitClient can clone
 // the publicdemo-all repo without OOMing. Run with:
 //
 //	GIT_SSH_KEY_FILE=/tmp/test-ssh-key go test ./internal/git -tags integration -run TestNativeGitClientClone_PublicDemo -v -timeout 120s
 func TestNativeGitClientClone_PublicDemo(t *testing.T) {
-	keyFile := os.Getenv("GIT_SSH_KEY_FILE")
-	if keyFile == "" {
-		t.Skip("GIT_SSH_KEY_FILE not set")
-	}
+	auth := integrationSSHAuth(t)
 
 	dir := t.TempDir()
 	repoPath := filepath.Join(dir, "repo")
@@ -27,7 +43,7 @@ func TestNativeGitClientClone_PublicDemo(t *testing.T) {
 	defer cancel()
 
 	client := &NativeGitClient{}
-	result, err := client.CloneOrFetch(ctx, "org-147873951@github.com:inductive-automation/publicdemo-all.git", "main", repoPath, nil)
+	result, err := client.CloneOrFetch(ctx, "org-147873951@github.com:inductive-automation/publicdemo-all.git", "main", repoPath, nil, auth)
 	if err != nil {
 		t.Fatalf("CloneOrFetch failed: %v", err)
 	}
@@ -45,10 +61,7 @@ func TestNativeGitClientClone_PublicDemo(t *testing.T) {
 }
 
 func TestNativeGitClientFetch_PublicDemo(t *testing.T) {
-	keyFile := os.Getenv("GIT_SSH_KEY_FILE")
-	if keyFile == "" {
-		t.Skip("GIT_SSH_KEY_FILE not set")
-	}
+	auth := integrationSSHAuth(t)
 
 	dir := t.TempDir()
 	repoPath := filepath.Join(dir, "repo")
@@ -57,14 +70,14 @@ func TestNativeGitClientFetch_PublicDemo(t *testing.T) {
 	repoURL := "org-147873951@github.com:inductive-automation/publicdemo-all.git"
 
 	// First call: clone
-	r1, err := client.CloneOrFetch(ctx, repoURL, "main", repoPath, nil)
+	r1, err := client.CloneOrFetch(ctx, repoURL, "main", repoPath, nil, auth)
 	if err != nil {
 		t.Fatalf("clone failed: %v", err)
 	}
 	t.Logf("Clone: commit=%s", r1.Commit)
 
 	// Second call: fetch (isCloned returns true)
-	r2, err := client.CloneOrFetch(ctx, repoURL, "main", repoPath, nil)
+	r2, err := client.CloneOrFetch(ctx, repoURL, "main", repoPath, nil, auth)
 	if err != nil {
 		t.Fatalf("fetch failed: %v", err)
 	}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+)
+
+// Ref expression prefixes. A ref with one of these prefixes selects the
+// highest matching tag instead of naming a ref exactly.
+const (
+	RefSemverPrefix = "semver:"
+	RefGlobPrefix   = "glob:"
+)
+
+// IsRefExpression reports whether ref is a semver or glob tag expression.
+func IsRefExpression(ref string) bool {
+	return strings.HasPrefix(ref, RefSemverPrefix) || strings.HasPrefix(ref, RefGlobPrefix)
+}
+
+// refMatcher reports whether a tag name matches a ref expression.
+type refMatcher func(tag string) bool
+
+// ParseRefExpression validates a ref expression: "semver:<constraint>"
+// (e.g. "semver:~2.3", "semver:>=1.0.0 <2.0.0") or "glob:<pattern>"
+// (e.g. "glob:release-*").
+func ParseRefExpression(ref string) error {
+	_, err := parseRefExpression(ref)
+	return err
+}
+
+func parseRefExpression(ref string) (refMatcher, error) {
+	if expr, ok := strings.CutPrefix(ref, RefSemverPrefix); ok {
+		c, err := semver.NewConstraint(strings.TrimSpace(expr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid semver constraint %q: %w", expr, err)
+		}
+		return func(tag string) bool {
+			v, err := semver.NewVersion(tag)
+			return err == nil && c.Check(v)
+		}, nil
+	}
+	if pattern, ok := strings.CutPrefix(ref, RefGlobPrefix); ok {
+		if pattern == "" || !doublestar.ValidatePattern(pattern) {
+			return nil, fmt.Errorf("invalid glob pattern %q", pattern)
+		}
+		return func(tag string) bool {
+			return doublestar.MatchUnvalidated(pattern, tag)
+		}, nil
+	}
+	return nil, fmt.Errorf("ref %q is not a semver: or glob: expression", ref)
+}
+
+// matchRefExpression picks the highest tag matching the ref expression.
+// Result.Ref is the chosen tag name.
+func matchRefExpression(ar *packp.AdvRefs, expr, repoURL string) (Result, error) {
+	match, err := parseRefExpression(expr)
+	if err != nil {
+		return Result{}, err
+	}
+
+	best := ""
+	for name := range ar.References {
+		tag, ok := strings.CutPrefix(name, "refs/tags/")
+		if !ok || strings.HasSuffix(tag, "^{}") || !match(tag) {
+			continue
+		}
+		if best == "" || tagLess(best, tag) {
+			best = tag
+		}
+	}
+	if best == "" {
+		return Result{}, fmt.Errorf("no tag in remote %s matches %q", repoURL, expr)
+	}
+	return matchRef(ar, best, repoURL)
+}
+
+// tagLess orders tags by the semantic version they carry (after any
+// non-numeric prefix such as "release-"), with versioned tags above the
+// rest and ties ordered by name.
+func tagLess(a, b string) bool {
+	va, vb := tagVersion(a), tagVersion(b)
+	switch {
+	case va != nil && vb != nil:
+		if cmp := va.Compare(vb); cmp != 0 {
+			return cmp < 0
+		}
+	case va != nil:
+		return false
+	case vb != nil:
+		return true
+	}
+	return a < b
+}
+
+// tagVersion parses the version in a tag, or returns nil.
+func tagVersion(tag string) *semver.Version {
+	if v, err := semver.NewVersion(tag); err == nil {
+		return v
+	}
+	if i := strings.IndexAny(tag, "0123456789"); i > 0 {
+		if v, err := semver.NewVersion(tag[i:]); err == nil {
+			return v
+		}
+	}
+	return nil
+}
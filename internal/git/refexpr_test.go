@@ -0,0 +1,75 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestMatchRef_Expressions(t *testing.T) {
+	ar := newAdvRefs(
+		map[string]string{
+			"refs/heads/main":         "1000000000000000000000000000000000000000",
+			"refs/heads/release-9":    "1100000000000000000000000000000000000000",
+			"refs/tags/v2.2.9":        "2290000000000000000000000000000000000000",
+			"refs/tags/v2.3.1":        "2310000000000000000000000000000000000000",
+			"refs/tags/v2.3.4":        "aaaa000000000000000000000000000000000000", // annotated
+			"refs/tags/v2.3.5-rc.1":   "2351000000000000000000000000000000000000",
+			"refs/tags/v2.4.0":        "2400000000000000000000000000000000000000",
+			"refs/tags/1.9.0":         "1900000000000000000000000000000000000000",
+			"refs/tags/release-a":     "ab00000000000000000000000000000000000000",
+			"refs/tags/release-b":     "bb00000000000000000000000000000000000000",
+			"refs/tags/release-2.10":  "2100000000000000000000000000000000000000",
+			"refs/tags/release-2.9":   "2900000000000000000000000000000000000000",
+			"refs/tags/site/a/v1.0.0": "5a10000000000000000000000000000000000000",
+		},
+		map[string]string{"refs/tags/v2.3.4": "2340000000000000000000000000000000000000"},
+	)
+
+	tests := []struct {
+		expr, ref, commit, tag string
+	}{
+		{"semver:~2.3", "v2.3.4", "2340000000000000000000000000000000000000", "aaaa000000000000000000000000000000000000"},
+		{"semver:>=1.0.0 <2.0.0", "1.9.0", "1900000000000000000000000000000000000000", ""},
+		{"semver:>=2.3.5-0 <2.4.0", "v2.3.5-rc.1", "2351000000000000000000000000000000000000", ""},
+		{"semver:*", "v2.4.0", "2400000000000000000000000000000000000000", ""},
+		// Tags carrying a version order by it, above the rest, which order by name.
+		{"glob:release-*", "release-2.10", "2100000000000000000000000000000000000000", ""},
+		{"glob:release-[ab]", "release-b", "bb00000000000000000000000000000000000000", ""},
+		{"glob:site/*/v*", "site/a/v1.0.0", "5a10000000000000000000000000000000000000", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			res, err := matchRef(ar, tt.expr, "https://example.com/repo.git")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Ref != tt.ref || res.Commit != tt.commit || res.Tag != tt.tag {
+				t.Errorf("got %+v, want ref %s commit %s tag %q", res, tt.ref, tt.commit, tt.tag)
+			}
+		})
+	}
+
+	for _, expr := range []string{"semver:~3", "glob:hotfix-*", "semver:not-a-version", "glob:["} {
+		if _, err := matchRef(ar, expr, "https://example.com/repo.git"); err == nil {
+			t.Errorf("%s: expected an error", expr)
+		}
+	}
+}
+
+func TestParseRefExpression(t *testing.T) {
+	for ref, valid := range map[string]bool{
+		"semver:~2.3":           true,
+		"semver:>=1.0.0 <2.0.0": true,
+		"glob:release-*":        true,
+		"semver:":               false,
+		"glob:":                 false,
+		"glob:[":                false,
+		"main":                  false,
+	} {
+		if err := ParseRefExpression(ref); (err == nil) != valid {
+			t.Errorf("ParseRefExpression(%q) = %v, want valid=%t", ref, err, valid)
+		}
+	}
+	if IsRefExpression("main") || !IsRefExpression("glob:*") {
+		t.Error("IsRefExpression misclassified a ref")
+	}
+}
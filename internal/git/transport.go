@@ -0,0 +1,95 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// Client implementations selectable with NewClient.
+const (
+	ClientGoGit  = "go-git"
+	ClientNative = "native"
+)
+
+// TransportOptions holds connection settings shared by both clients.
+type TransportOptions struct {
+	// ProxyURL is an HTTP(S) proxy for http:// and https:// remotes.
+	// SSH remotes connect directly.
+	ProxyURL string
+	// CABundle holds PEM certificates trusted for HTTPS remotes in addition
+	// to the system roots.
+	CABundle []byte
+}
+
+// NewClient returns the Client implementation named by kind (ClientGoGit or
+// ClientNative) configured with opts.
+func NewClient(kind string, opts TransportOptions) (Client, error) {
+	switch kind {
+	case ClientGoGit:
+		return &GoGitClient{TransportOptions: opts}, nil
+	case ClientNative:
+		return &NativeGitClient{TransportOptions: opts}, nil
+	default:
+		return nil, fmt.Errorf("unknown git client %q (want %q or %q)", kind, ClientGoGit, ClientNative)
+	}
+}
+
+// LoadTransportOptions builds TransportOptions from a proxy URL and an
+// optional PEM CA bundle file.
+func LoadTransportOptions(proxyURL, caFile string) (TransportOptions, error) {
+	opts := TransportOptions{ProxyURL: proxyURL}
+	if caFile != "" {
+		data, err := os.ReadFile(caFile)
+		if err != nil {
+			return TransportOptions{}, fmt.Errorf("reading CA bundle %s: %w", caFile, err)
+		}
+		opts.CABundle = data
+	}
+	return opts, nil
+}
+
+// proxyFor returns the go-git proxy options for repoURL; only HTTP(S)
+// remotes are proxied.
+func (o TransportOptions) proxyFor(repoURL string) transport.ProxyOptions {
+	if o.ProxyURL == "" || !isHTTPURL(repoURL) {
+		return transport.ProxyOptions{}
+	}
+	return transport.ProxyOptions{URL: o.ProxyURL}
+}
+
+// endpoint parses repoURL and applies the proxy and CA settings.
+func (o TransportOptions) endpoint(repoURL string) (*transport.Endpoint, error) {
+	ep, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing endpoint %s: %w", repoURL, err)
+	}
+	ep.CaBundle = o.CABundle
+	ep.Proxy = o.proxyFor(repoURL)
+	return ep, nil
+}
+
+func isHTTPURL(repoURL string) bool {
+	return strings.HasPrefix(repoURL, "https://") || strings.HasPrefix(repoURL, "http://")
+}
+
+// systemCABundles are the usual locations of the system CA bundle across
+// Debian/Alpine, RHEL, and macOS/BSD images.
+var systemCABundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/cert.pem",
+}
+
+// withSystemRoots prepends the system CA bundle to extra, since git's CA
+// file replaces the default bundle rather than adding to it.
+func withSystemRoots(extra []byte) []byte {
+	for _, f := range systemCABundles {
+		if data, err := os.ReadFile(f); err == nil {
+			return append(append(data, '\n'), extra...)
+		}
+	}
+	return extra
+}
@@ -0,0 +1,474 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"golang.org/x/crypto/ssh"
+)
+
+// Signature verification policies.
+const (
+	// VerifySignedCommit requires the synced commit to carry a trusted signature.
+	VerifySignedCommit = "SignedCommit"
+	// VerifySignedTag requires the ref to be an annotated tag with a trusted
+	// signature that points at the synced commit.
+	VerifySignedTag = "SignedTag"
+)
+
+// sshSigNamespace is the namespace git uses for SSH signatures (ssh-keygen -Y sign -n git).
+const sshSigNamespace = "git"
+
+// ErrUnsigned is returned when the verified object carries no signature.
+var ErrUnsigned = errors.New("object is not signed")
+
+// TrustedKeys holds the public keys allowed to sign commits and tags.
+type TrustedKeys struct {
+	pgp openpgp.EntityList
+	ssh []sshSigner
+}
+
+// sshSigner is a trusted SSH key. Keys from allowed_signers carry their
+// principals and, when restricted, the signature namespaces they may sign.
+type sshSigner struct {
+	key        ssh.PublicKey
+	principals string
+	namespaces string
+}
+
+// sshKeyTypes are the key types accepted in authorized_keys and allowed_signers lines.
+var sshKeyTypes = map[string]bool{
+	ssh.KeyAlgoRSA: true, ssh.KeyAlgoDSA: true, ssh.KeyAlgoED25519: true, ssh.KeyAlgoSKED25519: true,
+	ssh.KeyAlgoECDSA256: true, ssh.KeyAlgoECDSA384: true, ssh.KeyAlgoECDSA521: true, ssh.KeyAlgoSKECDSA256: true,
+}
+
+// ParseTrustedKeys builds a key set from Secret data. Each value holds
+// ASCII-armored OpenPGP public keys and/or SSH public keys, one per line,
+// either bare ("<type> <key>") or in git allowed_signers format
+// ("<principals> [namespaces=\"...\"] <type> <key>").
+func ParseTrustedKeys(data map[string][]byte) (*TrustedKeys, error) {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keys := &TrustedKeys{}
+	for _, name := range names {
+		if err := keys.add(data[name]); err != nil {
+			return nil, fmt.Errorf("parsing trusted key %q: %w", name, err)
+		}
+	}
+	if len(keys.pgp) == 0 && len(keys.ssh) == 0 {
+		return nil, fmt.Errorf("no GPG or SSH public keys found")
+	}
+	return keys, nil
+}
+
+// LoadTrustedKeys reads every file in dir (a mounted Secret) as trusted keys.
+func LoadTrustedKeys(dir string) (*TrustedKeys, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading trusted keys: %w", err)
+	}
+	data := map[string][]byte{}
+	for _, e := range entries {
+		// Skip the ..data and timestamped directories of Secret volumes.
+		if strings.HasPrefix(e.Name(), ".") || e.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("reading trusted key %s: %w", e.Name(), err)
+		}
+		data[e.Name()] = b
+	}
+	return ParseTrustedKeys(data)
+}
+
+func (k *TrustedKeys) add(data []byte) error {
+	rest := data
+	for {
+		start := bytes.Index(rest, []byte("-----BEGIN PGP PUBLIC KEY BLOCK-----"))
+		if start < 0 {
+			break
+		}
+		end := bytes.Index(rest[start:], []byte("-----END PGP PUBLIC KEY BLOCK-----"))
+		if end < 0 {
+			return fmt.Errorf("unterminated PGP public key block")
+		}
+		end += start + len("-----END PGP PUBLIC KEY BLOCK-----")
+		entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(rest[start:end]))
+		if err != nil {
+			return fmt.Errorf("reading PGP key: %w", err)
+		}
+		k.pgp = append(k.pgp, entities...)
+		rest = append(append([]byte{}, rest[:start]...), rest[end:]...)
+	}
+
+	for line := range strings.SplitSeq(string(rest), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		signer, err := parseSSHSigner(line)
+		if err != nil {
+			return err
+		}
+		k.ssh = append(k.ssh, signer)
+	}
+	return nil
+}
+
+// parseSSHSigner parses a bare public key line or an allowed_signers line.
+func parseSSHSigner(line string) (sshSigner, error) {
+	fields := splitQuoted(line)
+	var signer sshSigner
+	keyAt := 0
+	if len(fields) > 0 && !sshKeyTypes[fields[0]] {
+		signer.principals = strings.Trim(fields[0], `"`)
+		keyAt = 1
+		if len(fields) > 1 && !sshKeyTypes[fields[1]] {
+			if err := signer.parseOptions(fields[1]); err != nil {
+				return sshSigner{}, fmt.Errorf("key line %q: %w", line, err)
+			}
+			keyAt = 2
+		}
+	}
+	if len(fields) < keyAt+2 || !sshKeyTypes[fields[keyAt]] {
+		return sshSigner{}, fmt.Errorf("unrecognized key line %q", line)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.Join(fields[keyAt:], " ")))
+	if err != nil {
+		return sshSigner{}, fmt.Errorf("reading SSH key: %w", err)
+	}
+	signer.key = pub
+	return signer, nil
+}
+
+// parseOptions reads the comma-separated allowed_signers options. Options
+// that would restrict the key in ways not enforced here are rejected rather
+// than ignored.
+func (s *sshSigner) parseOptions(options string) error {
+	for _, opt := range splitOptions(options) {
+		name, value, _ := strings.Cut(opt, "=")
+		switch strings.ToLower(name) {
+		case "namespaces":
+			s.namespaces = strings.Trim(value, `"`)
+		default:
+			return fmt.Errorf("unsupported allowed_signers option %q", name)
+		}
+	}
+	return nil
+}
+
+// allows reports whether the key may sign in namespace: the namespaces
+// option, if set, is a comma-separated list of patterns, where a pattern
+// starting with "!" excludes.
+func (s sshSigner) allows(namespace string) bool {
+	if s.namespaces == "" {
+		return true
+	}
+	allowed := false
+	for pattern := range strings.SplitSeq(s.namespaces, ",") {
+		negated := strings.HasPrefix(pattern, "!")
+		if ok, _ := filepath.Match(strings.TrimPrefix(pattern, "!"), namespace); ok {
+			if negated {
+				return false
+			}
+			allowed = true
+		}
+	}
+	return allowed
+}
+
+// name identifies the signer: its principals, or the key fingerprint for a bare key.
+func (s sshSigner) name() string {
+	if s.principals != "" {
+		return s.principals
+	}
+	return ssh.FingerprintSHA256(s.key)
+}
+
+// splitQuoted splits line on whitespace outside double quotes.
+func splitQuoted(line string) []string {
+	var fields []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			cur.WriteRune(r)
+		case (r == ' ' || r == '\t') && !quoted:
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+// splitOptions splits an options field on commas outside double quotes.
+func splitOptions(options string) []string {
+	var out []string
+	start, quoted := 0, false
+	for i, r := range options {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			out = append(out, options[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, options[start:])
+}
+
+// VerifyCommit verifies c's signature and returns the signer.
+func (k *TrustedKeys) VerifyCommit(c *object.Commit) (string, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := c.EncodeWithoutSignature(encoded); err != nil {
+		return "", err
+	}
+	payload, err := readObject(encoded)
+	if err != nil {
+		return "", err
+	}
+	signer, err := k.verify(c.PGPSignature, payload)
+	if err != nil {
+		return "", fmt.Errorf("commit %s: %w", c.Hash.String()[:12], err)
+	}
+	return signer, nil
+}
+
+// VerifyTag verifies t's signature and returns the signer.
+func (k *TrustedKeys) VerifyTag(t *object.Tag) (string, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := t.EncodeWithoutSignature(encoded); err != nil {
+		return "", err
+	}
+	payload, err := readObject(encoded)
+	if err != nil {
+		return "", err
+	}
+	signer, err := k.verify(t.PGPSignature, payload)
+	if err != nil {
+		return "", fmt.Errorf("tag %s: %w", t.Name, err)
+	}
+	return signer, nil
+}
+
+func readObject(o plumbing.EncodedObject) ([]byte, error) {
+	r, err := o.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+	return io.ReadAll(r)
+}
+
+// verify checks a detached OpenPGP or SSH signature over payload.
+func (k *TrustedKeys) verify(signature string, payload []byte) (string, error) {
+	switch {
+	case strings.TrimSpace(signature) == "":
+		return "", ErrUnsigned
+	case strings.Contains(signature, "-----BEGIN PGP SIGNATURE-----"):
+		entity, err := openpgp.CheckArmoredDetachedSignature(k.pgp, bytes.NewReader(payload), strings.NewReader(signature), nil)
+		if err != nil {
+			return "", fmt.Errorf("GPG signature not verified by a trusted key: %w", err)
+		}
+		if id := entity.PrimaryIdentity(); id != nil {
+			return id.Name, nil
+		}
+		return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint), nil
+	case strings.Contains(signature, "-----BEGIN SSH SIGNATURE-----"):
+		return k.verifySSH(signature, payload)
+	default:
+		return "", fmt.Errorf("unsupported signature format (only GPG and SSH signatures are supported)")
+	}
+}
+
+// sshSignature is the SSHSIG blob (see OpenSSH PROTOCOL.sshsig).
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      []byte
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData is what an SSHSIG signature is computed over.
+type sshSignedData struct {
+	Namespace     string
+	Reserved      []byte
+	HashAlgorithm string
+	Hash          []byte
+}
+
+const sshSigMagic = "SSHSIG"
+
+func (k *TrustedKeys) verifySSH(armored string, payload []byte) (string, error) {
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return "", fmt.Errorf("malformed SSH signature")
+	}
+	blob, ok := bytes.CutPrefix(block.Bytes, []byte(sshSigMagic))
+	if !ok {
+		return "", fmt.Errorf("malformed SSH signature: missing %s preamble", sshSigMagic)
+	}
+	var sig sshSignature
+	if err := ssh.Unmarshal(blob, &sig); err != nil {
+		return "", fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if sig.Version != 1 {
+		return "", fmt.Errorf("unsupported SSH signature version %d", sig.Version)
+	}
+	if sig.Namespace != sshSigNamespace {
+		return "", fmt.Errorf("SSH signature namespace %q, want %q", sig.Namespace, sshSigNamespace)
+	}
+
+	pub, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("malformed SSH signature key: %w", err)
+	}
+	var signer *sshSigner
+	for i, s := range k.ssh {
+		if bytes.Equal(s.key.Marshal(), pub.Marshal()) && s.allows(sig.Namespace) {
+			signer = &k.ssh[i]
+			break
+		}
+	}
+	if signer == nil {
+		return "", fmt.Errorf("SSH signature key %s is not trusted", ssh.FingerprintSHA256(pub))
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported SSH signature hash %q", sig.HashAlgorithm)
+	}
+	h.Write(payload)
+	signed := append([]byte(sshSigMagic), ssh.Marshal(sshSignedData{
+		Namespace:     sig.Namespace,
+		Reserved:      sig.Reserved,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+
+	var s ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &s); err != nil {
+		return "", fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if err := pub.Verify(signed, &s); err != nil {
+		return "", fmt.Errorf("SSH signature does not match: %w", err)
+	}
+	return signer.name(), nil
+}
+
+// verifyObjects checks result against policy using the objects in s.
+func verifyObjects(s storer.EncodedObjectStorer, result Result, policy string, keys *TrustedKeys) (string, error) {
+	switch policy {
+	case VerifySignedCommit:
+		c, err := object.GetCommit(s, plumbing.NewHash(result.Commit))
+		if err != nil {
+			return "", fmt.Errorf("reading commit %s: %w", result.Commit, err)
+		}
+		return keys.VerifyCommit(c)
+	case VerifySignedTag:
+		if result.Tag == "" {
+			return "", fmt.Errorf("ref %q is not an annotated tag", result.Ref)
+		}
+		t, err := object.GetTag(s, plumbing.NewHash(result.Tag))
+		if err != nil {
+			return "", fmt.Errorf("reading tag %s: %w", result.Ref, err)
+		}
+		if t.Target.String() != result.Commit {
+			return "", fmt.Errorf("tag %s points at %s, not %s", t.Name, t.Target, result.Commit)
+		}
+		return keys.VerifyTag(t)
+	default:
+		return "", fmt.Errorf("unknown verification policy %q", policy)
+	}
+}
+
+// VerifyRemote fetches the commit, or the annotated tag for VerifySignedTag,
+// without trees or blobs where supported, and verifies its signature.
+func (g *GoGitClient) VerifyRemote(ctx context.Context, repoURL string, result Result, policy string, keys *TrustedKeys, auth transport.AuthMethod) (string, error) {
+	want := result.Commit
+	if policy == VerifySignedTag {
+		if result.Tag == "" {
+			return "", fmt.Errorf("ref %q is not an annotated tag", result.Ref)
+		}
+		want = result.Tag
+	}
+	st, err := g.fetchObject(ctx, repoURL, plumbing.NewHash(want), auth)
+	if err != nil {
+		return "", err
+	}
+	return verifyObjects(st, result, policy, keys)
+}
+
+// VerifyClone verifies the checked-out commit in the clone at path. For
+// VerifySignedTag the tag object is looked up by ref, falling back to
+// FETCH_HEAD for fetches that do not create tag refs.
+func VerifyClone(path string, result Result, policy string, keys *TrustedKeys) (string, error) {
+	repo, err := gogit.PlainOpen(path)
+	if err != nil {
+		return "", fmt.Errorf("opening repo at %s: %w", path, err)
+	}
+	if policy == VerifySignedTag && result.Tag == "" {
+		result.Tag = localTagObject(repo, path, result.Ref)
+	}
+	return verifyObjects(repo.Storer, result, policy, keys)
+}
+
+// localTagObject returns the annotated tag object SHA for ref, or "".
+func localTagObject(repo *gogit.Repository, path, ref string) string {
+	if r, err := repo.Tag(ref); err == nil {
+		if _, err := repo.TagObject(r.Hash()); err == nil {
+			return r.Hash().String()
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(path, ".git", "FETCH_HEAD"))
+	if err != nil {
+		return ""
+	}
+	sha, _, _ := strings.Cut(string(data), "\t")
+	sha = strings.TrimSpace(sha)
+	if !plumbing.IsHash(sha) {
+		return ""
+	}
+	if _, err := repo.TagObject(plumbing.NewHash(sha)); err != nil {
+		return ""
+	}
+	return sha
+}
@@ -0,0 +1,289 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// pgpKey returns a signing entity and its armored public key.
+func pgpKey(t *testing.T, email string) (*openpgp.Entity, []byte) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("Release Bot", "", email, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := entity.Serialize(w); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return entity, buf.Bytes()
+}
+
+// signedTestRepo creates a repo with one commit and an annotated tag v1.0.0,
+// both signed by key when it is non-nil.
+func signedTestRepo(t *testing.T, key *openpgp.Entity) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("README"); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "Release Bot", Email: "release@example.com", When: time.Now()}
+	hash, err := wt.Commit("Release 1.0.0\n", &gogit.CommitOptions{Author: sig, SignKey: key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateTag("v1.0.0", hash, &gogit.CreateTagOptions{
+		Tagger: sig, Message: "v1.0.0\n", SignKey: key,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return dir, hash.String()
+}
+
+func TestParseTrustedKeys(t *testing.T) {
+	_, pub := pgpKey(t, "release@example.com")
+	keys, err := ParseTrustedKeys(map[string][]byte{
+		"release.asc":     pub,
+		"allowed_signers": []byte("# comment\nci@example.com namespaces=\"git\" ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIC7xaAhUOnqkAj0MxzuhPNm0jqT9Xgl0Ld+8aLzusHHz\n"),
+	})
+	if err != nil {
+		t.Fatalf("ParseTrustedKeys: %v", err)
+	}
+	if len(keys.pgp) != 1 || len(keys.ssh) != 1 {
+		t.Errorf("got %d PGP and %d SSH keys, want 1 and 1", len(keys.pgp), len(keys.ssh))
+	}
+
+	if _, err := ParseTrustedKeys(map[string][]byte{"empty": nil}); err == nil {
+		t.Error("expected error for a Secret with no keys")
+	}
+	if _, err := ParseTrustedKeys(map[string][]byte{"bad": []byte("not a key")}); err == nil {
+		t.Error("expected error for an unrecognized key line")
+	}
+}
+
+func TestParseTrustedKeys_AllowedSigners(t *testing.T) {
+	const key = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIC7xaAhUOnqkAj0MxzuhPNm0jqT9Xgl0Ld+8aLzusHHz"
+	cases := []struct {
+		line       string
+		principals string
+		namespaces string
+	}{
+		{line: key, principals: ""},
+		{line: "ssh-admins@corp " + key, principals: "ssh-admins@corp"},
+		{line: "risk-team@corp,ci@corp namespaces=\"git,file\" " + key, principals: "risk-team@corp,ci@corp", namespaces: "git,file"},
+		{line: `"ops team@corp" namespaces="git" ` + key, principals: "ops team@corp", namespaces: "git"},
+	}
+	for _, tc := range cases {
+		signer, err := parseSSHSigner(tc.line)
+		if err != nil {
+			t.Errorf("%q: %v", tc.line, err)
+			continue
+		}
+		if signer.principals != tc.principals || signer.namespaces != tc.namespaces {
+			t.Errorf("%q: got principals %q namespaces %q", tc.line, signer.principals, signer.namespaces)
+		}
+	}
+
+	if _, err := parseSSHSigner("ci@corp valid-before=\"20300101\" " + key); err == nil {
+		t.Error("expected error for an unsupported option")
+	}
+	for namespaces, want := range map[string]bool{"": true, "git": true, "file,git": true, "g*": true, "file": false, "*,!git": false} {
+		if got := (sshSigner{namespaces: namespaces}).allows("git"); got != want {
+			t.Errorf("namespaces %q: allows(git) = %v, want %v", namespaces, got, want)
+		}
+	}
+}
+
+func TestVerifyRemote_PGP(t *testing.T) {
+	signer, pub := pgpKey(t, "release@example.com")
+	dir, sha := signedTestRepo(t, signer)
+	keys, err := ParseTrustedKeys(map[string][]byte{"release.asc": pub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	client := &GoGitClient{}
+
+	result, err := client.LsRemote(ctx, "file://"+dir, "v1.0.0", nil)
+	if err != nil {
+		t.Fatalf("LsRemote: %v", err)
+	}
+	if result.Commit != sha || result.Tag == "" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	for _, policy := range []string{VerifySignedCommit, VerifySignedTag} {
+		got, err := client.VerifyRemote(ctx, "file://"+dir, result, policy, keys, nil)
+		if err != nil {
+			t.Errorf("%s: %v", policy, err)
+		} else if !strings.Contains(got, "release@example.com") {
+			t.Errorf("%s: signer %q", policy, got)
+		}
+	}
+
+	// A branch has no tag object to verify.
+	branch, err := client.LsRemote(ctx, "file://"+dir, "master", nil)
+	if err != nil {
+		t.Fatalf("LsRemote: %v", err)
+	}
+	if _, err := client.VerifyRemote(ctx, "file://"+dir, branch, VerifySignedTag, keys, nil); err == nil {
+		t.Error("expected SignedTag to fail for a branch")
+	}
+
+	// A key that is not trusted is rejected.
+	_, otherPub := pgpKey(t, "other@example.com")
+	other, err := ParseTrustedKeys(map[string][]byte{"other.asc": otherPub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.VerifyRemote(ctx, "file://"+dir, result, VerifySignedCommit, other, nil); err == nil {
+		t.Error("expected verification with an untrusted key to fail")
+	}
+}
+
+func TestVerifyRemote_Unsigned(t *testing.T) {
+	_, pub := pgpKey(t, "release@example.com")
+	dir, sha := signedTestRepo(t, nil)
+	keys, err := ParseTrustedKeys(map[string][]byte{"release.asc": pub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = (&GoGitClient{}).VerifyRemote(context.Background(), "file://"+dir, Result{Commit: sha}, VerifySignedCommit, keys, nil)
+	if !errors.Is(err, ErrUnsigned) {
+		t.Errorf("expected ErrUnsigned, got %v", err)
+	}
+}
+
+func TestVerifyClone_SSH(t *testing.T) {
+	for _, bin := range []string{"git", "ssh-keygen"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			t.Skipf("%s not available", bin)
+		}
+	}
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "signing-key")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "ci@example.com", "-f", keyFile).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v: %s", err, out)
+	}
+	pub, err := os.ReadFile(keyFile + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repoDir := filepath.Join(dir, "repo")
+	gitCmd := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repoDir,
+			"-c", "user.name=CI Bot", "-c", "user.email=ci@example.com",
+			"-c", "gpg.format=ssh", "-c", "user.signingkey=" + keyFile}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "HOME="+dir)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if err := os.MkdirAll(repoDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gitCmd("init", "-q")
+	gitCmd("commit", "-q", "-S", "--allow-empty", "-m", "Signed with SSH")
+	gitCmd("tag", "-s", "-m", "v2.0.0", "v2.0.0")
+	gitCmd("commit", "-q", "--allow-empty", "-m", "Unsigned")
+	sha := gitCmd("rev-parse", "HEAD~1")
+	unsigned := gitCmd("rev-parse", "HEAD")
+
+	keys, err := ParseTrustedKeys(map[string][]byte{"allowed_signers": append([]byte("ci@example.com "), pub...)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := VerifyClone(repoDir, Result{Commit: sha, Ref: "v2.0.0"}, VerifySignedCommit, keys)
+	if err != nil {
+		t.Fatalf("VerifyClone commit: %v", err)
+	}
+	if signer != "ci@example.com" {
+		t.Errorf("signer: got %q, want the allowed_signers principal", signer)
+	}
+	if _, err := VerifyClone(repoDir, Result{Commit: sha, Ref: "v2.0.0"}, VerifySignedTag, keys); err != nil {
+		t.Errorf("VerifyClone tag: %v", err)
+	}
+	bare, err := ParseTrustedKeys(map[string][]byte{"key.pub": pub})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer, err := VerifyClone(repoDir, Result{Commit: sha}, VerifySignedCommit, bare); err != nil || !strings.HasPrefix(signer, "SHA256:") {
+		t.Errorf("bare key: got signer %q, want an SSH fingerprint (err=%v)", signer, err)
+	}
+	fileOnly, err := ParseTrustedKeys(map[string][]byte{"allowed_signers": append([]byte(`ci@example.com namespaces="file" `), pub...)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyClone(repoDir, Result{Commit: sha}, VerifySignedCommit, fileOnly); err == nil {
+		t.Error("expected a key restricted to another namespace to be rejected")
+	}
+	if _, err := VerifyClone(repoDir, Result{Commit: unsigned, Ref: "master"}, VerifySignedCommit, keys); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("expected ErrUnsigned, got %v", err)
+	}
+
+	// The agent's native checkout finds the tag object after a clone and a fetch.
+	cloneDir := filepath.Join(dir, "clone")
+	native := &NativeGitClient{}
+	for _, ref := range []string{"v2.0.0", "v2.0.1"} {
+		if ref == "v2.0.1" {
+			gitCmd("tag", "-s", "-m", ref, ref, sha)
+		}
+		result, err := native.CloneOrFetch(context.Background(), "file://"+repoDir, ref, cloneDir, nil, nil)
+		if err != nil {
+			t.Fatalf("CloneOrFetch %s: %v", ref, err)
+		}
+		if _, err := VerifyClone(cloneDir, result, VerifySignedTag, keys); err != nil {
+			t.Errorf("VerifyClone native %s: %v", ref, err)
+		}
+	}
+
+	// Tampering with the signed payload breaks the signature.
+	repo, err := gogit.PlainOpen(repoDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := repo.CommitObject(plumbing.NewHash(sha))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Message = "Tampered\n"
+	if _, err := keys.VerifyCommit(c); err == nil {
+		t.Error("expected a tampered commit to fail verification")
+	}
+}
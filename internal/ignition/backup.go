@@ -0,0 +1,49 @@
+package ignition
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// backupTimeout bounds a single backup download. Gateway backups include the
+// internal database and can take far longer than regular API calls.
+const backupTimeout = 10 * time.Minute
+
+// DownloadBackup requests a gateway backup (.gwbk) and streams it to w.
+// Uses the Ignition 8.3 REST API (GET /data/api/v1/backup).
+// Returns the number of bytes written.
+func (c *Client) DownloadBackup(ctx context.Context, w io.Writer) (int64, error) {
+	url := c.BaseURL + "/data/api/v1/backup"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("creating backup request: %w", err)
+	}
+	c.setAuth(req)
+
+	// Reuse the transport (and its TLS config) with a longer timeout.
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = backupTimeout
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("requesting gateway backup: %w", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("backup API returned HTTP %d", resp.StatusCode)
+	}
+
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("downloading gateway backup: %w", err)
+	}
+	return n, nil
+}
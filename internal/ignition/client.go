@@ -9,7 +9,8 @@ import (
 	"time"
 )
 
-// Client wraps Ignition gateway API calls.
+// Client wraps Ignition 8.3+ gateway REST API calls. It implements GatewayClient
+// with all capabilities; see LegacyClient for 8.1 gateways.
 type Client struct {
 	BaseURL    string
 	APIKey     string
@@ -18,27 +19,38 @@ type Client struct {
 
 // NewClient creates an Ignition API client.
 // scheme should be "http" or "https", host is the gateway address (e.g., "localhost:8088").
-func NewClient(scheme, host, apiKey string) *Client {
+// tlsConfig controls certificate verification for https; nil uses the system roots.
+func NewClient(scheme, host, apiKey string, tlsConfig *tls.Config) *Client {
 	return &Client{
 		BaseURL: fmt.Sprintf("%s://%s", scheme, host),
 		APIKey:  apiKey,
 		HTTPClient: &http.Client{
 			Timeout: 10 * time.Second,
 			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+				TLSClientConfig: tlsConfig,
 			},
 		},
 	}
 }
 
+// Capabilities reports that the 8.3 REST API supports every optional operation.
+func (c *Client) Capabilities() Capabilities {
+	return Capabilities{ScanAPI: true, DesignerSessions: true, Backup: true}
+}
+
 // ScanResult holds the outcome of scan API calls.
 type ScanResult struct {
 	ProjectsStatus int
 	ConfigStatus   int
 	Error          string
+	// Skipped is true when the gateway has no scan API (Ignition 8.1).
+	Skipped bool
 }
 
 func (r ScanResult) String() string {
+	if r.Skipped {
+		return "skipped: scan API not supported by gateway"
+	}
 	if r.Error != "" {
 		return fmt.Sprintf("error: %s", r.Error)
 	}
@@ -0,0 +1,116 @@
+package ignition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// ErrUnsupported is returned by gateway clients for operations the detected
+// Ignition version does not expose over HTTP.
+var ErrUnsupported = errors.New("not supported by this gateway version")
+
+// Capabilities describes which agent features the gateway API supports.
+type Capabilities struct {
+	// ScanAPI is true when projects/config can be rescanned over HTTP.
+	ScanAPI bool
+	// DesignerSessions is true when active Designer sessions can be listed,
+	// which is required to enforce designerSessionPolicy.
+	DesignerSessions bool
+	// Backup is true when a gateway backup (.gwbk) can be downloaded.
+	Backup bool
+}
+
+// GatewayClient is the set of gateway API operations the agent depends on.
+// Implementations exist per Ignition API generation; callers should consult
+// Capabilities before relying on optional features.
+type GatewayClient interface {
+	// HealthCheck verifies the gateway is running and the API key is accepted.
+	HealthCheck() error
+	// PortCheck verifies the gateway HTTP port answers, without authentication.
+	PortCheck() error
+	// TriggerScan asks the gateway to pick up project and config changes on disk.
+	TriggerScan() ScanResult
+	// GetDesignerSessions lists active Designer sessions.
+	GetDesignerSessions(ctx context.Context) ([]DesignerSession, error)
+	// DownloadBackup streams a gateway backup (.gwbk) to w.
+	DownloadBackup(ctx context.Context, w io.Writer) (int64, error)
+	// Capabilities reports which optional operations are available.
+	Capabilities() Capabilities
+}
+
+// GatewayVersion is a parsed Ignition gateway version.
+type GatewayVersion struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+func (v GatewayVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is major.minor or newer.
+func (v GatewayVersion) AtLeast(major, minor int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	return v.Minor >= minor
+}
+
+// gwinfoVersionRe matches the Version field in /system/gwinfo output, e.g.
+// "ContextStatus=RUNNING;Version=8.1.33 (b2023091211);...".
+var gwinfoVersionRe = regexp.MustCompile(`Version=(\d+)\.(\d+)\.(\d+)`)
+
+// ParseGatewayInfoVersion extracts the gateway version from a /system/gwinfo body.
+func ParseGatewayInfoVersion(gwinfo string) (GatewayVersion, error) {
+	m := gwinfoVersionRe.FindStringSubmatch(gwinfo)
+	if m == nil {
+		return GatewayVersion{}, fmt.Errorf("no version found in gateway info")
+	}
+	var v GatewayVersion
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	return v, nil
+}
+
+// DetectVersion reads /system/gwinfo, which every supported Ignition version
+// serves without authentication, and returns the gateway version.
+func (c *Client) DetectVersion(ctx context.Context) (GatewayVersion, error) {
+	url := c.BaseURL + "/system/gwinfo"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return GatewayVersion{}, fmt.Errorf("creating gateway info request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return GatewayVersion{}, fmt.Errorf("fetching gateway info: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return GatewayVersion{}, fmt.Errorf("gateway info returned HTTP %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return GatewayVersion{}, fmt.Errorf("reading gateway info: %w", err)
+	}
+	return ParseGatewayInfoVersion(string(body))
+}
+
+// ForVersion returns the client implementation for the given gateway version:
+// the full 8.3 REST client, or the reduced-capability 8.1 client for older gateways.
+func ForVersion(c *Client, v GatewayVersion) GatewayClient {
+	if v.AtLeast(8, 3) {
+		return c
+	}
+	return &LegacyClient{Client: c}
+}
@@ -0,0 +1,154 @@
+package ignition
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseGatewayInfoVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    GatewayVersion
+		wantErr bool
+	}{
+		{
+			name: "8.1",
+			in:   "ContextStatus=RUNNING;Version=8.1.33 (b2023091211);InstallType=STANDARD;RedundantRole=Independent",
+			want: GatewayVersion{Major: 8, Minor: 1, Patch: 33},
+		},
+		{
+			name: "8.3",
+			in:   "ContextStatus=RUNNING;Version=8.3.1 (b2025061011);",
+			want: GatewayVersion{Major: 8, Minor: 3, Patch: 1},
+		},
+		{name: "missing", in: "ContextStatus=STARTING", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGatewayInfoVersion(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGatewayVersion_AtLeast(t *testing.T) {
+	v := GatewayVersion{Major: 8, Minor: 1, Patch: 40}
+	if !v.AtLeast(8, 1) {
+		t.Error("8.1.40 should be at least 8.1")
+	}
+	if v.AtLeast(8, 3) {
+		t.Error("8.1.40 should not be at least 8.3")
+	}
+	if !(GatewayVersion{Major: 9}).AtLeast(8, 3) {
+		t.Error("9.0 should be at least 8.3")
+	}
+}
+
+func TestForVersion(t *testing.T) {
+	c := NewClient("http", "localhost:8088", "", nil)
+
+	if _, ok := ForVersion(c, GatewayVersion{Major: 8, Minor: 3}).(*Client); !ok {
+		t.Error("expected 8.3 client for 8.3 gateway")
+	}
+	legacy := ForVersion(c, GatewayVersion{Major: 8, Minor: 1})
+	if _, ok := legacy.(*LegacyClient); !ok {
+		t.Fatal("expected legacy client for 8.1 gateway")
+	}
+	if caps := legacy.Capabilities(); caps.ScanAPI || caps.DesignerSessions || caps.Backup {
+		t.Errorf("expected no optional capabilities on 8.1, got %+v", caps)
+	}
+}
+
+// fakeGateway serves /system/gwinfo with the given version and /StatusPing.
+func fakeGateway(t *testing.T, version string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/system/gwinfo":
+			_, _ = io.WriteString(w, "ContextStatus=RUNNING;Version="+version+" (b0);")
+		case "/StatusPing":
+			_, _ = io.WriteString(w, `{"state":"RUNNING"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestVersionedClient_Detect81(t *testing.T) {
+	srv := fakeGateway(t, "8.1.33")
+	vc := NewVersionedClient(NewClient("http", strings.TrimPrefix(srv.URL, "http://"), "key", nil))
+
+	// Before detection the client assumes the 8.3 API.
+	if !vc.Capabilities().ScanAPI {
+		t.Error("expected 8.3 capabilities before detection")
+	}
+	if _, ok := vc.Version(); ok {
+		t.Error("expected no version before detection")
+	}
+
+	v, err := vc.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	if v.String() != "8.1.33" {
+		t.Errorf("version: got %s, want 8.1.33", v)
+	}
+	if got, ok := vc.Version(); !ok || got != v {
+		t.Errorf("Version() = %v, %v", got, ok)
+	}
+
+	if vc.Capabilities().DesignerSessions {
+		t.Error("expected designer sessions unsupported after detecting 8.1")
+	}
+	if res := vc.TriggerScan(); !res.Skipped {
+		t.Errorf("expected skipped scan on 8.1, got %s", res)
+	}
+	if _, err := vc.GetDesignerSessions(context.Background()); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+	if _, err := vc.DownloadBackup(context.Background(), io.Discard); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("expected ErrUnsupported, got %v", err)
+	}
+	if err := vc.HealthCheck(); err != nil {
+		t.Errorf("expected 8.1 StatusPing health check to pass, got %v", err)
+	}
+}
+
+func TestVersionedClient_Detect83(t *testing.T) {
+	srv := fakeGateway(t, "8.3.2")
+	vc := NewVersionedClient(NewClient("http", strings.TrimPrefix(srv.URL, "http://"), "key", nil))
+
+	if _, err := vc.Detect(context.Background()); err != nil {
+		t.Fatalf("Detect: %v", err)
+	}
+	caps := vc.Capabilities()
+	if !caps.ScanAPI || !caps.DesignerSessions || !caps.Backup {
+		t.Errorf("expected full capabilities on 8.3, got %+v", caps)
+	}
+}
+
+func TestLegacyClient_HealthCheckNotRunning(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"state":"STARTING"}`)
+	}))
+	defer srv.Close()
+
+	c := &LegacyClient{Client: NewClient("http", strings.TrimPrefix(srv.URL, "http://"), "", nil)}
+	if err := c.HealthCheck(); err == nil {
+		t.Error("expected error while gateway is starting")
+	}
+}
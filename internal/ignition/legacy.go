@@ -0,0 +1,55 @@
+package ignition
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// LegacyClient talks to Ignition 8.1 gateways, which predate the
+// /data/api/v1 REST API. Only health checks are available; scans, Designer
+// session listing, and backups report ErrUnsupported.
+type LegacyClient struct {
+	*Client
+}
+
+// Capabilities reports that 8.1 gateways support none of the optional operations.
+func (c *LegacyClient) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+// HealthCheck uses the unauthenticated /StatusPing endpoint, which returns
+// {"state":"RUNNING"} once the gateway has started.
+func (c *LegacyClient) HealthCheck() error {
+	resp, err := c.HTTPClient.Get(c.BaseURL + "/StatusPing")
+	if err != nil {
+		return fmt.Errorf("gateway health check: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gateway returned HTTP %d", resp.StatusCode)
+	}
+	if !strings.Contains(string(body), "RUNNING") {
+		return fmt.Errorf("gateway not running: %s", strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// TriggerScan is a no-op on 8.1; the result is marked as skipped.
+func (c *LegacyClient) TriggerScan() ScanResult {
+	return ScanResult{Skipped: true}
+}
+
+// GetDesignerSessions is not available on 8.1.
+func (c *LegacyClient) GetDesignerSessions(context.Context) ([]DesignerSession, error) {
+	return nil, ErrUnsupported
+}
+
+// DownloadBackup is not available on 8.1.
+func (c *LegacyClient) DownloadBackup(context.Context, io.Writer) (int64, error) {
+	return 0, ErrUnsupported
+}
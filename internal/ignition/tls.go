@@ -0,0 +1,45 @@
+package ignition
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// defaultServerName is the name verified against the gateway certificate when
+// none is configured. The agent always dials the gateway on localhost.
+const defaultServerName = "localhost"
+
+// NewTLSConfig builds the TLS configuration used for gateway API calls.
+// caFile is an optional path to a PEM CA bundle; when empty, the system roots
+// are used. insecure disables verification and must be an explicit opt-in.
+func NewTLSConfig(caFile, serverName string, insecure bool) (*tls.Config, error) {
+	if insecure {
+		//nolint:gosec // explicit opt-in via spec.gateway.tlsTrust.insecureSkipVerify
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+
+	if serverName == "" {
+		serverName = defaultServerName
+	}
+	cfg := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if caFile == "" {
+		return cfg, nil
+	}
+
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading gateway CA bundle %s: %w", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificates found in gateway CA bundle %s", caFile)
+	}
+	cfg.RootCAs = pool
+	return cfg, nil
+}
@@ -0,0 +1,115 @@
+package ignition
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeServerCA writes the httptest server's certificate as a PEM CA bundle.
+func writeServerCA(t *testing.T, srv *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.crt")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("writing CA bundle: %v", err)
+	}
+	return path
+}
+
+func newTLSGateway(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNewTLSConfig_Insecure(t *testing.T) {
+	cfg, err := NewTLSConfig("", "", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.InsecureSkipVerify {
+		t.Error("expected InsecureSkipVerify=true")
+	}
+}
+
+func TestNewTLSConfig_DefaultServerName(t *testing.T) {
+	cfg, err := NewTLSConfig("", "", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.InsecureSkipVerify {
+		t.Error("expected verification to be enabled by default")
+	}
+	if cfg.ServerName != defaultServerName {
+		t.Errorf("ServerName: got %q, want %q", cfg.ServerName, defaultServerName)
+	}
+	if cfg.RootCAs != nil {
+		t.Error("expected system roots when no CA file is set")
+	}
+}
+
+func TestNewTLSConfig_MissingCAFile(t *testing.T) {
+	_, err := NewTLSConfig(filepath.Join(t.TempDir(), "missing.crt"), "", false)
+	if err == nil {
+		t.Fatal("expected error for missing CA file")
+	}
+}
+
+func TestNewTLSConfig_InvalidPEM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.crt")
+	if err := os.WriteFile(path, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewTLSConfig(path, "", false)
+	if err == nil || !strings.Contains(err.Error(), "no PEM certificates") {
+		t.Fatalf("expected no-PEM error, got %v", err)
+	}
+}
+
+func TestClient_VerifiesWithCABundle(t *testing.T) {
+	srv := newTLSGateway(t)
+
+	// httptest certificates are issued for example.com and 127.0.0.1.
+	cfg, err := NewTLSConfig(writeServerCA(t, srv), "example.com", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewClient("https", strings.TrimPrefix(srv.URL, "https://"), "key", cfg)
+	if err := c.HealthCheck(); err != nil {
+		t.Errorf("expected trusted gateway to pass health check, got %v", err)
+	}
+}
+
+func TestClient_RejectsUntrustedCertificate(t *testing.T) {
+	srv := newTLSGateway(t)
+
+	cfg, err := NewTLSConfig("", "example.com", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewClient("https", strings.TrimPrefix(srv.URL, "https://"), "key", cfg)
+	if err := c.HealthCheck(); err == nil {
+		t.Error("expected untrusted certificate to fail verification")
+	}
+}
+
+func TestClient_RejectsServerNameMismatch(t *testing.T) {
+	srv := newTLSGateway(t)
+
+	cfg, err := NewTLSConfig(writeServerCA(t, srv), "ignition.internal", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := NewClient("https", strings.TrimPrefix(srv.URL, "https://"), "key", cfg)
+	if err := c.HealthCheck(); err == nil {
+		t.Error("expected server name mismatch to fail verification")
+	}
+}
@@ -0,0 +1,65 @@
+package ignition
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// VersionedClient is a GatewayClient that selects its implementation from the
+// gateway version. Until Detect succeeds it behaves as the 8.3 client, since
+// the gateway is usually not running yet when the agent starts.
+type VersionedClient struct {
+	base *Client
+
+	mu       sync.RWMutex
+	impl     GatewayClient
+	version  GatewayVersion
+	detected bool
+}
+
+// NewVersionedClient wraps base and defaults to the 8.3 API until detection.
+func NewVersionedClient(base *Client) *VersionedClient {
+	return &VersionedClient{base: base, impl: base}
+}
+
+// Detect queries the gateway version and switches to the matching implementation.
+func (v *VersionedClient) Detect(ctx context.Context) (GatewayVersion, error) {
+	version, err := v.base.DetectVersion(ctx)
+	if err != nil {
+		return GatewayVersion{}, err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.version = version
+	v.detected = true
+	v.impl = ForVersion(v.base, version)
+	return version, nil
+}
+
+// Version returns the detected gateway version and whether detection has succeeded.
+func (v *VersionedClient) Version() (GatewayVersion, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.version, v.detected
+}
+
+func (v *VersionedClient) current() GatewayClient {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.impl
+}
+
+func (v *VersionedClient) HealthCheck() error      { return v.current().HealthCheck() }
+func (v *VersionedClient) PortCheck() error        { return v.current().PortCheck() }
+func (v *VersionedClient) TriggerScan() ScanResult { return v.current().TriggerScan() }
+
+func (v *VersionedClient) GetDesignerSessions(ctx context.Context) ([]DesignerSession, error) {
+	return v.current().GetDesignerSessions(ctx)
+}
+
+func (v *VersionedClient) DownloadBackup(ctx context.Context, w io.Writer) (int64, error) {
+	return v.current().DownloadBackup(ctx, w)
+}
+
+func (v *VersionedClient) Capabilities() Capabilities { return v.current().Capabilities() }
@@ -0,0 +1,24 @@
+// Package profilespec holds the sync profile checks that the controller and
+// the validating webhook share, so a spec admitted at apply time is never
+// rejected at reconcile time or the other way round.
+package profilespec
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// varKey matches Go identifiers, the only var keys {{.Vars.key}} can reach.
+var varKey = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ValidVarKey reports whether k can be used as a template var key.
+func ValidVarKey(k string) bool {
+	return varKey.MatchString(k)
+}
+
+// ContainsTraversal checks for ".." path components.
+func ContainsTraversal(p string) bool {
+	return slices.Contains(strings.Split(filepath.ToSlash(p), "/"), "..")
+}
@@ -0,0 +1,24 @@
+package profilespec
+
+import "testing"
+
+func TestContainsTraversal(t *testing.T) {
+	for p, want := range map[string]bool{
+		"config/resources": false,
+		"sites/..hidden":   false,
+		"..":               true,
+		"sites/../secrets": true,
+	} {
+		if got := ContainsTraversal(p); got != want {
+			t.Errorf("ContainsTraversal(%q) = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestValidVarKey(t *testing.T) {
+	for k, want := range map[string]bool{"site": true, "_area2": true, "my-var": false, "2site": false, "a.b": false} {
+		if got := ValidVarKey(k); got != want {
+			t.Errorf("ValidVarKey(%q) = %v, want %v", k, got, want)
+		}
+	}
+}
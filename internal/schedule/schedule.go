@@ -0,0 +1,177 @@
+// Package schedule evaluates spec.sync.schedule maintenance windows and
+// blackout dates. The controller and agents share it so both defer new
+// commits at the same times.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// dateLayout is the day-granular blackout format; RFC 3339 is also accepted.
+const dateLayout = "2006-01-02"
+
+// maxSteps bounds the NextOpen search across alternating windows and blackouts.
+const maxSteps = 1000
+
+// Schedule is a parsed spec.sync.schedule.
+type Schedule struct {
+	loc       *time.Location
+	windows   []window
+	blackouts []blackout
+}
+
+type window struct {
+	start    cron.Schedule
+	duration time.Duration
+}
+
+type blackout struct {
+	start, end time.Time
+	reason     string
+}
+
+// Spec converts spec.sync.schedule to the form shared with agents through
+// the metadata ConfigMap.
+func Spec(spec *stokerv1alpha1.SyncSchedule) stokertypes.SyncSchedule {
+	out := stokertypes.SyncSchedule{TimeZone: spec.TimeZone}
+	for _, w := range spec.Windows {
+		out.Windows = append(out.Windows, stokertypes.ScheduleWindow{Start: w.Start, Duration: w.Duration})
+	}
+	for _, b := range spec.Blackouts {
+		out.Blackouts = append(out.Blackouts, stokertypes.ScheduleBlackout{Start: b.Start, End: b.End, Reason: b.Reason})
+	}
+	return out
+}
+
+// New parses spec. Windows are 5-field cron expressions evaluated in
+// spec.TimeZone (UTC when empty).
+func New(spec stokertypes.SyncSchedule) (*Schedule, error) {
+	loc := time.UTC
+	if spec.TimeZone != "" {
+		var err error
+		if loc, err = time.LoadLocation(spec.TimeZone); err != nil {
+			return nil, fmt.Errorf("time zone %q: %w", spec.TimeZone, err)
+		}
+	}
+	s := &Schedule{loc: loc}
+
+	for i, w := range spec.Windows {
+		start, err := cron.ParseStandard(w.Start)
+		if err != nil {
+			return nil, fmt.Errorf("windows[%d].start %q: %w", i, w.Start, err)
+		}
+		d, err := time.ParseDuration(w.Duration)
+		if err != nil {
+			return nil, fmt.Errorf("windows[%d].duration %q: %w", i, w.Duration, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("windows[%d].duration %q must be positive", i, w.Duration)
+		}
+		s.windows = append(s.windows, window{start: start, duration: d})
+	}
+
+	for i, b := range spec.Blackouts {
+		start, err := parseBound(b.Start, loc, false)
+		if err != nil {
+			return nil, fmt.Errorf("blackouts[%d].start: %w", i, err)
+		}
+		end, err := parseBound(b.End, loc, true)
+		if err != nil {
+			return nil, fmt.Errorf("blackouts[%d].end: %w", i, err)
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("blackouts[%d]: end %q is not after start %q", i, b.End, b.Start)
+		}
+		s.blackouts = append(s.blackouts, blackout{start: start, end: end, reason: b.Reason})
+	}
+	return s, nil
+}
+
+// parseBound parses a blackout date or RFC 3339 instant. A date end bound
+// covers the whole day, so it becomes midnight of the following day.
+func parseBound(v string, loc *time.Location, end bool) (time.Time, error) {
+	if t, err := time.ParseInLocation(dateLayout, v, loc); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date (YYYY-MM-DD) nor RFC 3339", v)
+	}
+	return t, nil
+}
+
+// Open reports whether new commits may be applied at t.
+func (s *Schedule) Open(t time.Time) bool {
+	_, frozen := s.Blackout(t)
+	return !frozen && s.inWindow(t)
+}
+
+// Blackout reports whether t falls in a blackout, and its reason.
+func (s *Schedule) Blackout(t time.Time) (string, bool) {
+	if b := s.blackoutAt(t); b != nil {
+		return b.reason, true
+	}
+	return "", false
+}
+
+func (s *Schedule) blackoutAt(t time.Time) *blackout {
+	for i := range s.blackouts {
+		if b := &s.blackouts[i]; !t.Before(b.start) && t.Before(b.end) {
+			return b
+		}
+	}
+	return nil
+}
+
+// inWindow reports whether t is inside a window. With no windows every time is.
+func (s *Schedule) inWindow(t time.Time) bool {
+	if len(s.windows) == 0 {
+		return true
+	}
+	t = t.In(s.loc)
+	for _, w := range s.windows {
+		// The latest window start covering t is the first one after t-duration.
+		if !w.start.Next(t.Add(-w.duration)).After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// nextWindowStart returns the earliest window start after t.
+func (s *Schedule) nextWindowStart(t time.Time) time.Time {
+	var next time.Time
+	for _, w := range s.windows {
+		if n := w.start.Next(t.In(s.loc)); !n.IsZero() && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next
+}
+
+// NextOpen returns the first time at or after t when commits may be
+// applied. ok is false when the schedule never opens again.
+func (s *Schedule) NextOpen(t time.Time) (time.Time, bool) {
+	for range maxSteps {
+		if b := s.blackoutAt(t); b != nil {
+			t = b.end
+			continue
+		}
+		if s.inWindow(t) {
+			return t, true
+		}
+		if t = s.nextWindowStart(t); t.IsZero() {
+			return time.Time{}, false
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,122 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func mustNew(t *testing.T, spec stokertypes.SyncSchedule) *Schedule {
+	t.Helper()
+	s, err := New(spec)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return s
+}
+
+func TestOpen_Windows(t *testing.T) {
+	// Shift changes at 06:00, 14:00 and 22:00 Chicago time, 30 minutes each.
+	s := mustNew(t, stokertypes.SyncSchedule{
+		TimeZone: "America/Chicago",
+		Windows:  []stokertypes.ScheduleWindow{{Start: "0 6,14,22 * * *", Duration: "30m"}},
+	})
+	chicago, _ := time.LoadLocation("America/Chicago")
+
+	cases := []struct {
+		at   time.Time
+		open bool
+	}{
+		{time.Date(2026, 3, 2, 6, 0, 0, 0, chicago), true},
+		{time.Date(2026, 3, 2, 6, 29, 0, 0, chicago), true},
+		{time.Date(2026, 3, 2, 6, 30, 0, 0, chicago), false},
+		{time.Date(2026, 3, 2, 13, 59, 0, 0, chicago), false},
+		{time.Date(2026, 3, 2, 22, 10, 0, 0, chicago), true},
+		// 12:05 UTC is 06:05 in Chicago (CST).
+		{time.Date(2026, 3, 2, 12, 5, 0, 0, time.UTC), true},
+	}
+	for _, tc := range cases {
+		if got := s.Open(tc.at); got != tc.open {
+			t.Errorf("Open(%s) = %v, want %v", tc.at, got, tc.open)
+		}
+	}
+}
+
+func TestOpen_Blackouts(t *testing.T) {
+	s := mustNew(t, stokertypes.SyncSchedule{
+		Blackouts: []stokertypes.ScheduleBlackout{
+			{Start: "2026-12-24", End: "2026-12-26", Reason: "holiday freeze"},
+			{Start: "2026-03-01T08:00:00Z", End: "2026-03-01T10:00:00Z"},
+		},
+	})
+
+	cases := []struct {
+		at     time.Time
+		open   bool
+		reason string
+	}{
+		{at: time.Date(2026, 12, 23, 23, 59, 0, 0, time.UTC), open: true},
+		{at: time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC), reason: "holiday freeze"},
+		{at: time.Date(2026, 12, 26, 23, 59, 0, 0, time.UTC), reason: "holiday freeze"},
+		{at: time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC), open: true},
+		{at: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)},
+		{at: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), open: true},
+	}
+	for _, tc := range cases {
+		if got := s.Open(tc.at); got != tc.open {
+			t.Errorf("Open(%s) = %v, want %v", tc.at, got, tc.open)
+		}
+		if reason, _ := s.Blackout(tc.at); reason != tc.reason {
+			t.Errorf("Blackout(%s) reason = %q, want %q", tc.at, reason, tc.reason)
+		}
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	s := mustNew(t, stokertypes.SyncSchedule{
+		Windows:   []stokertypes.ScheduleWindow{{Start: "0 2 * * *", Duration: "1h"}},
+		Blackouts: []stokertypes.ScheduleBlackout{{Start: "2026-12-24", End: "2026-12-26"}},
+	})
+
+	cases := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{"already open", time.Date(2026, 3, 1, 2, 15, 0, 0, time.UTC), time.Date(2026, 3, 1, 2, 15, 0, 0, time.UTC)},
+		{"later today", time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)},
+		{"tomorrow", time.Date(2026, 3, 1, 3, 0, 0, 0, time.UTC), time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC)},
+		{"after blackout", time.Date(2026, 12, 24, 1, 0, 0, 0, time.UTC), time.Date(2026, 12, 27, 2, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := s.NextOpen(tc.at)
+			if !ok || !got.Equal(tc.want) {
+				t.Errorf("NextOpen = %s (ok=%v), want %s", got, ok, tc.want)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    stokertypes.SyncSchedule
+		wantErr string
+	}{
+		{"bad time zone", stokertypes.SyncSchedule{TimeZone: "Mars/Olympus"}, "time zone"},
+		{"bad cron", stokertypes.SyncSchedule{Windows: []stokertypes.ScheduleWindow{{Start: "every day", Duration: "1h"}}}, "windows[0].start"},
+		{"bad duration", stokertypes.SyncSchedule{Windows: []stokertypes.ScheduleWindow{{Start: "0 2 * * *", Duration: "soon"}}}, "windows[0].duration"},
+		{"bad date", stokertypes.SyncSchedule{Blackouts: []stokertypes.ScheduleBlackout{{Start: "12/24", End: "2026-12-26"}}}, "blackouts[0].start"},
+		{"end before start", stokertypes.SyncSchedule{Blackouts: []stokertypes.ScheduleBlackout{{Start: "2026-12-26", End: "2026-12-24"}}}, "not after"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := New(tc.spec); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -14,6 +14,9 @@ type SyncResult struct {
 	ProjectsSynced []string
 	Duration       time.Duration
 	DryRunDiff     *DryRunDiff
+	// Changed lists the files a live apply added, modified, and deleted,
+	// relative to the live directory. Nil for dry-run syncs.
+	Changed *DryRunDiff
 }
 
 // Engine handles syncing files from a source directory to a destination directory.
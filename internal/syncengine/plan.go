@@ -34,6 +34,14 @@ type SyncPlan struct {
 	// Binary files must be rejected by the implementation. If nil, template-enabled
 	// mappings are staged without content transformation (no error).
 	ApplyTemplate func(stagedPath string) error
+	// BeforeApply is called in live mode after staging and before any file in
+	// the live directory is touched, with the diff the merge is about to apply.
+	// Returning an error aborts the sync with the live directory unchanged.
+	BeforeApply func(diff *DryRunDiff) error
+	// RetiredRoots are destinations a previous plan managed that this plan no
+	// longer maps (e.g. after a profile switch). They are treated as managed
+	// for orphan cleanup, so files left there by the previous plan are removed.
+	RetiredRoots []string
 }
 
 // DryRunDiff reports what a dry-run sync would change.
@@ -76,6 +84,9 @@ func (e *Engine) ExecutePlan(plan *SyncPlan) (*SyncResult, error) {
 
 	// Compute managed destination roots for orphan scoping.
 	managedRoots := computeManagedRoots(plan.Mappings)
+	for _, root := range plan.RetiredRoots {
+		managedRoots[filepath.ToSlash(root)] = true
+	}
 
 	if plan.DryRun {
 		// Phase 2 (dry-run): Compute diff without writing to live.
@@ -88,20 +99,35 @@ func (e *Engine) ExecutePlan(plan *SyncPlan) (*SyncResult, error) {
 		result.FilesModified = len(diff.Modified)
 		result.FilesDeleted = len(diff.Deleted)
 	} else {
+		if plan.BeforeApply != nil {
+			diff, err := computeDryRunDiff(plan.StagingDir, plan.LiveDir, managedRoots, excludes)
+			if err != nil {
+				return nil, fmt.Errorf("computing pre-apply diff: %w", err)
+			}
+			if err := plan.BeforeApply(diff); err != nil {
+				_ = os.RemoveAll(plan.StagingDir)
+				return nil, fmt.Errorf("before apply: %w", err)
+			}
+		}
+
 		// Phase 2 (live): Merge staging to live directory.
+		changed := &DryRunDiff{}
 		added, modified, err := mergeStagingToLive(plan.StagingDir, plan.LiveDir)
 		if err != nil {
 			return nil, fmt.Errorf("merging staging to live: %w", err)
 		}
-		result.FilesAdded = added
-		result.FilesModified = modified
+		changed.Added, changed.Modified = added, modified
+		result.FilesAdded = len(added)
+		result.FilesModified = len(modified)
 
 		// Orphan cleanup — only within managed roots.
 		deleted, err := cleanOrphans(plan.StagingDir, plan.LiveDir, managedRoots, excludes)
 		if err != nil {
 			return nil, fmt.Errorf("cleaning orphans: %w", err)
 		}
-		result.FilesDeleted = deleted
+		changed.Deleted = deleted
+		result.FilesDeleted = len(deleted)
+		result.Changed = changed
 	}
 
 	// Phase 3: Cleanup staging.
@@ -262,8 +288,9 @@ func isAncestorOfManagedRoot(relPath string, managedRoots map[string]bool) bool
 	return false
 }
 
-// mergeStagingToLive walks staging and copies changed files to live.
-func mergeStagingToLive(stagingDir, liveDir string) (added, modified int, err error) {
+// mergeStagingToLive walks staging and copies changed files to live. Returns
+// the relative paths of the files it created and overwrote.
+func mergeStagingToLive(stagingDir, liveDir string) (added, modified []string, err error) {
 	err = filepath.WalkDir(stagingDir, func(stagingPath string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
 			return walkErr
@@ -296,9 +323,9 @@ func mergeStagingToLive(stagingDir, liveDir string) (added, modified int, err er
 
 		if written {
 			if existed {
-				modified++
+				modified = append(modified, filepath.ToSlash(relPath))
 			} else {
-				added++
+				added = append(added, filepath.ToSlash(relPath))
 			}
 		}
 		return nil
@@ -306,9 +333,10 @@ func mergeStagingToLive(stagingDir, liveDir string) (added, modified int, err er
 	return added, modified, err
 }
 
-// cleanOrphans removes files in live that are under managed roots but not in staging.
-func cleanOrphans(stagingDir, liveDir string, managedRoots map[string]bool, excludes []string) (int, error) {
-	deleted := 0
+// cleanOrphans removes files in live that are under managed roots but not in
+// staging. Returns the relative paths of the removed files.
+func cleanOrphans(stagingDir, liveDir string, managedRoots map[string]bool, excludes []string) ([]string, error) {
+	var deleted []string
 
 	err := filepath.WalkDir(liveDir, func(livePath string, d fs.DirEntry, walkErr error) error {
 		if walkErr != nil {
@@ -366,7 +394,7 @@ func cleanOrphans(stagingDir, liveDir string, managedRoots map[string]bool, excl
 			if removeErr := os.Remove(livePath); removeErr != nil && !os.IsNotExist(removeErr) {
 				return fmt.Errorf("removing orphan %s: %w", relPath, removeErr)
 			}
-			deleted++
+			deleted = append(deleted, filepath.ToSlash(relPath))
 			// Remove now-empty parent directories up to the managed root boundary.
 			parentDir := filepath.Dir(livePath)
 			for {
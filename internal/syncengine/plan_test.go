@@ -561,6 +561,111 @@ func TestExecutePlan_DryRun_NestedManagedRoot_DetectsOrphan(t *testing.T) {
 	}
 }
 
+func TestExecutePlan_BeforeApply(t *testing.T) {
+	tmp := t.TempDir()
+
+	src := filepath.Join(tmp, "src")
+	live := filepath.Join(tmp, "live")
+
+	writeTestFile(t, filepath.Join(src, "new.txt"), "new-content")
+	writeTestFile(t, filepath.Join(live, "config", "orphan.txt"), "delete-me")
+
+	var seen *DryRunDiff
+	engine := &Engine{}
+	plan := &SyncPlan{
+		Mappings: []ResolvedMapping{
+			{Source: src, Destination: "config", Type: "dir"},
+		},
+		StagingDir: filepath.Join(tmp, "staging"),
+		LiveDir:    live,
+		BeforeApply: func(diff *DryRunDiff) error {
+			seen = diff
+			// Live must still be untouched when the hook runs.
+			if _, err := os.Stat(filepath.Join(live, "config", "new.txt")); !os.IsNotExist(err) {
+				t.Error("BeforeApply called after live was modified")
+			}
+			return nil
+		},
+	}
+
+	if _, err := engine.ExecutePlan(plan); err != nil {
+		t.Fatalf("ExecutePlan: %v", err)
+	}
+	if seen == nil {
+		t.Fatal("expected BeforeApply to be called")
+	}
+	if len(seen.Added) != 1 || len(seen.Deleted) != 1 {
+		t.Errorf("unexpected diff: %+v", seen)
+	}
+	if got := readTestFile(t, filepath.Join(live, "config", "new.txt")); got != "new-content" {
+		t.Errorf("expected new.txt to be applied, got %q", got)
+	}
+}
+
+func TestExecutePlan_BeforeApplyErrorLeavesLiveUnchanged(t *testing.T) {
+	tmp := t.TempDir()
+
+	src := filepath.Join(tmp, "src")
+	live := filepath.Join(tmp, "live")
+
+	writeTestFile(t, filepath.Join(src, "changed.txt"), "updated")
+	writeTestFile(t, filepath.Join(live, "config", "changed.txt"), "original")
+
+	engine := &Engine{}
+	plan := &SyncPlan{
+		Mappings: []ResolvedMapping{
+			{Source: src, Destination: "config", Type: "dir"},
+		},
+		StagingDir:  filepath.Join(tmp, "staging"),
+		LiveDir:     live,
+		BeforeApply: func(*DryRunDiff) error { return os.ErrPermission },
+	}
+
+	if _, err := engine.ExecutePlan(plan); err == nil {
+		t.Fatal("expected BeforeApply error to abort the sync")
+	}
+	if got := readTestFile(t, filepath.Join(live, "config", "changed.txt")); got != "original" {
+		t.Errorf("live should be unchanged after aborted sync; got %q", got)
+	}
+}
+
+func TestExecutePlan_RetiredRootsCleaned(t *testing.T) {
+	tmp := t.TempDir()
+
+	src := filepath.Join(tmp, "src")
+	live := filepath.Join(tmp, "live")
+
+	writeTestFile(t, filepath.Join(src, "app.json"), "new-profile")
+	// Left behind by the previous profile, which mapped projects/legacy.
+	writeTestFile(t, filepath.Join(live, "projects", "legacy", "view.json"), "old-profile")
+	// Not managed by either profile.
+	writeTestFile(t, filepath.Join(live, "projects", "other", "view.json"), "unmanaged")
+
+	engine := &Engine{}
+	plan := &SyncPlan{
+		Mappings: []ResolvedMapping{
+			{Source: src, Destination: "projects/site", Type: "dir"},
+		},
+		StagingDir:   filepath.Join(tmp, "staging"),
+		LiveDir:      live,
+		RetiredRoots: []string{"projects/legacy"},
+	}
+
+	result, err := engine.ExecutePlan(plan)
+	if err != nil {
+		t.Fatalf("ExecutePlan: %v", err)
+	}
+	if result.FilesDeleted != 1 {
+		t.Errorf("expected 1 retired file deleted, got %d", result.FilesDeleted)
+	}
+	if _, err := os.Stat(filepath.Join(live, "projects", "legacy", "view.json")); !os.IsNotExist(err) {
+		t.Error("file under retired root should be removed")
+	}
+	if got := readTestFile(t, filepath.Join(live, "projects", "other", "view.json")); got != "unmanaged" {
+		t.Error("unmanaged file should be untouched")
+	}
+}
+
 // Helpers
 
 func writeTestFile(t *testing.T, path, content string) {
@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// GatewaySyncCRDName is the name of the GatewaySync CustomResourceDefinition.
+	GatewaySyncCRDName = "gatewaysyncs.stoker.io"
+
+	// ConversionPath is where the conversion webhook is served.
+	ConversionPath = "/convert"
+
+	// defaultCABundlePollInterval is how often the CA bundle file is checked
+	// for rotation.
+	defaultCABundlePollInterval = time.Minute
+)
+
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,resourceNames=gatewaysyncs.stoker.io,verbs=get;patch
+
+// CRDConversionPatcher points the GatewaySync CRD's conversion at this
+// manager's webhook server at startup and then serves every version. It keeps
+// watching the CA bundle file and patches the CRD again when the certificate
+// rotates, so the API server keeps trusting the webhook.
+// Helm installs CRDs from crds/ without templating, so the service and CA
+// bundle can only be filled in at runtime. The CRD ships with v1beta1
+// unserved so it is never read through the lossy None strategy. It implements
+// manager.Runnable.
+type CRDConversionPatcher struct {
+	Client client.Client
+	// Service is the webhook Service fronting the manager on port 443.
+	Service types.NamespacedName
+	// CABundlePath is the PEM CA that signed the webhook serving certificate.
+	CABundlePath string
+	// PollInterval is how often CABundlePath is checked for a new CA.
+	// Defaults to one minute.
+	PollInterval time.Duration
+}
+
+// Start patches the CRD once and fails if that does not succeed, then polls
+// the CA bundle and patches again whenever its contents change. Later failures
+// are logged and retried on the next poll. Blocks until ctx is cancelled.
+func (p *CRDConversionPatcher) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("crd-conversion")
+
+	caBundle, err := os.ReadFile(p.CABundlePath)
+	if err != nil {
+		return fmt.Errorf("reading conversion webhook CA bundle: %w", err)
+	}
+	if err := p.patch(ctx, caBundle); err != nil {
+		return err
+	}
+	log.Info("configured conversion webhook", "crd", GatewaySyncCRDName, "service", p.Service.String())
+
+	interval := p.PollInterval
+	if interval <= 0 {
+		interval = defaultCABundlePollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		current, err := os.ReadFile(p.CABundlePath)
+		if err != nil {
+			log.Error(err, "reading conversion webhook CA bundle")
+			continue
+		}
+		if bytes.Equal(current, caBundle) {
+			continue
+		}
+		if err := p.patch(ctx, current); err != nil {
+			log.Error(err, "updating conversion webhook CA bundle")
+			continue
+		}
+		caBundle = current
+		log.Info("updated conversion webhook CA bundle", "crd", GatewaySyncCRDName)
+	}
+}
+
+// patch sets spec.conversion with the given CA bundle and marks every version
+// served. The versions list is patched in the same request so no version is
+// served before the webhook converts it.
+func (p *CRDConversionPatcher) patch(ctx context.Context, caBundle []byte) error {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := p.Client.Get(ctx, client.ObjectKey{Name: GatewaySyncCRDName}, crd); err != nil {
+		return fmt.Errorf("getting %s: %w", GatewaySyncCRDName, err)
+	}
+	versions, found, err := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	if err != nil {
+		return fmt.Errorf("reading %s versions: %w", GatewaySyncCRDName, err)
+	}
+	if !found {
+		return fmt.Errorf("%s has no versions", GatewaySyncCRDName)
+	}
+	for _, v := range versions {
+		if m, ok := v.(map[string]any); ok {
+			m["served"] = true
+		}
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"versions": versions,
+			"conversion": map[string]any{
+				"strategy": "Webhook",
+				"webhook": map[string]any{
+					"conversionReviewVersions": []string{"v1"},
+					"clientConfig": map[string]any{
+						"caBundle": caBundle,
+						"service": map[string]any{
+							"namespace": p.Service.Namespace,
+							"name":      p.Service.Name,
+							"path":      ConversionPath,
+							"port":      443,
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := p.Client.Patch(ctx, crd, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("patching %s conversion: %w", GatewaySyncCRDName, err)
+	}
+	return nil
+}
+
+// NeedLeaderElection returns false: every replica serves /convert, and the
+// patch is idempotent.
+func (p *CRDConversionPatcher) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCRDConversionPatcher(t *testing.T) {
+	caPath := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(caPath, []byte("test-ca"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	crd.SetName(GatewaySyncCRDName)
+	_ = unstructured.SetNestedField(crd.Object, "None", "spec", "conversion", "strategy")
+	_ = unstructured.SetNestedSlice(crd.Object, []any{
+		map[string]any{"name": "v1alpha1", "served": true, "storage": true},
+		map[string]any{"name": "v1beta1", "served": false, "storage": false},
+	}, "spec", "versions")
+	c := fake.NewClientBuilder().WithObjects(crd).Build()
+
+	p := &CRDConversionPatcher{
+		Client:       c,
+		Service:      types.NamespacedName{Namespace: "stoker-system", Name: "stoker-webhook-service"},
+		CABundlePath: caPath,
+		PollInterval: 10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Start(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Start: %v", err)
+		}
+	}()
+
+	got := waitForCABundle(t, c, "dGVzdC1jYQ==")
+	if s, _, _ := unstructured.NestedString(got.Object, "spec", "conversion", "strategy"); s != "Webhook" {
+		t.Errorf("expected Webhook strategy, got %q", s)
+	}
+	versions, _, _ := unstructured.NestedSlice(got.Object, "spec", "versions")
+	if len(versions) != 2 {
+		t.Fatalf("expected both versions kept, got %v", versions)
+	}
+	for _, v := range versions {
+		if served, _, _ := unstructured.NestedBool(v.(map[string]any), "served"); !served {
+			t.Errorf("expected %v to be served", v)
+		}
+	}
+	cfg := []string{"spec", "conversion", "webhook", "clientConfig"}
+	if path, _, _ := unstructured.NestedString(got.Object, append(cfg, "service", "path")...); path != ConversionPath {
+		t.Errorf("expected path %s, got %q", ConversionPath, path)
+	}
+	if ns, _, _ := unstructured.NestedString(got.Object, append(cfg, "service", "namespace")...); ns != "stoker-system" {
+		t.Errorf("expected service namespace stoker-system, got %q", ns)
+	}
+
+	// A rotated CA is patched in on the next poll.
+	if err := os.WriteFile(caPath, []byte("rotated-ca"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitForCABundle(t, c, "cm90YXRlZC1jYQ==")
+}
+
+func TestCRDConversionPatcher_MissingCA(t *testing.T) {
+	p := &CRDConversionPatcher{
+		Client:       fake.NewClientBuilder().Build(),
+		Service:      types.NamespacedName{Namespace: "ns", Name: "svc"},
+		CABundlePath: filepath.Join(t.TempDir(), "ca.crt"),
+	}
+	if err := p.Start(context.Background()); err == nil {
+		t.Fatal("expected an error without a CA bundle")
+	}
+}
+
+// waitForCABundle polls the CRD until its conversion caBundle (base64 of the
+// PEM file) equals want, and returns the CRD.
+func waitForCABundle(t *testing.T, c client.Client, want string) *unstructured.Unstructured {
+	t.Helper()
+	var ca string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		got := &unstructured.Unstructured{}
+		got.SetGroupVersionKind(crdGVK)
+		if err := c.Get(context.Background(), client.ObjectKey{Name: GatewaySyncCRDName}, got); err != nil {
+			t.Fatal(err)
+		}
+		ca, _, _ = unstructured.NestedString(got.Object, "spec", "conversion", "webhook", "clientConfig", "caBundle")
+		if ca == want {
+			return got
+		}
+	}
+	t.Fatalf("caBundle: got %q, want %q", ca, want)
+	return nil
+}
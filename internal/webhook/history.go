@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// GatewayHistory is one gateway's sync history as served by GET /history.
+type GatewayHistory struct {
+	Name    string                   `json:"name"`
+	History []stokertypes.SyncRecord `json:"history"`
+}
+
+// handleHistory serves the sync history agents report in their per-gateway
+// status ConfigMaps to callers presenting the bearer token. Optional query
+// parameters: gateway=<name> limits the response to one gateway,
+// since=<duration> (e.g. 24h) drops older records.
+func (rv *Receiver) handleHistory(w http.ResponseWriter, r *http.Request) {
+	log := logf.FromContext(r.Context()).WithName("webhook-receiver")
+
+	namespace := r.PathValue("namespace")
+	crName := r.PathValue("crName")
+
+	// A GET has no body to sign, so history requires the bearer token and is
+	// disabled without one.
+	if rv.BearerToken == "" {
+		http.Error(w, "history disabled: no bearer token configured", http.StatusForbidden)
+		return
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(rv.BearerToken)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			http.Error(w, `{"error":"since must be a positive duration such as 24h"}`, http.StatusBadRequest)
+			return
+		}
+		since = time.Now().Add(-d)
+	}
+	gatewayFilter := r.URL.Query().Get("gateway")
+
+	var gs stokerv1alpha1.GatewaySync
+	if err := rv.Client.Get(r.Context(), types.NamespacedName{Name: crName, Namespace: namespace}, &gs); err != nil {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	var cmList corev1.ConfigMapList
+	if err := rv.Client.List(r.Context(), &cmList,
+		client.InNamespace(namespace),
+		client.MatchingLabels{
+			stokertypes.LabelCRName:        crName,
+			stokertypes.LabelGatewayStatus: "true",
+		},
+	); err != nil {
+		log.Error(err, "failed to list gateway status ConfigMaps", "namespace", namespace, "cr", crName)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	gateways := []GatewayHistory{}
+	for _, cm := range cmList.Items {
+		for gatewayName, statusJSON := range cm.Data {
+			if gatewayFilter != "" && gatewayName != gatewayFilter {
+				continue
+			}
+			var status stokertypes.GatewayStatus
+			if err := json.Unmarshal([]byte(statusJSON), &status); err != nil {
+				log.Error(err, "failed to unmarshal gateway status", "gateway", gatewayName)
+				continue
+			}
+			gateways = append(gateways, GatewayHistory{
+				Name:    gatewayName,
+				History: filterHistory(status.History, since),
+			})
+		}
+	}
+	sort.Slice(gateways, func(i, j int) bool { return gateways[i].Name < gateways[j].Name })
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"namespace": namespace,
+		"name":      crName,
+		"gateways":  gateways,
+	})
+}
+
+// filterHistory returns the records that finished at or after since. A zero
+// since keeps every record.
+func filterHistory(history []stokertypes.SyncRecord, since time.Time) []stokertypes.SyncRecord {
+	records := []stokertypes.SyncRecord{}
+	for _, rec := range history {
+		if !since.IsZero() {
+			end, err := time.Parse(time.RFC3339, rec.EndTime)
+			if err != nil || end.Before(since) {
+				continue
+			}
+		}
+		records = append(records, rec)
+	}
+	return records
+}
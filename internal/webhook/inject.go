@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
@@ -17,6 +18,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
 	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
 )
 
@@ -25,6 +27,9 @@ const (
 
 	defaultAgentImage = "ghcr.io/ia-eknorr/stoker-agent:latest"
 
+	// defaultBackupMaxCount mirrors the CRD default for retention.maxCount.
+	defaultBackupMaxCount = 10
+
 	// Volume names injected by the webhook.
 	volumeSyncRepo       = "sync-repo"
 	volumeGitCredentials = "git-credentials"
@@ -32,6 +37,12 @@ const (
 	volumeGitHubToken    = "git-token"
 	volumeGitTmp         = "git-tmp"
 	volumeKnownHosts     = "known-hosts"
+	volumeGatewayCA      = "gateway-ca"
+	volumeBackups        = "gateway-backups"
+	volumePodInfo        = "pod-info"
+	volumeAuditLogs      = "audit-logs"
+	volumeAuditToken     = "audit-token"
+	volumeTrustedKeys    = "trusted-keys"
 
 	// Mount paths inside the agent container.
 	mountRepo           = "/repo"
@@ -40,10 +51,23 @@ const (
 	mountAPIKey         = "/etc/stoker/api-key"
 	mountGitHubToken    = "/etc/stoker/git-token"
 	mountKnownHosts     = "/etc/stoker/known-hosts"
+	mountGatewayCA      = "/etc/stoker/gateway-ca"
+	mountBackups        = "/backups"
+	mountPodInfo        = "/etc/stoker/pod-info"
+	mountAuditLogs      = "/var/log/stoker-audit"
+	mountAuditToken     = "/etc/stoker/audit-token"
+	mountTrustedKeys    = "/etc/stoker/trusted-keys"
+
+	// podAnnotationsFile is the Downward API file the agent reads live for
+	// per-pod overrides such as stoker.io/ref-override.
+	podAnnotationsFile = "annotations"
 
 	// Environment variable for operator-level default agent image.
 	envDefaultAgentImage = "DEFAULT_AGENT_IMAGE"
 
+	// Environment variable for the operator-level git proxy, passed on to agents.
+	envGitProxyURL = "GIT_PROXY_URL"
+
 	// annotationTrue is the canonical "true" value for boolean annotations.
 	annotationTrue = "true"
 )
@@ -75,8 +99,14 @@ func (p *PodInjector) Handle(ctx context.Context, req admission.Request) admissi
 		return admission.Allowed("already injected")
 	}
 
+	// The CR lives in the pod's namespace unless stoker.io/cr-namespace names another.
+	crNamespace := req.Namespace
+	if ns := pod.Annotations[stokertypes.AnnotationCRNamespace]; ns != "" {
+		crNamespace = ns
+	}
+
 	// Resolve CR name (annotation or auto-derive)
-	crName, err := p.resolveCRName(ctx, req.Namespace, pod)
+	crName, err := p.resolveCRName(ctx, crNamespace, req.Namespace, pod)
 	if err != nil {
 		log.Info("denied injection", "pod", pod.Name, "reason", err.Error())
 		return admission.Denied(err.Error())
@@ -92,28 +122,32 @@ func (p *PodInjector) Handle(ctx context.Context, req admission.Request) admissi
 
 	// Fetch GatewaySync CR
 	var gs stokerv1alpha1.GatewaySync
-	key := client.ObjectKey{Name: crName, Namespace: req.Namespace}
+	key := client.ObjectKey{Name: crName, Namespace: crNamespace}
 	if err := p.Client.Get(ctx, key, &gs); err != nil {
 		if apierrors.IsNotFound(err) {
 			return admission.Denied(fmt.Sprintf(
-				"GatewaySync '%s' not found in namespace '%s'", crName, req.Namespace))
+				"GatewaySync '%s' not found in namespace '%s'", crName, crNamespace))
 		}
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
+	// A CR in another namespace must allow this one
+	if !gs.AllowsNamespace(req.Namespace) {
+		return admission.Denied(fmt.Sprintf(
+			"GatewaySync '%s/%s' does not list namespace '%s' in spec.allowedNamespaces", crNamespace, crName, req.Namespace))
+	}
+
 	// Check if CR is paused
 	if gs.Spec.Paused {
 		return admission.Denied(fmt.Sprintf(
 			"GatewaySync '%s' is paused", crName))
 	}
 
-	// Validate profile if specified — check against embedded profiles map
+	// Validate profile if specified — check against embedded and referenced profiles
 	profileName := pod.Annotations[stokertypes.AnnotationProfile]
-	if profileName != "" {
-		if _, exists := gs.Spec.Sync.Profiles[profileName]; !exists {
-			return admission.Denied(fmt.Sprintf(
-				"profile '%s' not found in GatewaySync '%s'", profileName, gs.Name))
-		}
+	if profileName != "" && !hasProfile(&gs, profileName) {
+		return admission.Denied(fmt.Sprintf(
+			"profile '%s' not found in GatewaySync '%s'", profileName, gs.Name))
 	}
 
 	// Inject sidecar
@@ -126,7 +160,7 @@ func (p *PodInjector) Handle(ctx context.Context, req admission.Request) admissi
 	}
 
 	webhookInjectorInjectionsTotal.WithLabelValues(req.Namespace, "injected").Inc()
-	log.Info("injected stoker-agent sidecar", "pod", pod.Name, "cr", crName, "namespace", req.Namespace)
+	log.Info("injected stoker-agent sidecar", "pod", pod.Name, "cr", crName, "crNamespace", crNamespace, "namespace", req.Namespace)
 	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledPod)
 }
 
@@ -140,8 +174,19 @@ func isAlreadyInjected(pod *corev1.Pod) bool {
 	return false
 }
 
-// resolveCRName resolves the GatewaySync CR name from annotation or auto-derives it.
-func (p *PodInjector) resolveCRName(ctx context.Context, namespace string, pod *corev1.Pod) (string, error) {
+// hasProfile reports whether name is an inline profile or a spec.sync.profileRefs entry.
+func hasProfile(gs *stokerv1alpha1.GatewaySync, name string) bool {
+	if _, ok := gs.Spec.Sync.Profiles[name]; ok {
+		return true
+	}
+	return slices.ContainsFunc(gs.Spec.Sync.ProfileRefs, func(ref stokerv1alpha1.ProfileRef) bool {
+		return ref.As == name || (ref.As == "" && ref.Name == name)
+	})
+}
+
+// resolveCRName resolves the GatewaySync CR name from annotation or auto-derives it
+// from the CRs in namespace that allow pods from podNamespace.
+func (p *PodInjector) resolveCRName(ctx context.Context, namespace, podNamespace string, pod *corev1.Pod) (string, error) {
 	if crName := pod.Annotations[stokertypes.AnnotationCRName]; crName != "" {
 		return crName, nil
 	}
@@ -151,17 +196,22 @@ func (p *PodInjector) resolveCRName(ctx context.Context, namespace string, pod *
 	if err := p.Client.List(ctx, &list, client.InNamespace(namespace)); err != nil {
 		return "", fmt.Errorf("failed to list GatewaySync CRs: %w", err)
 	}
+	var names []string
+	for i := range list.Items {
+		if list.Items[i].AllowsNamespace(podNamespace) {
+			names = append(names, list.Items[i].Name)
+		}
+	}
 
-	switch len(list.Items) {
+	switch len(names) {
 	case 0:
+		if namespace != podNamespace {
+			return "", fmt.Errorf("no GatewaySync CR in namespace '%s' allows namespace '%s'", namespace, podNamespace)
+		}
 		return "", fmt.Errorf("no GatewaySync CR found in namespace '%s'", namespace)
 	case 1:
-		return list.Items[0].Name, nil
+		return names[0], nil
 	default:
-		names := make([]string, len(list.Items))
-		for i, item := range list.Items {
-			names[i] = item.Name
-		}
 		return "", fmt.Errorf(
 			"multiple GatewaySync CRs in namespace '%s': [%s] — set annotation '%s' explicitly",
 			namespace, strings.Join(names, ", "), stokertypes.AnnotationCRName)
@@ -196,7 +246,8 @@ func injectSidecar(pod *corev1.Pod, gs *stokerv1alpha1.GatewaySync) {
 	}
 
 	// Build env vars
-	env := buildEnvVars(crName, gatewayName, profile, gatewayPort, gatewayTLS, gs)
+	crNamespace := pod.Annotations[stokertypes.AnnotationCRNamespace]
+	env := buildEnvVars(crName, crNamespace, gatewayName, profile, gatewayPort, gatewayTLS, gs)
 
 	// Build resources
 	resources := buildResources(gs)
@@ -335,7 +386,8 @@ func resolveAgentPullPolicy(gs *stokerv1alpha1.GatewaySync) string {
 }
 
 // buildEnvVars constructs the environment variables for the agent container.
-func buildEnvVars(crName, gatewayName, profile, gatewayPort, gatewayTLS string, gs *stokerv1alpha1.GatewaySync) []corev1.EnvVar {
+// An empty crNamespace means the CR is in the pod's namespace.
+func buildEnvVars(crName, crNamespace, gatewayName, profile, gatewayPort, gatewayTLS string, gs *stokerv1alpha1.GatewaySync) []corev1.EnvVar {
 	env := []corev1.EnvVar{
 		{
 			Name: "POD_NAME",
@@ -350,12 +402,7 @@ func buildEnvVars(crName, gatewayName, profile, gatewayPort, gatewayTLS string,
 			},
 		},
 		{Name: "CR_NAME", Value: crName},
-		{
-			Name: "CR_NAMESPACE",
-			ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
-			},
-		},
+		crNamespaceEnvVar(crNamespace),
 		{Name: "GATEWAY_NAME", Value: gatewayName},
 		{Name: "PROFILE", Value: profile},
 		{Name: "REPO_PATH", Value: mountRepo},
@@ -363,6 +410,7 @@ func buildEnvVars(crName, gatewayName, profile, gatewayPort, gatewayTLS string,
 		{Name: "GATEWAY_PORT", Value: gatewayPort},
 		{Name: "GATEWAY_TLS", Value: gatewayTLS},
 		{Name: "API_KEY_FILE", Value: mountAPIKey + "/" + gs.Spec.Gateway.API.SecretKey},
+		{Name: "POD_ANNOTATIONS_FILE", Value: mountPodInfo + "/" + podAnnotationsFile},
 	}
 
 	// Git credential env vars depend on auth type
@@ -391,9 +439,116 @@ func buildEnvVars(crName, gatewayName, profile, gatewayPort, gatewayTLS string,
 		}
 	}
 
+	// Agents reach the repository through the same proxy as the controller.
+	if proxy := os.Getenv(envGitProxyURL); proxy != "" {
+		env = append(env, corev1.EnvVar{Name: envGitProxyURL, Value: proxy})
+	}
+
+	// Commit signature verification after checkout
+	if needsTrustedKeysVolume(gs) {
+		policy := gs.Spec.Git.Verification.Policy
+		if policy == "" {
+			policy = git.VerifySignedCommit
+		}
+		env = append(env,
+			corev1.EnvVar{Name: "VERIFY_POLICY", Value: policy},
+			corev1.EnvVar{Name: "VERIFY_KEYS_DIR", Value: mountTrustedKeys},
+		)
+	}
+
+	// Gateway certificate verification settings
+	if trust := gs.Spec.Gateway.TLSTrust; trust != nil {
+		if trust.InsecureSkipVerify {
+			env = append(env, corev1.EnvVar{Name: "GATEWAY_TLS_INSECURE", Value: annotationTrue})
+		}
+		if trust.ServerName != "" {
+			env = append(env, corev1.EnvVar{Name: "GATEWAY_TLS_SERVER_NAME", Value: trust.ServerName})
+		}
+		if needsGatewayCAVolume(gs) {
+			env = append(env, corev1.EnvVar{
+				Name:  "GATEWAY_CA_FILE",
+				Value: mountGatewayCA + "/" + gatewayCABundleKey(trust.CABundle),
+			})
+		}
+	}
+
+	// Pre-change gateway backups
+	if needsBackupVolume(gs) {
+		backup := gs.Spec.Gateway.Backup
+		env = append(env, corev1.EnvVar{Name: "BACKUP_DIR", Value: mountBackups})
+		if len(backup.Paths) > 0 {
+			env = append(env, corev1.EnvVar{Name: "BACKUP_PATHS", Value: strings.Join(backup.Paths, ",")})
+		}
+		// The CRD defaults maxCount only when retention is set, so apply it
+		// here too; otherwise backups grow without limit.
+		maxCount := int32(defaultBackupMaxCount)
+		if r := backup.Retention; r != nil {
+			if r.MaxCount > 0 {
+				maxCount = r.MaxCount
+			}
+			if r.MaxAge != "" {
+				env = append(env, corev1.EnvVar{Name: "BACKUP_MAX_AGE", Value: r.MaxAge})
+			}
+		}
+		env = append(env, corev1.EnvVar{Name: "BACKUP_MAX_COUNT", Value: fmt.Sprintf("%d", maxCount)})
+	}
+
 	// Sync period defaults to 30
 	env = append(env, corev1.EnvVar{Name: "SYNC_PERIOD", Value: "30"})
 
+	if limit := gs.Spec.Agent.SyncHistoryLimit; limit > 0 {
+		env = append(env, corev1.EnvVar{Name: "SYNC_HISTORY_LIMIT", Value: fmt.Sprintf("%d", limit)})
+	}
+
+	return append(env, auditEnvVars(gs.Spec.Agent.Audit)...)
+}
+
+// crNamespaceEnvVar returns CR_NAMESPACE: crNamespace, or the pod's own
+// namespace through the Downward API when empty.
+func crNamespaceEnvVar(crNamespace string) corev1.EnvVar {
+	if crNamespace != "" {
+		return corev1.EnvVar{Name: "CR_NAMESPACE", Value: crNamespace}
+	}
+	return corev1.EnvVar{
+		Name: "CR_NAMESPACE",
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+		},
+	}
+}
+
+// auditEnvVars returns the agent env vars for the configured audit sinks.
+func auditEnvVars(spec *stokerv1alpha1.AuditSpec) []corev1.EnvVar {
+	if spec == nil {
+		return nil
+	}
+	var env []corev1.EnvVar
+	if spec.Stdout {
+		env = append(env, corev1.EnvVar{Name: "AUDIT_STDOUT", Value: annotationTrue})
+	}
+	if f := spec.File; f != nil && f.ClaimName != "" {
+		// One file per pod: gateways sharing the claim never write the same file.
+		// $(POD_NAME) is expanded by the kubelet from the env var defined above.
+		env = append(env, corev1.EnvVar{Name: "AUDIT_FILE", Value: mountAuditLogs + "/$(POD_NAME).log"})
+		if f.MaxSizeMB > 0 {
+			env = append(env, corev1.EnvVar{Name: "AUDIT_FILE_MAX_SIZE_MB", Value: fmt.Sprintf("%d", f.MaxSizeMB)})
+		}
+		if f.MaxBackups > 0 {
+			env = append(env, corev1.EnvVar{Name: "AUDIT_FILE_MAX_BACKUPS", Value: fmt.Sprintf("%d", f.MaxBackups)})
+		}
+	}
+	if sl := spec.Syslog; sl != nil {
+		env = append(env, corev1.EnvVar{Name: "AUDIT_SYSLOG_ADDRESS", Value: sl.Address})
+		if sl.Protocol != "" {
+			env = append(env, corev1.EnvVar{Name: "AUDIT_SYSLOG_PROTOCOL", Value: sl.Protocol})
+		}
+	}
+	if h := spec.HTTP; h != nil {
+		env = append(env, corev1.EnvVar{Name: "AUDIT_HTTP_URL", Value: h.URL})
+		if h.TokenSecretRef != nil {
+			env = append(env, corev1.EnvVar{Name: "AUDIT_HTTP_TOKEN_FILE", Value: mountAuditToken + "/" + h.TokenSecretRef.Key})
+		}
+	}
 	return env
 }
 
@@ -453,17 +608,74 @@ func needsGitHubTokenVolume(gs *stokerv1alpha1.GatewaySync) bool {
 	return gs.Spec.Git.Auth != nil && gs.Spec.Git.Auth.GitHubApp != nil
 }
 
+// needsTrustedKeysVolume returns true when commit signature verification is configured.
+func needsTrustedKeysVolume(gs *stokerv1alpha1.GatewaySync) bool {
+	return gs.Spec.Git.Verification != nil && gs.Spec.Git.Verification.TrustedKeysSecretName != ""
+}
+
 // gitHubTokenSecretName returns the controller-managed Secret name for a GitHub App token.
 func gitHubTokenSecretName(crName string) string {
 	return fmt.Sprintf("stoker-github-token-%s", crName)
 }
 
+// needsGatewayCAVolume returns true when a gateway CA bundle is configured.
+func needsGatewayCAVolume(gs *stokerv1alpha1.GatewaySync) bool {
+	trust := gs.Spec.Gateway.TLSTrust
+	return trust != nil && trust.CABundle != nil &&
+		(trust.CABundle.SecretRef != nil || trust.CABundle.ConfigMapRef != nil)
+}
+
+// needsBackupVolume returns true when pre-change gateway backups are enabled.
+func needsBackupVolume(gs *stokerv1alpha1.GatewaySync) bool {
+	backup := gs.Spec.Gateway.Backup
+	return backup != nil && backup.ClaimName != "" && (backup.Enabled == nil || *backup.Enabled)
+}
+
+// needsAuditLogVolume returns true when the audit file sink is configured.
+func needsAuditLogVolume(gs *stokerv1alpha1.GatewaySync) bool {
+	audit := gs.Spec.Agent.Audit
+	return audit != nil && audit.File != nil && audit.File.ClaimName != ""
+}
+
+// needsAuditTokenVolume returns true when the audit HTTP sink uses a bearer token.
+func needsAuditTokenVolume(gs *stokerv1alpha1.GatewaySync) bool {
+	audit := gs.Spec.Agent.Audit
+	return audit != nil && audit.HTTP != nil && audit.HTTP.TokenSecretRef != nil
+}
+
+// gatewayCABundleKey returns the key holding the PEM bundle within the referenced object.
+func gatewayCABundleKey(src *stokerv1alpha1.CABundleSource) string {
+	if src.SecretRef != nil {
+		return src.SecretRef.Key
+	}
+	return src.ConfigMapRef.Key
+}
+
+// gatewayCAVolumeSource returns the Secret or ConfigMap volume source for the CA bundle.
+func gatewayCAVolumeSource(src *stokerv1alpha1.CABundleSource, mode *int32) corev1.VolumeSource {
+	if src.SecretRef != nil {
+		return corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  src.SecretRef.Name,
+				DefaultMode: mode,
+			},
+		}
+	}
+	return corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: src.ConfigMapRef.Name},
+			DefaultMode:          mode,
+		},
+	}
+}
+
 // agentVolumeMounts returns the volume mounts for the agent container.
 func agentVolumeMounts(gs *stokerv1alpha1.GatewaySync) []corev1.VolumeMount {
 	mounts := []corev1.VolumeMount{
 		{Name: volumeSyncRepo, MountPath: mountRepo},
 		{Name: volumeAPIKey, MountPath: mountAPIKey, ReadOnly: true},
 		{Name: volumeGitTmp, MountPath: "/tmp"},
+		{Name: volumePodInfo, MountPath: mountPodInfo, ReadOnly: true},
 	}
 	if needsGitCredentialVolume(gs) {
 		mounts = append(mounts, corev1.VolumeMount{
@@ -480,6 +692,31 @@ func agentVolumeMounts(gs *stokerv1alpha1.GatewaySync) []corev1.VolumeMount {
 			Name: volumeKnownHosts, MountPath: mountKnownHosts, ReadOnly: true,
 		})
 	}
+	if needsTrustedKeysVolume(gs) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name: volumeTrustedKeys, MountPath: mountTrustedKeys, ReadOnly: true,
+		})
+	}
+	if needsGatewayCAVolume(gs) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name: volumeGatewayCA, MountPath: mountGatewayCA, ReadOnly: true,
+		})
+	}
+	if needsBackupVolume(gs) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name: volumeBackups, MountPath: mountBackups,
+		})
+	}
+	if needsAuditLogVolume(gs) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name: volumeAuditLogs, MountPath: mountAuditLogs,
+		})
+	}
+	if needsAuditTokenVolume(gs) {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name: volumeAuditToken, MountPath: mountAuditToken, ReadOnly: true,
+		})
+	}
 	return mounts
 }
 
@@ -510,6 +747,19 @@ func agentVolumes(gs *stokerv1alpha1.GatewaySync) []corev1.Volume {
 				},
 			},
 		},
+		{
+			// The kubelet refreshes Downward API files when annotations change,
+			// so per-pod overrides apply without a restart.
+			Name: volumePodInfo,
+			VolumeSource: corev1.VolumeSource{
+				DownwardAPI: &corev1.DownwardAPIVolumeSource{
+					Items: []corev1.DownwardAPIVolumeFile{{
+						Path:     podAnnotationsFile,
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.annotations"},
+					}},
+				},
+			},
+		},
 	}
 	if needsGitCredentialVolume(gs) {
 		vols = append(vols, corev1.Volume{
@@ -544,6 +794,54 @@ func agentVolumes(gs *stokerv1alpha1.GatewaySync) []corev1.Volume {
 			},
 		})
 	}
+	if needsTrustedKeysVolume(gs) {
+		vols = append(vols, corev1.Volume{
+			Name: volumeTrustedKeys,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  gs.Spec.Git.Verification.TrustedKeysSecretName,
+					DefaultMode: &secretMode,
+				},
+			},
+		})
+	}
+	if needsGatewayCAVolume(gs) {
+		vols = append(vols, corev1.Volume{
+			Name:         volumeGatewayCA,
+			VolumeSource: gatewayCAVolumeSource(gs.Spec.Gateway.TLSTrust.CABundle, &secretMode),
+		})
+	}
+	if needsBackupVolume(gs) {
+		vols = append(vols, corev1.Volume{
+			Name: volumeBackups,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: gs.Spec.Gateway.Backup.ClaimName,
+				},
+			},
+		})
+	}
+	if needsAuditLogVolume(gs) {
+		vols = append(vols, corev1.Volume{
+			Name: volumeAuditLogs,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: gs.Spec.Agent.Audit.File.ClaimName,
+				},
+			},
+		})
+	}
+	if needsAuditTokenVolume(gs) {
+		vols = append(vols, corev1.Volume{
+			Name: volumeAuditToken,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  gs.Spec.Agent.Audit.HTTP.TokenSecretRef.Name,
+					DefaultMode: &secretMode,
+				},
+			},
+		})
+	}
 	return vols
 }
 
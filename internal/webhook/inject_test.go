@@ -3,6 +3,7 @@ package webhook
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	admissionv1 "k8s.io/api/admission/v1"
@@ -193,6 +194,26 @@ func TestInject_InvalidProfile(t *testing.T) {
 	assertContains(t, resp.Result.Message, "not found")
 }
 
+func TestInject_ReferencedProfile(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Sync.ProfileRefs = []stokerv1alpha1.ProfileRef{
+		{Kind: stokerv1alpha1.KindClusterSyncProfile, Name: "standard-site", As: "site"},
+	}
+	injector := newInjector(gs)
+
+	for profile, allowed := range map[string]bool{"site": true, "standard-site": false} {
+		pod := basePod(map[string]string{
+			stokertypes.AnnotationInject:  "true",
+			stokertypes.AnnotationCRName:  "my-sync",
+			stokertypes.AnnotationProfile: profile,
+		})
+		resp := injector.Handle(context.Background(), makeAdmissionRequest(pod))
+		if resp.Allowed != allowed {
+			t.Errorf("profile %q: expected allowed=%v, got %v (%s)", profile, allowed, resp.Allowed, resp.Result.Message)
+		}
+	}
+}
+
 func TestInject_AlreadyInjected(t *testing.T) {
 	injector := newInjector(testGatewaySync())
 
@@ -278,6 +299,67 @@ func TestInject_AutoDeriveCRName_MultipleCRs(t *testing.T) {
 	assertContains(t, resp.Result.Message, "multiple GatewaySync CRs")
 }
 
+func TestInject_CrossNamespace_Allowed(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.AllowedNamespaces = []string{"plant-1"}
+	injector := newInjector(gs)
+
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject:      "true",
+		stokertypes.AnnotationCRNamespace: testNamespace,
+	})
+	pod.Namespace = "plant-1"
+	req := makeAdmissionRequest(pod)
+	req.Namespace = "plant-1"
+	resp := injector.Handle(context.Background(), req)
+
+	if !resp.Allowed {
+		t.Fatalf("expected allowed for an allowed namespace, got: %s", resp.Result.Message)
+	}
+
+	patched := injectDirect(t, pod, gs)
+	assertEnvVar(t, findInitContainer(patched), "CR_NAMESPACE", testNamespace)
+}
+
+func TestInject_CrossNamespace_NotAllowed(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.AllowedNamespaces = []string{"plant-2"}
+	injector := newInjector(gs)
+
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject:      "true",
+		stokertypes.AnnotationCRName:      "my-sync",
+		stokertypes.AnnotationCRNamespace: testNamespace,
+	})
+	pod.Namespace = "plant-1"
+	req := makeAdmissionRequest(pod)
+	req.Namespace = "plant-1"
+	resp := injector.Handle(context.Background(), req)
+
+	if resp.Allowed {
+		t.Fatal("expected denied when namespace is not in spec.allowedNamespaces")
+	}
+	assertContains(t, resp.Result.Message, "does not list namespace 'plant-1'")
+}
+
+func TestInject_SameNamespace_CRNamespaceFromDownwardAPI(t *testing.T) {
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+	patched := injectDirect(t, pod, testGatewaySync())
+
+	for _, env := range findInitContainer(patched).Env {
+		if env.Name == "CR_NAMESPACE" {
+			if env.ValueFrom == nil || env.ValueFrom.FieldRef == nil || env.ValueFrom.FieldRef.FieldPath != "metadata.namespace" {
+				t.Errorf("expected CR_NAMESPACE from metadata.namespace, got %+v", env)
+			}
+			return
+		}
+	}
+	t.Error("env CR_NAMESPACE not found")
+}
+
 func TestInject_SSHAuth(t *testing.T) {
 	gs := testGatewaySync()
 	gs.Spec.Git.Auth = &stokerv1alpha1.GitAuthSpec{
@@ -642,6 +724,325 @@ func TestNeedsKnownHostsVolume(t *testing.T) {
 	}
 }
 
+func TestInject_GatewayTLSTrust_CABundleSecret(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Gateway.TLSTrust = &stokerv1alpha1.GatewayTLSTrust{
+		CABundle: &stokerv1alpha1.CABundleSource{
+			SecretRef: &stokerv1alpha1.SecretKeyRef{Name: "gateway-ca-secret", Key: "ca.crt"},
+		},
+		ServerName: "ignition.example.com",
+	}
+
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, gs)
+	agent := findInitContainer(patched)
+	if agent == nil {
+		t.Fatal("stoker-agent not found")
+	}
+
+	assertEnvVar(t, agent, "GATEWAY_CA_FILE", mountGatewayCA+"/ca.crt")
+	assertEnvVar(t, agent, "GATEWAY_TLS_SERVER_NAME", "ignition.example.com")
+	assertHasVolume(t, patched, volumeGatewayCA)
+	assertVolumeSecret(t, patched, volumeGatewayCA, "gateway-ca-secret")
+
+	for _, env := range agent.Env {
+		if env.Name == "GATEWAY_TLS_INSECURE" {
+			t.Error("GATEWAY_TLS_INSECURE should not be set when a CA bundle is configured")
+		}
+	}
+}
+
+func TestInject_GatewayTLSTrust_CABundleConfigMap(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Gateway.TLSTrust = &stokerv1alpha1.GatewayTLSTrust{
+		CABundle: &stokerv1alpha1.CABundleSource{
+			ConfigMapRef: &stokerv1alpha1.ConfigMapKeyRef{Name: "gateway-ca-bundle", Key: "bundle.pem"},
+		},
+	}
+
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, gs)
+	agent := findInitContainer(patched)
+	assertEnvVar(t, agent, "GATEWAY_CA_FILE", mountGatewayCA+"/bundle.pem")
+
+	found := false
+	for _, v := range patched.Spec.Volumes {
+		if v.Name == volumeGatewayCA {
+			found = true
+			if v.ConfigMap == nil || v.ConfigMap.Name != "gateway-ca-bundle" {
+				t.Errorf("gateway-ca volume should reference ConfigMap gateway-ca-bundle, got %+v", v.VolumeSource)
+			}
+		}
+	}
+	if !found {
+		t.Error("gateway-ca volume not found")
+	}
+}
+
+func TestInject_GatewayTLSTrust_Insecure(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Gateway.TLSTrust = &stokerv1alpha1.GatewayTLSTrust{InsecureSkipVerify: true}
+
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, gs)
+	agent := findInitContainer(patched)
+	assertEnvVar(t, agent, "GATEWAY_TLS_INSECURE", "true")
+
+	for _, v := range patched.Spec.Volumes {
+		if v.Name == volumeGatewayCA {
+			t.Error("gateway-ca volume should not be present without a CA bundle")
+		}
+	}
+}
+
+func TestInject_GatewayBackup(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Gateway.Backup = &stokerv1alpha1.GatewayBackupSpec{
+		ClaimName: "gateway-backups-pvc",
+		Paths:     []string{"config", "projects/global"},
+		Retention: &stokerv1alpha1.BackupRetention{MaxCount: 5, MaxAge: "720h"},
+	}
+
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, gs)
+	agent := findInitContainer(patched)
+	if agent == nil {
+		t.Fatal("stoker-agent not found")
+	}
+
+	assertEnvVar(t, agent, "BACKUP_DIR", mountBackups)
+	assertEnvVar(t, agent, "BACKUP_PATHS", "config,projects/global")
+	assertEnvVar(t, agent, "BACKUP_MAX_COUNT", "5")
+	assertEnvVar(t, agent, "BACKUP_MAX_AGE", "720h")
+
+	found := false
+	for _, v := range patched.Spec.Volumes {
+		if v.Name == volumeBackups {
+			found = true
+			if v.PersistentVolumeClaim == nil || v.PersistentVolumeClaim.ClaimName != "gateway-backups-pvc" {
+				t.Errorf("backup volume should reference PVC gateway-backups-pvc, got %+v", v.VolumeSource)
+			}
+		}
+	}
+	if !found {
+		t.Error("gateway-backups volume not found")
+	}
+}
+
+func TestInject_GatewayBackup_Disabled(t *testing.T) {
+	gs := testGatewaySync()
+	disabled := false
+	gs.Spec.Gateway.Backup = &stokerv1alpha1.GatewayBackupSpec{
+		Enabled:   &disabled,
+		ClaimName: "gateway-backups-pvc",
+	}
+
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, gs)
+	for _, v := range patched.Spec.Volumes {
+		if v.Name == volumeBackups {
+			t.Error("gateway-backups volume should not be present when backups are disabled")
+		}
+	}
+	for _, env := range findInitContainer(patched).Env {
+		if env.Name == "BACKUP_DIR" {
+			t.Error("BACKUP_DIR should not be set when backups are disabled")
+		}
+	}
+}
+
+func TestInject_GatewayBackup_DefaultMaxCount(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Gateway.Backup = &stokerv1alpha1.GatewayBackupSpec{ClaimName: "gateway-backups-pvc"}
+
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, gs)
+	agent := findInitContainer(patched)
+	if agent == nil {
+		t.Fatal("stoker-agent not found")
+	}
+	assertEnvVar(t, agent, "BACKUP_MAX_COUNT", "10")
+	for _, env := range agent.Env {
+		if env.Name == "BACKUP_MAX_AGE" {
+			t.Error("BACKUP_MAX_AGE should not be set without retention.maxAge")
+		}
+	}
+}
+
+func TestInject_PodInfoAnnotationsVolume(t *testing.T) {
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, testGatewaySync())
+	agent := findInitContainer(patched)
+	assertEnvVar(t, agent, "POD_ANNOTATIONS_FILE", mountPodInfo+"/"+podAnnotationsFile)
+
+	for _, v := range patched.Spec.Volumes {
+		if v.Name != volumePodInfo {
+			continue
+		}
+		if v.DownwardAPI == nil || len(v.DownwardAPI.Items) != 1 ||
+			v.DownwardAPI.Items[0].FieldRef.FieldPath != "metadata.annotations" {
+			t.Errorf("pod-info volume should project metadata.annotations, got %+v", v.VolumeSource)
+		}
+		return
+	}
+	t.Error("pod-info volume not found")
+}
+
+func TestInject_SyncHistoryLimit(t *testing.T) {
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+	gs := testGatewaySync()
+	gs.Spec.Agent.SyncHistoryLimit = 50
+
+	patched := injectDirect(t, pod, gs)
+	assertEnvVar(t, findInitContainer(patched), "SYNC_HISTORY_LIMIT", "50")
+}
+
+func TestInject_AuditSinks(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Agent.Audit = &stokerv1alpha1.AuditSpec{
+		Stdout: true,
+		File:   &stokerv1alpha1.AuditFileSink{ClaimName: "audit-pvc", MaxSizeMB: 20, MaxBackups: 3},
+		Syslog: &stokerv1alpha1.AuditSyslogSink{Address: "siem.example.com:6514", Protocol: "tcp"},
+		HTTP: &stokerv1alpha1.AuditHTTPSink{
+			URL:            "https://siem.example.com/collector",
+			TokenSecretRef: &stokerv1alpha1.SecretKeyRef{Name: "siem-token", Key: "token"},
+		},
+	}
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, gs)
+	agent := findInitContainer(patched)
+	assertEnvVar(t, agent, "AUDIT_STDOUT", "true")
+	assertEnvVar(t, agent, "AUDIT_FILE", mountAuditLogs+"/$(POD_NAME).log")
+	assertEnvVar(t, agent, "AUDIT_FILE_MAX_SIZE_MB", "20")
+	assertEnvVar(t, agent, "AUDIT_FILE_MAX_BACKUPS", "3")
+	assertEnvVar(t, agent, "AUDIT_SYSLOG_ADDRESS", "siem.example.com:6514")
+	assertEnvVar(t, agent, "AUDIT_SYSLOG_PROTOCOL", "tcp")
+	assertEnvVar(t, agent, "AUDIT_HTTP_URL", "https://siem.example.com/collector")
+	assertEnvVar(t, agent, "AUDIT_HTTP_TOKEN_FILE", mountAuditToken+"/token")
+	assertVolumeSecret(t, patched, volumeAuditToken, "siem-token")
+
+	found := false
+	for _, v := range patched.Spec.Volumes {
+		if v.Name == volumeAuditLogs {
+			found = true
+			if v.PersistentVolumeClaim == nil || v.PersistentVolumeClaim.ClaimName != "audit-pvc" {
+				t.Errorf("audit volume should reference PVC audit-pvc, got %+v", v.VolumeSource)
+			}
+		}
+	}
+	if !found {
+		t.Error("audit-logs volume not found")
+	}
+}
+
+func TestInject_AuditDisabled(t *testing.T) {
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, testGatewaySync())
+	for _, env := range findInitContainer(patched).Env {
+		if strings.HasPrefix(env.Name, "AUDIT_") {
+			t.Errorf("%s should not be set without spec.agent.audit", env.Name)
+		}
+	}
+}
+
+func TestInject_SignatureVerification(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Git.Verification = &stokerv1alpha1.GitVerificationSpec{
+		Policy:                "SignedTag",
+		TrustedKeysSecretName: "release-signers",
+	}
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, gs)
+	agent := findInitContainer(patched)
+	assertEnvVar(t, agent, "VERIFY_POLICY", "SignedTag")
+	assertEnvVar(t, agent, "VERIFY_KEYS_DIR", mountTrustedKeys)
+	assertVolumeSecret(t, patched, volumeTrustedKeys, "release-signers")
+
+	found := false
+	for _, vm := range agent.VolumeMounts {
+		if vm.Name == volumeTrustedKeys {
+			found = vm.MountPath == mountTrustedKeys && vm.ReadOnly
+		}
+	}
+	if !found {
+		t.Error("trusted-keys volume should be mounted read-only at " + mountTrustedKeys)
+	}
+
+	// Without verification no env vars or volume are injected.
+	patched = injectDirect(t, pod, testGatewaySync())
+	for _, env := range findInitContainer(patched).Env {
+		if strings.HasPrefix(env.Name, "VERIFY_") {
+			t.Errorf("%s should not be set without spec.git.verification", env.Name)
+		}
+	}
+	for _, v := range patched.Spec.Volumes {
+		if v.Name == volumeTrustedKeys {
+			t.Error("trusted-keys volume should not be added without spec.git.verification")
+		}
+	}
+}
+
+func TestInject_GitProxy(t *testing.T) {
+	pod := basePod(map[string]string{
+		stokertypes.AnnotationInject: "true",
+		stokertypes.AnnotationCRName: "my-sync",
+	})
+
+	patched := injectDirect(t, pod, testGatewaySync())
+	for _, env := range findInitContainer(patched).Env {
+		if env.Name == envGitProxyURL {
+			t.Errorf("%s should not be set when the controller has no proxy", envGitProxyURL)
+		}
+	}
+
+	t.Setenv(envGitProxyURL, "http://proxy.corp:3128")
+	patched = injectDirect(t, pod, testGatewaySync())
+	assertEnvVar(t, findInitContainer(patched), envGitProxyURL, "http://proxy.corp:3128")
+}
+
 // --- Helpers ---
 
 // injectDirect calls injectSidecar on a pod copy with the given CR for testing.
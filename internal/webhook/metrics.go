@@ -25,11 +25,22 @@ var (
 		},
 		[]string{"namespace", "result"},
 	)
+
+	webhookValidationsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "stoker",
+			Subsystem: "webhook_validator",
+			Name:      "validations_total",
+			Help:      "Total number of GatewaySync admission validations.",
+		},
+		[]string{"namespace", "result"},
+	)
 )
 
 func init() {
 	metrics.Registry.MustRegister(
 		webhookReceiverRequestsTotal,
 		webhookInjectorInjectionsTotal,
+		webhookValidationsTotal,
 	)
 }
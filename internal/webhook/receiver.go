@@ -27,7 +27,8 @@ const (
 )
 
 // Receiver is an HTTP server that receives webhook payloads and annotates
-// GatewaySync CRs with the requested ref. It implements manager.Runnable.
+// GatewaySync CRs with the requested ref, and serves gateway sync history.
+// It implements manager.Runnable.
 type Receiver struct {
 	Client      client.Client
 	HMACSecret  string
@@ -42,6 +43,7 @@ func (rv *Receiver) Start(ctx context.Context) error {
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST /webhook/{namespace}/{crName}", rv.handleWebhook)
+	mux.HandleFunc("GET /history/{namespace}/{crName}", rv.handleHistory)
 
 	addr := fmt.Sprintf(":%d", rv.Port)
 	server := &http.Server{
@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -88,6 +90,7 @@ func newTestReceiver(hmacSecret string, objects ...runtime.Object) (*Receiver, *
 func newTestReceiverFull(hmacSecret, bearerToken string, objects ...runtime.Object) (*Receiver, *http.ServeMux) {
 	scheme := runtime.NewScheme()
 	_ = stokerv1alpha1.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
 
 	fakeClient := fake.NewClientBuilder().
 		WithScheme(scheme).
@@ -104,6 +107,7 @@ func newTestReceiverFull(hmacSecret, bearerToken string, objects ...runtime.Obje
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST /webhook/{namespace}/{crName}", rv.handleWebhook)
+	mux.HandleFunc("GET /history/{namespace}/{crName}", rv.handleHistory)
 	return rv, mux
 }
 
@@ -349,3 +353,90 @@ func TestHandler_BearerTokenAuthorizesFallbackWhenHMACSet(t *testing.T) {
 		t.Fatalf("expected 202, got %d: bearer token should authorize when HMAC is also configured", w.Code)
 	}
 }
+
+// --- History endpoint tests ---
+
+func historyConfigMap(t *testing.T, gatewayName string, history ...stokertypes.SyncRecord) *corev1.ConfigMap {
+	t.Helper()
+	data, err := json.Marshal(stokertypes.GatewayStatus{SyncStatus: stokertypes.SyncStatusSynced, History: history})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stoker-status-my-sync-" + gatewayName,
+			Namespace: "default",
+			Labels: map[string]string{
+				stokertypes.LabelCRName:        "my-sync",
+				stokertypes.LabelGatewayStatus: "true",
+			},
+		},
+		Data: map[string]string{gatewayName: string(data)},
+	}
+}
+
+func TestHandler_History(t *testing.T) {
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	_, mux := newTestReceiverFull("", "bearer-token", testCR(),
+		historyConfigMap(t, "gw-a",
+			stokertypes.SyncRecord{Commit: "bbb", EndTime: recent, Result: stokertypes.SyncStatusSynced},
+			stokertypes.SyncRecord{Commit: "aaa", EndTime: old, Result: stokertypes.SyncStatusError},
+		),
+		historyConfigMap(t, "gw-b", stokertypes.SyncRecord{Commit: "bbb", EndTime: recent}),
+	)
+
+	get := func(path string) (int, []GatewayHistory) {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer bearer-token")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		var resp struct {
+			Gateways []GatewayHistory `json:"gateways"`
+		}
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+		}
+		return w.Code, resp.Gateways
+	}
+
+	code, gateways := get("/history/default/my-sync")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(gateways) != 2 || gateways[0].Name != "gw-a" || len(gateways[0].History) != 2 {
+		t.Errorf("unexpected history: %+v", gateways)
+	}
+
+	_, gateways = get("/history/default/my-sync?gateway=gw-a&since=24h")
+	if len(gateways) != 1 || len(gateways[0].History) != 1 || gateways[0].History[0].Commit != "bbb" {
+		t.Errorf("filtered history: %+v", gateways)
+	}
+
+	if code, _ := get("/history/default/my-sync?since=yesterday"); code != http.StatusBadRequest {
+		t.Errorf("invalid since: expected 400, got %d", code)
+	}
+	if code, _ := get("/history/default/nonexistent"); code != http.StatusNotFound {
+		t.Errorf("unknown CR: expected 404, got %d", code)
+	}
+
+	req := httptest.NewRequest("GET", "/history/default/my-sync", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("missing token: expected 401, got %d", w.Code)
+	}
+}
+
+func TestHandler_History_RequiresBearerToken(t *testing.T) {
+	for name, hmacSecret := range map[string]string{"no auth": "", "HMAC only": "hmac-secret"} {
+		_, mux := newTestReceiverFull(hmacSecret, "", testCR())
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/history/default/my-sync", nil))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403, got %d", name, w.Code)
+		}
+	}
+}
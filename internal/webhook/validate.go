@@ -0,0 +1,407 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	"github.com/ia-eknorr/stoker-operator/internal/profilespec"
+	"github.com/ia-eknorr/stoker-operator/internal/schedule"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+// defaultProfile is the profile agents use when a pod has no stoker.io/profile annotation.
+const defaultProfile = "default"
+
+// +kubebuilder:webhook:path=/validate-stoker-io-v1alpha1-gatewaysync,mutating=false,failurePolicy=fail,sideEffects=None,groups=stoker.io,resources=gatewaysyncs,verbs=create;update,versions=v1alpha1,name=vgatewaysync.stoker.io,admissionReviewVersions=v1
+
+// GatewaySyncValidator implements admission.Handler for GatewaySync create
+// and update. It rejects specs the controller would only report through the
+// ProfilesValid condition after the fact, so GitOps applies fail early.
+type GatewaySyncValidator struct {
+	Decoder admission.Decoder
+	// AllowAllNamespaces admits "*" in spec.allowedNamespaces. Off by default,
+	// since it lets a pod in any namespace claim the CR's mirrored Secrets.
+	AllowAllNamespaces bool
+}
+
+// Handle validates a GatewaySync and denies it with field-level causes.
+func (v *GatewaySyncValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	gs := &stokerv1alpha1.GatewaySync{}
+	if err := v.Decoder.Decode(req, gs); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	var old *stokerv1alpha1.GatewaySync
+	if req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		old = &stokerv1alpha1.GatewaySync{}
+		if err := v.Decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	// Updates that leave the spec alone, such as the controller removing its
+	// finalizer or annotations, are not held to rules added after the CR was
+	// created; otherwise such CRs could never be updated or deleted.
+	var errs field.ErrorList
+	var warnings admission.Warnings
+	if old == nil || (gs.DeletionTimestamp == nil && !equality.Semantic.DeepEqual(old.Spec, gs.Spec)) {
+		errs, warnings = ValidateGatewaySync(gs)
+		if !v.AllowAllNamespaces {
+			if i := slices.Index(gs.Spec.AllowedNamespaces, "*"); i >= 0 {
+				errs = append(errs, field.Forbidden(field.NewPath("spec", "allowedNamespaces").Index(i),
+					`"*" is disabled; list the namespaces, or have an administrator enable webhook.validation.allowAllNamespaces`))
+			}
+		}
+	}
+	if err := validateApprover(gs, old, req.UserInfo.Username); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		webhookValidationsTotal.WithLabelValues(req.Namespace, "denied").Inc()
+		logf.FromContext(ctx).WithName("gatewaysync-validator").Info("denied GatewaySync",
+			"name", gs.Name, "namespace", req.Namespace, "errors", errs.ToAggregate().Error())
+		status := apierrors.NewInvalid(stokerv1alpha1.GroupVersion.WithKind("GatewaySync").GroupKind(), gs.Name, errs).Status()
+		resp := admission.Denied(status.Message)
+		resp.Result = &status
+		return resp.WithWarnings(warnings...)
+	}
+	webhookValidationsTotal.WithLabelValues(req.Namespace, "allowed").Inc()
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// ValidateGatewaySync returns the spec errors that would fail a sync, and
+// warnings for specs that are valid but likely mistakes.
+func ValidateGatewaySync(gs *stokerv1alpha1.GatewaySync) (field.ErrorList, admission.Warnings) {
+	spec := field.NewPath("spec")
+	var errs field.ErrorList
+	var warnings admission.Warnings
+
+	errs = append(errs, validateGit(gs.Spec.Git, spec.Child("git"))...)
+
+	for i, ns := range gs.Spec.AllowedNamespaces {
+		if ns == "*" {
+			continue
+		}
+		for _, msg := range validation.IsDNS1123Label(ns) {
+			errs = append(errs, field.Invalid(spec.Child("allowedNamespaces").Index(i), ns, msg))
+		}
+	}
+
+	if interval := gs.Spec.Polling.Interval; interval != "" {
+		if d, err := time.ParseDuration(interval); err != nil || d <= 0 {
+			errs = append(errs, field.Invalid(spec.Child("polling", "interval"), interval,
+				`must be a positive duration (e.g., "60s", "5m")`))
+		}
+	}
+
+	if b := gs.Spec.Gateway.Backup; b != nil && b.Retention != nil && b.Retention.MaxAge != "" {
+		if d, err := time.ParseDuration(b.Retention.MaxAge); err != nil || d <= 0 {
+			errs = append(errs, field.Invalid(spec.Child("gateway", "backup", "retention", "maxAge"), b.Retention.MaxAge,
+				`must be a positive duration (e.g., "720h")`))
+		}
+	}
+
+	errs = append(errs, validateRollout(gs.Spec.Rollout, spec.Child("rollout"))...)
+	errs = append(errs, validateRollback(gs.Spec.Rollback, spec.Child("rollback"))...)
+	errs = append(errs, validateApproval(gs.Spec.Approval, spec.Child("approval"))...)
+
+	sync := spec.Child("sync")
+	errs = append(errs, validateSyncOptions(gs.Spec.Sync.Defaults.Vars, gs.Spec.Sync.Defaults.ExcludePatterns,
+		gs.Spec.Sync.Defaults.DesignerSessionPolicy, sync.Child("defaults"))...)
+
+	names := make([]string, 0, len(gs.Spec.Sync.Profiles))
+	for name := range gs.Spec.Sync.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		errs = append(errs, validateProfile(gs.Spec.Sync.Profiles[name], sync.Child("profiles").Key(name))...)
+	}
+	for i, ref := range gs.Spec.Sync.ProfileRefs {
+		name := ref.ProfileName()
+		rp := sync.Child("profileRefs").Index(i)
+		if slices.Contains(names, name) {
+			if _, inline := gs.Spec.Sync.Profiles[name]; !inline {
+				errs = append(errs, field.Duplicate(rp, name))
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"%s: inline profile %q shadows the referenced %s %q", rp, name, ref.ProfileKind(), ref.Name))
+			continue
+		}
+		names = append(names, name)
+	}
+	if !slices.Contains(names, defaultProfile) {
+		errs = append(errs, field.Required(sync.Child("profiles").Key(defaultProfile), fmt.Sprintf(
+			"gateway pods without the %s annotation use the %q profile; define it inline or through profileRefs",
+			stokertypes.AnnotationProfile, defaultProfile)))
+	}
+
+	if s := gs.Spec.Sync.Schedule; s != nil {
+		if _, err := schedule.New(schedule.Spec(s)); err != nil {
+			errs = append(errs, field.Invalid(sync.Child("schedule"), "", err.Error()))
+		}
+	}
+	return errs, warnings
+}
+
+// validateGit checks the repo URL, ref expression, auth, and verification settings.
+func validateGit(g stokerv1alpha1.GitSpec, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if strings.HasPrefix(g.Repo, "-") {
+		errs = append(errs, field.Invalid(path.Child("repo"), g.Repo, "must not start with '-'"))
+	}
+	if git.IsRefExpression(g.Ref) {
+		if err := git.ParseRefExpression(g.Ref); err != nil {
+			errs = append(errs, field.Invalid(path.Child("ref"), g.Ref, err.Error()))
+		}
+	}
+	errs = append(errs, validateAuth(g.Auth, path.Child("auth"))...)
+	if v := g.Verification; v != nil {
+		for _, msg := range validation.IsDNS1123Subdomain(v.TrustedKeysSecretName) {
+			errs = append(errs, field.Invalid(path.Child("verification", "trustedKeysSecretName"), v.TrustedKeysSecretName, msg))
+		}
+	}
+	return errs
+}
+
+// validateRollout checks selectors, wave percents, and maxUnavailable. Percent
+// waves are cumulative, so each must exceed the one before it.
+func validateRollout(r *stokerv1alpha1.RolloutSpec, path *field.Path) field.ErrorList {
+	if r == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if r.Canary != nil && r.Canary.Selector != nil {
+		errs = append(errs, metav1validation.ValidateLabelSelector(r.Canary.Selector,
+			metav1validation.LabelSelectorValidationOptions{}, path.Child("canary", "selector"))...)
+	}
+	var lastPercent int32
+	waveNames := map[string]bool{}
+	for i, wave := range r.Waves {
+		wp := path.Child("waves").Index(i)
+		if wave.Name != "" {
+			if waveNames[wave.Name] {
+				errs = append(errs, field.Duplicate(wp.Child("name"), wave.Name))
+			}
+			waveNames[wave.Name] = true
+		}
+		switch {
+		case (wave.Percent == nil) == (wave.Selector == nil):
+			errs = append(errs, field.Invalid(wp, "", "exactly one of percent or selector must be set"))
+		case wave.Percent != nil:
+			if p := *wave.Percent; p < 1 || p > 100 {
+				errs = append(errs, field.Invalid(wp.Child("percent"), p, "must be between 1 and 100"))
+			} else if p <= lastPercent {
+				errs = append(errs, field.Invalid(wp.Child("percent"), p,
+					fmt.Sprintf("is cumulative and must exceed the previous wave's %d", lastPercent)))
+			} else {
+				lastPercent = p
+			}
+		default:
+			errs = append(errs, metav1validation.ValidateLabelSelector(wave.Selector,
+				metav1validation.LabelSelectorValidationOptions{}, wp.Child("selector"))...)
+		}
+	}
+	if r.MaxUnavailable != nil {
+		if n, err := intstr.GetScaledValueFromIntOrPercent(r.MaxUnavailable, 100, true); err != nil || n < 1 {
+			errs = append(errs, field.Invalid(path.Child("maxUnavailable"), r.MaxUnavailable.String(),
+				`must be a positive count or percentage (e.g., 2 or "25%")`))
+		}
+	}
+	return errs
+}
+
+// validateRollback checks the error threshold and window.
+func validateRollback(r *stokerv1alpha1.RollbackSpec, path *field.Path) field.ErrorList {
+	if r == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	if r.ErrorThreshold != nil {
+		if n, err := intstr.GetScaledValueFromIntOrPercent(r.ErrorThreshold, 100, false); err != nil || n < 0 {
+			errs = append(errs, field.Invalid(path.Child("errorThreshold"), r.ErrorThreshold.String(),
+				`must be a non-negative count or percentage (e.g., 1 or "10%")`))
+		}
+	}
+	if r.Window != "" {
+		if d, err := time.ParseDuration(r.Window); err != nil || d <= 0 {
+			errs = append(errs, field.Invalid(path.Child("window"), r.Window,
+				`must be a positive duration (e.g., "10m")`))
+		}
+	}
+	return errs
+}
+
+// validateApproval rejects empty and duplicate approver names.
+func validateApproval(a *stokerv1alpha1.ApprovalSpec, path *field.Path) field.ErrorList {
+	if a == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	seen := map[string]bool{}
+	for i, name := range a.Approvers {
+		ap := path.Child("approvers").Index(i)
+		switch {
+		case strings.TrimSpace(name) == "":
+			errs = append(errs, field.Invalid(ap, name, "must not be empty"))
+		case seen[name]:
+			errs = append(errs, field.Duplicate(ap, name))
+		}
+		seen[name] = true
+	}
+	return errs
+}
+
+// validateApprover requires a new or changed approval to name the user
+// making the request, so stoker.io/approved-by is the approver's verified
+// identity rather than a name anyone with update rights can claim.
+func validateApprover(gs, old *stokerv1alpha1.GatewaySync, username string) *field.Error {
+	by := gs.Annotations[stokertypes.AnnotationApprovedBy]
+	commit := gs.Annotations[stokertypes.AnnotationApprovedCommit]
+	if by == "" && commit == "" {
+		return nil
+	}
+	if old != nil && old.Annotations[stokertypes.AnnotationApprovedBy] == by &&
+		old.Annotations[stokertypes.AnnotationApprovedCommit] == commit {
+		return nil
+	}
+	if strings.TrimSpace(by) != username {
+		return field.Forbidden(field.NewPath("metadata", "annotations").Key(stokertypes.AnnotationApprovedBy),
+			fmt.Sprintf("must be the requesting user %q", username))
+	}
+	return nil
+}
+
+// validateAuth rejects more than one git auth method.
+func validateAuth(auth *stokerv1alpha1.GitAuthSpec, path *field.Path) field.ErrorList {
+	if auth == nil {
+		return nil
+	}
+	var set []string
+	if auth.SSHKey != nil {
+		set = append(set, "sshKey")
+	}
+	if auth.GitHubApp != nil {
+		set = append(set, "githubApp")
+	}
+	if auth.Token != nil {
+		set = append(set, "token")
+	}
+	if len(set) > 1 {
+		return field.ErrorList{field.Forbidden(path,
+			fmt.Sprintf("only one of sshKey, githubApp, or token may be set, got %s", strings.Join(set, " and ")))}
+	}
+	return nil
+}
+
+// validateSyncOptions checks the fields shared by spec.sync.defaults and profiles.
+func validateSyncOptions(vars map[string]string, excludePatterns []string, designerSessionPolicy string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		if !profilespec.ValidVarKey(k) {
+			errs = append(errs, field.Invalid(path.Child("vars").Key(k), k,
+				"must be a valid identifier (letters, digits, underscores; dashes are not supported in template variable names)"))
+		}
+		errs = append(errs, validateTemplate(vars[k], path.Child("vars").Key(k))...)
+	}
+	for i, p := range excludePatterns {
+		if !doublestar.ValidatePattern(p) {
+			errs = append(errs, field.Invalid(path.Child("excludePatterns").Index(i), p, "invalid glob pattern"))
+		}
+	}
+	if designerSessionPolicy != "" && !slices.Contains([]string{"proceed", "wait", "fail"}, designerSessionPolicy) {
+		errs = append(errs, field.NotSupported(path.Child("designerSessionPolicy"), designerSessionPolicy,
+			[]string{"proceed", "wait", "fail"}))
+	}
+	return errs
+}
+
+// validateProfile checks a profile's options, mappings and patches.
+func validateProfile(p stokerv1alpha1.SyncProfileSpec, path *field.Path) field.ErrorList {
+	errs := validateSyncOptions(p.Vars, p.ExcludePatterns, p.DesignerSessionPolicy, path)
+	for i, m := range p.Mappings {
+		mp := path.Child("mappings").Index(i)
+		errs = append(errs, validateMappingPath(m.Source, mp.Child("source"))...)
+		errs = append(errs, validateMappingPath(m.Destination, mp.Child("destination"))...)
+		if m.Type != "" && m.Type != "dir" && m.Type != "file" {
+			errs = append(errs, field.NotSupported(mp.Child("type"), m.Type, []string{"dir", "file"}))
+		}
+		for j, patch := range m.Patches {
+			pp := mp.Child("patches").Index(j)
+			if patch.File != "" {
+				if !doublestar.ValidatePattern(patch.File) {
+					errs = append(errs, field.Invalid(pp.Child("file"), patch.File, "invalid glob pattern"))
+				} else if filepath.IsAbs(patch.File) || profilespec.ContainsTraversal(patch.File) {
+					errs = append(errs, field.Invalid(pp.Child("file"), patch.File,
+						"must be relative to the mapping destination, without path traversal (..)"))
+				}
+			}
+			setKeys := make([]string, 0, len(patch.Set))
+			for k := range patch.Set {
+				setKeys = append(setKeys, k)
+			}
+			slices.Sort(setKeys)
+			for _, k := range setKeys {
+				if strings.TrimSpace(k) == "" {
+					errs = append(errs, field.Invalid(pp.Child("set"), k, "paths must not be empty"))
+					continue
+				}
+				errs = append(errs, validateTemplate(patch.Set[k], pp.Child("set").Key(k))...)
+			}
+		}
+	}
+	return errs
+}
+
+// validateMappingPath rejects absolute paths, path traversal, and
+// unparsable templates in a mapping source or destination.
+func validateMappingPath(p string, path *field.Path) field.ErrorList {
+	if filepath.IsAbs(p) {
+		return field.ErrorList{field.Invalid(path, p, "absolute paths are not allowed")}
+	}
+	if profilespec.ContainsTraversal(p) {
+		return field.ErrorList{field.Invalid(path, p, "path traversal (..) is not allowed")}
+	}
+	return validateTemplate(p, path)
+}
+
+// validateTemplate rejects values that would fail to parse as Go templates
+// at sync time.
+func validateTemplate(v string, path *field.Path) field.ErrorList {
+	if !strings.Contains(v, "{{") {
+		return nil
+	}
+	if _, err := template.New("").Option("missingkey=error").Parse(v); err != nil {
+		return field.ErrorList{field.Invalid(path, v, fmt.Sprintf("invalid template: %v", err))}
+	}
+	return nil
+}
@@ -0,0 +1,408 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	stokerv1alpha1 "github.com/ia-eknorr/stoker-operator/api/v1alpha1"
+	"github.com/ia-eknorr/stoker-operator/internal/git"
+	stokertypes "github.com/ia-eknorr/stoker-operator/pkg/types"
+)
+
+func makeValidationRequest(gs *stokerv1alpha1.GatewaySync) admission.Request {
+	raw, _ := json.Marshal(gs)
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Namespace: testNamespace,
+			Name:      gs.Name,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+}
+
+func newValidator() *GatewaySyncValidator {
+	return &GatewaySyncValidator{Decoder: admission.NewDecoder(newScheme())}
+}
+
+// validGatewaySync returns testGatewaySync with the required default profile.
+func validGatewaySync() *stokerv1alpha1.GatewaySync {
+	gs := testGatewaySync()
+	gs.Spec.Sync.Profiles["default"] = stokerv1alpha1.SyncProfileSpec{
+		Mappings: []stokerv1alpha1.SyncMapping{{Source: "shared/", Destination: "config/"}},
+	}
+	return gs
+}
+
+func TestValidate_Valid(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Sync.Profiles["default"] = stokerv1alpha1.SyncProfileSpec{
+		Vars: map[string]string{"site": "{{.GatewayName}}"},
+		Mappings: []stokerv1alpha1.SyncMapping{{
+			Source:      "sites/{{.Vars.site}}/",
+			Destination: "config/",
+			Patches:     []stokerv1alpha1.MappingPatch{{File: "**/*.json", Set: map[string]string{"SystemName": "{{.GatewayName}}"}}},
+		}},
+	}
+
+	resp := newValidator().Handle(context.Background(), makeValidationRequest(gs))
+	if !resp.Allowed {
+		t.Fatalf("expected allowed, got %v", resp.Result)
+	}
+	if len(resp.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", resp.Warnings)
+	}
+}
+
+func TestValidate_Rejects(t *testing.T) {
+	cases := []struct {
+		name   string
+		mutate func(*stokerv1alpha1.GatewaySync)
+		field  string
+	}{
+		{
+			name: "source traversal",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Profiles["my-profile"].Mappings[0].Source = "../secrets/"
+			},
+			field: "spec.sync.profiles[my-profile].mappings[0].source",
+		},
+		{
+			name: "absolute destination",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Profiles["my-profile"].Mappings[0].Destination = "/etc/"
+			},
+			field: "spec.sync.profiles[my-profile].mappings[0].destination",
+		},
+		{
+			name: "unparsable destination template",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Profiles["my-profile"].Mappings[0].Destination = "config/{{.GatewayName"
+			},
+			field: "spec.sync.profiles[my-profile].mappings[0].destination",
+		},
+		{
+			name: "unparsable var template",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Defaults.Vars = map[string]string{"site": "{{ if }}"}
+			},
+			field: "spec.sync.defaults.vars[site]",
+		},
+		{
+			name: "var key with dash",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Profiles["my-profile"] = withProfile(gs, func(p *stokerv1alpha1.SyncProfileSpec) {
+					p.Vars = map[string]string{"site-name": "a"}
+				})
+			},
+			field: "spec.sync.profiles[my-profile].vars[site-name]",
+		},
+		{
+			name: "unparsable patch value",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Profiles["my-profile"] = withProfile(gs, func(p *stokerv1alpha1.SyncProfileSpec) {
+					p.Mappings[0].Patches = []stokerv1alpha1.MappingPatch{{File: "*.json", Set: map[string]string{"SystemName": "{{.GatewayName}"}}}
+				})
+			},
+			field: "spec.sync.profiles[my-profile].mappings[0].patches[0].set[SystemName]",
+		},
+		{
+			name: "bad patch glob",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Profiles["my-profile"] = withProfile(gs, func(p *stokerv1alpha1.SyncProfileSpec) {
+					p.Mappings[0].Patches = []stokerv1alpha1.MappingPatch{{File: "[*.json", Set: map[string]string{"a": "b"}}}
+				})
+			},
+			field: "spec.sync.profiles[my-profile].mappings[0].patches[0].file",
+		},
+		{
+			name: "unknown mapping type",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Profiles["my-profile"].Mappings[0].Type = "symlink"
+			},
+			field: "spec.sync.profiles[my-profile].mappings[0].type",
+		},
+		{
+			name: "unknown designer session policy",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Defaults.DesignerSessionPolicy = "ignore"
+			},
+			field: "spec.sync.defaults.designerSessionPolicy",
+		},
+		{
+			name: "malformed polling interval",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Polling.Interval = "60"
+			},
+			field: "spec.polling.interval",
+		},
+		{
+			name: "malformed backup maxAge",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Gateway.Backup = &stokerv1alpha1.GatewayBackupSpec{
+					ClaimName: "gateway-backups-pvc",
+					Retention: &stokerv1alpha1.BackupRetention{MaxCount: 10, MaxAge: "30d"},
+				}
+			},
+			field: "spec.gateway.backup.retention.maxAge",
+		},
+		{
+			name: "malformed rollback window",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Rollback = &stokerv1alpha1.RollbackSpec{Window: "ten minutes"}
+			},
+			field: "spec.rollback.window",
+		},
+		{
+			name: "negative rollback threshold",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				threshold := intstr.FromInt32(-1)
+				gs.Spec.Rollback = &stokerv1alpha1.RollbackSpec{ErrorThreshold: &threshold}
+			},
+			field: "spec.rollback.errorThreshold",
+		},
+		{
+			name: "malformed ref expression",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Git.Ref = git.RefSemverPrefix + "not-a-version"
+			},
+			field: "spec.git.ref",
+		},
+		{
+			name: "invalid trusted keys secret",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Git.Verification = &stokerv1alpha1.GitVerificationSpec{TrustedKeysSecretName: "Trusted_Keys"}
+			},
+			field: "spec.git.verification.trustedKeysSecretName",
+		},
+		{
+			name: "invalid canary selector",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Rollout = &stokerv1alpha1.RolloutSpec{Canary: &stokerv1alpha1.RolloutCanary{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "not valid"}},
+				}}
+			},
+			field: "spec.rollout.canary.selector.matchLabels",
+		},
+		{
+			name: "wave with percent and selector",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Rollout = &stokerv1alpha1.RolloutSpec{Waves: []stokerv1alpha1.RolloutWave{{
+					Percent:  ptr.To[int32](50),
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "edge"}},
+				}}}
+			},
+			field: "spec.rollout.waves[0]",
+		},
+		{
+			name: "decreasing wave percent",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Rollout = &stokerv1alpha1.RolloutSpec{Waves: []stokerv1alpha1.RolloutWave{
+					{Percent: ptr.To[int32](50)},
+					{Percent: ptr.To[int32](25)},
+				}}
+			},
+			field: "spec.rollout.waves[1].percent",
+		},
+		{
+			name: "duplicate wave name",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Rollout = &stokerv1alpha1.RolloutSpec{Waves: []stokerv1alpha1.RolloutWave{
+					{Name: "first", Percent: ptr.To[int32](25)},
+					{Name: "first", Percent: ptr.To[int32](50)},
+				}}
+			},
+			field: "spec.rollout.waves[1].name",
+		},
+		{
+			name: "zero max unavailable",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				maxUnavailable := intstr.FromString("0%")
+				gs.Spec.Rollout = &stokerv1alpha1.RolloutSpec{MaxUnavailable: &maxUnavailable}
+			},
+			field: "spec.rollout.maxUnavailable",
+		},
+		{
+			name: "duplicate approver",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Approval = &stokerv1alpha1.ApprovalSpec{Approvers: []string{"alice", "alice"}}
+			},
+			field: "spec.approval.approvers[1]",
+		},
+		{
+			name: "missing default profile",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				delete(gs.Spec.Sync.Profiles, "default")
+			},
+			field: "spec.sync.profiles[default]",
+		},
+		{
+			name: "conflicting auth",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Git.Auth.SSHKey = &stokerv1alpha1.SSHKeyAuth{SecretRef: stokerv1alpha1.SecretKeyRef{Name: "ssh", Key: "key"}}
+			},
+			field: "spec.git.auth",
+		},
+		{
+			name: "duplicate profile ref",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.ProfileRefs = []stokerv1alpha1.ProfileRef{
+					{Name: "standard-site", As: "site"},
+					{Kind: stokerv1alpha1.KindClusterSyncProfile, Name: "standard-site", As: "site"},
+				}
+			},
+			field: "spec.sync.profileRefs[1]",
+		},
+		{
+			name: "option-like repo URL",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Git.Repo = "--upload-pack=touch /tmp/pwned"
+			},
+			field: "spec.git.repo",
+		},
+		{
+			name: "invalid schedule",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.Sync.Schedule = &stokerv1alpha1.SyncSchedule{
+					Windows: []stokerv1alpha1.ScheduleWindow{{Start: "0 25 * * *", Duration: "1h"}},
+				}
+			},
+			field: "spec.sync.schedule",
+		},
+		{
+			name: "invalid allowed namespace",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.AllowedNamespaces = []string{"plant-2", "Plant_1"}
+			},
+			field: "spec.allowedNamespaces[1]",
+		},
+		{
+			name: "wildcard namespace without opt-in",
+			mutate: func(gs *stokerv1alpha1.GatewaySync) {
+				gs.Spec.AllowedNamespaces = []string{"plant-2", "*"}
+			},
+			field: "spec.allowedNamespaces[1]",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gs := validGatewaySync()
+			tc.mutate(gs)
+
+			resp := newValidator().Handle(context.Background(), makeValidationRequest(gs))
+			if resp.Allowed {
+				t.Fatal("expected denied")
+			}
+			if resp.Result.Code != http.StatusUnprocessableEntity || resp.Result.Reason != metav1.StatusReasonInvalid {
+				t.Errorf("expected 422 Invalid, got %d %s", resp.Result.Code, resp.Result.Reason)
+			}
+			if resp.Result.Details == nil || len(resp.Result.Details.Causes) == 0 || resp.Result.Details.Causes[0].Field != tc.field {
+				t.Errorf("expected cause on %s, got %+v", tc.field, resp.Result.Details)
+			}
+		})
+	}
+}
+
+func TestValidate_AllowAllNamespaces(t *testing.T) {
+	gs := validGatewaySync()
+	gs.Spec.AllowedNamespaces = []string{"*"}
+
+	v := newValidator()
+	v.AllowAllNamespaces = true
+	if resp := v.Handle(context.Background(), makeValidationRequest(gs)); !resp.Allowed {
+		t.Errorf("expected \"*\" to be allowed with the opt-in, got %v", resp.Result)
+	}
+}
+
+func TestValidate_UpdateWithUnchangedSpec(t *testing.T) {
+	// Valid before a default profile was required.
+	old := testGatewaySync()
+	old.Finalizers = []string{"stoker.io/finalizer"}
+	update := func(gs *stokerv1alpha1.GatewaySync) admission.Response {
+		req := makeValidationRequest(gs)
+		req.Operation = admissionv1.Update
+		req.OldObject.Raw, _ = json.Marshal(old)
+		return newValidator().Handle(context.Background(), req)
+	}
+
+	gs := old.DeepCopy()
+	gs.Finalizers = nil
+	if resp := update(gs); !resp.Allowed {
+		t.Errorf("removing a finalizer should be allowed, got %v", resp.Result)
+	}
+
+	gs = old.DeepCopy()
+	gs.Spec.Paused = true
+	if resp := update(gs); resp.Allowed {
+		t.Error("changing the spec of an invalid CR should be validated")
+	}
+
+	now := metav1.Now()
+	gs.DeletionTimestamp = &now
+	if resp := update(gs); !resp.Allowed {
+		t.Errorf("updates to a CR being deleted should be allowed, got %v", resp.Result)
+	}
+}
+
+func TestValidate_Approver(t *testing.T) {
+	approve := func(gs *stokerv1alpha1.GatewaySync, by string) *stokerv1alpha1.GatewaySync {
+		gs = gs.DeepCopy()
+		gs.Annotations = map[string]string{
+			stokertypes.AnnotationApprovedBy:     by,
+			stokertypes.AnnotationApprovedCommit: "4f2a9c1",
+		}
+		return gs
+	}
+	update := func(old, gs *stokerv1alpha1.GatewaySync, username string) admission.Response {
+		req := makeValidationRequest(gs)
+		req.Operation = admissionv1.Update
+		req.UserInfo = authenticationv1.UserInfo{Username: username}
+		req.OldObject.Raw, _ = json.Marshal(old)
+		return newValidator().Handle(context.Background(), req)
+	}
+	gs := validGatewaySync()
+
+	if resp := update(gs, approve(gs, "jane"), "jane"); !resp.Allowed {
+		t.Errorf("approving as yourself should be allowed, got %v", resp.Result)
+	}
+	resp := update(gs, approve(gs, "jane"), "mallory")
+	if resp.Allowed {
+		t.Fatal("approving in another user's name should be denied")
+	}
+	if resp.Result.Details == nil || len(resp.Result.Details.Causes) == 0 ||
+		resp.Result.Details.Causes[0].Field != "metadata.annotations[stoker.io/approved-by]" {
+		t.Errorf("expected a cause on the approved-by annotation, got %+v", resp.Result.Details)
+	}
+	// Other changes leave an existing approval alone.
+	if resp := update(approve(gs, "jane"), approve(gs, "jane"), "ci-bot"); !resp.Allowed {
+		t.Errorf("an unchanged approval should be allowed, got %v", resp.Result)
+	}
+}
+
+func TestValidate_DefaultProfileFromRef(t *testing.T) {
+	gs := testGatewaySync()
+	gs.Spec.Sync.ProfileRefs = []stokerv1alpha1.ProfileRef{{Kind: stokerv1alpha1.KindClusterSyncProfile, Name: "standard-site", As: "default"}}
+
+	resp := newValidator().Handle(context.Background(), makeValidationRequest(gs))
+	if !resp.Allowed || len(resp.Warnings) != 0 {
+		t.Errorf("a referenced default profile should be accepted without warnings, got %v %v", resp.Result, resp.Warnings)
+	}
+}
+
+// withProfile returns a copy of my-profile with its mappings copied, changed by fn.
+func withProfile(gs *stokerv1alpha1.GatewaySync, fn func(*stokerv1alpha1.SyncProfileSpec)) stokerv1alpha1.SyncProfileSpec {
+	p := gs.Spec.Sync.Profiles["my-profile"]
+	p.Mappings = append([]stokerv1alpha1.SyncMapping(nil), p.Mappings...)
+	fn(&p)
+	return p
+}
@@ -8,7 +8,8 @@ const (
 	// TypeRefResolved indicates whether the git ref has been resolved to a commit SHA.
 	TypeRefResolved = "RefResolved"
 
-	// TypeProfilesValid indicates whether all embedded profiles pass validation.
+	// TypeProfilesValid indicates whether all embedded and referenced profiles
+	// resolve and pass validation.
 	TypeProfilesValid = "ProfilesValid"
 
 	// TypeAllGatewaysSynced indicates whether all discovered gateways have completed sync.
@@ -19,6 +20,27 @@ const (
 
 	// TypeSSHHostKeyVerification indicates whether SSH host key verification is enabled.
 	TypeSSHHostKeyVerification = "SSHHostKeyVerification"
+
+	// TypeGatewayTLSVerification indicates whether the agent verifies the gateway TLS certificate.
+	TypeGatewayTLSVerification = "GatewayTLSVerification"
+
+	// TypeRefSkew indicates whether any gateway is pinned to a ref other than spec.git.ref.
+	TypeRefSkew = "RefSkew"
+
+	// TypeCommitVerified indicates whether the resolved commit (or tag) carries a trusted signature.
+	TypeCommitVerified = "CommitVerified"
+
+	// TypeRolloutProgressing indicates whether a staged rollout (spec.rollout) is in progress.
+	TypeRolloutProgressing = "RolloutProgressing"
+
+	// TypeRolledBack indicates whether gateways are pinned back by an automatic rollback.
+	TypeRolledBack = "RolledBack"
+
+	// TypeAwaitingApproval indicates whether a resolved commit is waiting for approval (spec.approval).
+	TypeAwaitingApproval = "AwaitingApproval"
+
+	// TypeDeferred indicates whether a new commit is held until the next spec.sync.schedule window.
+	TypeDeferred = "Deferred"
 )
 
 // Condition reasons for GatewaySync status.conditions[].reason
@@ -32,6 +54,7 @@ const (
 	ReasonNoGateways                  = "NoGatewaysDiscovered"
 	ReasonProfilesValid               = "ProfilesValid"
 	ReasonProfilesInvalid             = "ProfilesInvalid"
+	ReasonProfileRefsUnresolved       = "ProfileRefsUnresolved"
 	ReasonValidationPassed            = "ValidationPassed"
 	ReasonValidationFailed            = "ValidationFailed"
 	ReasonSidecarMissing              = "SidecarMissing"
@@ -39,6 +62,30 @@ const (
 	ReasonGitHubAppExchangeFailed     = "GitHubAppExchangeFailed"
 	ReasonHostKeyVerificationDisabled = "HostKeyVerificationDisabled"
 	ReasonHostKeyVerificationEnabled  = "HostKeyVerificationEnabled"
+	ReasonTLSVerificationDisabled     = "TLSVerificationDisabled"
+	ReasonTLSVerificationEnabled      = "TLSVerificationEnabled"
+	ReasonRefOverrideActive           = "RefOverrideActive"
+	ReasonNoRefSkew                   = "NoRefSkew"
+	ReasonSignatureVerified           = "SignatureVerified"
+	ReasonSignatureVerificationFailed = "SignatureVerificationFailed"
+	ReasonRolloutProgressing          = "RolloutProgressing"
+	ReasonRolloutHalted               = "RolloutHalted"
+	ReasonRolloutComplete             = "RolloutComplete"
+	ReasonErrorThresholdExceeded      = "ErrorThresholdExceeded"
+	ReasonNotRolledBack               = "NotRolledBack"
+	ReasonRollbackWindowInvalid       = "RollbackWindowInvalid"
+	ReasonAwaitingApproval            = "AwaitingApproval"
+	ReasonApprovalRejected            = "ApprovalRejected"
+	ReasonApproved                    = "Approved"
+	ReasonOutsideWindow               = "OutsideWindow"
+	ReasonBlackout                    = "Blackout"
+	ReasonWindowOpen                  = "WindowOpen"
+	ReasonScheduleOverridden          = "ScheduleOverridden"
+	ReasonScheduleInvalid             = "ScheduleInvalid"
+	ReasonNoNamespacesSelected        = "NoNamespacesSelected"
+	ReasonNamespaceSelectorInvalid    = "NamespaceSelectorInvalid"
+	ReasonChildrenNotReady            = "ChildrenNotReady"
+	ReasonChildrenReady               = "ChildrenReady"
 )
 
 // Event reasons for K8s Events (not used as condition reasons).
@@ -49,4 +96,23 @@ const (
 	ReasonDesignerSessionsBlocked = "DesignerSessionsBlocked"
 	ReasonWebhookReceived         = "WebhookReceived"
 	ReasonCloneFailed             = "CloneFailed"
+	ReasonResyncRequested         = "ResyncRequested"
+	ReasonResyncCompleted         = "ResyncCompleted"
+	ReasonResyncFailed            = "ResyncFailed"
+	ReasonBackupCreated           = "BackupCreated"
+	ReasonBackupFailed            = "BackupFailed"
+	ReasonBackupUnavailable       = "BackupUnavailable"
+	ReasonProfileSwitched         = "ProfileSwitched"
+	ReasonNewCommit               = "NewCommit"
+	ReasonRolloutStarted          = "RolloutStarted"
+	ReasonRolloutStepCompleted    = "RolloutStepCompleted"
+	ReasonRolloutCompleted        = "RolloutCompleted"
+	ReasonRolledBack              = "RolledBack"
+	ReasonRollbackAcknowledged    = "RollbackAcknowledged"
+	ReasonApprovalRequested       = "ApprovalRequested"
+	ReasonCommitApproved          = "CommitApproved"
+	ReasonCommitDeferred          = "CommitDeferred"
+	ReasonChildCreated            = "ChildCreated"
+	ReasonChildDeleted            = "ChildDeleted"
+	ReasonChildConflict           = "ChildConflict"
 )
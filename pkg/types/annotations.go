@@ -9,10 +9,15 @@ const (
 	// AnnotationInject enables sidecar injection when set to "true".
 	AnnotationInject = AnnotationPrefix + "/inject"
 
-	// AnnotationCRName identifies which GatewaySync CR in this namespace to use.
-	// Auto-derived if exactly one CR exists in the namespace.
+	// AnnotationCRName identifies which GatewaySync CR to use.
+	// Auto-derived if exactly one CR exists in the CR namespace.
 	AnnotationCRName = AnnotationPrefix + "/cr-name"
 
+	// AnnotationCRNamespace names the namespace of the GatewaySync CR when it
+	// is not the pod's own. The CR must list the pod's namespace in
+	// spec.allowedNamespaces.
+	AnnotationCRNamespace = AnnotationPrefix + "/cr-namespace"
+
 	// AnnotationGatewayName overrides gateway identity (defaults to pod label app.kubernetes.io/name).
 	AnnotationGatewayName = AnnotationPrefix + "/gateway-name"
 
@@ -21,10 +26,10 @@ const (
 	AnnotationProfile = AnnotationPrefix + "/profile"
 
 	// AnnotationRefOverride overrides the git ref for this pod only.
-	// Read by the agent sidecar, NOT the controller. The agent resolves
-	// the ref independently via ls-remote and syncs to that commit instead
-	// of the metadata ConfigMap's ref. The controller detects the skew
-	// (syncedRef != lastSyncRef) and sets a RefSkew warning condition.
+	// The agent reads it live from a Downward API file, resolves the ref
+	// independently via ls-remote, and syncs that commit instead of the
+	// metadata ConfigMap's. The controller never resolves it; it reports the
+	// pinned gateways in status and sets a RefSkew warning condition.
 	// Intended for dev/test gateways in production namespaces.
 	AnnotationRefOverride = AnnotationPrefix + "/ref-override"
 
@@ -40,6 +45,31 @@ const (
 	// AnnotationRequestedBy records the source of the webhook request (e.g., "argocd", "kargo", "github").
 	AnnotationRequestedBy = AnnotationPrefix + "/requested-by"
 
+	// CR annotations — set by users on the GatewaySync CR.
+
+	// AnnotationRollbackAcknowledged releases an automatic rollback: the
+	// controller resumes publishing the commit spec.git.ref resolves to and
+	// removes the annotation. Any non-empty value acknowledges.
+	AnnotationRollbackAcknowledged = AnnotationPrefix + "/rollback-acknowledged"
+
+	// AnnotationApprovedBy names who approves the pending commit when
+	// spec.approval is set: the Kubernetes username of whoever sets it, which
+	// the validating webhook enforces. Must be one of spec.approval.approvers
+	// when listed.
+	AnnotationApprovedBy = AnnotationPrefix + "/approved-by"
+
+	// AnnotationApprovedCommit is the pending commit being approved, as a full
+	// or abbreviated (7+ character) SHA, so an approval never applies to a
+	// commit the approver has not seen. The controller removes both approval
+	// annotations once the approval is recorded.
+	AnnotationApprovedCommit = AnnotationPrefix + "/approved-commit"
+
+	// AnnotationScheduleOverride bypasses spec.sync.schedule for an
+	// emergency change: while set, the controller publishes and agents apply
+	// new commits outside windows and blackouts. The value records who and
+	// why (e.g., "jane: line 3 alarm fix"). Remove it when done.
+	AnnotationScheduleOverride = AnnotationPrefix + "/schedule-override"
+
 	// Webhook injection annotations — set by the webhook on injected pods.
 
 	// AnnotationInjected is set by the webhook after successful injection for tracking.
@@ -54,6 +84,19 @@ const (
 	// LabelCRName is used on owned resources (PVCs, ConfigMaps, Secrets) to identify the parent CR.
 	LabelCRName = AnnotationPrefix + "/cr-name"
 
+	// LabelCRNamespace is used with LabelCRName on resources the controller
+	// creates outside the CR's namespace (agent RoleBindings, mirrored Secrets).
+	LabelCRNamespace = AnnotationPrefix + "/cr-namespace"
+
+	// LabelGatewayStatus is set to "true" on the per-gateway status ConfigMaps
+	// written by agents, so the controller can list them by CR.
+	LabelGatewayStatus = AnnotationPrefix + "/gateway-status"
+
+	// LabelRemoteAgent is set to "true" on the Roles and RoleBindings that
+	// grant a gateway ServiceAccount outside the CR's namespace access to the
+	// CR's metadata ConfigMap and its own status ConfigMaps.
+	LabelRemoteAgent = AnnotationPrefix + "/remote-agent"
+
 	// AnnotationSecretType annotates controller-managed Secrets with their purpose.
 	AnnotationSecretType = AnnotationPrefix + "/secret-type"
 
@@ -65,6 +108,10 @@ const (
 	// Applied to namespaces: kubectl label namespace site1 stoker.io/injection=enabled
 	LabelNamespaceInjection = AnnotationPrefix + "/injection"
 
+	// LabelGatewaySyncSet is set on GatewaySyncs created by a GatewaySyncSet
+	// to the name of the set.
+	LabelGatewaySyncSet = AnnotationPrefix + "/gatewaysyncset"
+
 	// Finalizer
 
 	// Finalizer is added to GatewaySync CRs to ensure cleanup on deletion.
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// MetadataConfigMapName returns the metadata ConfigMap name for a CR.
+func MetadataConfigMapName(crName string) string {
+	return fmt.Sprintf("stoker-metadata-%s", crName)
+}
+
+// StatusConfigMapName returns the status ConfigMap name for one gateway of a CR.
+// Each agent writes its own ConfigMap so status updates never contend. The
+// controller finds them by label. gatewayNamespace is the pod's namespace for
+// a gateway outside the CR's namespace and empty otherwise, so plants running
+// the same chart in different namespaces do not share a ConfigMap. The names
+// may contain "-", so a hash of all three keeps CR "a" with gateway "b-c"
+// apart from CR "a-b" with gateway "c".
+func StatusConfigMapName(crName, gatewayNamespace, gatewayName string) string {
+	gateway := gatewayName
+	if gatewayNamespace != "" {
+		gateway = gatewayNamespace + "-" + gatewayName
+	}
+	sum := sha256.Sum256([]byte(crName + "/" + gatewayNamespace + "/" + gatewayName))
+	return BoundedName(fmt.Sprintf("stoker-status-%s-%s-%s", crName, gateway, hex.EncodeToString(sum[:])[:8]))
+}
+
+// BoundedName fits name to the 253-character object name limit. Longer names
+// are truncated and end in a hash of the full name, so they stay unique.
+func BoundedName(name string) string {
+	if len(name) <= validation.DNS1123SubdomainMaxLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:])[:10]
+	prefix := strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength-len(suffix)-1], "-.")
+	return prefix + "-" + suffix
+}
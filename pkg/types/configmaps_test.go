@@ -0,0 +1,36 @@
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestStatusConfigMapName(t *testing.T) {
+	if got := StatusConfigMapName("my-sync", "", "gw-0"); !strings.HasPrefix(got, "stoker-status-my-sync-gw-0-") {
+		t.Errorf("short names should be kept readable, got %q", got)
+	}
+	if StatusConfigMapName("a", "", "b-c") == StatusConfigMapName("a-b", "", "c") {
+		t.Error("CR and gateway names split differently should not collide")
+	}
+	if got := StatusConfigMapName("site", "plant-1", "ignition-0"); !strings.HasPrefix(got, "stoker-status-site-plant-1-ignition-0-") ||
+		got == StatusConfigMapName("site", "plant-2", "ignition-0") || got == StatusConfigMapName("site", "", "ignition-0") {
+		t.Errorf("remote gateways should get a ConfigMap per namespace, got %q", got)
+	}
+
+	crName := strings.Repeat("c", 200)
+	a := StatusConfigMapName(crName, "", strings.Repeat("g", 60)+"-a")
+	b := StatusConfigMapName(crName, "", strings.Repeat("g", 60)+"-b")
+	for _, name := range []string{a, b} {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			t.Errorf("%q is not a valid ConfigMap name: %v", name, errs)
+		}
+	}
+	if a == b {
+		t.Errorf("truncated names should stay unique, both are %q", a)
+	}
+	if a != StatusConfigMapName(crName, "", strings.Repeat("g", 60)+"-a") {
+		t.Error("truncated name should be stable")
+	}
+}
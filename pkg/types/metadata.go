@@ -10,6 +10,9 @@ type ResolvedProfile struct {
 	DryRun                bool              `json:"dryRun"`
 	DesignerSessionPolicy string            `json:"designerSessionPolicy"`
 	Paused                bool              `json:"paused"`
+	// Library is the SyncProfile or ClusterSyncProfile version the profile
+	// came from, as Kind/name@generation. Empty for inline profiles.
+	Library string `json:"library,omitempty"`
 }
 
 // ResolvedMapping is a source->destination mapping in a resolved profile.
@@ -30,3 +33,35 @@ type ResolvedPatch struct {
 	File string            `json:"file,omitempty"`
 	Set  map[string]string `json:"set"`
 }
+
+// GatewayTarget is the commit published to one gateway during a staged
+// rollout, serialized as JSON into the metadata ConfigMap's "gatewayTargets"
+// key (keyed by gateway name). Gateways without an entry follow the
+// metadata "commit" and "ref".
+type GatewayTarget struct {
+	Commit string `json:"commit"`
+	Ref    string `json:"ref"`
+}
+
+// SyncSchedule is spec.sync.schedule, serialized as JSON into the metadata
+// ConfigMap's "schedule" key so agents defer applying new commits outside
+// its windows too.
+type SyncSchedule struct {
+	Windows   []ScheduleWindow   `json:"windows,omitempty"`
+	TimeZone  string             `json:"timeZone,omitempty"`
+	Blackouts []ScheduleBlackout `json:"blackouts,omitempty"`
+}
+
+// ScheduleWindow opens on a 5-field cron schedule for Duration.
+type ScheduleWindow struct {
+	Start    string `json:"start"`
+	Duration string `json:"duration"`
+}
+
+// ScheduleBlackout is a change freeze from Start to End: dates (End
+// inclusive) or RFC 3339 instants (End exclusive).
+type ScheduleBlackout struct {
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Reason string `json:"reason,omitempty"`
+}
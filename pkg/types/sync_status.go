@@ -14,7 +14,7 @@ const (
 )
 
 // GatewayStatus is the JSON payload each sync agent writes
-// as a value in ConfigMap stoker-status-{crName}.
+// as a value in its own ConfigMap (see StatusConfigMapName).
 // Key = gateway name, Value = JSON of this struct.
 type GatewayStatus struct {
 	// SyncStatus is the current sync state (Pending, Syncing, Synced, Error).
@@ -26,6 +26,9 @@ type GatewayStatus struct {
 	// SyncedRef is the git ref currently synced to this gateway.
 	SyncedRef string `json:"syncedRef"`
 
+	// SyncedCommitInfo describes the synced commit, read from the agent's clone.
+	SyncedCommitInfo *CommitInfo `json:"syncedCommitInfo,omitempty"`
+
 	// LastSyncTime is when this gateway was last synced (RFC3339 format).
 	LastSyncTime string `json:"lastSyncTime"`
 
@@ -44,12 +47,29 @@ type GatewayStatus struct {
 	// ProjectsSynced lists the Ignition project names synced to this gateway.
 	ProjectsSynced []string `json:"projectsSynced"`
 
+	// LastBackup is the file name of the most recent pre-change gateway backup.
+	LastBackup string `json:"lastBackup,omitempty"`
+
+	// GatewayVersion is the detected Ignition gateway version (e.g., "8.1.33").
+	GatewayVersion string `json:"gatewayVersion,omitempty"`
+
 	// ErrorMessage contains error details if SyncStatus is Error.
 	ErrorMessage string `json:"errorMessage,omitempty"`
 
 	// ProfileName is the name of the sync profile used for this sync.
 	ProfileName string `json:"profileName,omitempty"`
 
+	// ProfileLibrary is the library version of that profile
+	// (ResolvedProfile.Library). Empty for inline profiles.
+	ProfileLibrary string `json:"profileLibrary,omitempty"`
+
+	// PreviousProfileName is the profile in use before the most recent
+	// stoker.io/profile switch. Empty if the profile has not changed.
+	PreviousProfileName string `json:"previousProfileName,omitempty"`
+
+	// ProfileChangedTime is when the most recent profile switch was applied (RFC3339 format).
+	ProfileChangedTime string `json:"profileChangedTime,omitempty"`
+
 	// DryRun indicates the sync was a dry-run (no files written to live dir).
 	DryRun bool `json:"dryRun,omitempty"`
 
@@ -64,4 +84,77 @@ type GatewayStatus struct {
 
 	// DesignerSessionsBlocked indicates the agent is waiting for designer sessions to close.
 	DesignerSessionsBlocked bool `json:"designerSessionsBlocked,omitempty"`
+
+	// History holds the most recent sync records, newest first. The agent
+	// keeps up to spec.agent.syncHistoryLimit entries (default 20).
+	History []SyncRecord `json:"history,omitempty"`
+}
+
+// Sync trigger values recorded in SyncRecord.Trigger.
+const (
+	// SyncTriggerInitial is the blocking sync before the gateway starts.
+	SyncTriggerInitial = "initial"
+
+	// SyncTriggerPostCommission is the re-sync after the gateway first becomes responsive.
+	SyncTriggerPostCommission = "post-commission"
+
+	// SyncTriggerCommit is a sync of a new commit on the tracked ref.
+	SyncTriggerCommit = "commit"
+
+	// SyncTriggerProfileSwitch is a re-plan after the stoker.io/profile annotation changed.
+	SyncTriggerProfileSwitch = "profile-switch"
+
+	// SyncTriggerProfileUpdate is a re-sync after spec.sync.profiles changed.
+	SyncTriggerProfileUpdate = "profile-update"
+
+	// SyncTriggerResync is a manual resync; the mode is appended (e.g. "resync:full").
+	SyncTriggerResync = "resync"
+)
+
+// CommitInfo describes a git commit.
+type CommitInfo struct {
+	Author    string `json:"author,omitempty"`
+	Committer string `json:"committer,omitempty"`
+	Subject   string `json:"subject,omitempty"`
+	// Time is the commit (committer) time (RFC3339 format).
+	Time string `json:"time,omitempty"`
+}
+
+// SyncRecord is one entry in a gateway's sync history.
+type SyncRecord struct {
+	// Commit is the git commit SHA synced (or attempted).
+	Commit string `json:"commit"`
+
+	// Ref is the git ref synced.
+	Ref string `json:"ref,omitempty"`
+
+	// Profile is the sync profile used.
+	Profile string `json:"profile,omitempty"`
+
+	// Trigger is what started the sync (see the SyncTrigger constants).
+	Trigger string `json:"trigger,omitempty"`
+
+	// StartTime is when the sync started (RFC3339 format).
+	StartTime string `json:"startTime"`
+
+	// EndTime is when the sync finished (RFC3339 format).
+	EndTime string `json:"endTime"`
+
+	// Result is the resulting sync status (Pending, Synced, Error).
+	Result string `json:"result"`
+
+	// FilesAdded is the number of files created in the live directory.
+	FilesAdded int32 `json:"filesAdded,omitempty"`
+
+	// FilesModified is the number of files changed in the live directory.
+	FilesModified int32 `json:"filesModified,omitempty"`
+
+	// FilesDeleted is the number of files removed from the live directory.
+	FilesDeleted int32 `json:"filesDeleted,omitempty"`
+
+	// ScanResult summarizes the Ignition scan API response.
+	ScanResult string `json:"scanResult,omitempty"`
+
+	// Error contains error details if Result is Error.
+	Error string `json:"error,omitempty"`
 }